@@ -5,6 +5,8 @@ import (
 	"github.com/KaminurOrynbek/e-commerce_microservices/api-gateway/config"
 	"github.com/KaminurOrynbek/e-commerce_microservices/api-gateway/internal/handler"
 	"github.com/KaminurOrynbek/e-commerce_microservices/api-gateway/internal/middleware"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"log"
 )
@@ -15,10 +17,15 @@ func main() {
 	inventoryHandler := handler.NewInventoryHandler(cfg.Services.InventoryServiceURL)
 	orderHandler := handler.NewOrderHandler(cfg.Services.OrderServiceURL)
 
-	router := gin.Default()
+	// Errors are answered as problem details, which only show internal
+	// errors when PROBLEM_VERBOSE is set.
+	problem.SetVerbose(cfg.Problem.Verbose)
+	router := gin.New()
 
 	router.Use(middleware.Logger())
+	router.Use(ginproblem.RequestID(), ginproblem.Recovery())
 	router.Use(middleware.AuthMiddleware())
+	router.NoRoute(ginproblem.NoRoute)
 
 	api := router.Group("/api")
 	{
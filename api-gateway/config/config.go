@@ -4,11 +4,13 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
 )
 
 type Config struct {
 	Server   *ServerConfig
 	Services *ServicesConfig
+	Problem  *ProblemConfig
 }
 
 type ServerConfig struct {
@@ -20,6 +22,13 @@ type ServicesConfig struct {
 	OrderServiceURL     string
 }
 
+// ProblemConfig sets whether error responses show the detail of internal
+// errors, which may expose internals of the gateway or the services. It is
+// off unless asked for.
+type ProblemConfig struct {
+	Verbose bool
+}
+
 func NewConfig() *Config {
 	if err := godotenv.Load(); err != nil {
 		log.Printf("Warning: .env file not found")
@@ -33,6 +42,9 @@ func NewConfig() *Config {
 			InventoryServiceURL: getEnv("INVENTORY_SERVICE_URL", "http://localhost:8080"),
 			OrderServiceURL:     getEnv("ORDER_SERVICE_URL", "http://localhost:8081"),
 		},
+		Problem: &ProblemConfig{
+			Verbose: getBoolEnv("PROBLEM_VERBOSE", false),
+		},
 	}
 }
 
@@ -42,3 +54,11 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -3,6 +3,7 @@ module github.com/KaminurOrynbek/e-commerce_microservices/api-gateway
 go 1.20
 
 require (
+	github.com/KaminurOrynbek/e-commerce_microservices/pkg v0.0.0
 	github.com/gin-gonic/gin v1.9.1
 	github.com/joho/godotenv v1.5.1
 )
@@ -33,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.30.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace github.com/KaminurOrynbek/e-commerce_microservices/pkg => ../pkg
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"net/http/httputil"
@@ -26,15 +27,13 @@ func NewInventoryHandler(serviceURL string) *InventoryHandler {
 func (h *InventoryHandler) ProxyRequest(c *gin.Context) {
 	targetURL, err := url.Parse(h.serviceURL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid service URL"})
+		ginproblem.WriteInternal(c, http.StatusInternalServerError, fmt.Errorf("invalid service URL: %w", err))
 		return
 	}
 
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
 	proxy.ErrorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
-		c.JSON(http.StatusBadGateway, gin.H{
-			"error": fmt.Sprintf("Error proxying request: %v", err),
-		})
+		ginproblem.WriteInternal(c, http.StatusBadGateway, fmt.Errorf("error proxying request: %w", err))
 	}
 
 	path := c.Param("path")
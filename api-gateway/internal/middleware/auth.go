@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strings"
@@ -10,19 +12,19 @@ func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+			ginproblem.Write(c, problem.New(http.StatusUnauthorized, "Authorization header is required").WithCode("missing_authorization"))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format"})
+			ginproblem.Write(c, problem.New(http.StatusUnauthorized, "Invalid authorization format").WithCode("invalid_authorization"))
 			return
 		}
 
 		token := parts[1]
 		if len(token) < 10 {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token length"})
+			ginproblem.Write(c, problem.New(http.StatusUnauthorized, "Invalid token length").WithCode("invalid_token"))
 			return
 		}
 
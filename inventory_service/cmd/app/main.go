@@ -1,19 +1,45 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/config"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/adapter/imaging"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/adapter/notifier"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/adapter/storage"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/cache"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/postgres"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/worker"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"log"
+	"strings"
 	_ "github.com/lib/pq"
 )
 
 func main() {
 	cfg := config.NewConfig()
 
+	if err := configureMoney(cfg.Money); err != nil {
+		log.Fatalf("Invalid money settings: %v", err)
+	}
+	currencies, err := supportedCurrencies(cfg.Money)
+	if err != nil {
+		log.Fatalf("Invalid money settings: %v", err)
+	}
+	deletePolicy, err := domain.ParseCategoryDeletePolicy(cfg.Category.DeletePolicy)
+	if err != nil || deletePolicy == domain.DeleteReassign {
+		log.Fatalf("Invalid CATEGORY_DELETE_POLICY %q: must be reject or cascade", cfg.Category.DeletePolicy)
+	}
+
 	// PostgreSQL connection
 	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.DB.Host, cfg.DB.Port, cfg.DB.User, cfg.DB.Password, cfg.DB.DBName)
@@ -32,20 +58,180 @@ func main() {
 	// repositories
 	productRepo := postgres.NewProductRepository(db)
 	categoryRepo := postgres.NewCategoryRepository(db)
+	variantRepo := postgres.NewVariantRepository(db)
+	warehouseRepo := postgres.NewWarehouseRepository(db)
+	movementRepo := postgres.NewStockMovementRepository(db)
+	alertRepo := postgres.NewStockAlertRepository(db)
+	importJobs := memory.NewImportJobStore()
+	imageRepo := postgres.NewProductImageRepository(db)
+	attributeRepo := postgres.NewAttributeRepository(db)
+	priceRepo := postgres.NewPriceRepository(db)
+	priceListRepo := postgres.NewPriceListRepository(db)
+	rateRepo := postgres.NewExchangeRateRepository(db)
+	trashRepo := postgres.NewTrashRepository(db)
+	bundleRepo := postgres.NewBundleRepository(db)
+
+	// read-through caches in front of the product and category repositories
+	var productCache *cache.Cache
+	var cacheStats []domain.CacheStatsProvider
+	if cfg.Cache.Enabled {
+		productCache = cache.New("products", cfg.Cache.Size, cfg.Cache.TTL)
+		categoryCache := cache.New("categories", cfg.Cache.Size, cfg.Cache.TTL)
+		productRepo = cache.NewProductRepository(productRepo, productCache, bundleRepo)
+		categoryRepo = cache.NewCategoryRepository(categoryRepo, categoryCache, productCache)
+		movementRepo = cache.NewStockMovementRepository(movementRepo, productCache, bundleRepo)
+		priceRepo = cache.NewPriceRepository(priceRepo, productCache, bundleRepo)
+		bundleRepo = cache.NewBundleRepository(bundleRepo, productCache)
+		cacheStats = append(cacheStats, productCache, categoryCache)
+	}
+
+	mediaStorage, err := storage.NewLocalStorage(cfg.Media.Root, cfg.Media.BaseURL)
+	if err != nil {
+		log.Fatalf("Error preparing media storage: %v", err)
+	}
+
+	// use cases
+	warehouseUseCase := usecase.NewWarehouseUseCase(warehouseRepo)
+	stockUseCase := usecase.NewStockUseCase(movementRepo)
+	alertUseCase := usecase.NewStockAlertUseCase(alertRepo, cfg.StockAlert.DefaultReorderPoint)
+	attributeUseCase := usecase.NewAttributeUseCase(attributeRepo, categoryRepo)
+	productUseCase := usecase.NewProductUseCase(productRepo, categoryRepo, variantRepo, imageRepo, mediaStorage, attributeUseCase)
+	categoryUseCase := usecase.NewCategoryUseCase(categoryRepo)
+	catalogUseCase := usecase.NewCatalogUseCase(productRepo, categoryRepo, attributeUseCase, importJobs)
+	priceUseCase := usecase.NewPriceUseCase(priceRepo, productRepo)
+	currencyUseCase := usecase.NewCurrencyUseCase(priceListRepo, rateRepo, productRepo, currencies)
+	trashUseCase := usecase.NewTrashUseCase(productRepo, categoryRepo, trashRepo, mediaStorage, cfg.Trash.Retention)
+	bundleUseCase := usecase.NewBundleUseCase(bundleRepo, productRepo)
+	imageUseCase := usecase.NewProductImageUseCase(imageRepo, productRepo, mediaStorage, imaging.NewProcessor(), cfg.Media.MaxUploadSize)
+
+	// background workers
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if stockNotifier := newStockNotifier(cfg.StockAlert); stockNotifier != nil {
+		go worker.NewStockAlertChecker(alertUseCase, stockNotifier, cfg.StockAlert.Interval).Run(ctx)
+	}
+	go worker.NewPriceScheduler(priceUseCase, cfg.Pricing.SchedulerInterval).Run(ctx)
+	go worker.NewTrashPurger(trashUseCase, cfg.Trash.PurgeInterval).Run(ctx)
+	if publisher := newEventPublisher(cfg.Outbox); publisher != nil {
+		if productCache != nil {
+			publisher = cache.NewInvalidatingPublisher(publisher, productCache)
+		}
+		go outbox.NewRelay(db, publisher, outbox.RelayOptions{
+			Interval:    cfg.Outbox.RelayInterval,
+			BatchSize:   cfg.Outbox.BatchSize,
+			MaxAttempts: cfg.Outbox.MaxAttempts,
+			RetryDelay:  cfg.Outbox.RetryDelay,
+		}).Run(ctx)
+	}
 
 	// handlers
-	productHandler := http.NewProductHandler(productRepo)
-	categoryHandler := http.NewCategoryHandler(categoryRepo)
+	productHandler := http.NewProductHandler(productUseCase, currencyUseCase)
+	categoryHandler := http.NewCategoryHandler(categoryUseCase, deletePolicy)
+	variantHandler := http.NewVariantHandler(variantRepo)
+	warehouseHandler := http.NewWarehouseHandler(warehouseUseCase)
+	stockHandler := http.NewStockHandler(stockUseCase)
+	stockAlertHandler := http.NewStockAlertHandler(alertUseCase)
+	catalogHandler := http.NewCatalogHandler(catalogUseCase)
+	imageHandler := http.NewProductImageHandler(imageUseCase, mediaStorage, cfg.Media.MaxUploadSize)
+	attributeHandler := http.NewAttributeHandler(attributeUseCase)
+	priceHandler := http.NewPriceHandler(priceUseCase)
+	currencyHandler := http.NewCurrencyHandler(currencyUseCase)
+	trashHandler := http.NewTrashHandler(trashUseCase)
+	bundleHandler := http.NewBundleHandler(bundleUseCase)
+	cacheHandler := http.NewCacheHandler(cacheStats...)
 
-	// Gin router
-	router := gin.Default()
+	// Gin router. Errors are answered as problem details, which only show
+	// internal errors when PROBLEM_VERBOSE is set.
+	problem.SetVerbose(cfg.Problem.Verbose)
+	router := gin.New()
+	router.Use(gin.Logger(), ginproblem.RequestID(), ginproblem.Recovery())
+	router.Use(http.ActorMiddleware())
+	router.NoRoute(ginproblem.NoRoute)
 
 	// routes
 	productHandler.RegisterRoutes(router)
 	categoryHandler.RegisterRoutes(router)
+	variantHandler.RegisterRoutes(router)
+	warehouseHandler.RegisterRoutes(router)
+	stockHandler.RegisterRoutes(router)
+	stockAlertHandler.RegisterRoutes(router)
+	catalogHandler.RegisterRoutes(router)
+	imageHandler.RegisterRoutes(router)
+	attributeHandler.RegisterRoutes(router)
+	priceHandler.RegisterRoutes(router)
+	currencyHandler.RegisterRoutes(router)
+	trashHandler.RegisterRoutes(router)
+	bundleHandler.RegisterRoutes(router)
+	cacheHandler.RegisterRoutes(router)
+	router.Static(cfg.Media.BaseURL, mediaStorage.Root())
 
 	serverAddr := fmt.Sprintf(":%s", cfg.Server.Port)
 	if err := router.Run(serverAddr); err != nil {
 		log.Fatalf("Error starting server: %v", err)
 	}
 }
+
+// configureMoney applies the process-wide money settings before any amount is
+// parsed or written.
+func configureMoney(cfg *config.MoneyConfig) error {
+	currency, err := money.ParseCurrency(cfg.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	format, err := money.ParseFormat(cfg.JSONFormat)
+	if err != nil {
+		return err
+	}
+	if err := money.SetDefaultCurrency(currency); err != nil {
+		return err
+	}
+	money.SetJSONFormat(format)
+	return nil
+}
+
+// supportedCurrencies parses the configured list. The default currency is
+// always supported.
+func supportedCurrencies(cfg *config.MoneyConfig) ([]money.Currency, error) {
+	currencies := []money.Currency{money.DefaultCurrency()}
+	for _, code := range strings.Split(cfg.SupportedCurrencies, ",") {
+		if strings.TrimSpace(code) == "" {
+			continue
+		}
+		currency, err := money.ParseCurrency(code)
+		if err != nil {
+			return nil, err
+		}
+		if currency != currencies[0] {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies, nil
+}
+
+// newStockNotifier picks the configured alert sink, or nil to disable the
+// low-stock checker.
+func newStockNotifier(cfg *config.StockAlertConfig) domain.StockNotifier {
+	switch cfg.Notifier {
+	case "none":
+		return nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			log.Printf("STOCK_ALERT_WEBHOOK_URL is not set, falling back to log notifier")
+			return notifier.NewLogNotifier()
+		}
+		return notifier.NewWebhookNotifier(cfg.WebhookURL, cfg.WebhookTimeout)
+	default:
+		return notifier.NewLogNotifier()
+	}
+}
+
+// newEventPublisher picks where outbox events go, or nil to leave them in the
+// outbox.
+func newEventPublisher(cfg *config.OutboxConfig) outbox.Publisher {
+	switch cfg.Publisher {
+	case "none":
+		return nil
+	default:
+		return outbox.NewLogPublisher()
+	}
+}
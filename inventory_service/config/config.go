@@ -4,11 +4,22 @@ import (
 	"github.com/joho/godotenv"
 	"log"
 	"os"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	DB     *DBConfig
-	Server *ServerConfig
+	DB         *DBConfig
+	Server     *ServerConfig
+	StockAlert *StockAlertConfig
+	Media      *MediaConfig
+	Pricing    *PricingConfig
+	Money      *MoneyConfig
+	Trash      *TrashConfig
+	Category   *CategoryConfig
+	Outbox     *OutboxConfig
+	Cache      *CacheConfig
+	Problem    *ProblemConfig
 }
 
 type DBConfig struct {
@@ -23,6 +34,78 @@ type ServerConfig struct {
 	Port string
 }
 
+// StockAlertConfig controls the low-stock checker. Notifier is one of "log",
+// "webhook" or "none"; the webhook notifier needs WebhookURL.
+type StockAlertConfig struct {
+	Notifier            string
+	WebhookURL          string
+	WebhookTimeout      time.Duration
+	Interval            time.Duration
+	DefaultReorderPoint int
+}
+
+// MediaConfig places uploaded media on the local filesystem. Files under Root
+// are served at BaseURL.
+type MediaConfig struct {
+	Root          string
+	BaseURL       string
+	MaxUploadSize int64
+}
+
+// PricingConfig sets how often the scheduler looks for due price changes.
+type PricingConfig struct {
+	SchedulerInterval time.Duration
+}
+
+// MoneyConfig sets the currency assumed for amounts given without one and
+// whether amounts are written to JSON as "decimal" strings or "minor" units.
+// SupportedCurrencies is a comma-separated list of the currencies prices can
+// be listed and requested in.
+type MoneyConfig struct {
+	DefaultCurrency     string
+	JSONFormat          string
+	SupportedCurrencies string
+}
+
+// TrashConfig sets how long soft-deleted products and categories can be
+// restored before the purge job deletes them for good, and how often it runs.
+type TrashConfig struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// CategoryConfig sets the policy for category deletes that do not name one:
+// "reject" or "cascade".
+type CategoryConfig struct {
+	DeletePolicy string
+}
+
+// OutboxConfig controls the relay that publishes domain events. Publisher is
+// "log" or "none"; with "none" the relay does not run and events wait in the
+// outbox. Failed deliveries are retried after RetryDelay, doubling each time,
+// and dead-lettered after MaxAttempts.
+type OutboxConfig struct {
+	Publisher     string
+	RelayInterval time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	RetryDelay    time.Duration
+}
+
+// CacheConfig switches the in-process product and category caches. Each
+// holds up to Size entries for at most TTL.
+type CacheConfig struct {
+	Enabled bool
+	Size    int
+	TTL     time.Duration
+}
+
+// ProblemConfig sets whether error responses show the detail of internal
+// errors, which may expose internals such as SQL. It is off unless asked for.
+type ProblemConfig struct {
+	Verbose bool
+}
+
 func NewConfig() *Config {
 	err := godotenv.Load()
 	if err != nil {
@@ -40,6 +123,48 @@ func NewConfig() *Config {
 		Server: &ServerConfig{
 			Port: getEnv("SERVER_PORT", "8080"),
 		},
+		StockAlert: &StockAlertConfig{
+			Notifier:            getEnv("STOCK_ALERT_NOTIFIER", "log"),
+			WebhookURL:          getEnv("STOCK_ALERT_WEBHOOK_URL", ""),
+			WebhookTimeout:      getDurationEnv("STOCK_ALERT_WEBHOOK_TIMEOUT", 5*time.Second),
+			Interval:            getDurationEnv("STOCK_ALERT_INTERVAL", time.Minute),
+			DefaultReorderPoint: getIntEnv("STOCK_ALERT_DEFAULT_REORDER_POINT", 0),
+		},
+		Media: &MediaConfig{
+			Root:          getEnv("MEDIA_ROOT", "./media"),
+			BaseURL:       getEnv("MEDIA_BASE_URL", "/media"),
+			MaxUploadSize: int64(getIntEnv("MEDIA_MAX_UPLOAD_BYTES", 10<<20)),
+		},
+		Pricing: &PricingConfig{
+			SchedulerInterval: getDurationEnv("PRICE_SCHEDULER_INTERVAL", time.Minute),
+		},
+		Money: &MoneyConfig{
+			DefaultCurrency:     getEnv("DEFAULT_CURRENCY", "USD"),
+			JSONFormat:          getEnv("MONEY_JSON_FORMAT", "decimal"),
+			SupportedCurrencies: getEnv("SUPPORTED_CURRENCIES", "USD,EUR,KZT"),
+		},
+		Trash: &TrashConfig{
+			Retention:     getDurationEnv("TRASH_RETENTION", 30*24*time.Hour),
+			PurgeInterval: getDurationEnv("TRASH_PURGE_INTERVAL", time.Hour),
+		},
+		Category: &CategoryConfig{
+			DeletePolicy: getEnv("CATEGORY_DELETE_POLICY", "reject"),
+		},
+		Outbox: &OutboxConfig{
+			Publisher:     getEnv("OUTBOX_PUBLISHER", "log"),
+			RelayInterval: getDurationEnv("OUTBOX_RELAY_INTERVAL", time.Second),
+			BatchSize:     getIntEnv("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:   getIntEnv("OUTBOX_MAX_ATTEMPTS", 10),
+			RetryDelay:    getDurationEnv("OUTBOX_RETRY_DELAY", time.Second),
+		},
+		Cache: &CacheConfig{
+			Enabled: getBoolEnv("CACHE_ENABLED", false),
+			Size:    getIntEnv("CACHE_SIZE", 10000),
+			TTL:     getDurationEnv("CACHE_TTL", 30*time.Second),
+		},
+		Problem: &ProblemConfig{
+			Verbose: getBoolEnv("PROBLEM_VERBOSE", false),
+		},
 	}
 }
 
@@ -50,3 +175,27 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getIntEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
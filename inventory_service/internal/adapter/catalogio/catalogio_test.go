@@ -0,0 +1,193 @@
+package catalogio
+
+import (
+	"bytes"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newProduct(t *testing.T, sku, price string, stock int, attributes map[string]interface{}) *domain.Product {
+	t.Helper()
+	amount, err := money.Parse(price, "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	product, err := domain.NewProduct("Desk lamp, "+sku, "Warm \"white\" light", amount, stock, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	product.SetID(7)
+	product.SetIdentifiers(sku, "ext-"+sku)
+	product.SetAttributes(attributes)
+	return product
+}
+
+func TestProductRoundTrip(t *testing.T) {
+	for _, format := range []domain.CatalogFormat{domain.CatalogCSV, domain.CatalogNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			products := []*domain.Product{
+				newProduct(t, "LAMP-1", "12.50", 4, map[string]interface{}{"color": "red", "watts": 40.0}),
+				newProduct(t, "LAMP-2", "0.99", 0, nil),
+			}
+
+			var buf bytes.Buffer
+			writer, err := NewProductWriter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, product := range products {
+				if err := writer.Write(product, "lighting"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewProductReader(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, want := range products {
+				record, err := reader.Next()
+				if err != nil {
+					t.Fatalf("record %d: %v", i, err)
+				}
+				if record.SKU != want.SKU() || record.ExternalID != want.ExternalID() || record.Name != want.Name() || record.Description != want.Description() {
+					t.Errorf("record %d: got %+v", i, record)
+				}
+				if !record.Price.Equal(want.Price()) {
+					t.Errorf("record %d: got price %v, want %v", i, record.Price, want.Price())
+				}
+				if record.Stock == nil || *record.Stock != want.Stock() {
+					t.Errorf("record %d: got stock %v, want %d", i, record.Stock, want.Stock())
+				}
+				if record.CategoryExternalID != "lighting" {
+					t.Errorf("record %d: got category %q", i, record.CategoryExternalID)
+				}
+				if len(want.Attributes()) == 0 {
+					if record.Attributes != nil {
+						t.Errorf("record %d: got attributes %v, want none", i, record.Attributes)
+					}
+				} else if !reflect.DeepEqual(record.Attributes, want.Attributes()) {
+					t.Errorf("record %d: got attributes %v, want %v", i, record.Attributes, want.Attributes())
+				}
+			}
+			if _, err := reader.Next(); err != io.EOF {
+				t.Fatalf("got %v after the last record, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestCategoryRoundTrip(t *testing.T) {
+	for _, format := range []domain.CatalogFormat{domain.CatalogCSV, domain.CatalogNDJSON} {
+		t.Run(string(format), func(t *testing.T) {
+			category := domain.NewCategory("Desk lamps", "Lamps, for desks")
+			category.SetID(2)
+			category.SetExternalID("desk-lamps")
+
+			var buf bytes.Buffer
+			writer, err := NewCategoryWriter(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Write(category, "lighting"); err != nil {
+				t.Fatal(err)
+			}
+			if err := writer.Flush(); err != nil {
+				t.Fatal(err)
+			}
+
+			reader, err := NewCategoryReader(format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			record, err := reader.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := domain.CategoryRecord{Row: record.Row, ExternalID: "desk-lamps", Name: "Desk lamps", Description: "Lamps, for desks", ParentExternalID: "lighting"}
+			if *record != want {
+				t.Fatalf("got %+v, want %+v", *record, want)
+			}
+			if _, err := reader.Next(); err != io.EOF {
+				t.Fatalf("got %v after the last record, want io.EOF", err)
+			}
+		})
+	}
+}
+
+func TestProductReaderRowErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  domain.CatalogFormat
+		input   string
+		wantRow int
+		want    string
+	}{
+		{"bad price", domain.CatalogCSV, "sku,name,price\nA,Lamp,abc\n", 2, "price"},
+		{"bad stock", domain.CatalogCSV, "sku,name,price,stock\nA,Lamp,1.00,many\n", 2, "stock must be an integer"},
+		{"bad currency", domain.CatalogCSV, "sku,name,price,currency\nA,Lamp,1.00,XX\n", 2, ""},
+		{"bad attributes", domain.CatalogCSV, "sku,name,price,attributes\nA,Lamp,1.00,[1]\n", 2, "attributes must be a JSON object"},
+		{"invalid json", domain.CatalogNDJSON, "{\"sku\":\"A\",\"name\":\"Lamp\",\"price\":1}\n\n{oops\n", 3, "invalid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewProductReader(tt.format, strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var rowErr *domain.ImportRowError
+			for {
+				_, err = reader.Next()
+				if err == nil {
+					continue
+				}
+				if !errors.As(err, &rowErr) {
+					t.Fatalf("got error %v, want a row error", err)
+				}
+				break
+			}
+			if rowErr.Row != tt.wantRow || !strings.Contains(rowErr.Message, tt.want) {
+				t.Fatalf("got row %d %q, want row %d containing %q", rowErr.Row, rowErr.Message, tt.wantRow, tt.want)
+			}
+		})
+	}
+}
+
+func TestProductReaderDefaultsAndHeaders(t *testing.T) {
+	// A byte order mark and mixed-case headers are tolerated, and a row
+	// without stock or attributes leaves them unset.
+	reader, err := NewProductReader(domain.CatalogCSV, strings.NewReader("\ufeffSKU, Name ,Price\nA,Lamp,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.SKU != "A" || record.Name != "Lamp" || record.Row != 2 {
+		t.Fatalf("got %+v", record)
+	}
+	if record.Price.Currency() != money.DefaultCurrency() {
+		t.Fatalf("got currency %s, want the default", record.Price.Currency())
+	}
+	if record.Stock != nil || record.Attributes != nil {
+		t.Fatalf("got stock %v and attributes %v, want neither", record.Stock, record.Attributes)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewProductReader("xml", strings.NewReader("")); !errors.Is(err, domain.ErrUnknownCatalogFormat) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrUnknownCatalogFormat)
+	}
+	if _, err := NewCategoryWriter("xml", io.Discard); !errors.Is(err, domain.ErrUnknownCatalogFormat) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrUnknownCatalogFormat)
+	}
+}
@@ -0,0 +1,218 @@
+// Package catalogio reads and writes the catalog in the CSV and NDJSON
+// formats used by bulk import and export. Both formats share the same field
+// names, so an export can be edited and imported back.
+package catalogio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// maxLineSize bounds a single NDJSON line.
+const maxLineSize = 1 << 20
+
+// fieldSource yields one row at a time as named string fields.
+type fieldSource interface {
+	next() (row int, fields map[string]string, err error)
+}
+
+func newFieldSource(format domain.CatalogFormat, r io.Reader) (fieldSource, error) {
+	switch format {
+	case domain.CatalogCSV:
+		return newCSVSource(r), nil
+	case domain.CatalogNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+		return &ndjsonSource{scanner: scanner}, nil
+	default:
+		return nil, domain.ErrUnknownCatalogFormat
+	}
+}
+
+type csvSource struct {
+	reader *csv.Reader
+	header []string
+	row    int
+}
+
+func newCSVSource(r io.Reader) *csvSource {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return &csvSource{reader: reader}
+}
+
+func (s *csvSource) next() (int, map[string]string, error) {
+	if s.header == nil {
+		header, err := s.reader.Read()
+		if err == io.EOF {
+			return 0, nil, io.EOF
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("reading csv header: %w", err)
+		}
+		for i, name := range header {
+			header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		}
+		s.header = header
+		s.row = 1
+	}
+
+	record, err := s.reader.Read()
+	s.row++
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		s.row = parseErr.Line
+		return s.row, nil, &domain.ImportRowError{Row: parseErr.Line, Message: parseErr.Err.Error()}
+	}
+	if err != nil {
+		return 0, nil, err
+	}
+	if line, _ := s.reader.FieldPos(0); line > 0 {
+		s.row = line
+	}
+
+	fields := make(map[string]string, len(s.header))
+	for i, name := range s.header {
+		if i < len(record) {
+			fields[name] = strings.TrimSpace(record[i])
+		}
+	}
+	return s.row, fields, nil
+}
+
+type ndjsonSource struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (s *ndjsonSource) next() (int, map[string]string, error) {
+	for s.scanner.Scan() {
+		s.row++
+		line := bytes.TrimSpace(s.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		var values map[string]interface{}
+		if err := decoder.Decode(&values); err != nil {
+			return s.row, nil, &domain.ImportRowError{Row: s.row, Message: "invalid JSON: " + err.Error()}
+		}
+
+		fields := make(map[string]string, len(values))
+		for name, value := range values {
+			switch value.(type) {
+			case nil:
+			case map[string]interface{}, []interface{}:
+				// Nested values, such as attributes, travel as JSON text
+				// just like in a CSV cell.
+				encoded, _ := json.Marshal(value)
+				fields[strings.ToLower(name)] = string(encoded)
+			default:
+				fields[strings.ToLower(name)] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+		return s.row, fields, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return 0, nil, err
+	}
+	return 0, nil, io.EOF
+}
+
+type productReader struct {
+	source fieldSource
+}
+
+// NewProductReader reads product records with the fields sku, external_id,
+// name, description, price, currency, stock, category_external_id and
+// attributes, the last being a JSON object. Rows without a currency are in
+// the service default.
+func NewProductReader(format domain.CatalogFormat, r io.Reader) (domain.ProductRecordReader, error) {
+	source, err := newFieldSource(format, r)
+	if err != nil {
+		return nil, err
+	}
+	return &productReader{source: source}, nil
+}
+
+func (r *productReader) Next() (*domain.ProductRecord, error) {
+	row, fields, err := r.source.next()
+	if err != nil {
+		return nil, err
+	}
+
+	record := &domain.ProductRecord{
+		Row:                row,
+		SKU:                fields["sku"],
+		ExternalID:         fields["external_id"],
+		Name:               fields["name"],
+		Description:        fields["description"],
+		CategoryExternalID: fields["category_external_id"],
+	}
+	currency := money.DefaultCurrency()
+	if value := fields["currency"]; value != "" {
+		if currency, err = money.ParseCurrency(value); err != nil {
+			return nil, &domain.ImportRowError{Row: row, Key: record.Key(), Message: err.Error()}
+		}
+	}
+	if record.Price, err = money.Parse(fields["price"], currency); err != nil {
+		return nil, &domain.ImportRowError{Row: row, Key: record.Key(), Message: "price: " + err.Error()}
+	}
+	if value := fields["stock"]; value != "" {
+		stock, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, &domain.ImportRowError{Row: row, Key: record.Key(), Message: "stock must be an integer"}
+		}
+		record.Stock = &stock
+	}
+	if value := fields["attributes"]; value != "" {
+		if err := json.Unmarshal([]byte(value), &record.Attributes); err != nil || record.Attributes == nil {
+			return nil, &domain.ImportRowError{Row: row, Key: record.Key(), Message: "attributes must be a JSON object"}
+		}
+	}
+	return record, nil
+}
+
+type categoryReader struct {
+	source fieldSource
+}
+
+// NewCategoryReader reads category records with the fields external_id,
+// name, description and parent_external_id.
+func NewCategoryReader(format domain.CatalogFormat, r io.Reader) (domain.CategoryRecordReader, error) {
+	source, err := newFieldSource(format, r)
+	if err != nil {
+		return nil, err
+	}
+	return &categoryReader{source: source}, nil
+}
+
+func (r *categoryReader) Next() (*domain.CategoryRecord, error) {
+	row, fields, err := r.source.next()
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.CategoryRecord{
+		Row:              row,
+		ExternalID:       fields["external_id"],
+		Name:             fields["name"],
+		Description:      fields["description"],
+		ParentExternalID: fields["parent_external_id"],
+	}, nil
+}
@@ -0,0 +1,144 @@
+package catalogio
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"io"
+	"strconv"
+)
+
+var (
+	productFields  = []string{"id", "sku", "external_id", "name", "description", "price", "currency", "stock", "category_external_id", "attributes"}
+	categoryFields = []string{"id", "external_id", "name", "description", "parent_external_id"}
+)
+
+// recordWriter writes rows of string fields in a fixed column order.
+type recordWriter interface {
+	write(values []string) error
+	Flush() error
+}
+
+func newRecordWriter(format domain.CatalogFormat, w io.Writer, fields []string) (recordWriter, error) {
+	switch format {
+	case domain.CatalogCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(fields); err != nil {
+			return nil, err
+		}
+		return &csvWriter{writer: writer}, nil
+	case domain.CatalogNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w), fields: fields}, nil
+	default:
+		return nil, domain.ErrUnknownCatalogFormat
+	}
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) write(values []string) error {
+	return w.writer.Write(values)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	fields  []string
+}
+
+// write emits numeric columns as JSON numbers, attributes as a nested object,
+// and leaves out empty columns.
+func (w *ndjsonWriter) write(values []string) error {
+	object := make(map[string]interface{}, len(values))
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		switch w.fields[i] {
+		case "id", "price", "stock":
+			object[w.fields[i]] = json.Number(value)
+		case "attributes":
+			object[w.fields[i]] = json.RawMessage(value)
+		default:
+			object[w.fields[i]] = value
+		}
+	}
+	return w.encoder.Encode(object)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return nil
+}
+
+// ProductWriter writes products in import format.
+type ProductWriter struct {
+	w recordWriter
+}
+
+func NewProductWriter(format domain.CatalogFormat, w io.Writer) (*ProductWriter, error) {
+	writer, err := newRecordWriter(format, w, productFields)
+	if err != nil {
+		return nil, err
+	}
+	return &ProductWriter{w: writer}, nil
+}
+
+func (w *ProductWriter) Write(product *domain.Product, categoryExternalID string) error {
+	var attributes string
+	if len(product.Attributes()) > 0 {
+		encoded, err := json.Marshal(product.Attributes())
+		if err != nil {
+			return err
+		}
+		attributes = string(encoded)
+	}
+	return w.w.write([]string{
+		strconv.FormatUint(product.ID(), 10),
+		product.SKU(),
+		product.ExternalID(),
+		product.Name(),
+		product.Description(),
+		product.Price().Decimal(),
+		string(product.Price().Currency()),
+		strconv.Itoa(product.Stock()),
+		categoryExternalID,
+		attributes,
+	})
+}
+
+func (w *ProductWriter) Flush() error {
+	return w.w.Flush()
+}
+
+// CategoryWriter writes categories in import format.
+type CategoryWriter struct {
+	w recordWriter
+}
+
+func NewCategoryWriter(format domain.CatalogFormat, w io.Writer) (*CategoryWriter, error) {
+	writer, err := newRecordWriter(format, w, categoryFields)
+	if err != nil {
+		return nil, err
+	}
+	return &CategoryWriter{w: writer}, nil
+}
+
+func (w *CategoryWriter) Write(category *domain.Category, parentExternalID string) error {
+	return w.w.write([]string{
+		strconv.FormatUint(category.ID(), 10),
+		category.ExternalID(),
+		category.Name(),
+		category.Description(),
+		parentExternalID,
+	})
+}
+
+func (w *CategoryWriter) Flush() error {
+	return w.w.Flush()
+}
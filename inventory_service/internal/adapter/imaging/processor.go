@@ -0,0 +1,87 @@
+// Package imaging validates uploaded images and renders thumbnails using only
+// the standard library decoders.
+package imaging
+
+import (
+	"bytes"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// maxPixels rejects images whose decoded size would exhaust memory, whatever
+// their compressed size.
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+type Processor struct{}
+
+func NewProcessor() *Processor {
+	return &Processor{}
+}
+
+// Process sniffs the content type from the data itself rather than trusting
+// the client, then decodes it and renders each thumbnail size. JPEG sources
+// get JPEG thumbnails; PNG and GIF sources get PNG thumbnails, which keep
+// transparency.
+func (p *Processor) Process(data []byte, sizes []domain.ThumbnailSize) (*domain.ProcessedImage, error) {
+	contentType := http.DetectContentType(data)
+	result := &domain.ProcessedImage{ContentType: contentType, Original: data, Thumbnails: make(map[string][]byte, len(sizes))}
+	switch contentType {
+	case "image/jpeg":
+		result.Extension, result.ThumbnailContentType, result.ThumbnailExtension = ".jpg", "image/jpeg", ".jpg"
+	case "image/png":
+		result.Extension, result.ThumbnailContentType, result.ThumbnailExtension = ".png", "image/png", ".png"
+	case "image/gif":
+		result.Extension, result.ThumbnailContentType, result.ThumbnailExtension = ".gif", "image/png", ".png"
+	default:
+		return nil, domain.ErrUnsupportedImageType
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, domain.ErrInvalidImage
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return nil, domain.ErrInvalidImage
+	}
+	result.Width, result.Height = config.Width, config.Height
+
+	var src image.Image
+	switch contentType {
+	case "image/jpeg":
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, domain.ErrInvalidImage
+	}
+
+	maxSides := make([]int, len(sizes))
+	for i, size := range sizes {
+		maxSides[i] = size.MaxSide
+	}
+	thumbnails := FitEach(src, maxSides)
+	for i, size := range sizes {
+		thumbnail := thumbnails[i]
+		var buf bytes.Buffer
+		if result.ThumbnailContentType == "image/jpeg" {
+			err = jpeg.Encode(&buf, thumbnail, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&buf, thumbnail)
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails[size.Name] = buf.Bytes()
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessRendersThumbnails(t *testing.T) {
+	data := encodePNG(t, filled(600, 300, color.NRGBA{R: 10, A: 255}))
+	sizes := []domain.ThumbnailSize{{Name: "small", MaxSide: 100}, {Name: "large", MaxSide: 1000}}
+
+	result, err := NewProcessor().Process(data, sizes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ContentType != "image/png" || result.ThumbnailContentType != "image/png" || result.Width != 600 || result.Height != 300 {
+		t.Fatalf("got %+v", result)
+	}
+
+	want := map[string]image.Point{"small": {100, 50}, "large": {600, 300}}
+	for name, size := range want {
+		thumbnail, err := png.Decode(bytes.NewReader(result.Thumbnails[name]))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := thumbnail.Bounds().Size(); got != size {
+			t.Errorf("%s: got %v, want %v", name, got, size)
+		}
+	}
+}
+
+func TestProcessRejectsOtherContent(t *testing.T) {
+	if _, err := NewProcessor().Process([]byte("not an image at all"), domain.ThumbnailSizes); !errors.Is(err, domain.ErrUnsupportedImageType) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrUnsupportedImageType)
+	}
+	truncated := encodePNG(t, filled(10, 10, color.White))[:40]
+	if _, err := NewProcessor().Process(truncated, domain.ThumbnailSizes); !errors.Is(err, domain.ErrInvalidImage) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrInvalidImage)
+	}
+}
@@ -0,0 +1,115 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+	"sort"
+)
+
+// Fit scales src down so that its longer side is at most maxSide, keeping the
+// aspect ratio. Smaller images are returned unscaled.
+func Fit(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= maxSide && bounds.Dy() <= maxSide {
+		return src
+	}
+	return fit(toRGBA(src), maxSide)
+}
+
+// FitEach returns src fitted to each of maxSides, in the same order. The
+// source is converted only once, and each size is derived from the next
+// larger one, so that every pass averages far fewer pixels than the original
+// has.
+func FitEach(src image.Image, maxSides []int) []image.Image {
+	order := make([]int, len(maxSides))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return maxSides[order[a]] > maxSides[order[b]] })
+
+	bounds := src.Bounds()
+	fitted := make([]image.Image, len(maxSides))
+	var current *image.RGBA
+	for _, i := range order {
+		if current == nil {
+			if bounds.Dx() <= maxSides[i] && bounds.Dy() <= maxSides[i] {
+				fitted[i] = src
+				continue
+			}
+			current = toRGBA(src)
+		}
+		current = fit(current, maxSides[i])
+		fitted[i] = current
+	}
+	return fitted
+}
+
+func fit(src *image.RGBA, maxSide int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxSide && height <= maxSide {
+		return src
+	}
+
+	if width >= height {
+		height = max(1, height*maxSide/width)
+		width = maxSide
+	} else {
+		width = max(1, width*maxSide/height)
+		height = maxSide
+	}
+	return downscale(src, width, height)
+}
+
+// toRGBA copies src into an RGBA image whose bounds start at the origin.
+func toRGBA(src image.Image) *image.RGBA {
+	bounds := src.Bounds()
+	if rgba, ok := src.(*image.RGBA); ok && bounds.Min == (image.Point{}) {
+		return rgba
+	}
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+	return rgba
+}
+
+// downscale resizes by area averaging: every destination pixel is the mean
+// of the source pixels it covers. It suits the large reductions thumbnails
+// need better than point sampling, which aliases badly. Averaging the
+// premultiplied values keeps transparent pixels from bleeding their color.
+func downscale(src *image.RGBA, width, height int) *image.RGBA {
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, max((y+1)*srcHeight/height, y*srcHeight/height+1)
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, max((x+1)*srcWidth/width, x*srcWidth/width+1)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += uint64(src.Pix[offset])
+					g += uint64(src.Pix[offset+1])
+					b += uint64(src.Pix[offset+2])
+					a += uint64(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
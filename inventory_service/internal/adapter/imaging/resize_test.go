@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func filled(width, height int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFitKeepsAspectRatio(t *testing.T) {
+	tests := []struct {
+		width, height, maxSide int
+		wantW, wantH           int
+	}{
+		{800, 400, 200, 200, 100},
+		{400, 800, 200, 100, 200},
+		{1000, 3, 100, 100, 1},
+		{100, 50, 200, 100, 50},
+		{200, 200, 200, 200, 200},
+	}
+	for _, tt := range tests {
+		got := Fit(filled(tt.width, tt.height, color.White), tt.maxSide).Bounds()
+		if got.Dx() != tt.wantW || got.Dy() != tt.wantH {
+			t.Errorf("Fit(%dx%d, %d): got %dx%d, want %dx%d", tt.width, tt.height, tt.maxSide, got.Dx(), got.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestFitReturnsSmallImagesUnscaled(t *testing.T) {
+	src := filled(10, 10, color.White)
+	if Fit(src, 10) != image.Image(src) {
+		t.Fatal("an image within the limit was copied")
+	}
+}
+
+func TestFitAveragesArea(t *testing.T) {
+	// Alternating black and white columns average to mid grey rather than
+	// aliasing to either color.
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if x%2 == 0 {
+				src.Set(x, y, color.White)
+			} else {
+				src.Set(x, y, color.Black)
+			}
+		}
+	}
+	r, g, b, a := Fit(src, 2).At(0, 0).RGBA()
+	if r>>8 != 127 || g>>8 != 127 || b>>8 != 127 || a>>8 != 255 {
+		t.Fatalf("got %d,%d,%d,%d, want mid grey", r>>8, g>>8, b>>8, a>>8)
+	}
+}
+
+func TestFitIgnoresTransparentColor(t *testing.T) {
+	// A transparent red pixel must not tint its opaque blue neighbour.
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, color.NRGBA{R: 255, A: 0})
+	src.Set(1, 0, color.NRGBA{B: 255, A: 255})
+	got := color.NRGBAModel.Convert(Fit(src, 1).At(0, 0)).(color.NRGBA)
+	if got.R != 0 || got.B < 250 || got.A != 127 {
+		t.Fatalf("got %+v, want half-transparent blue", got)
+	}
+}
+
+func TestFitHandlesOffsetBounds(t *testing.T) {
+	src := filled(20, 20, color.NRGBA{G: 255, A: 255}).SubImage(image.Rect(10, 10, 20, 20))
+	got := Fit(src, 5)
+	if got.Bounds() != image.Rect(0, 0, 5, 5) {
+		t.Fatalf("got bounds %v", got.Bounds())
+	}
+	if _, g, _, _ := got.At(2, 2).RGBA(); g>>8 != 255 {
+		t.Fatalf("got green %d, want 255", g>>8)
+	}
+}
+
+func TestFitEach(t *testing.T) {
+	src := filled(1200, 600, color.NRGBA{R: 200, G: 100, B: 50, A: 255})
+	maxSides := []int{150, 1024, 400, 2000}
+	fitted := FitEach(src, maxSides)
+	if len(fitted) != len(maxSides) {
+		t.Fatalf("got %d images, want %d", len(fitted), len(maxSides))
+	}
+	want := []image.Rectangle{
+		image.Rect(0, 0, 150, 75),
+		image.Rect(0, 0, 1024, 512),
+		image.Rect(0, 0, 400, 200),
+		image.Rect(0, 0, 1200, 600),
+	}
+	for i, img := range fitted {
+		if img.Bounds() != want[i] {
+			t.Errorf("size %d: got %v, want %v", maxSides[i], img.Bounds(), want[i])
+		}
+		// A flat color survives every pass unchanged.
+		if r, g, b, _ := img.At(0, 0).RGBA(); r>>8 != 200 || g>>8 != 100 || b>>8 != 50 {
+			t.Errorf("size %d: got %d,%d,%d", maxSides[i], r>>8, g>>8, b>>8)
+		}
+	}
+	if fitted[3] != image.Image(src) {
+		t.Error("a size larger than the source was not the source itself")
+	}
+}
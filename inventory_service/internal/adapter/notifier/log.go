@@ -0,0 +1,20 @@
+package notifier
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"log"
+)
+
+// LogNotifier writes stock alerts to the standard logger.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, alert domain.StockAlert) error {
+	log.Printf("stock alert: product %d (%s) is %s: stock %d, reorder point %d",
+		alert.Item.ProductID, alert.Item.Name, alert.Item.Level, alert.Item.Stock, alert.Item.ReorderPoint)
+	return nil
+}
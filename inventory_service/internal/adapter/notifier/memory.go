@@ -0,0 +1,37 @@
+package notifier
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"sync"
+)
+
+// MemoryNotifier keeps every alert it receives, for tests and local runs.
+type MemoryNotifier struct {
+	mu     sync.Mutex
+	alerts []domain.StockAlert
+}
+
+func NewMemoryNotifier() *MemoryNotifier {
+	return &MemoryNotifier{}
+}
+
+func (n *MemoryNotifier) Notify(ctx context.Context, alert domain.StockAlert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return nil
+}
+
+// Alerts returns a copy of the alerts received so far.
+func (n *MemoryNotifier) Alerts() []domain.StockAlert {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]domain.StockAlert(nil), n.alerts...)
+}
+
+func (n *MemoryNotifier) Reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = nil
+}
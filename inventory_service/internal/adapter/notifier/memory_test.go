@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"testing"
+)
+
+func TestMemoryNotifier(t *testing.T) {
+	n := NewMemoryNotifier()
+	alert := domain.StockAlert{Item: domain.LowStockItem{ProductID: 1, Level: domain.StockLevelLow}}
+	if err := n.Notify(context.Background(), alert); err != nil {
+		t.Fatal(err)
+	}
+
+	got := n.Alerts()
+	if len(got) != 1 || got[0].Item.ProductID != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	got[0].Item.ProductID = 99
+	if n.Alerts()[0].Item.ProductID != 1 {
+		t.Fatal("Alerts shares its slice with the notifier")
+	}
+
+	n.Reset()
+	if len(n.Alerts()) != 0 {
+		t.Fatal("Reset kept alerts")
+	}
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"net/http"
+	"time"
+)
+
+// WebhookNotifier posts each alert as JSON to a configured URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+type webhookPayload struct {
+	Event        string    `json:"event"`
+	ProductID    uint64    `json:"product_id"`
+	Name         string    `json:"name"`
+	CategoryID   uint64    `json:"category_id,omitempty"`
+	Stock        int       `json:"stock"`
+	ReorderPoint int       `json:"reorder_point"`
+	Previous     string    `json:"previous_level"`
+	At           time.Time `json:"at"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert domain.StockAlert) error {
+	body, err := json.Marshal(webhookPayload{
+		Event:        string(alert.Item.Level),
+		ProductID:    alert.Item.ProductID,
+		Name:         alert.Item.Name,
+		CategoryID:   alert.Item.CategoryID,
+		Stock:        alert.Item.Stock,
+		ReorderPoint: alert.Item.ReorderPoint,
+		Previous:     string(alert.Previous),
+		At:           alert.At,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
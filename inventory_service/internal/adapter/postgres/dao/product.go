@@ -6,7 +6,8 @@ type Product struct {
 	ID          uint64    `db:"id"`
 	Name        string    `db:"name"`
 	Description string    `db:"description"`
-	Price       float64   `db:"price"`
+	Price       string    `db:"price"`
+	Currency    string    `db:"currency"`
 	Stock       int       `db:"stock"`
 	CategoryID  uint64    `db:"category_id"`
 	CreatedAt   time.Time `db:"created_at"`
@@ -0,0 +1,84 @@
+// Package storage holds MediaStorage implementations.
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage keeps media on the local filesystem under a root directory,
+// served by the application itself under baseURL.
+type LocalStorage struct {
+	root    string
+	baseURL string
+}
+
+func NewLocalStorage(root, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Root is the directory media is stored in.
+func (s *LocalStorage) Root() string {
+	return s.root
+}
+
+// Put writes to a temporary file and renames it into place, so readers never
+// see a partially written object.
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(target)
+	if errors.Is(err, os.ErrNotExist) {
+		return domain.ErrMediaObjectNotFound
+	}
+	return err
+}
+
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// path maps a key to a file below root, refusing keys that would escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if key == "" || cleaned == "/" || cleaned[1:] != key {
+		return "", domain.ErrInvalidMediaStorageKey
+	}
+	return filepath.Join(s.root, filepath.FromSlash(cleaned)), nil
+}
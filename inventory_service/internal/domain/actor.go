@@ -0,0 +1,21 @@
+package domain
+
+import "context"
+
+// SystemActor is recorded for changes made without an identified user.
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor attaches the identity of whoever triggers a change so that audit
+// records written deeper in the stack can attribute it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"math"
+	"sort"
+)
+
+var (
+	ErrUnknownAllocationStrategy = invalid("unknown_allocation_strategy", "unknown allocation strategy")
+	ErrNoFulfillingWarehouse     = conflict("no_fulfilling_warehouse", "no warehouse can fulfil the requested quantity")
+)
+
+const (
+	AllocationNearest   = "nearest"
+	AllocationMostStock = "most_stock"
+)
+
+type AllocationRequest struct {
+	ProductID   uint64
+	Quantity    int
+	Destination *GeoPoint
+	// AllowSplit lets the quantity be spread over several warehouses when no
+	// single one holds enough.
+	AllowSplit bool
+}
+
+type Allocation struct {
+	WarehouseID uint64
+	Quantity    int
+}
+
+// AllocationStrategy picks the warehouses that should fulfil a request. It
+// only plans; reserving the stock is left to the caller.
+type AllocationStrategy interface {
+	Name() string
+	Allocate(req AllocationRequest, warehouses map[uint64]*Warehouse, levels []*StockLevel) ([]Allocation, error)
+}
+
+func AllocationStrategyByName(name string) (AllocationStrategy, error) {
+	switch name {
+	case "", AllocationNearest:
+		return NearestAllocation{}, nil
+	case AllocationMostStock:
+		return MostStockAllocation{}, nil
+	}
+	return nil, ErrUnknownAllocationStrategy
+}
+
+// NearestAllocation prefers warehouses closest to the destination. Without a
+// destination it behaves like MostStockAllocation.
+type NearestAllocation struct{}
+
+func (NearestAllocation) Name() string {
+	return AllocationNearest
+}
+
+func (NearestAllocation) Allocate(req AllocationRequest, warehouses map[uint64]*Warehouse, levels []*StockLevel) ([]Allocation, error) {
+	if req.Destination == nil {
+		return MostStockAllocation{}.Allocate(req, warehouses, levels)
+	}
+
+	distance := func(level *StockLevel) float64 {
+		location := warehouses[level.WarehouseID].Location()
+		if location == nil {
+			return math.Inf(1)
+		}
+		return location.DistanceKm(*req.Destination)
+	}
+	candidates := allocationCandidates(warehouses, levels)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i]) < distance(candidates[j])
+	})
+	return allocateInOrder(req, candidates)
+}
+
+// MostStockAllocation prefers the warehouses holding the most stock, which
+// keeps shipments from being split.
+type MostStockAllocation struct{}
+
+func (MostStockAllocation) Name() string {
+	return AllocationMostStock
+}
+
+func (MostStockAllocation) Allocate(req AllocationRequest, warehouses map[uint64]*Warehouse, levels []*StockLevel) ([]Allocation, error) {
+	candidates := allocationCandidates(warehouses, levels)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Quantity > candidates[j].Quantity
+	})
+	return allocateInOrder(req, candidates)
+}
+
+func allocationCandidates(warehouses map[uint64]*Warehouse, levels []*StockLevel) []*StockLevel {
+	var candidates []*StockLevel
+	for _, level := range levels {
+		if w, ok := warehouses[level.WarehouseID]; ok && w.IsActive() && level.Quantity > 0 {
+			candidates = append(candidates, level)
+		}
+	}
+	return candidates
+}
+
+// allocateInOrder takes the first candidate that can ship everything, falling
+// back to filling the request candidate by candidate when splitting is allowed.
+func allocateInOrder(req AllocationRequest, candidates []*StockLevel) ([]Allocation, error) {
+	if req.Quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+
+	for _, level := range candidates {
+		if level.Quantity >= req.Quantity {
+			return []Allocation{{WarehouseID: level.WarehouseID, Quantity: req.Quantity}}, nil
+		}
+	}
+	if !req.AllowSplit {
+		return nil, ErrNoFulfillingWarehouse
+	}
+
+	var allocations []Allocation
+	remaining := req.Quantity
+	for _, level := range candidates {
+		take := level.Quantity
+		if take > remaining {
+			take = remaining
+		}
+		allocations = append(allocations, Allocation{WarehouseID: level.WarehouseID, Quantity: take})
+		remaining -= take
+		if remaining == 0 {
+			return allocations, nil
+		}
+	}
+	return nil, ErrInsufficientStock
+}
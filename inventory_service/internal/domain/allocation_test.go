@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func allocationFixture(t *testing.T) (map[uint64]*Warehouse, []*StockLevel) {
+	t.Helper()
+	points := map[uint64]*GeoPoint{
+		1: {Latitude: 43.24, Longitude: 76.95}, // Almaty
+		2: {Latitude: 51.17, Longitude: 71.45}, // Astana
+		3: nil,
+		4: {Latitude: 43.30, Longitude: 76.90},
+	}
+	warehouses := make(map[uint64]*Warehouse)
+	for id, point := range points {
+		w, err := NewWarehouse("W", "W", point)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.SetID(id)
+		warehouses[id] = w
+	}
+	inactive := false
+	if err := warehouses[4].Apply(WarehouseChanges{IsActive: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+
+	levels := []*StockLevel{
+		{WarehouseID: 1, Quantity: 3},
+		{WarehouseID: 2, Quantity: 10},
+		{WarehouseID: 3, Quantity: 6},
+		{WarehouseID: 4, Quantity: 100},
+	}
+	return warehouses, levels
+}
+
+func TestAllocationStrategies(t *testing.T) {
+	warehouses, levels := allocationFixture(t)
+	nearAlmaty := &GeoPoint{Latitude: 43.25, Longitude: 76.94}
+
+	cases := []struct {
+		name     string
+		strategy AllocationStrategy
+		req      AllocationRequest
+		want     []Allocation
+		err      error
+	}{
+		{"nearest holding enough", NearestAllocation{}, AllocationRequest{Quantity: 2, Destination: nearAlmaty}, []Allocation{{1, 2}}, nil},
+		{"nearest skips the too small", NearestAllocation{}, AllocationRequest{Quantity: 5, Destination: nearAlmaty}, []Allocation{{2, 5}}, nil},
+		{"nearest split", NearestAllocation{}, AllocationRequest{Quantity: 15, Destination: nearAlmaty, AllowSplit: true}, []Allocation{{1, 3}, {2, 10}, {3, 2}}, nil},
+		{"nearest without destination", NearestAllocation{}, AllocationRequest{Quantity: 4}, []Allocation{{2, 4}}, nil},
+		{"most stock", MostStockAllocation{}, AllocationRequest{Quantity: 4}, []Allocation{{2, 4}}, nil},
+		{"most stock split", MostStockAllocation{}, AllocationRequest{Quantity: 12, AllowSplit: true}, []Allocation{{2, 10}, {3, 2}}, nil},
+		{"no single warehouse", MostStockAllocation{}, AllocationRequest{Quantity: 12}, nil, ErrNoFulfillingWarehouse},
+		{"not enough anywhere", MostStockAllocation{}, AllocationRequest{Quantity: 20, AllowSplit: true}, nil, ErrInsufficientStock},
+		{"zero quantity", MostStockAllocation{}, AllocationRequest{Quantity: 0}, nil, ErrInvalidQuantity},
+	}
+	for _, tc := range cases {
+		got, err := tc.strategy.Allocate(tc.req, warehouses, levels)
+		if !errors.Is(err, tc.err) || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: got %v, error %v", tc.name, got, err)
+		}
+	}
+}
+
+func TestAllocationStrategyByName(t *testing.T) {
+	for name, want := range map[string]string{"": AllocationNearest, "nearest": AllocationNearest, "most_stock": AllocationMostStock} {
+		strategy, err := AllocationStrategyByName(name)
+		if err != nil || strategy.Name() != want {
+			t.Errorf("%q: got %v, error %v", name, strategy, err)
+		}
+	}
+	if _, err := AllocationStrategyByName("random"); !errors.Is(err, ErrUnknownAllocationStrategy) {
+		t.Fatalf("got error %v", err)
+	}
+}
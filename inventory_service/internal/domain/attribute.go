@@ -0,0 +1,288 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAttributeNotFound       = notFound("attribute_not_found", "attribute not found")
+	ErrInvalidAttributeCode    = invalid("invalid_attribute_code", "attribute code must start with a letter and contain only lowercase letters, digits and underscores")
+	ErrInvalidAttributeType    = invalid("invalid_attribute_type", "attribute type must be one of: string, number, boolean, enum")
+	ErrMissingAllowedValues    = invalid("missing_allowed_values", "enum attributes need at least one allowed value")
+	ErrUnexpectedAllowedValues = invalid("unexpected_allowed_values", "allowed values only apply to string and enum attributes")
+	ErrDuplicateAttributeCode  = conflict("duplicate_attribute_code", "attribute code already exists in this category or its ancestors")
+	ErrInvalidAttributeFilter  = invalid("invalid_attribute_filter", "invalid attribute filter")
+	ErrTooManyAttributeFilters = invalid("too_many_attribute_filters", "too many attribute filters")
+)
+
+// MaxAttributeFilters bounds the attribute conditions of one listing query.
+const MaxAttributeFilters = 10
+
+var attributeCodePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type AttributeType string
+
+const (
+	AttributeString  AttributeType = "string"
+	AttributeNumber  AttributeType = "number"
+	AttributeBoolean AttributeType = "boolean"
+	AttributeEnum    AttributeType = "enum"
+)
+
+func (t AttributeType) Valid() bool {
+	switch t {
+	case AttributeString, AttributeNumber, AttributeBoolean, AttributeEnum:
+		return true
+	}
+	return false
+}
+
+// AttributeDefinition is one field of a category's attribute schema. Products
+// in the category and in all of its descendants carry a value for it.
+type AttributeDefinition struct {
+	ID         uint64
+	CategoryID uint64
+	Code       string
+	Name       string
+	Type       AttributeType
+	Unit       string
+	Required   bool
+	// AllowedValues restricts string and enum values; enums need at least one.
+	AllowedValues []string
+	CreatedAt     time.Time
+}
+
+func NewAttributeDefinition(categoryID uint64, code, name string, attrType AttributeType, unit string, required bool, allowedValues []string) (*AttributeDefinition, error) {
+	d := &AttributeDefinition{CategoryID: categoryID, Code: strings.TrimSpace(code), CreatedAt: time.Now()}
+	if !attributeCodePattern.MatchString(d.Code) {
+		return nil, ErrInvalidAttributeCode
+	}
+	if err := d.Update(name, attrType, unit, required, allowedValues); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Update changes everything but the code, which product values are keyed by.
+// Values already stored are not revalidated.
+func (d *AttributeDefinition) Update(name string, attrType AttributeType, unit string, required bool, allowedValues []string) error {
+	if !attrType.Valid() {
+		return ErrInvalidAttributeType
+	}
+	if attrType == AttributeEnum && len(allowedValues) == 0 {
+		return ErrMissingAllowedValues
+	}
+	if len(allowedValues) > 0 && attrType != AttributeString && attrType != AttributeEnum {
+		return ErrUnexpectedAllowedValues
+	}
+
+	d.Name = strings.TrimSpace(name)
+	if d.Name == "" {
+		d.Name = d.Code
+	}
+	d.Type = attrType
+	d.Unit = strings.TrimSpace(unit)
+	d.Required = required
+	d.AllowedValues = allowedValues
+	return nil
+}
+
+// Normalize checks a decoded JSON value against the definition and returns
+// it in its stored form.
+func (d *AttributeDefinition) Normalize(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case AttributeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case int:
+			return float64(v), nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a number")
+	case AttributeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("must be a boolean")
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		if len(d.AllowedValues) > 0 && !containsString(d.AllowedValues, s) {
+			return nil, fmt.Errorf("must be one of: %s", strings.Join(d.AllowedValues, ", "))
+		}
+		return s, nil
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// AttributeValidationError lists every attribute that failed validation,
+// keyed by attribute code.
+type AttributeValidationError struct {
+	Fields map[string]string
+}
+
+func (e *AttributeValidationError) Error() string {
+	codes := make([]string, 0, len(e.Fields))
+	for code := range e.Fields {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = code + " " + e.Fields[code]
+	}
+	return "invalid attributes: " + strings.Join(parts, "; ")
+}
+
+// Kind reports the values as unprocessable: the request is well formed but
+// does not fit the category's schema.
+func (e *AttributeValidationError) Kind() ErrorKind {
+	return KindUnprocessable
+}
+
+func (e *AttributeValidationError) Code() string {
+	return "invalid_attributes"
+}
+
+// ValidateAttributes checks values against a schema and returns them
+// normalized. Unknown codes and missing required attributes are errors.
+func ValidateAttributes(schema []*AttributeDefinition, values map[string]interface{}) (map[string]interface{}, error) {
+	definitions := make(map[string]*AttributeDefinition, len(schema))
+	for _, d := range schema {
+		definitions[d.Code] = d
+	}
+
+	normalized := make(map[string]interface{}, len(values))
+	failures := make(map[string]string)
+	for code, value := range values {
+		d, ok := definitions[code]
+		if !ok {
+			failures[code] = "is not defined for this category"
+			continue
+		}
+		if value == nil {
+			continue
+		}
+		v, err := d.Normalize(value)
+		if err != nil {
+			failures[code] = err.Error()
+			continue
+		}
+		normalized[code] = v
+	}
+	for _, d := range schema {
+		if _, ok := normalized[d.Code]; d.Required && !ok && failures[d.Code] == "" {
+			failures[d.Code] = "is required"
+		}
+	}
+
+	if len(failures) > 0 {
+		return nil, &AttributeValidationError{Fields: failures}
+	}
+	return normalized, nil
+}
+
+type AttributeOperator string
+
+const (
+	AttributeEq  AttributeOperator = "="
+	AttributeNe  AttributeOperator = "!="
+	AttributeGt  AttributeOperator = ">"
+	AttributeGte AttributeOperator = ">="
+	AttributeLt  AttributeOperator = "<"
+	AttributeLte AttributeOperator = "<="
+)
+
+// AttributeFilter restricts a listing by product attribute value. Equality
+// accepts a comma-separated list of alternatives; the ordering operators
+// need a number.
+type AttributeFilter struct {
+	Code     string
+	Operator AttributeOperator
+	Values   []string
+}
+
+var attributeFilterPattern = regexp.MustCompile(`^attr\.([a-z][a-z0-9_]{0,62})(>=|<=|!=|>|<|=)(.*)$`)
+
+// ParseAttributeFilter parses a query term such as attr.ram_gb>=16. It reports
+// false when the term is not an attribute filter at all.
+func ParseAttributeFilter(term string) (AttributeFilter, bool, error) {
+	if !strings.HasPrefix(term, "attr.") {
+		return AttributeFilter{}, false, nil
+	}
+	m := attributeFilterPattern.FindStringSubmatch(term)
+	if m == nil {
+		return AttributeFilter{}, true, ErrInvalidAttributeFilter
+	}
+
+	f := AttributeFilter{Code: m[1], Operator: AttributeOperator(m[2])}
+	if f.Operator == AttributeEq {
+		for _, v := range strings.Split(m[3], ",") {
+			f.Values = append(f.Values, strings.TrimSpace(v))
+		}
+	} else {
+		f.Values = []string{strings.TrimSpace(m[3])}
+	}
+	if err := f.Validate(); err != nil {
+		return AttributeFilter{}, true, err
+	}
+	return f, true, nil
+}
+
+func (f AttributeFilter) Validate() error {
+	if !attributeCodePattern.MatchString(f.Code) || len(f.Values) == 0 {
+		return ErrInvalidAttributeFilter
+	}
+	for _, v := range f.Values {
+		if v == "" {
+			return ErrInvalidAttributeFilter
+		}
+	}
+	switch f.Operator {
+	case AttributeEq, AttributeNe:
+		return nil
+	case AttributeGt, AttributeGte, AttributeLt, AttributeLte:
+		if _, err := strconv.ParseFloat(f.Values[0], 64); err != nil {
+			return fmt.Errorf("%w: %s needs a number", ErrInvalidAttributeFilter, f.Operator)
+		}
+		return nil
+	}
+	return ErrInvalidAttributeFilter
+}
+
+type AttributeRepository interface {
+	Create(ctx context.Context, definition *AttributeDefinition) error
+	GetByID(ctx context.Context, id uint64) (*AttributeDefinition, error)
+	Update(ctx context.Context, definition *AttributeDefinition) error
+	Delete(ctx context.Context, id uint64) error
+	// ListForCategory returns the schema that applies to products of the
+	// category: its own definitions and, when inherited is set, those of all
+	// its ancestors.
+	ListForCategory(ctx context.Context, categoryID uint64, inherited bool) ([]*AttributeDefinition, error)
+}
@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrBundleNotFound          = notFound("bundle_not_found", "product is not a bundle")
+	ErrEmptyBundle             = invalid("empty_bundle", "bundle must have at least one component")
+	ErrBundleComponentNotFound = unprocessable("bundle_component_not_found", "bundle component not found")
+	ErrNestedBundle            = conflict("nested_bundle", "bundles cannot contain or be contained in other bundles")
+	ErrInvalidBundlePricing    = invalid("invalid_bundle_pricing", "bundle pricing must be fixed or discount")
+	ErrInvalidBundleDiscount   = invalid("invalid_bundle_discount", "bundle discount must be a percentage between 0 and 100 with up to 2 decimals")
+	ErrBundleStockDerived      = conflict("bundle_stock_derived", "bundle stock is derived from its components; restock or adjust the components instead")
+	ErrBundlePriceDerived      = conflict("bundle_price_derived", "bundle price is derived from its components")
+	ErrBundleHasStock          = conflict("bundle_has_stock", "product has stock of its own; bring it to zero before making it a bundle")
+)
+
+// BundleDiscountRounding rounds derived bundle prices.
+const BundleDiscountRounding = money.HalfUp
+
+type BundlePricing string
+
+const (
+	// BundlePricingFixed keeps the bundle product's own price.
+	BundlePricingFixed BundlePricing = "fixed"
+	// BundlePricingDiscount prices the bundle at the sum of its components
+	// less a percentage, and follows component price changes.
+	BundlePricingDiscount BundlePricing = "discount"
+)
+
+func (p BundlePricing) Valid() bool {
+	return p == BundlePricingFixed || p == BundlePricingDiscount
+}
+
+// BundleComponent is one line of a bundle: Quantity units of ProductID go into
+// every bundle sold. Name, Price and Stock describe the component as read.
+type BundleComponent struct {
+	ProductID uint64
+	Quantity  int
+	Name      string
+	Price     money.Money
+	Stock     int
+}
+
+// Bundle turns a product into a kit of other products. The bundle has no stock
+// of its own: its availability is the number of complete kits the component
+// stock allows, and selling or reserving it takes stock from the components.
+type Bundle struct {
+	ProductID  uint64
+	Pricing    BundlePricing
+	Discount   *big.Rat // percentage, set for discount pricing only
+	Components []BundleComponent
+	UpdatedAt  time.Time
+}
+
+// NewBundle validates a bundle definition. Repeated components are merged and
+// components are kept in product ID order, which is also the order in which
+// their stock is locked.
+func NewBundle(productID uint64, pricing BundlePricing, discount string, components []BundleComponent) (*Bundle, error) {
+	if !pricing.Valid() {
+		return nil, ErrInvalidBundlePricing
+	}
+	if len(components) == 0 {
+		return nil, ErrEmptyBundle
+	}
+
+	bundle := &Bundle{ProductID: productID, Pricing: pricing}
+	if pricing == BundlePricingDiscount {
+		rate, err := ParseBundleDiscount(discount)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Discount = rate
+	} else if strings.TrimSpace(discount) != "" {
+		return nil, ErrInvalidBundleDiscount
+	}
+
+	quantities := make(map[uint64]int, len(components))
+	for _, c := range components {
+		if c.Quantity <= 0 {
+			return nil, ErrInvalidQuantity
+		}
+		if c.ProductID == productID {
+			return nil, ErrNestedBundle
+		}
+		quantities[c.ProductID] += c.Quantity
+	}
+	for id, quantity := range quantities {
+		bundle.Components = append(bundle.Components, BundleComponent{ProductID: id, Quantity: quantity})
+	}
+	sort.Slice(bundle.Components, func(i, j int) bool {
+		return bundle.Components[i].ProductID < bundle.Components[j].ProductID
+	})
+	return bundle, nil
+}
+
+var bundleDiscountPattern = regexp.MustCompile(`^\d{1,3}(\.\d{1,2})?$`)
+
+// ParseBundleDiscount reads a percentage such as "15" or "12.5". It must lie
+// strictly between 0 and 100.
+func ParseBundleDiscount(s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if !bundleDiscountPattern.MatchString(s) {
+		return nil, ErrInvalidBundleDiscount
+	}
+	rate, ok := new(big.Rat).SetString(s)
+	if !ok || rate.Sign() <= 0 || rate.Cmp(big.NewRat(100, 1)) >= 0 {
+		return nil, ErrInvalidBundleDiscount
+	}
+	return rate, nil
+}
+
+// DiscountString formats the discount the way it was stored, or "" for fixed
+// pricing.
+func (b *Bundle) DiscountString() string {
+	if b.Discount == nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.TrimRight(b.Discount.FloatString(2), "0"), ".")
+}
+
+// Available is the number of complete bundles the component stock allows.
+func (b *Bundle) Available() int {
+	available := -1
+	for _, c := range b.Components {
+		if n := c.Stock / c.Quantity; available < 0 || n < available {
+			available = n
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// ComponentsTotal is the price of buying every component separately.
+func (b *Bundle) ComponentsTotal() (money.Money, error) {
+	var total money.Money
+	for i, c := range b.Components {
+		line, err := c.Price.Mul(int64(c.Quantity))
+		if err != nil {
+			return money.Money{}, err
+		}
+		if i == 0 {
+			total = line
+			continue
+		}
+		if total, err = total.Add(line); err != nil {
+			return money.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// DiscountedPrice applies the discount to the components total. It is only
+// meaningful for discount pricing.
+func (b *Bundle) DiscountedPrice() (money.Money, error) {
+	total, err := b.ComponentsTotal()
+	if err != nil {
+		return money.Money{}, err
+	}
+	factor := new(big.Rat).Sub(big.NewRat(1, 1), new(big.Rat).Quo(b.Discount, big.NewRat(100, 1)))
+	return total.MulRat(factor, BundleDiscountRounding)
+}
+
+type BundleRepository interface {
+	// Get returns the bundle with its components' current name, price and
+	// stock, or nil when the product is not a bundle.
+	Get(ctx context.Context, productID uint64) (*Bundle, error)
+	// Save defines or replaces the bundle and, for discount pricing, sets the
+	// product price from the components.
+	Save(ctx context.Context, bundle *Bundle) error
+	// Delete turns the bundle back into a plain product with its own stock.
+	Delete(ctx context.Context, productID uint64) error
+	// LinkedProducts returns the products whose stock or price follows that
+	// of productID, or that it follows: the bundles it is a component of, or
+	// its components when it is a bundle.
+	LinkedProducts(ctx context.Context, productID uint64) ([]uint64, error)
+}
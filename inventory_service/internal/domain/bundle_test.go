@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestNewBundle(t *testing.T) {
+	for name, tc := range map[string]struct {
+		pricing    BundlePricing
+		discount   string
+		components []BundleComponent
+		want       error
+	}{
+		"unknown pricing":     {"free", "", []BundleComponent{{ProductID: 2, Quantity: 1}}, ErrInvalidBundlePricing},
+		"no components":       {BundlePricingFixed, "", nil, ErrEmptyBundle},
+		"zero quantity":       {BundlePricingFixed, "", []BundleComponent{{ProductID: 2}}, ErrInvalidQuantity},
+		"contains itself":     {BundlePricingFixed, "", []BundleComponent{{ProductID: 1, Quantity: 1}}, ErrNestedBundle},
+		"fixed with discount": {BundlePricingFixed, "10", []BundleComponent{{ProductID: 2, Quantity: 1}}, ErrInvalidBundleDiscount},
+		"discount without it": {BundlePricingDiscount, "", []BundleComponent{{ProductID: 2, Quantity: 1}}, ErrInvalidBundleDiscount},
+	} {
+		if _, err := NewBundle(1, tc.pricing, tc.discount, tc.components); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got error %v, want %v", name, err, tc.want)
+		}
+	}
+
+	bundle, err := NewBundle(1, BundlePricingDiscount, "12.5", []BundleComponent{
+		{ProductID: 3, Quantity: 1},
+		{ProductID: 2, Quantity: 1},
+		{ProductID: 3, Quantity: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundle.Components) != 2 || bundle.Components[0].ProductID != 2 || bundle.Components[1].Quantity != 3 {
+		t.Fatalf("got components %+v, want them merged in product ID order", bundle.Components)
+	}
+	if bundle.DiscountString() != "12.5" {
+		t.Fatalf("got discount %q", bundle.DiscountString())
+	}
+}
+
+func TestParseBundleDiscount(t *testing.T) {
+	for _, s := range []string{"15", " 12.5 ", "99.99", "0.01"} {
+		if _, err := ParseBundleDiscount(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	for _, s := range []string{"0", "100", "12.345", "-5", "ten", ""} {
+		if _, err := ParseBundleDiscount(s); !errors.Is(err, ErrInvalidBundleDiscount) {
+			t.Errorf("%q: got error %v", s, err)
+		}
+	}
+}
+
+func TestBundleAvailableAndPrice(t *testing.T) {
+	bundle, err := NewBundle(1, BundlePricingDiscount, "10", []BundleComponent{
+		{ProductID: 2, Quantity: 2},
+		{ProductID: 3, Quantity: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle.Components[0].Price, bundle.Components[0].Stock = mustMoney(t, "5.00"), 7
+	bundle.Components[1].Price, bundle.Components[1].Stock = mustMoney(t, "3.35"), 5
+
+	if got := bundle.Available(); got != 3 {
+		t.Fatalf("got %d available, want 3", got)
+	}
+	total, err := bundle.ComponentsTotal()
+	if err != nil || total.Decimal() != "13.35" {
+		t.Fatalf("got total %s, %v", total.Decimal(), err)
+	}
+	price, err := bundle.DiscountedPrice()
+	if err != nil || price.Decimal() != "12.02" {
+		t.Fatalf("got discounted price %s, %v, want 12.02 rounded half up", price.Decimal(), err)
+	}
+
+	bundle.Components[1].Stock = 0
+	if got := bundle.Available(); got != 0 {
+		t.Fatalf("got %d available with a component out of stock", got)
+	}
+}
+
+func TestBundleStockChangedPayload(t *testing.T) {
+	data, err := json.Marshal(NewBundleStockChanged(7, 3, 1, SystemActor))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The database writes the same payload when a component change moves
+	// the bundle's stock at commit; see migrations/000019_outbox.up.sql.
+	want := `{"product_id":7,"delta":-2,"stock":1,"reason":"bundle","actor":"system"}`
+	if string(data) != want {
+		t.Fatalf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+// CacheStats describes one read-through cache.
+type CacheStats struct {
+	Name string
+	// Hits counts reads served from the cache, including reads that waited
+	// for a concurrent load of the same key.
+	Hits      uint64
+	Misses    uint64
+	Coalesced uint64
+	Evictions uint64
+	Size      int
+	Capacity  int
+}
+
+// HitRatio is the share of reads served without a load of their own.
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// CacheStatsProvider is implemented by caches that report their statistics.
+type CacheStatsProvider interface {
+	Stats() CacheStats
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+var (
+	ErrUnknownCatalogEntity = invalid("unknown_catalog_entity", "entity must be one of: products, categories")
+	ErrUnknownCatalogFormat = invalid("unknown_catalog_format", "format must be one of: csv, ndjson")
+	ErrImportJobNotFound    = notFound("import_job_not_found", "import job not found")
+	ErrMissingImportKey     = invalid("missing_import_key", "sku or external_id is required")
+	ErrMissingExternalID    = invalid("missing_external_id", "external_id is required")
+	ErrMissingName          = invalid("missing_name", "name is required")
+)
+
+// MaxImportRowErrors caps the row errors kept on a job; the failed count
+// keeps growing past it.
+const MaxImportRowErrors = 1000
+
+type CatalogEntity string
+
+const (
+	CatalogProducts   CatalogEntity = "products"
+	CatalogCategories CatalogEntity = "categories"
+)
+
+func (e CatalogEntity) Valid() bool {
+	return e == CatalogProducts || e == CatalogCategories
+}
+
+type CatalogFormat string
+
+const (
+	CatalogCSV    CatalogFormat = "csv"
+	CatalogNDJSON CatalogFormat = "ndjson"
+)
+
+func (f CatalogFormat) Valid() bool {
+	return f == CatalogCSV || f == CatalogNDJSON
+}
+
+type ImportJobStatus string
+
+const (
+	ImportQueued    ImportJobStatus = "queued"
+	ImportRunning   ImportJobStatus = "running"
+	ImportCompleted ImportJobStatus = "completed"
+	ImportFailed    ImportJobStatus = "failed"
+)
+
+// ImportRowError reports a row that was rejected. Row is the line number in
+// the uploaded file, counting a CSV header as line 1.
+type ImportRowError struct {
+	Row     int
+	Key     string
+	Message string
+}
+
+func (e *ImportRowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// ImportJob tracks an asynchronous bulk import. A dry run validates and
+// counts every row exactly as a real import would, without writing.
+type ImportJob struct {
+	ID              string
+	Entity          CatalogEntity
+	Format          CatalogFormat
+	DryRun          bool
+	Status          ImportJobStatus
+	Processed       int
+	Created         int
+	Updated         int
+	Failed          int
+	Errors          []ImportRowError
+	ErrorsTruncated bool
+	// Error is set when the job as a whole failed, e.g. on an unreadable file.
+	Error      string
+	CreatedAt  time.Time
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+func NewImportJob(id string, entity CatalogEntity, format CatalogFormat, dryRun bool) *ImportJob {
+	return &ImportJob{
+		ID:        id,
+		Entity:    entity,
+		Format:    format,
+		DryRun:    dryRun,
+		Status:    ImportQueued,
+		Errors:    []ImportRowError{},
+		CreatedAt: time.Now(),
+	}
+}
+
+func (j *ImportJob) Start() {
+	now := time.Now()
+	j.Status = ImportRunning
+	j.StartedAt = &now
+}
+
+func (j *ImportJob) Finish(err error) {
+	now := time.Now()
+	j.Status = ImportCompleted
+	if err != nil {
+		j.Status = ImportFailed
+		j.Error = err.Error()
+	}
+	j.FinishedAt = &now
+}
+
+func (j *ImportJob) RecordRow(created bool) {
+	j.Processed++
+	if created {
+		j.Created++
+	} else {
+		j.Updated++
+	}
+}
+
+func (j *ImportJob) RecordRowError(e ImportRowError) {
+	j.Processed++
+	j.Failed++
+	if len(j.Errors) < MaxImportRowErrors {
+		j.Errors = append(j.Errors, e)
+	} else {
+		j.ErrorsTruncated = true
+	}
+}
+
+// ProductRecord is one product row of an import file.
+type ProductRecord struct {
+	Row         int
+	SKU         string
+	ExternalID  string
+	Name        string
+	Description string
+	Price       money.Money
+	// Stock is nil when the row leaves stock unchanged, or zero for a new
+	// product.
+	Stock              *int
+	CategoryExternalID string
+	// Attributes is nil when the row leaves attribute values unchanged.
+	Attributes map[string]interface{}
+}
+
+// Key identifies the record in row errors.
+func (r *ProductRecord) Key() string {
+	if r.SKU != "" {
+		return r.SKU
+	}
+	return r.ExternalID
+}
+
+// CategoryRecord is one category row of an import file. Parents are referenced
+// by external ID and must appear before their children.
+type CategoryRecord struct {
+	Row              int
+	ExternalID       string
+	Name             string
+	Description      string
+	ParentExternalID string
+}
+
+// ProductRecordReader streams records from an import file. Next returns
+// io.EOF at the end, an *ImportRowError for a malformed row that can be
+// skipped, and any other error when reading cannot continue.
+type ProductRecordReader interface {
+	Next() (*ProductRecord, error)
+}
+
+// CategoryRecordReader behaves like ProductRecordReader.
+type CategoryRecordReader interface {
+	Next() (*CategoryRecord, error)
+}
+
+type ImportJobStore interface {
+	// Save stores a snapshot of the job, replacing any earlier one.
+	Save(ctx context.Context, job *ImportJob) error
+	// Get returns a snapshot of the job, or nil when it is unknown.
+	Get(ctx context.Context, id string) (*ImportJob, error)
+}
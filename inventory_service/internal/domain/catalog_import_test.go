@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestImportJobLifecycle(t *testing.T) {
+	job := NewImportJob("job", CatalogProducts, CatalogCSV, true)
+	if job.Status != ImportQueued || job.StartedAt != nil || job.Errors == nil {
+		t.Fatalf("new job: got %+v", job)
+	}
+
+	job.Start()
+	if job.Status != ImportRunning || job.StartedAt == nil {
+		t.Fatalf("started job: got %+v", job)
+	}
+
+	job.RecordRow(true)
+	job.RecordRow(false)
+	job.RecordRowError(ImportRowError{Row: 4, Key: "A", Message: "bad"})
+	if job.Processed != 3 || job.Created != 1 || job.Updated != 1 || job.Failed != 1 {
+		t.Fatalf("got processed %d, created %d, updated %d, failed %d", job.Processed, job.Created, job.Updated, job.Failed)
+	}
+
+	job.Finish(nil)
+	if job.Status != ImportCompleted || job.FinishedAt == nil || job.Error != "" {
+		t.Fatalf("finished job: got %+v", job)
+	}
+
+	failed := NewImportJob("failed", CatalogCategories, CatalogNDJSON, false)
+	failed.Start()
+	failed.Finish(errors.New("unreadable"))
+	if failed.Status != ImportFailed || failed.Error != "unreadable" {
+		t.Fatalf("failed job: got %+v", failed)
+	}
+}
+
+func TestImportJobCapsRowErrors(t *testing.T) {
+	job := NewImportJob("job", CatalogProducts, CatalogCSV, false)
+	for i := 0; i < MaxImportRowErrors+5; i++ {
+		job.RecordRowError(ImportRowError{Row: i + 2, Message: "bad"})
+	}
+	if len(job.Errors) != MaxImportRowErrors || !job.ErrorsTruncated {
+		t.Fatalf("got %d errors, truncated %v", len(job.Errors), job.ErrorsTruncated)
+	}
+	if job.Failed != MaxImportRowErrors+5 {
+		t.Fatalf("got failed %d, want %d", job.Failed, MaxImportRowErrors+5)
+	}
+}
+
+func TestProductRecordKey(t *testing.T) {
+	if key := (&ProductRecord{SKU: "A", ExternalID: "x"}).Key(); key != "A" {
+		t.Fatalf("got key %q, want the SKU", key)
+	}
+	if key := (&ProductRecord{ExternalID: "x"}).Key(); key != "x" {
+		t.Fatalf("got key %q, want the external ID", key)
+	}
+	if err := (&ImportRowError{Row: 3, Message: "bad"}).Error(); err != "row 3: bad" {
+		t.Fatalf("got %q", err)
+	}
+}
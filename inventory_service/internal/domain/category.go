@@ -2,20 +2,76 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"strings"
 	"time"
 )
 
 var (
-	ErrCategoryNotFound = errors.New("category not found")
+	ErrCategoryNotFound       = notFound("category_not_found", "category not found")
+	ErrParentCategoryNotFound = unprocessable("parent_category_not_found", "parent category not found")
+	ErrCategoryCycle          = conflict("category_cycle", "category cannot be moved under itself or its descendants")
+	ErrCategoryHasProducts    = conflict("category_has_products", "category or its subcategories still contain products")
+	ErrInvalidDeletePolicy    = invalid("invalid_delete_policy", "delete policy must be one of reject, reassign or cascade")
+	ErrReassignTargetRequired = invalid("reassign_target_required", "reassign policy needs a target category")
+	ErrReassignTargetNotFound = unprocessable("reassign_target_not_found", "target category not found")
+	ErrInvalidReassignTarget  = invalid("invalid_reassign_target", "products cannot be reassigned to a category that is being deleted")
 )
 
+// CategoryDeletePolicy decides what happens to the live products of a
+// category being deleted, including those in its subcategories. The
+// subcategories themselves are always deleted along with it.
+type CategoryDeletePolicy string
+
+const (
+	// DeleteReject refuses to delete a category that still has products.
+	DeleteReject CategoryDeletePolicy = "reject"
+	// DeleteReassign moves the products to another category first. Their
+	// attribute values are kept as they are.
+	DeleteReassign CategoryDeletePolicy = "reassign"
+	// DeleteCascade moves the products to the trash along with the category.
+	DeleteCascade CategoryDeletePolicy = "cascade"
+)
+
+func ParseCategoryDeletePolicy(s string) (CategoryDeletePolicy, error) {
+	switch policy := CategoryDeletePolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case DeleteReject, DeleteReassign, DeleteCascade:
+		return policy, nil
+	}
+	return "", ErrInvalidDeletePolicy
+}
+
+type CategoryDeleteOptions struct {
+	Policy CategoryDeletePolicy
+	// TargetID receives the products under DeleteReassign.
+	TargetID uint64
+}
+
+func (o CategoryDeleteOptions) Validate() error {
+	switch o.Policy {
+	case DeleteReject, DeleteCascade:
+		return nil
+	case DeleteReassign:
+		if o.TargetID == 0 {
+			return ErrReassignTargetRequired
+		}
+		return nil
+	}
+	return ErrInvalidDeletePolicy
+}
+
 type Category struct {
 	id          uint64
+	externalID  string
+	slug        string
 	name        string
 	description string
+	parentID    uint64
 	createdAt   time.Time
 	updatedAt   time.Time
+	deletedAt   time.Time
+	version     int
+
+	productCount int
 }
 
 func NewCategory(name, description string) *Category {
@@ -25,9 +81,16 @@ func NewCategory(name, description string) *Category {
 		description: description,
 		createdAt:   now,
 		updatedAt:   now,
+		version:     1,
 	}
 }
 
+// Clone returns a copy of c.
+func (c *Category) Clone() *Category {
+	clone := *c
+	return &clone
+}
+
 func (c *Category) ID() uint64 {
 	return c.id
 }
@@ -36,6 +99,42 @@ func (c *Category) SetID(id uint64) {
 	c.id = id
 }
 
+// ExternalID is the category's key in an upstream system, or empty.
+func (c *Category) ExternalID() string {
+	return c.externalID
+}
+
+func (c *Category) SetExternalID(externalID string) {
+	c.externalID = strings.TrimSpace(externalID)
+}
+
+// Slug is the category's URL name; see Product.Slug.
+func (c *Category) Slug() string {
+	return c.slug
+}
+
+// SetSlug assigns a slug, or with an empty one asks the repository to derive
+// one from the name when the category is saved.
+func (c *Category) SetSlug(slug string) {
+	c.slug = strings.TrimSpace(slug)
+}
+
+// ApplyIdentifiers sets the external ID and slug when they are not nil; see
+// Product.ApplyIdentifiers.
+func (c *Category) ApplyIdentifiers(externalID, slug *string) error {
+	if externalID != nil {
+		c.externalID = strings.TrimSpace(*externalID)
+	}
+	if slug != nil {
+		requested, err := requestedSlug(*slug)
+		if err != nil {
+			return err
+		}
+		c.slug = requested
+	}
+	return nil
+}
+
 func (c *Category) Name() string {
 	return c.name
 }
@@ -44,6 +143,26 @@ func (c *Category) Description() string {
 	return c.description
 }
 
+// ParentID returns the parent category ID, or 0 for a root category.
+func (c *Category) ParentID() uint64 {
+	return c.parentID
+}
+
+func (c *Category) SetParentID(parentID uint64) {
+	c.parentID = parentID
+}
+
+// MoveTo re-parents the category. Moving under a descendant is detected by
+// the repository, which sees the whole tree.
+func (c *Category) MoveTo(parentID uint64) error {
+	if parentID != 0 && parentID == c.id {
+		return ErrCategoryCycle
+	}
+	c.parentID = parentID
+	c.updatedAt = time.Now()
+	return nil
+}
+
 func (c *Category) CreatedAt() time.Time {
 	return c.createdAt
 }
@@ -52,16 +171,116 @@ func (c *Category) UpdatedAt() time.Time {
 	return c.updatedAt
 }
 
+// ProductCount is the number of live products assigned directly to the
+// category, as of when it was read.
+func (c *Category) ProductCount() int {
+	return c.productCount
+}
+
+func (c *Category) SetProductCount(count int) {
+	c.productCount = count
+}
+
+// DeletedAt is when the category was moved to the trash, or zero for a live
+// category.
+func (c *Category) DeletedAt() time.Time {
+	return c.deletedAt
+}
+
+func (c *Category) MarkDeleted(at time.Time) {
+	c.deletedAt = at
+	c.updatedAt = at
+}
+
+// Version is incremented on every persisted change and is used for
+// optimistic concurrency control.
+func (c *Category) Version() int {
+	return c.version
+}
+
+func (c *Category) SetVersion(version int) {
+	c.version = version
+}
+
+func (c *Category) SetTimestamps(createdAt, updatedAt time.Time) {
+	c.createdAt = createdAt
+	c.updatedAt = updatedAt
+}
+
 func (c *Category) Update(name, description string) {
 	c.name = name
 	c.description = description
 	c.updatedAt = time.Now()
 }
 
+// CategoryChanges are the fields an update replaces. Nil identifiers are
+// left as they are. The parent is changed by a move instead.
+type CategoryChanges struct {
+	Name        string
+	Description string
+	ExternalID  *string
+	Slug        *string
+}
+
+func (c *Category) Apply(changes CategoryChanges) error {
+	c.Update(changes.Name, changes.Description)
+	return c.ApplyIdentifiers(changes.ExternalID, changes.Slug)
+}
+
 type CategoryRepository interface {
 	Create(ctx context.Context, category *Category) error
 	GetByID(ctx context.Context, id uint64) (*Category, error)
 	List(ctx context.Context, offset, limit int) ([]*Category, error)
 	Update(ctx context.Context, category *Category) error
-	Delete(ctx context.Context, id uint64) error
+	// Delete moves the category and its subcategories to the trash, dealing
+	// with their products as the options say.
+	Delete(ctx context.Context, id uint64, version int, options CategoryDeleteOptions) error
+	// Tree returns the category with the given ID and all of its live
+	// descendants, or the whole catalog tree when rootID is 0. A live
+	// category whose parent is in the trash is a root of the whole tree, so
+	// that it stays reachable.
+	Tree(ctx context.Context, rootID uint64) ([]*Category, error)
+	// Path returns the category and its ancestors, ordered from the root.
+	Path(ctx context.Context, id uint64) ([]*Category, error)
+	// Move re-parents a category together with its subtree.
+	Move(ctx context.Context, category *Category) error
+	// GetByExternalID and GetBySlug return nil when no live category
+	// matches.
+	GetByExternalID(ctx context.Context, externalID string) (*Category, error)
+	GetBySlug(ctx context.Context, slug string) (*Category, error)
+	// SlugRedirect returns the current slug of the live category that used
+	// to have the given slug, or an empty string.
+	SlugRedirect(ctx context.Context, slug string) (string, error)
+	// ListDeleted returns the categories in the trash, most recently deleted
+	// first.
+	ListDeleted(ctx context.Context, offset, limit int) ([]*Category, error)
+	// Restore takes a category out of the trash, together with the
+	// descendants deleted along with it. It fails with ErrCategoryTrashed
+	// while its parent is still deleted.
+	Restore(ctx context.Context, id uint64, version int) error
+}
+
+type CategoryNode struct {
+	Category *Category
+	Children []*CategoryNode
+}
+
+// BuildCategoryTree links a flat list of categories into a forest. Any
+// category whose parent is not in the list becomes a root.
+func BuildCategoryTree(categories []*Category) []*CategoryNode {
+	nodes := make(map[uint64]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.ID()] = &CategoryNode{Category: category}
+	}
+
+	var roots []*CategoryNode
+	for _, category := range categories {
+		node := nodes[category.ID()]
+		if parent, ok := nodes[category.ParentID()]; ok && category.ParentID() != 0 {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
 }
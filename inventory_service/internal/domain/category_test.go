@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func newTestCategory(id, parentID uint64, name string) *Category {
+	c := NewCategory(name, "")
+	c.SetID(id)
+	c.SetParentID(parentID)
+	return c
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	roots := BuildCategoryTree([]*Category{
+		newTestCategory(1, 0, "Home"),
+		newTestCategory(2, 1, "Lighting"),
+		newTestCategory(3, 2, "Lamps"),
+		newTestCategory(4, 1, "Textiles"),
+		// The parent of 5 is not in the list, as when it is in the trash.
+		newTestCategory(5, 99, "Orphan"),
+	})
+
+	if len(roots) != 2 || roots[0].Category.ID() != 1 || roots[1].Category.ID() != 5 {
+		t.Fatalf("got %d roots", len(roots))
+	}
+	home := roots[0]
+	if len(home.Children) != 2 || home.Children[0].Category.ID() != 2 || home.Children[1].Category.ID() != 4 {
+		t.Fatalf("got %d children under the root", len(home.Children))
+	}
+	if lighting := home.Children[0]; len(lighting.Children) != 1 || lighting.Children[0].Category.ID() != 3 {
+		t.Fatal("lamps are not under lighting")
+	}
+}
+
+func TestCategoryMoveTo(t *testing.T) {
+	c := newTestCategory(3, 1, "Lamps")
+	if err := c.MoveTo(3); !errors.Is(err, ErrCategoryCycle) {
+		t.Fatalf("got error %v moving a category under itself", err)
+	}
+	if err := c.MoveTo(0); err != nil || c.ParentID() != 0 {
+		t.Fatalf("got parent %d, error %v moving to the top level", c.ParentID(), err)
+	}
+}
+
+func TestCategoryDeletePolicy(t *testing.T) {
+	for input, want := range map[string]CategoryDeletePolicy{"reject": DeleteReject, " Reassign ": DeleteReassign, "CASCADE": DeleteCascade} {
+		got, err := ParseCategoryDeletePolicy(input)
+		if err != nil || got != want {
+			t.Errorf("ParseCategoryDeletePolicy(%q): got %q, %v", input, got, err)
+		}
+	}
+	for _, input := range []string{"", "delete", "orphan"} {
+		if _, err := ParseCategoryDeletePolicy(input); !errors.Is(err, ErrInvalidDeletePolicy) {
+			t.Errorf("ParseCategoryDeletePolicy(%q): got error %v", input, err)
+		}
+	}
+
+	tests := []struct {
+		options CategoryDeleteOptions
+		want    error
+	}{
+		{CategoryDeleteOptions{Policy: DeleteReject}, nil},
+		{CategoryDeleteOptions{Policy: DeleteCascade, TargetID: 4}, nil},
+		{CategoryDeleteOptions{Policy: DeleteReassign, TargetID: 4}, nil},
+		{CategoryDeleteOptions{Policy: DeleteReassign}, ErrReassignTargetRequired},
+		{CategoryDeleteOptions{}, ErrInvalidDeletePolicy},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("%+v: got error %v, want %v", tt.options, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"math/big"
+	"regexp"
+	"time"
+)
+
+var (
+	ErrUnsupportedCurrency   = invalid("unsupported_currency", "currency is not supported")
+	ErrExchangeRateNotFound  = notFound("exchange_rate_not_found", "no exchange rate between the currencies")
+	ErrInvalidExchangeRate   = invalid("invalid_exchange_rate", "exchange rate must be a positive decimal number with at most 12 decimal places")
+	ErrSameCurrencyRate      = invalid("same_currency_rate", "exchange rate needs two different currencies")
+	ErrListPriceNotFound     = notFound("list_price_not_found", "no list price in that currency")
+	ErrBaseCurrencyListPrice = conflict("base_currency_list_price", "the price in the product's own currency is the product price")
+)
+
+// ConversionRounding rounds prices converted through an exchange rate.
+const ConversionRounding = money.HalfUp
+
+// ExchangeRate says how many units of Quote one unit of Base buys.
+type ExchangeRate struct {
+	Base      money.Currency
+	Quote     money.Currency
+	Rate      *big.Rat
+	Actor     string
+	UpdatedAt time.Time
+}
+
+// Rates are stored with twelve decimal places.
+var ratePattern = regexp.MustCompile(`^\d+(\.\d{1,12})?$`)
+
+func NewExchangeRate(base, quote money.Currency, rate string, actor string) (*ExchangeRate, error) {
+	if !base.Valid() || !quote.Valid() {
+		return nil, money.ErrInvalidCurrency
+	}
+	if base == quote {
+		return nil, ErrSameCurrencyRate
+	}
+	if !ratePattern.MatchString(rate) {
+		return nil, ErrInvalidExchangeRate
+	}
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidExchangeRate
+	}
+	if actor == "" {
+		actor = SystemActor
+	}
+	return &ExchangeRate{Base: base, Quote: quote, Rate: r, Actor: actor, UpdatedAt: time.Now()}, nil
+}
+
+type ExchangeRates []*ExchangeRate
+
+// Lookup finds the rate from one currency to another, using the reverse pair
+// inverted when only that one is on file.
+func (rates ExchangeRates) Lookup(from, to money.Currency) (*big.Rat, bool) {
+	for _, r := range rates {
+		if r.Base == from && r.Quote == to {
+			return r.Rate, true
+		}
+	}
+	for _, r := range rates {
+		if r.Base == to && r.Quote == from {
+			return new(big.Rat).Inv(r.Rate), true
+		}
+	}
+	return nil, false
+}
+
+// PriceSource tells where a localized price came from.
+type PriceSource string
+
+const (
+	PriceFromBase  PriceSource = "base"
+	PriceFromList  PriceSource = "list"
+	PriceConverted PriceSource = "converted"
+)
+
+type LocalizedPrice struct {
+	Price  money.Money
+	Source PriceSource
+}
+
+// ListPrice is an explicit product price in a currency other than the
+// product's own.
+type ListPrice struct {
+	ProductID uint64
+	Price     money.Money
+	UpdatedAt time.Time
+}
+
+// PriceLocalizer expresses prices in one currency: a product's list price in
+// that currency when there is one, otherwise its base price converted.
+type PriceLocalizer struct {
+	Currency   money.Currency
+	ListPrices map[uint64]money.Money
+	Rates      ExchangeRates
+}
+
+func (l *PriceLocalizer) Product(p *Product) (LocalizedPrice, error) {
+	if price, ok := l.ListPrices[p.ID()]; ok {
+		return LocalizedPrice{Price: price, Source: PriceFromList}, nil
+	}
+	return l.Convert(p.Price())
+}
+
+// Convert expresses an amount that has no list price, such as a variant
+// override.
+func (l *PriceLocalizer) Convert(amount money.Money) (LocalizedPrice, error) {
+	if amount.Currency() == l.Currency {
+		return LocalizedPrice{Price: amount, Source: PriceFromBase}, nil
+	}
+	rate, ok := l.Rates.Lookup(amount.Currency(), l.Currency)
+	if !ok {
+		return LocalizedPrice{}, ErrExchangeRateNotFound
+	}
+	converted, err := amount.Convert(rate, l.Currency, ConversionRounding)
+	if err != nil {
+		return LocalizedPrice{}, err
+	}
+	return LocalizedPrice{Price: converted, Source: PriceConverted}, nil
+}
+
+type PriceListRepository interface {
+	ListForProduct(ctx context.Context, productID uint64) ([]*ListPrice, error)
+	// ListForProducts returns the list prices in one currency, keyed by
+	// product ID; products without one are absent.
+	ListForProducts(ctx context.Context, productIDs []uint64, currency money.Currency) (map[uint64]money.Money, error)
+	Set(ctx context.Context, price *ListPrice) error
+	Delete(ctx context.Context, productID uint64, currency money.Currency) error
+}
+
+type ExchangeRateRepository interface {
+	List(ctx context.Context) (ExchangeRates, error)
+	Set(ctx context.Context, rate *ExchangeRate) error
+	Delete(ctx context.Context, base, quote money.Currency) error
+}
@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+func TestNewExchangeRate(t *testing.T) {
+	tests := []struct {
+		name        string
+		base, quote money.Currency
+		rate        string
+		want        error
+	}{
+		{"whole rate", "USD", "JPY", "151", nil},
+		{"fractional rate", "USD", "EUR", "0.923456789012", nil},
+		{"too precise", "USD", "EUR", "0.9234567890123", ErrInvalidExchangeRate},
+		{"zero", "USD", "EUR", "0", ErrInvalidExchangeRate},
+		{"negative", "USD", "EUR", "-0.9", ErrInvalidExchangeRate},
+		{"exponent", "USD", "EUR", "9e-1", ErrInvalidExchangeRate},
+		{"same currency", "USD", "USD", "1", ErrSameCurrencyRate},
+		{"invalid currency", "usd", "EUR", "0.9", money.ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := NewExchangeRate(tt.base, tt.quote, tt.rate, "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			if err == nil && (rate.Actor != SystemActor || rate.Base != tt.base || rate.Rate.Sign() <= 0) {
+				t.Fatalf("got %+v", rate)
+			}
+		})
+	}
+}
+
+func mustRate(t *testing.T, base, quote money.Currency, rate string) *ExchangeRate {
+	t.Helper()
+	r, err := NewExchangeRate(base, quote, rate, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestExchangeRatesLookup(t *testing.T) {
+	rates := ExchangeRates{
+		mustRate(t, "USD", "EUR", "0.8"),
+		mustRate(t, "EUR", "USD", "1.3"),
+		mustRate(t, "GBP", "USD", "1.25"),
+	}
+
+	// A direct pair wins over the inverse of the reverse pair.
+	if rate, ok := rates.Lookup("EUR", "USD"); !ok || rate.RatString() != "13/10" {
+		t.Fatalf("got %v, %v", rate, ok)
+	}
+	if rate, ok := rates.Lookup("USD", "GBP"); !ok || rate.RatString() != "4/5" {
+		t.Fatalf("got inverse %v, %v", rate, ok)
+	}
+	if _, ok := rates.Lookup("USD", "JPY"); ok {
+		t.Fatal("found a rate that is not on file")
+	}
+
+	// The inverse is a fresh value; the stored rate is untouched.
+	rates.Lookup("USD", "GBP")
+	if rates[2].Rate.RatString() != "5/4" {
+		t.Fatalf("stored rate changed to %s", rates[2].Rate.RatString())
+	}
+}
+
+func TestPriceLocalizer(t *testing.T) {
+	usd := func(amount string) money.Money {
+		m, err := money.Parse(amount, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	listed, err := money.Parse("9.00", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withList, err := NewProduct("Lamp", "", usd("10.00"), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withList.SetID(1)
+	converted, err := NewProduct("Bulb", "", usd("10.01"), 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted.SetID(2)
+
+	localizer := &PriceLocalizer{
+		Currency:   "EUR",
+		ListPrices: map[uint64]money.Money{1: listed},
+		Rates:      ExchangeRates{mustRate(t, "EUR", "USD", "2")},
+	}
+
+	price, err := localizer.Product(withList)
+	if err != nil || price.Source != PriceFromList || !price.Price.Equal(listed) {
+		t.Fatalf("list price: got %+v, %v", price, err)
+	}
+	// 10.01 USD at 0.5 is 5.005 EUR, which rounds half up.
+	price, err = localizer.Product(converted)
+	if err != nil || price.Source != PriceConverted || price.Price.String() != "5.01 EUR" {
+		t.Fatalf("converted price: got %+v, %v", price, err)
+	}
+	price, err = localizer.Convert(listed)
+	if err != nil || price.Source != PriceFromBase || !price.Price.Equal(listed) {
+		t.Fatalf("same currency: got %+v, %v", price, err)
+	}
+
+	yen, err := money.Parse("100", "JPY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := localizer.Convert(yen); !errors.Is(err, ErrExchangeRateNotFound) {
+		t.Fatalf("got error %v, want %v", err, ErrExchangeRateNotFound)
+	}
+}
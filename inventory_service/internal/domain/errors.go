@@ -0,0 +1,89 @@
+package domain
+
+import "errors"
+
+// ErrorKind classifies domain errors by what went wrong, so that callers can
+// react to a whole class of errors, such as answering every not-found with
+// the same status, without listing each one.
+type ErrorKind int
+
+const (
+	// KindUnknown is the kind of errors that are not domain errors, such as
+	// a lost database connection.
+	KindUnknown ErrorKind = iota
+	// KindInvalid is input that breaks a rule on its own, such as a
+	// negative price.
+	KindInvalid
+	// KindNotFound is an entity addressed directly that does not exist.
+	KindNotFound
+	// KindConflict is a request that is valid but clashes with the current
+	// state, such as a duplicate SKU.
+	KindConflict
+	// KindStale is a write based on an outdated version of the entity.
+	KindStale
+	// KindUnprocessable is a request that refers to another entity which
+	// does not exist, such as a product in a missing category.
+	KindUnprocessable
+)
+
+// Error is a domain error of a known kind. Errors are compared by identity,
+// so the sentinels declared with it work with errors.Is.
+type Error struct {
+	kind ErrorKind
+	// code is a stable snake_case name for the error that clients can rely
+	// on, unlike the message.
+	code    string
+	message string
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Kind() ErrorKind {
+	return e.kind
+}
+
+func (e *Error) Code() string {
+	return e.code
+}
+
+func invalid(code, message string) error {
+	return &Error{kind: KindInvalid, code: code, message: message}
+}
+
+func notFound(code, message string) error {
+	return &Error{kind: KindNotFound, code: code, message: message}
+}
+
+func conflict(code, message string) error {
+	return &Error{kind: KindConflict, code: code, message: message}
+}
+
+func stale(code, message string) error {
+	return &Error{kind: KindStale, code: code, message: message}
+}
+
+func unprocessable(code, message string) error {
+	return &Error{kind: KindUnprocessable, code: code, message: message}
+}
+
+// CodeOf returns the code of the first error in err's chain that has one,
+// or an empty string.
+func CodeOf(err error) string {
+	var coded interface{ Code() string }
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+	return ""
+}
+
+// KindOf returns the kind of the first error in err's chain that has one,
+// or KindUnknown.
+func KindOf(err error) ErrorKind {
+	var kinded interface{ Kind() ErrorKind }
+	if errors.As(err, &kinded) {
+		return kinded.Kind()
+	}
+	return KindUnknown
+}
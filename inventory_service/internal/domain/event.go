@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+	"strconv"
+	"time"
+)
+
+// AggregateProduct groups every event about a product, stock and price
+// included, so that consumers see them in the order they happened.
+const AggregateProduct = "product"
+
+// AggregateCategory groups the events about a category.
+const AggregateCategory = "category"
+
+const (
+	EventProductCreated         = "product.created"
+	EventProductUpdated         = "product.updated"
+	EventProductDeleted         = "product.deleted"
+	EventProductRestored        = "product.restored"
+	EventProductCategoryChanged = "product.category_changed"
+	EventProductPriceChanged    = "product.price_changed"
+	EventProductStockChanged    = "product.stock_changed"
+
+	EventCategoryDeleted  = "category.deleted"
+	EventCategoryRestored = "category.restored"
+)
+
+// ProductSnapshot is the payload of product.created and product.updated.
+type ProductSnapshot struct {
+	ID         uint64      `json:"id"`
+	SKU        string      `json:"sku,omitempty"`
+	ExternalID string      `json:"external_id,omitempty"`
+	Slug       string      `json:"slug"`
+	Name       string      `json:"name"`
+	Price      money.Money `json:"price"`
+	Stock      int         `json:"stock"`
+	CategoryID uint64      `json:"category_id"`
+	Version    int         `json:"version"`
+	Actor      string      `json:"actor"`
+}
+
+func NewProductSnapshot(p *Product, actor string) *ProductSnapshot {
+	return &ProductSnapshot{
+		ID:         p.ID(),
+		SKU:        p.SKU(),
+		ExternalID: p.ExternalID(),
+		Slug:       p.Slug(),
+		Name:       p.Name(),
+		Price:      p.Price(),
+		Stock:      p.Stock(),
+		CategoryID: p.CategoryID(),
+		Version:    p.Version(),
+		Actor:      actor,
+	}
+}
+
+// ProductLifecycle is the payload of product.deleted, product.restored and
+// product.category_changed.
+type ProductLifecycle struct {
+	ID         uint64    `json:"id"`
+	CategoryID uint64    `json:"category_id,omitempty"`
+	Version    int       `json:"version"`
+	Actor      string    `json:"actor"`
+	At         time.Time `json:"at"`
+}
+
+// CategoryLifecycle is the payload of category.deleted and
+// category.restored, written for every category of the subtree the change
+// applies to.
+type CategoryLifecycle struct {
+	ID       uint64    `json:"id"`
+	ParentID uint64    `json:"parent_id,omitempty"`
+	Version  int       `json:"version"`
+	Actor    string    `json:"actor"`
+	At       time.Time `json:"at"`
+}
+
+// StockChanged is the payload of product.stock_changed, written for every
+// ledger entry and whenever a bundle's derived stock moves. Stock is the
+// level after the change. A bundle's change has no ledger entry of its own:
+// MovementID is 0 and Reason is StockChangeBundle.
+type StockChanged struct {
+	MovementID  uint64 `json:"movement_id,omitempty"`
+	ProductID   uint64 `json:"product_id"`
+	VariantID   uint64 `json:"variant_id,omitempty"`
+	Delta       int    `json:"delta"`
+	Stock       int    `json:"stock"`
+	Reason      string `json:"reason"`
+	ReferenceID string `json:"reference_id,omitempty"`
+	Actor       string `json:"actor"`
+}
+
+func NewStockChanged(m *StockMovement, stock int) *StockChanged {
+	return &StockChanged{
+		MovementID:  m.ID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		Delta:       m.Delta,
+		Stock:       stock,
+		Reason:      string(m.Reason),
+		ReferenceID: m.ReferenceID,
+		Actor:       m.Actor,
+	}
+}
+
+// StockChangeBundle is the reason of a stock change that a bundle derives
+// from its components.
+const StockChangeBundle = "bundle"
+
+func NewBundleStockChanged(bundleID uint64, previous, stock int, actor string) *StockChanged {
+	return &StockChanged{
+		ProductID: bundleID,
+		Delta:     stock - previous,
+		Stock:     stock,
+		Reason:    StockChangeBundle,
+		Actor:     actor,
+	}
+}
+
+// PriceChanged is the payload of product.price_changed, written for every
+// price history entry.
+type PriceChanged struct {
+	ChangeID   uint64      `json:"change_id"`
+	ProductID  uint64      `json:"product_id"`
+	Price      money.Money `json:"price"`
+	Source     string      `json:"source"`
+	ScheduleID uint64      `json:"schedule_id,omitempty"`
+	Actor      string      `json:"actor"`
+}
+
+func NewPriceChanged(c *PriceChange) *PriceChanged {
+	return &PriceChanged{
+		ChangeID:   c.ID,
+		ProductID:  c.ProductID,
+		Price:      c.Price,
+		Source:     string(c.Source),
+		ScheduleID: c.ScheduleID,
+		Actor:      c.Actor,
+	}
+}
+
+// NewProductEvent wraps payload in an outbox event for product productID.
+func NewProductEvent(eventType string, productID uint64, payload interface{}) (outbox.Event, error) {
+	return outbox.NewEvent(AggregateProduct, strconv.FormatUint(productID, 10), eventType, payload)
+}
+
+// NewCategoryEvent wraps payload in an outbox event for category categoryID.
+func NewCategoryEvent(eventType string, categoryID uint64, payload interface{}) (outbox.Event, error) {
+	return outbox.NewEvent(AggregateCategory, strconv.FormatUint(categoryID, 10), eventType, payload)
+}
@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"sort"
+	"time"
+)
+
+var (
+	ErrPriceScheduleNotFound = notFound("price_schedule_not_found", "price schedule not found")
+	ErrPriceScheduleClosed   = conflict("price_schedule_closed", "price schedule already completed or canceled")
+	ErrPriceScheduleOverlap  = conflict("price_schedule_overlap", "price schedule overlaps another scheduled change")
+	ErrInvalidPriceWindow    = invalid("invalid_price_window", "price schedule must start in the future and end after it starts")
+	ErrNoPriceAt             = notFound("no_price_at", "product had no price at that time")
+)
+
+type PriceChangeSource string
+
+const (
+	PriceChangeManual    PriceChangeSource = "manual"
+	PriceChangeScheduled PriceChangeSource = "scheduled"
+	// PriceChangeBundle marks a discount bundle following its components.
+	PriceChangeBundle PriceChangeSource = "bundle"
+)
+
+// PriceChange is an immutable history entry: the product price became Price
+// at ChangedAt. ScheduleID links changes made by the scheduler.
+type PriceChange struct {
+	ID         uint64
+	ProductID  uint64
+	Price      money.Money
+	Source     PriceChangeSource
+	ScheduleID uint64
+	Actor      string
+	ChangedAt  time.Time
+}
+
+type PriceScheduleStatus string
+
+const (
+	PriceSchedulePending   PriceScheduleStatus = "pending"
+	PriceScheduleActive    PriceScheduleStatus = "active"
+	PriceScheduleCompleted PriceScheduleStatus = "completed"
+	// PriceScheduleExpired marks a temporary schedule whose whole window
+	// passed before the scheduler got to it, e.g. while the service was
+	// down. Its price was never applied.
+	PriceScheduleExpired  PriceScheduleStatus = "expired"
+	PriceScheduleCanceled PriceScheduleStatus = "canceled"
+)
+
+// PriceSchedule is a future-dated price change. Without EndsAt it is a
+// permanent change; with EndsAt it is a temporary one, such as a sale, and
+// the price it replaced is restored when it ends.
+type PriceSchedule struct {
+	ID        uint64
+	ProductID uint64
+	Price     money.Money
+	StartsAt  time.Time
+	EndsAt    *time.Time
+	Status    PriceScheduleStatus
+	// PreviousPrice is the price replaced when the schedule started.
+	PreviousPrice *money.Money
+	Actor         string
+	CreatedAt     time.Time
+}
+
+func NewPriceSchedule(productID uint64, price money.Money, startsAt time.Time, endsAt *time.Time, actor string) (*PriceSchedule, error) {
+	if !validPrice(price) {
+		return nil, ErrInvalidPrice
+	}
+	now := time.Now()
+	if !startsAt.After(now) || endsAt != nil && !endsAt.After(startsAt) {
+		return nil, ErrInvalidPriceWindow
+	}
+	if actor == "" {
+		actor = SystemActor
+	}
+
+	return &PriceSchedule{
+		ProductID: productID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Status:    PriceSchedulePending,
+		Actor:     actor,
+		CreatedAt: now,
+	}, nil
+}
+
+// Open reports whether the schedule still has work to do.
+func (s *PriceSchedule) Open() bool {
+	return s.Status == PriceSchedulePending || s.Status == PriceScheduleActive
+}
+
+// Transition returns the status the scheduler moves the schedule to at now,
+// or false when nothing is due. A pending schedule whose window has already
+// ended expires without being applied, so that a late scheduler does not
+// briefly put a stale sale price in force.
+func (s *PriceSchedule) Transition(now time.Time) (PriceScheduleStatus, bool) {
+	ended := s.EndsAt != nil && !s.EndsAt.After(now)
+	switch {
+	case s.Status == PriceScheduleActive && ended:
+		return PriceScheduleCompleted, true
+	case s.Status != PriceSchedulePending || s.StartsAt.After(now):
+		return s.Status, false
+	case ended:
+		return PriceScheduleExpired, true
+	case s.EndsAt == nil:
+		return PriceScheduleCompleted, true
+	default:
+		return PriceScheduleActive, true
+	}
+}
+
+// Overlaps reports whether two schedules would be in force at the same time.
+// A permanent change occupies only its start instant.
+func (s *PriceSchedule) Overlaps(other *PriceSchedule) bool {
+	sEnd, oEnd := s.StartsAt, other.StartsAt
+	if s.EndsAt != nil {
+		sEnd = *s.EndsAt
+	}
+	if other.EndsAt != nil {
+		oEnd = *other.EndsAt
+	}
+	if s.StartsAt.Equal(other.StartsAt) {
+		return true
+	}
+	return s.StartsAt.Before(oEnd) && other.StartsAt.Before(sEnd)
+}
+
+// PriceTimeline is the full price picture of a product: what it was, what it
+// is and the changes still scheduled.
+type PriceTimeline struct {
+	ProductID    uint64
+	CurrentPrice money.Money
+	// History is ordered oldest first.
+	History   []*PriceChange
+	Schedules []*PriceSchedule
+}
+
+// PriceAt returns the price in effect at t. Past prices come from the
+// history; future ones replay the open schedules the way the scheduler
+// applies them.
+func (t *PriceTimeline) PriceAt(at, now time.Time) (money.Money, error) {
+	if !at.After(now) {
+		var price money.Money
+		found := false
+		for _, change := range t.History {
+			if change.ChangedAt.After(at) {
+				break
+			}
+			price, found = change.Price, true
+		}
+		if !found {
+			return money.Money{}, ErrNoPriceAt
+		}
+		return price, nil
+	}
+
+	var open []*PriceSchedule
+	for _, s := range t.Schedules {
+		if s.Open() {
+			open = append(open, s)
+		}
+	}
+	sort.Slice(open, func(i, j int) bool { return open[i].StartsAt.Before(open[j].StartsAt) })
+
+	price := t.CurrentPrice
+	for _, s := range open {
+		if s.StartsAt.After(at) {
+			break
+		}
+		ended := s.EndsAt != nil && !s.EndsAt.After(at)
+		if s.Status == PriceScheduleActive {
+			// A manual change during the window wins over the restore.
+			if ended && price.Equal(s.Price) && s.PreviousPrice != nil {
+				price = *s.PreviousPrice
+			}
+			continue
+		}
+		if !ended {
+			price = s.Price
+		}
+	}
+	return price, nil
+}
+
+type PriceRepository interface {
+	// ListChanges returns the price history of a product, oldest first.
+	ListChanges(ctx context.Context, productID uint64) ([]*PriceChange, error)
+	ListSchedules(ctx context.Context, productID uint64, openOnly bool) ([]*PriceSchedule, error)
+	// CreateSchedule rejects a schedule overlapping another open one.
+	CreateSchedule(ctx context.Context, schedule *PriceSchedule) error
+	// CancelSchedule drops a pending schedule or ends an active one early,
+	// restoring the price it replaced.
+	CancelSchedule(ctx context.Context, productID, scheduleID uint64) (*PriceSchedule, error)
+	// ApplyDue makes the Transition of every schedule due at now and returns
+	// how many transitions it made.
+	ApplyDue(ctx context.Context, now time.Time) (int, error)
+}
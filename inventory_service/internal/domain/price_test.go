@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewPriceScheduleValidatesWindow(t *testing.T) {
+	now := time.Now()
+	later, earlier := now.Add(2*time.Hour), now.Add(-time.Hour)
+	start := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		price    string
+		startsAt time.Time
+		endsAt   *time.Time
+		want     error
+	}{
+		{"permanent change", "9.99", start, nil, nil},
+		{"temporary change", "9.99", start, &later, nil},
+		{"starts in the past", "9.99", earlier, nil, ErrInvalidPriceWindow},
+		{"ends before it starts", "9.99", later, &start, ErrInvalidPriceWindow},
+		{"ends when it starts", "9.99", start, &start, ErrInvalidPriceWindow},
+		{"negative price", "-1.00", start, nil, ErrInvalidPrice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := NewPriceSchedule(1, mustMoney(t, tt.price), tt.startsAt, tt.endsAt, "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			if err == nil && (schedule.Status != PriceSchedulePending || schedule.Actor != SystemActor) {
+				t.Fatalf("got status %s, actor %q", schedule.Status, schedule.Actor)
+			}
+		})
+	}
+}
+
+func TestPriceScheduleTransition(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+	until := func(hours int) *time.Time { t := at(hours); return &t }
+
+	tests := []struct {
+		name     string
+		schedule PriceSchedule
+		now      time.Time
+		want     PriceScheduleStatus
+		due      bool
+	}{
+		{"pending before start", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1)}, at(0), PriceSchedulePending, false},
+		{"permanent change at start", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1)}, at(1), PriceScheduleCompleted, true},
+		{"sale at start", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1), EndsAt: until(3)}, at(1), PriceScheduleActive, true},
+		{"sale started late", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1), EndsAt: until(3)}, at(2), PriceScheduleActive, true},
+		{"sale missed entirely", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1), EndsAt: until(3)}, at(5), PriceScheduleExpired, true},
+		{"sale missed at its end", PriceSchedule{Status: PriceSchedulePending, StartsAt: at(1), EndsAt: until(3)}, at(3), PriceScheduleExpired, true},
+		{"active sale running", PriceSchedule{Status: PriceScheduleActive, StartsAt: at(1), EndsAt: until(3)}, at(2), PriceScheduleActive, false},
+		{"active sale ending", PriceSchedule{Status: PriceScheduleActive, StartsAt: at(1), EndsAt: until(3)}, at(3), PriceScheduleCompleted, true},
+		{"canceled", PriceSchedule{Status: PriceScheduleCanceled, StartsAt: at(1)}, at(5), PriceScheduleCanceled, false},
+		{"expired", PriceSchedule{Status: PriceScheduleExpired, StartsAt: at(1), EndsAt: until(3)}, at(5), PriceScheduleExpired, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, due := tt.schedule.Transition(tt.now)
+			if got != tt.want || due != tt.due {
+				t.Fatalf("got %s, %v, want %s, %v", got, due, tt.want, tt.due)
+			}
+		})
+	}
+}
+
+func TestPriceScheduleTransitionAcrossZones(t *testing.T) {
+	// The same instant written in another zone is still due.
+	startsAt := time.Date(2024, 3, 1, 14, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	schedule := PriceSchedule{Status: PriceSchedulePending, StartsAt: startsAt}
+	if _, due := schedule.Transition(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)); !due {
+		t.Fatal("a schedule starting at the same instant in another zone is not due")
+	}
+	if _, due := schedule.Transition(time.Date(2024, 3, 1, 11, 59, 0, 0, time.UTC)); due {
+		t.Fatal("a schedule a minute ahead is due")
+	}
+}
+
+func TestPriceScheduleOverlaps(t *testing.T) {
+	base := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	window := func(start, end int) *PriceSchedule {
+		s := &PriceSchedule{StartsAt: base.AddDate(0, 0, start)}
+		if end > 0 {
+			e := base.AddDate(0, 0, end)
+			s.EndsAt = &e
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a, b *PriceSchedule
+		want bool
+	}{
+		{"disjoint windows", window(1, 3), window(4, 6), false},
+		{"touching windows", window(1, 3), window(3, 6), false},
+		{"overlapping windows", window(1, 4), window(3, 6), true},
+		{"nested windows", window(1, 10), window(3, 4), true},
+		{"same start", window(1, 0), window(1, 5), true},
+		{"permanent change inside a window", window(1, 5), window(3, 0), true},
+		{"permanent change after a window", window(1, 5), window(6, 0), false},
+		{"two permanent changes", window(1, 0), window(2, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Overlaps(tt.b); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			if got := tt.b.Overlaps(tt.a); got != tt.want {
+				t.Fatalf("reversed: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceTimelinePriceAt(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return now.AddDate(0, 0, d) }
+	end := func(d int) *time.Time { t := day(d); return &t }
+	previous := mustMoney(t, "20.00")
+
+	timeline := &PriceTimeline{
+		CurrentPrice: mustMoney(t, "15.00"),
+		History: []*PriceChange{
+			{Price: mustMoney(t, "20.00"), ChangedAt: day(-10)},
+			{Price: mustMoney(t, "15.00"), ChangedAt: day(-1)},
+		},
+		Schedules: []*PriceSchedule{
+			// A sale in force now that restores 20.00 when it ends.
+			{Status: PriceScheduleActive, Price: mustMoney(t, "15.00"), StartsAt: day(-1), EndsAt: end(2), PreviousPrice: &previous},
+			{Status: PriceSchedulePending, Price: mustMoney(t, "25.00"), StartsAt: day(5)},
+			{Status: PriceSchedulePending, Price: mustMoney(t, "18.00"), StartsAt: day(7), EndsAt: end(8)},
+			{Status: PriceScheduleCanceled, Price: mustMoney(t, "1.00"), StartsAt: day(6)},
+		},
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want string
+	}{
+		{"before the history", day(-20), ""},
+		{"first price", day(-5), "20.00"},
+		{"current price", now, "15.00"},
+		{"during the sale", day(1), "15.00"},
+		{"after the sale", day(3), "20.00"},
+		{"after a permanent change", day(6), "25.00"},
+		{"during a later sale", day(7), "18.00"},
+		{"after the later sale", day(9), "25.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := timeline.PriceAt(tt.at, now)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNoPriceAt) {
+					t.Fatalf("got %v, %v, want %v", got, err, ErrNoPriceAt)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Decimal() != tt.want {
+				t.Fatalf("got %s, want %s", got.Decimal(), tt.want)
+			}
+		})
+	}
+}
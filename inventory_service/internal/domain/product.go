@@ -2,31 +2,46 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strings"
 	"time"
 )
 
 var (
-	ErrInvalidPrice      = errors.New("price must be greater than or equal to 0")
-	ErrInvalidStock      = errors.New("stock must be greater than or equal to 0")
-	ErrInsufficientStock = errors.New("insufficient stock")
-	ErrProductNotFound   = errors.New("product not found")
+	ErrInvalidPrice      = invalid("invalid_price", "price must be a non-negative amount with a currency")
+	ErrInvalidStock      = invalid("invalid_stock", "stock must be greater than or equal to 0")
+	ErrInsufficientStock = conflict("insufficient_stock", "insufficient stock")
+	ErrProductNotFound   = notFound("product_not_found", "product not found")
+	// A product keeps the currency it was created with; variant overrides and
+	// scheduled prices are stored in it.
+	ErrCurrencyChanged  = invalid("currency_changed", "product currency cannot be changed")
+	ErrCurrencyMismatch = invalid("currency_mismatch", "price currency must match the product currency")
+	// Products and categories each keep their external IDs unique.
+	ErrDuplicateExternalID = conflict("duplicate_external_id", "external id already exists")
+	// A product can only be filed under a live category.
+	ErrProductCategoryNotFound = unprocessable("product_category_not_found", "product category does not exist or is in the trash")
 )
 
 type Product struct {
 	id          uint64
+	sku         string
+	externalID  string
+	slug        string
 	name        string
 	description string
-	price       float64
+	price       money.Money
 	stock       int
 	categoryID  uint64
+	attributes  map[string]interface{}
 	createdAt   time.Time
 	updatedAt   time.Time
 	isDeleted   bool
+	deletedAt   time.Time
+	version     int
 }
 
-func NewProduct(name, description string, price float64, stock int, categoryID uint64) (*Product, error) {
-	if price < 0 {
+func NewProduct(name, description string, price money.Money, stock int, categoryID uint64) (*Product, error) {
+	if !validPrice(price) {
 		return nil, ErrInvalidPrice
 	}
 	if stock < 0 {
@@ -40,15 +55,96 @@ func NewProduct(name, description string, price float64, stock int, categoryID u
 		price:       price,
 		stock:       stock,
 		categoryID:  categoryID,
+		attributes:  map[string]interface{}{},
 		createdAt:   now,
 		updatedAt:   now,
+		version:     1,
 	}, nil
 }
 
+func validPrice(price money.Money) bool {
+	return price.IsSet() && !price.IsNegative()
+}
+
+// Clone returns a copy that shares nothing mutable with p.
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.attributes = cloneAttributes(p.attributes)
+	return &clone
+}
+
+func cloneAttributes(attributes map[string]interface{}) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+	clone := make(map[string]interface{}, len(attributes))
+	for code, value := range attributes {
+		if values, ok := value.([]interface{}); ok {
+			value = append([]interface{}(nil), values...)
+		}
+		clone[code] = value
+	}
+	return clone
+}
+
 func (p *Product) ID() uint64 {
 	return p.id
 }
 
+func (p *Product) SetID(id uint64) {
+	p.id = id
+}
+
+// SKU is the merchant's stock keeping unit for the product, or empty when
+// none has been assigned.
+func (p *Product) SKU() string {
+	return p.sku
+}
+
+// ExternalID is the product's key in an upstream system such as a
+// merchandising spreadsheet, or empty.
+func (p *Product) ExternalID() string {
+	return p.externalID
+}
+
+// SetIdentifiers assigns the keys a bulk import matches existing products on.
+func (p *Product) SetIdentifiers(sku, externalID string) {
+	p.sku = strings.TrimSpace(sku)
+	p.externalID = strings.TrimSpace(externalID)
+}
+
+// Slug is the product's URL name. Renaming a product keeps its slug; a slug
+// changed on purpose leaves a redirect behind.
+func (p *Product) Slug() string {
+	return p.slug
+}
+
+// SetSlug assigns a slug, or with an empty one asks the repository to derive
+// one from the name when the product is saved. Slugs given by clients are
+// checked with ValidateSlug first.
+func (p *Product) SetSlug(slug string) {
+	p.slug = strings.TrimSpace(slug)
+}
+
+// ApplyIdentifiers sets the identifiers that are not nil. An empty slug asks
+// for one derived from the name; any other is checked with ValidateSlug.
+func (p *Product) ApplyIdentifiers(sku, externalID, slug *string) error {
+	if sku != nil {
+		p.sku = strings.TrimSpace(*sku)
+	}
+	if externalID != nil {
+		p.externalID = strings.TrimSpace(*externalID)
+	}
+	if slug != nil {
+		requested, err := requestedSlug(*slug)
+		if err != nil {
+			return err
+		}
+		p.slug = requested
+	}
+	return nil
+}
+
 func (p *Product) Name() string {
 	return p.name
 }
@@ -57,7 +153,7 @@ func (p *Product) Description() string {
 	return p.description
 }
 
-func (p *Product) Price() float64 {
+func (p *Product) Price() money.Money {
 	return p.price
 }
 
@@ -69,6 +165,21 @@ func (p *Product) CategoryID() uint64 {
 	return p.categoryID
 }
 
+// Attributes holds the product's values for its category's attribute schema,
+// keyed by attribute code.
+func (p *Product) Attributes() map[string]interface{} {
+	return p.attributes
+}
+
+// SetAttributes replaces the attribute values. They are expected to have been
+// checked with ValidateAttributes.
+func (p *Product) SetAttributes(attributes map[string]interface{}) {
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	p.attributes = attributes
+}
+
 func (p *Product) CreatedAt() time.Time {
 	return p.createdAt
 }
@@ -81,10 +192,34 @@ func (p *Product) IsDeleted() bool {
 	return p.isDeleted
 }
 
-func (p *Product) Update(name, description string, price float64, stock int, categoryID uint64) error {
-	if price < 0 {
+// DeletedAt is when the product was moved to the trash, or zero for a live
+// product.
+func (p *Product) DeletedAt() time.Time {
+	return p.deletedAt
+}
+
+// Version is incremented on every persisted change and is used for
+// optimistic concurrency control.
+func (p *Product) Version() int {
+	return p.version
+}
+
+func (p *Product) SetVersion(version int) {
+	p.version = version
+}
+
+func (p *Product) SetTimestamps(createdAt, updatedAt time.Time) {
+	p.createdAt = createdAt
+	p.updatedAt = updatedAt
+}
+
+func (p *Product) Update(name, description string, price money.Money, stock int, categoryID uint64) error {
+	if !validPrice(price) {
 		return ErrInvalidPrice
 	}
+	if price.Currency() != p.price.Currency() {
+		return ErrCurrencyChanged
+	}
 	if stock < 0 {
 		return ErrInvalidStock
 	}
@@ -98,6 +233,30 @@ func (p *Product) Update(name, description string, price float64, stock int, cat
 	return nil
 }
 
+// ProductChanges are the fields an update replaces. Nil identifiers are left
+// as they are, and nil attributes keep the values the category's schema
+// still defines.
+type ProductChanges struct {
+	Name        string
+	Description string
+	Price       money.Money
+	Stock       int
+	CategoryID  uint64
+	SKU         *string
+	ExternalID  *string
+	Slug        *string
+	Attributes  map[string]interface{}
+}
+
+// Apply makes the changes other than the attributes, which need the
+// category's schema.
+func (p *Product) Apply(changes ProductChanges) error {
+	if err := p.Update(changes.Name, changes.Description, changes.Price, changes.Stock, changes.CategoryID); err != nil {
+		return err
+	}
+	return p.ApplyIdentifiers(changes.SKU, changes.ExternalID, changes.Slug)
+}
+
 func (p *Product) UpdateStock(quantity int) error {
 	newStock := p.stock + quantity
 	if newStock < 0 {
@@ -109,14 +268,40 @@ func (p *Product) UpdateStock(quantity int) error {
 }
 
 func (p *Product) Delete() {
+	p.MarkDeleted(time.Now())
+}
+
+// MarkDeleted records that the product was moved to the trash at the given
+// time.
+func (p *Product) MarkDeleted(at time.Time) {
 	p.isDeleted = true
-	p.updatedAt = time.Now()
+	p.deletedAt = at
+	p.updatedAt = at
 }
 
 type ProductRepository interface {
 	Create(ctx context.Context, product *Product) error
 	GetByID(ctx context.Context, id uint64) (*Product, error)
-	List(ctx context.Context, categoryID uint64, offset, limit int) ([]*Product, error)
+	List(ctx context.Context, filter ProductFilter) (*ProductPage, error)
 	Update(ctx context.Context, product *Product) error
-	Delete(ctx context.Context, id uint64) error
+	Delete(ctx context.Context, id uint64, version int) error
+	Search(ctx context.Context, query ProductSearchQuery) ([]*ProductSearchResult, error)
+	// GetBySKU, GetByExternalID and GetBySlug return nil when no live
+	// product matches.
+	GetBySKU(ctx context.Context, sku string) (*Product, error)
+	GetByExternalID(ctx context.Context, externalID string) (*Product, error)
+	GetBySlug(ctx context.Context, slug string) (*Product, error)
+	// SlugRedirect returns the current slug of the live product that used
+	// to have the given slug, or an empty string.
+	SlugRedirect(ctx context.Context, slug string) (string, error)
+	// GetBatch returns the live products with any of the batch's IDs or
+	// SKUs, in no particular order.
+	GetBatch(ctx context.Context, batch ProductBatch) ([]*Product, error)
+	Facets(ctx context.Context, filter ProductFilter, request FacetRequest) (*ProductFacets, error)
+	// ListDeleted returns the products in the trash, most recently deleted
+	// first.
+	ListDeleted(ctx context.Context, offset, limit int) ([]*Product, error)
+	// Restore takes a product out of the trash. It fails with
+	// ErrCategoryTrashed while the product's category is still deleted.
+	Restore(ctx context.Context, id uint64, version int) error
 }
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxProductBatch bounds how many IDs and SKUs one batch lookup may name.
+const MaxProductBatch = 100
+
+var (
+	ErrEmptyProductBatch    = invalid("empty_product_batch", "batch must name at least one product id or sku")
+	ErrProductBatchTooLarge = invalid("product_batch_too_large", fmt.Sprintf("batch can name at most %d product ids and skus", MaxProductBatch))
+)
+
+// ProductBatch names products to look up at once, by ID, by SKU or both.
+type ProductBatch struct {
+	IDs  []uint64
+	SKUs []string
+}
+
+// Validate drops blank and repeated entries, keeping the first of each, and
+// checks what is left against MaxProductBatch.
+func (b *ProductBatch) Validate() error {
+	ids := make([]uint64, 0, len(b.IDs))
+	seenIDs := make(map[uint64]bool, len(b.IDs))
+	for _, id := range b.IDs {
+		if !seenIDs[id] {
+			seenIDs[id] = true
+			ids = append(ids, id)
+		}
+	}
+	skus := make([]string, 0, len(b.SKUs))
+	seenSKUs := make(map[string]bool, len(b.SKUs))
+	for _, sku := range b.SKUs {
+		sku = strings.TrimSpace(sku)
+		if sku != "" && !seenSKUs[sku] {
+			seenSKUs[sku] = true
+			skus = append(skus, sku)
+		}
+	}
+	b.IDs, b.SKUs = ids, skus
+
+	switch n := len(ids) + len(skus); {
+	case n == 0:
+		return ErrEmptyProductBatch
+	case n > MaxProductBatch:
+		return ErrProductBatchTooLarge
+	}
+	return nil
+}
+
+// ProductBatchResult is what a batch lookup found. Products are in the order
+// they were named, IDs first; a product named by both its ID and its SKU
+// appears once.
+type ProductBatchResult struct {
+	Products    []*Product
+	MissingIDs  []uint64
+	MissingSKUs []string
+}
+
+// Resolve sorts the products a repository found for the batch into the
+// result.
+func (b ProductBatch) Resolve(found []*Product) *ProductBatchResult {
+	byID := make(map[uint64]*Product, len(found))
+	bySKU := make(map[string]*Product, len(found))
+	for _, p := range found {
+		byID[p.ID()] = p
+		if p.SKU() != "" {
+			bySKU[p.SKU()] = p
+		}
+	}
+
+	result := &ProductBatchResult{MissingIDs: []uint64{}, MissingSKUs: []string{}}
+	added := make(map[uint64]bool, len(found))
+	add := func(p *Product) {
+		if !added[p.ID()] {
+			added[p.ID()] = true
+			result.Products = append(result.Products, p)
+		}
+	}
+	for _, id := range b.IDs {
+		if p, ok := byID[id]; ok {
+			add(p)
+		} else {
+			result.MissingIDs = append(result.MissingIDs, id)
+		}
+	}
+	for _, sku := range b.SKUs {
+		if p, ok := bySKU[sku]; ok {
+			add(p)
+		} else {
+			result.MissingSKUs = append(result.MissingSKUs, sku)
+		}
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestProductBatchValidate(t *testing.T) {
+	batch := ProductBatch{IDs: []uint64{3, 1, 3}, SKUs: []string{" RICE ", "", "BEANS", "RICE"}}
+	if err := batch.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batch.IDs) != "[3 1]" || fmt.Sprint(batch.SKUs) != "[RICE BEANS]" {
+		t.Fatalf("got ids %v and skus %v, want repeats and blanks dropped in order", batch.IDs, batch.SKUs)
+	}
+
+	empty := ProductBatch{SKUs: []string{" "}}
+	if err := empty.Validate(); !errors.Is(err, ErrEmptyProductBatch) {
+		t.Fatalf("got error %v for a blank batch", err)
+	}
+
+	full := ProductBatch{SKUs: []string{"RICE"}}
+	for id := uint64(1); id < MaxProductBatch; id++ {
+		full.IDs = append(full.IDs, id)
+	}
+	if err := full.Validate(); err != nil {
+		t.Fatalf("got error %v at the limit", err)
+	}
+	full.SKUs = append(full.SKUs, "BEANS")
+	if err := full.Validate(); !errors.Is(err, ErrProductBatchTooLarge) {
+		t.Fatalf("got error %v over the limit", err)
+	}
+}
+
+func TestProductBatchResolve(t *testing.T) {
+	product := func(id uint64, sku string) *Product {
+		p, err := NewProduct("Rice", "", mustMoney(t, "3.00"), 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SetID(id)
+		p.SetIdentifiers(sku, "")
+		return p
+	}
+	rice, beans, flour := product(1, "RICE"), product(2, "BEANS"), product(3, "")
+
+	batch := ProductBatch{IDs: []uint64{3, 1, 9}, SKUs: []string{"BEANS", "RICE", "NONE"}}
+	result := batch.Resolve([]*Product{beans, rice, flour})
+
+	var ids []uint64
+	for _, p := range result.Products {
+		ids = append(ids, p.ID())
+	}
+	if fmt.Sprint(ids) != "[3 1 2]" {
+		t.Fatalf("got products %v, want IDs in the order named, then SKUs, each once", ids)
+	}
+	if fmt.Sprint(result.MissingIDs) != "[9]" || fmt.Sprint(result.MissingSKUs) != "[NONE]" {
+		t.Fatalf("got missing ids %v and skus %v", result.MissingIDs, result.MissingSKUs)
+	}
+
+	result = ProductBatch{IDs: []uint64{1}}.Resolve([]*Product{rice})
+	if result.MissingIDs == nil || result.MissingSKUs == nil {
+		t.Fatal("missing lists are nil rather than empty")
+	}
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+)
+
+var (
+	ErrUnknownFacet       = invalid("unknown_facet", "facets must be a list of: category, price, stock, attributes, attr.<code>")
+	ErrInvalidPriceBucket = invalid("invalid_price_bucket", "price buckets must be ascending non-negative amounts in the filter currency")
+)
+
+// MaxFacetValues bounds the values returned per attribute facet; the most
+// frequent values are kept.
+const MaxFacetValues = 20
+
+// DefaultPriceBuckets are the bucket boundaries used unless a request sets
+// its own, as amounts in the currency prices are counted in.
+var DefaultPriceBuckets = []string{"0", "25", "50", "100", "250", "500", "1000"}
+
+// FacetRequest selects the facets to compute alongside a listing.
+type FacetRequest struct {
+	Categories bool
+	Price      bool
+	Stock      bool
+	// AllAttributes requests a facet for every attribute code present in the
+	// result; AttributeCodes requests specific ones.
+	AllAttributes  bool
+	AttributeCodes []string
+	PriceBuckets   []money.Money
+}
+
+func (r FacetRequest) Empty() bool {
+	return !r.Categories && !r.Price && !r.Stock && !r.AllAttributes && len(r.AttributeCodes) == 0
+}
+
+// Validate checks the request against currency, the one prices are counted
+// in, and fills in the default price buckets in it.
+func (r *FacetRequest) Validate(currency money.Currency) error {
+	if len(r.PriceBuckets) == 0 {
+		for _, amount := range DefaultPriceBuckets {
+			bound, err := money.Parse(amount, currency)
+			if err != nil {
+				return err
+			}
+			r.PriceBuckets = append(r.PriceBuckets, bound)
+		}
+	}
+	for i, bound := range r.PriceBuckets {
+		if bound.Currency() != currency || bound.IsNegative() {
+			return ErrInvalidPriceBucket
+		}
+		if i > 0 {
+			if cmp, _ := r.PriceBuckets[i-1].Cmp(bound); cmp >= 0 {
+				return ErrInvalidPriceBucket
+			}
+		}
+	}
+	for _, code := range r.AttributeCodes {
+		if !attributeCodePattern.MatchString(code) {
+			return ErrInvalidAttributeCode
+		}
+	}
+	return nil
+}
+
+type CategoryFacet struct {
+	CategoryID uint64
+	Name       string
+	Count      int
+}
+
+// PriceFacet counts products priced from Min (inclusive) up to Max
+// (exclusive). Max is nil for the open-ended top bucket.
+type PriceFacet struct {
+	Min   money.Money
+	Max   *money.Money
+	Count int
+}
+
+type StockFacet struct {
+	InStock    int
+	OutOfStock int
+}
+
+type AttributeFacet struct {
+	Value string
+	Count int
+}
+
+// ProductFacets holds counts computed for a filter set. Each facet ignores
+// the filter on its own dimension, so selecting one category still reports
+// how many products the sibling categories would show.
+type ProductFacets struct {
+	Categories []CategoryFacet
+	// Prices only counts products priced in PriceCurrency, the filter's
+	// currency.
+	Prices        []PriceFacet
+	PriceCurrency money.Currency
+	Stock         *StockFacet
+	Attributes    map[string][]AttributeFacet
+}
+
+// WithoutCategories returns a copy of the filter without its category
+// condition; the other Without methods do the same for their dimension.
+func (f ProductFilter) WithoutCategories() ProductFilter {
+	f.CategoryIDs = nil
+	f.IncludeDescendants = false
+	return f
+}
+
+// WithoutPrice keeps the currency, which the price facet is counted in.
+func (f ProductFilter) WithoutPrice() ProductFilter {
+	f.MinPrice, f.MaxPrice = nil, nil
+	return f
+}
+
+func (f ProductFilter) WithoutStock() ProductFilter {
+	f.InStockOnly = false
+	return f
+}
+
+// WithoutAttribute drops the filters on one attribute code.
+func (f ProductFilter) WithoutAttribute(code string) ProductFilter {
+	var kept []AttributeFilter
+	for _, a := range f.Attributes {
+		if a.Code != code {
+			kept = append(kept, a)
+		}
+	}
+	f.Attributes = kept
+	return f
+}
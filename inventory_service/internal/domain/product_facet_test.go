@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+func TestFacetRequestValidate(t *testing.T) {
+	var request FacetRequest
+	if !request.Empty() {
+		t.Fatal("a zero request is not empty")
+	}
+	if err := request.Validate("EUR"); err != nil {
+		t.Fatal(err)
+	}
+	if len(request.PriceBuckets) != len(DefaultPriceBuckets) || request.PriceBuckets[1].String() != "25.00 EUR" {
+		t.Fatalf("got buckets %v, want the defaults in EUR", request.PriceBuckets)
+	}
+
+	buckets := func(amounts ...string) []money.Money {
+		var parsed []money.Money
+		for _, amount := range amounts {
+			parsed = append(parsed, mustMoney(t, amount))
+		}
+		return parsed
+	}
+	euros, err := money.Parse("10", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		request FacetRequest
+		want    error
+	}{
+		{"ascending buckets", FacetRequest{Price: true, PriceBuckets: buckets("10", "20.5", "100")}, nil},
+		{"descending buckets", FacetRequest{Price: true, PriceBuckets: buckets("100", "10")}, ErrInvalidPriceBucket},
+		{"repeated bound", FacetRequest{Price: true, PriceBuckets: buckets("10", "10.00")}, ErrInvalidPriceBucket},
+		{"negative bound", FacetRequest{Price: true, PriceBuckets: buckets("-5", "10")}, ErrInvalidPriceBucket},
+		{"other currency", FacetRequest{Price: true, PriceBuckets: []money.Money{euros}}, ErrInvalidPriceBucket},
+		{"attribute code", FacetRequest{AttributeCodes: []string{"ram_gb"}}, nil},
+		{"bad attribute code", FacetRequest{AttributeCodes: []string{"RAM GB"}}, ErrInvalidAttributeCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.request.Empty() {
+				t.Fatal("request is empty")
+			}
+			if err := tt.request.Validate("USD"); !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterWithoutDimension(t *testing.T) {
+	min, max := mustMoney(t, "10"), mustMoney(t, "20")
+	filter := ProductFilter{
+		CategoryIDs:        []uint64{1},
+		IncludeDescendants: true,
+		MinPrice:           &min,
+		MaxPrice:           &max,
+		InStockOnly:        true,
+		Attributes: []AttributeFilter{
+			{Code: "color", Operator: AttributeEq, Values: []string{"red"}},
+			{Code: "ram_gb", Operator: AttributeGte, Values: []string{"16"}},
+		},
+		Limit: 10,
+	}
+
+	if f := filter.WithoutCategories(); f.CategoryIDs != nil || f.IncludeDescendants || !f.InStockOnly {
+		t.Fatalf("WithoutCategories: got %+v", f)
+	}
+	if f := filter.WithoutPrice(); f.MinPrice != nil || f.MaxPrice != nil || len(f.CategoryIDs) != 1 {
+		t.Fatalf("WithoutPrice: got %+v", f)
+	}
+	if f := filter.WithoutStock(); f.InStockOnly || f.MinPrice == nil {
+		t.Fatalf("WithoutStock: got %+v", f)
+	}
+	if f := filter.WithoutAttribute("color"); len(f.Attributes) != 1 || f.Attributes[0].Code != "ram_gb" {
+		t.Fatalf("WithoutAttribute: got %+v", f.Attributes)
+	}
+	if len(filter.Attributes) != 2 || !filter.InStockOnly || filter.CategoryIDs == nil {
+		t.Fatal("the original filter was changed")
+	}
+}
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrInvalidSortField = invalid("invalid_sort_field", "invalid sort field")
+	ErrInvalidCursor    = invalid("invalid_cursor", "invalid cursor")
+	ErrInvalidRange     = invalid("invalid_range", "range lower bound is greater than upper bound")
+)
+
+type ProductSortField string
+
+const (
+	ProductSortCreatedAt ProductSortField = "created_at"
+	ProductSortUpdatedAt ProductSortField = "updated_at"
+	ProductSortPrice     ProductSortField = "price"
+	ProductSortName      ProductSortField = "name"
+	ProductSortStock     ProductSortField = "stock"
+)
+
+func (f ProductSortField) Valid() bool {
+	switch f {
+	case ProductSortCreatedAt, ProductSortUpdatedAt, ProductSortPrice, ProductSortName, ProductSortStock:
+		return true
+	}
+	return false
+}
+
+// ProductCursor marks the product a keyset page starts after (or, when
+// Backward is set, ends before). Value is the product's sort key.
+type ProductCursor struct {
+	Value    string
+	ID       uint64
+	Backward bool
+}
+
+type ProductFilter struct {
+	CategoryIDs []uint64
+	// IncludeDescendants widens CategoryIDs to all of their subcategories.
+	IncludeDescendants bool
+	MinPrice           *money.Money
+	MaxPrice           *money.Money
+	// Currency is the currency of MinPrice and MaxPrice, and of the price
+	// facet. Amounts in different currencies cannot be compared, so a price
+	// bound only matches products priced in it. Empty means the service
+	// default; see PriceCurrency.
+	Currency    money.Currency
+	InStockOnly bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	Attributes  []AttributeFilter
+
+	SortBy   ProductSortField
+	SortDesc bool
+
+	// Cursor takes precedence over Offset when both are set.
+	Cursor *ProductCursor
+	Offset int
+	Limit  int
+}
+
+func (f *ProductFilter) Validate() error {
+	if f.SortBy == "" {
+		f.SortBy = ProductSortCreatedAt
+		f.SortDesc = true
+	}
+	if !f.SortBy.Valid() {
+		return ErrInvalidSortField
+	}
+	if f.Currency != "" && !f.Currency.Valid() {
+		return money.ErrInvalidCurrency
+	}
+	for _, bound := range []*money.Money{f.MinPrice, f.MaxPrice} {
+		if bound == nil {
+			continue
+		}
+		if bound.Currency() != f.PriceCurrency() {
+			return money.ErrCurrencyMismatch
+		}
+		if bound.IsNegative() {
+			return ErrInvalidPrice
+		}
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil {
+		if cmp, _ := f.MinPrice.Cmp(*f.MaxPrice); cmp > 0 {
+			return ErrInvalidRange
+		}
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return ErrInvalidRange
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return ErrInvalidRange
+	}
+	if len(f.Attributes) > MaxAttributeFilters {
+		return ErrTooManyAttributeFilters
+	}
+	for _, a := range f.Attributes {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PriceCurrency returns the currency price bounds and price facets apply to.
+func (f ProductFilter) PriceCurrency() money.Currency {
+	if f.Currency == "" {
+		return money.DefaultCurrency()
+	}
+	return f.Currency
+}
+
+// HasPriceBounds reports whether the filter restricts prices.
+func (f ProductFilter) HasPriceBounds() bool {
+	return f.MinPrice != nil || f.MaxPrice != nil
+}
+
+// SortValue returns the product's value for the filter's sort field in the
+// form stored in a ProductCursor.
+func (f ProductFilter) SortValue(p *Product) string {
+	switch f.SortBy {
+	case ProductSortPrice:
+		return p.Price().Decimal()
+	case ProductSortName:
+		return p.Name()
+	case ProductSortStock:
+		return strconv.Itoa(p.Stock())
+	case ProductSortUpdatedAt:
+		return p.UpdatedAt().UTC().Format(time.RFC3339Nano)
+	default:
+		return p.CreatedAt().UTC().Format(time.RFC3339Nano)
+	}
+}
+
+type ProductPage struct {
+	Products []*Product
+	Total    int
+	Next     *ProductCursor
+	Prev     *ProductCursor
+}
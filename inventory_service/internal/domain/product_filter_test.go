@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+	"time"
+)
+
+func TestProductFilterValidate(t *testing.T) {
+	f := ProductFilter{}
+	if err := f.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if f.SortBy != ProductSortCreatedAt || !f.SortDesc {
+		t.Fatalf("got default sort %q desc=%v, want newest first", f.SortBy, f.SortDesc)
+	}
+
+	low, high := mustMoney(t, "5"), mustMoney(t, "1")
+	negative := mustMoney(t, "-1")
+	euros, err := money.Parse("1", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	cases := []struct {
+		name   string
+		filter ProductFilter
+		want   error
+	}{
+		{"unknown sort", ProductFilter{SortBy: "color"}, ErrInvalidSortField},
+		{"negative price", ProductFilter{MinPrice: &negative}, ErrInvalidPrice},
+		{"inverted price range", ProductFilter{MinPrice: &low, MaxPrice: &high}, ErrInvalidRange},
+		{"bound in another currency", ProductFilter{MaxPrice: &euros}, money.ErrCurrencyMismatch},
+		{"inverted created range", ProductFilter{CreatedFrom: &later, CreatedTo: &earlier}, ErrInvalidRange},
+		{"inverted updated range", ProductFilter{UpdatedFrom: &later, UpdatedTo: &earlier}, ErrInvalidRange},
+		{"price sort", ProductFilter{SortBy: ProductSortPrice, MinPrice: &high, MaxPrice: &low}, nil},
+	}
+	for _, tc := range cases {
+		if err := tc.filter.Validate(); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got error %v, want %v", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestProductFilterSortValue(t *testing.T) {
+	price, err := money.Parse("12.50", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewProduct("Lamp", "", price, 7, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2024, 3, 1, 12, 0, 0, 500, time.FixedZone("UTC+3", 3*3600))
+	p.SetTimestamps(created, created.Add(time.Minute))
+
+	cases := map[ProductSortField]string{
+		ProductSortPrice:     "12.50",
+		ProductSortName:      "Lamp",
+		ProductSortStock:     "7",
+		ProductSortCreatedAt: "2024-03-01T09:00:00.0000005Z",
+		ProductSortUpdatedAt: "2024-03-01T09:01:00.0000005Z",
+	}
+	for field, want := range cases {
+		if got := (ProductFilter{SortBy: field}).SortValue(p); got != want {
+			t.Errorf("SortValue(%s) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestProductFilterPriceCurrency(t *testing.T) {
+	var filter ProductFilter
+	if filter.PriceCurrency() != money.DefaultCurrency() || filter.HasPriceBounds() {
+		t.Fatalf("got %s, bounds %v for a zero filter", filter.PriceCurrency(), filter.HasPriceBounds())
+	}
+	max, err := money.Parse("10", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	filter = ProductFilter{MaxPrice: &max, Currency: "EUR"}
+	if filter.PriceCurrency() != "EUR" || !filter.HasPriceBounds() {
+		t.Fatalf("got %s, bounds %v", filter.PriceCurrency(), filter.HasPriceBounds())
+	}
+	if err := filter.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	filter.Currency = "eur"
+	if err := filter.Validate(); !errors.Is(err, money.ErrInvalidCurrency) {
+		t.Fatalf("got error %v, want %v", err, money.ErrInvalidCurrency)
+	}
+	if kept := (ProductFilter{MaxPrice: &max, Currency: "EUR"}).WithoutPrice(); kept.Currency != "EUR" || kept.HasPriceBounds() {
+		t.Fatalf("WithoutPrice: got %+v", kept)
+	}
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+var (
+	ErrImageNotFound          = notFound("image_not_found", "image not found")
+	ErrUnsupportedImageType   = invalid("unsupported_image_type", "image must be a JPEG, PNG or GIF")
+	ErrImageTooLarge          = invalid("image_too_large", "image exceeds the maximum upload size")
+	ErrInvalidImage           = invalid("invalid_image", "image could not be decoded")
+	ErrInvalidImageOrder      = invalid("invalid_image_order", "image order must list every image of the product exactly once")
+	ErrMediaObjectNotFound    = notFound("media_object_not_found", "media object not found")
+	ErrInvalidMediaStorageKey = invalid("invalid_media_storage_key", "invalid media storage key")
+)
+
+// ThumbnailSize names a generated thumbnail; MaxSide is the length of its
+// longer edge in pixels.
+type ThumbnailSize struct {
+	Name    string
+	MaxSide int
+}
+
+// ThumbnailSizes are generated for every uploaded image. Images smaller than
+// a size are not enlarged.
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", MaxSide: 150},
+	{Name: "medium", MaxSide: 400},
+	{Name: "large", MaxSide: 1024},
+}
+
+// ProductImage is an uploaded product image. Position orders a product's
+// images, and exactly one of them is primary while any exist.
+type ProductImage struct {
+	ID          uint64
+	ProductID   uint64
+	StorageKey  string
+	ContentType string
+	Width       int
+	Height      int
+	SizeBytes   int64
+	Position    int
+	IsPrimary   bool
+	// ThumbnailKeys maps thumbnail size names to storage keys.
+	ThumbnailKeys map[string]string
+	CreatedAt     time.Time
+}
+
+// StorageKeys returns the keys of the original and all its thumbnails.
+func (i *ProductImage) StorageKeys() []string {
+	keys := []string{i.StorageKey}
+	for _, key := range i.ThumbnailKeys {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// MediaStorage stores binary media under slash-separated keys.
+type MediaStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	// URL returns the address clients fetch the object from.
+	URL(key string) string
+}
+
+// ProcessedImage is a validated upload together with its thumbnails, all
+// encoded and ready to store.
+type ProcessedImage struct {
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+	Original    []byte
+	// Thumbnails maps thumbnail size names to encoded images, which all share
+	// ThumbnailContentType.
+	Thumbnails           map[string][]byte
+	ThumbnailContentType string
+	ThumbnailExtension   string
+}
+
+// ImageProcessor validates uploaded image bytes and renders thumbnails.
+type ImageProcessor interface {
+	Process(data []byte, sizes []ThumbnailSize) (*ProcessedImage, error)
+}
+
+type ProductImageRepository interface {
+	// Create appends the image after the product's existing images. The first
+	// image of a product always becomes primary.
+	Create(ctx context.Context, image *ProductImage) error
+	GetByID(ctx context.Context, productID, imageID uint64) (*ProductImage, error)
+	ListByProduct(ctx context.Context, productID uint64) ([]*ProductImage, error)
+	ListByProducts(ctx context.Context, productIDs []uint64) (map[uint64][]*ProductImage, error)
+	// Reorder sets positions from imageIDs, which must list every image of
+	// the product.
+	Reorder(ctx context.Context, productID uint64, imageIDs []uint64) error
+	SetPrimary(ctx context.Context, productID, imageID uint64) error
+	// Delete removes the image and promotes the next one if it was primary.
+	Delete(ctx context.Context, productID, imageID uint64) (*ProductImage, error)
+}
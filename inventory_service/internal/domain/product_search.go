@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+const DefaultSearchLanguage = "english"
+
+var (
+	ErrEmptySearchQuery          = invalid("empty_search_query", "search query must contain at least one word")
+	ErrUnsupportedSearchLanguage = invalid("unsupported_search_language", "unsupported search language")
+)
+
+// supportedSearchLanguages are the text search configurations products are
+// indexed with.
+var supportedSearchLanguages = map[string]bool{"english": true, "russian": true, "simple": true}
+
+// ProductSearchQuery combines search text with the listing filter. Results
+// are always ordered by relevance, so the filter's sort and cursor are
+// ignored.
+type ProductSearchQuery struct {
+	Text     string
+	Language string
+	Filter   ProductFilter
+}
+
+// ProductSearchResult is a product matched by full-text search together with
+// its relevance and highlighted fragments of the matched fields. Highlights
+// are HTML-escaped, with the matches wrapped in <mark>.
+type ProductSearchResult struct {
+	Product              *Product
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// Validate normalizes the language and checks that the query has something
+// to search for.
+func (q *ProductSearchQuery) Validate() error {
+	if q.Language == "" {
+		q.Language = DefaultSearchLanguage
+	}
+	q.Language = strings.ToLower(q.Language)
+	if !supportedSearchLanguages[q.Language] {
+		return ErrUnsupportedSearchLanguage
+	}
+	if len(q.Terms()) == 0 {
+		return ErrEmptySearchQuery
+	}
+	return q.Filter.Validate()
+}
+
+// Terms splits the search text into words, dropping punctuation and any
+// characters that carry meaning in query syntax.
+func (q ProductSearchQuery) Terms() []string {
+	return strings.FieldsFunc(strings.ToLower(q.Text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
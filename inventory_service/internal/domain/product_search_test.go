@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestProductSearchQueryTerms(t *testing.T) {
+	cases := map[string][]string{
+		"Desk Lamp":              {"desk", "lamp"},
+		"lamp & (shade | !bulb)": {"lamp", "shade", "bulb"},
+		"настольная лампа":       {"настольная", "лампа"},
+		"a:* <-> b":              {"a", "b"},
+		"  ":                     {},
+	}
+	for text, want := range cases {
+		if got := (ProductSearchQuery{Text: text}).Terms(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Terms(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestProductSearchQueryValidate(t *testing.T) {
+	q := ProductSearchQuery{Text: "lamp", Filter: ProductFilter{Limit: 10}}
+	if err := q.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if q.Language != DefaultSearchLanguage {
+		t.Fatalf("got language %q, want the default", q.Language)
+	}
+
+	q = ProductSearchQuery{Text: "lamp", Language: "Russian", Filter: ProductFilter{Limit: 10}}
+	if err := q.Validate(); err != nil || q.Language != "russian" {
+		t.Fatalf("got language %q, error %v", q.Language, err)
+	}
+
+	q = ProductSearchQuery{Text: "lamp", Language: "klingon", Filter: ProductFilter{Limit: 10}}
+	if err := q.Validate(); !errors.Is(err, ErrUnsupportedSearchLanguage) {
+		t.Fatalf("got error %v, want %v", err, ErrUnsupportedSearchLanguage)
+	}
+
+	q = ProductSearchQuery{Text: "&|!", Filter: ProductFilter{Limit: 10}}
+	if err := q.Validate(); !errors.Is(err, ErrEmptySearchQuery) {
+		t.Fatalf("got error %v, want %v", err, ErrEmptySearchQuery)
+	}
+}
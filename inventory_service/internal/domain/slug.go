@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrInvalidSlug   = invalid("invalid_slug", "slug must be lowercase letters, digits and single hyphens, at most 100 characters")
+	ErrDuplicateSlug = conflict("duplicate_slug", "slug already exists")
+)
+
+const MaxSlugLength = 100
+
+// maxGeneratedSlugLength leaves room for the numeric suffix that keeps
+// generated slugs unique.
+const maxGeneratedSlugLength = MaxSlugLength - 10
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func ValidateSlug(slug string) error {
+	if len(slug) > MaxSlugLength || !slugPattern.MatchString(slug) {
+		return ErrInvalidSlug
+	}
+	return nil
+}
+
+// requestedSlug trims a slug given by a client and checks it. An empty slug
+// is passed through, since it asks for one derived from the name.
+func requestedSlug(slug string) (string, error) {
+	slug = strings.TrimSpace(slug)
+	if slug != "" {
+		if err := ValidateSlug(slug); err != nil {
+			return "", err
+		}
+	}
+	return slug, nil
+}
+
+// transliterations spells out the letters of the catalog's languages that
+// have no ASCII form, Russian and Kazakh Cyrillic and common Latin accents.
+var transliterations = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ғ': "gh", 'қ': "q", 'ң': "ng", 'ө': "o", 'ұ': "u", 'ү': "u",
+	'һ': "h", 'і': "i",
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c", 'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ì': "i", 'í': "i",
+	'î': "i", 'ï': "i", 'ñ': "n", 'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o",
+	'ö': "o", 'ø': "o", 'ß': "ss", 'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y", 'ı': "i", 'ş': "s", 'ğ': "g",
+}
+
+// Slugify derives a slug from a name. It returns an empty string when the
+// name has nothing to spell a slug with.
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(name) {
+		var part string
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			part = string(r)
+		case transliterations[r] != "":
+			part = transliterations[r]
+		default:
+			if _, silent := transliterations[r]; !silent {
+				hyphen = b.Len() > 0
+			}
+			continue
+		}
+		if hyphen {
+			b.WriteByte('-')
+			hyphen = false
+		}
+		b.WriteString(part)
+	}
+
+	slug := b.String()
+	if len(slug) > maxGeneratedSlugLength {
+		slug = strings.TrimRight(slug[:maxGeneratedSlugLength], "-")
+	}
+	return slug
+}
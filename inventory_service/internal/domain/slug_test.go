@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	for name, want := range map[string]string{
+		"Desk Lamp":            "desk-lamp",
+		"  Home & Garden!  ":   "home-garden",
+		"USB-C -- 65W charger": "usb-c-65w-charger",
+		"Щётка для обуви":      "shchetka-dlya-obuvi",
+		"Объектив":             "obektiv",
+		"Қазақ тілі":           "qazaq-tili",
+		"Crème Brûlée":         "creme-brulee",
+		"Straße":               "strasse",
+		"日本 Lamp":              "lamp",
+		"日本":                   "",
+		"":                     "",
+	} {
+		if got := Slugify(name); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSlugifyLeavesRoomForASuffix(t *testing.T) {
+	slug := Slugify(strings.Repeat("a", maxGeneratedSlugLength-1) + " bcd")
+	if slug != strings.Repeat("a", maxGeneratedSlugLength-1) {
+		t.Fatalf("got %q, want the name cut before the trailing hyphen", slug)
+	}
+	if err := ValidateSlug(slug + "-12345"); err != nil {
+		t.Fatalf("a suffixed generated slug is invalid: %v", err)
+	}
+}
+
+func TestValidateSlug(t *testing.T) {
+	for _, slug := range []string{"lamp", "desk-lamp-2", strings.Repeat("a", MaxSlugLength)} {
+		if err := ValidateSlug(slug); err != nil {
+			t.Errorf("%q: %v", slug, err)
+		}
+	}
+	for _, slug := range []string{"", "Lamp", "desk--lamp", "-lamp", "lamp-", "desk lamp", "лампа", strings.Repeat("a", MaxSlugLength+1)} {
+		if err := ValidateSlug(slug); !errors.Is(err, ErrInvalidSlug) {
+			t.Errorf("%q: got error %v", slug, err)
+		}
+	}
+}
+
+func TestApplyIdentifiersSlug(t *testing.T) {
+	product, err := NewProduct("Lamp", "", mustMoney(t, "10.00"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slug := " desk-lamp "
+	if err := product.ApplyIdentifiers(nil, nil, &slug); err != nil || product.Slug() != "desk-lamp" {
+		t.Fatalf("got slug %q, error %v", product.Slug(), err)
+	}
+	empty := ""
+	if err := product.ApplyIdentifiers(nil, nil, &empty); err != nil || product.Slug() != "" {
+		t.Fatalf("got slug %q, error %v for an empty slug", product.Slug(), err)
+	}
+	bad := "Desk Lamp"
+	if err := product.ApplyIdentifiers(nil, nil, &bad); !errors.Is(err, ErrInvalidSlug) {
+		t.Fatalf("got error %v for an invalid slug", err)
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrInvalidReorderPoint = invalid("invalid_reorder_point", "reorder point must be greater than or equal to 0")
+)
+
+// StockAlertLevel describes how a product's stock compares to its reorder
+// point.
+type StockAlertLevel string
+
+const (
+	StockLevelOK         StockAlertLevel = "ok"
+	StockLevelLow        StockAlertLevel = "low_stock"
+	StockLevelOutOfStock StockAlertLevel = "out_of_stock"
+)
+
+// StockAlertLevelFor classifies stock against a reorder point. Running out is
+// always reported, even when no reorder point applies.
+func StockAlertLevelFor(stock, reorderPoint int) StockAlertLevel {
+	switch {
+	case stock <= 0:
+		return StockLevelOutOfStock
+	case stock <= reorderPoint:
+		return StockLevelLow
+	default:
+		return StockLevelOK
+	}
+}
+
+// LowStockItem is a product at or below its effective reorder point, which is
+// its own reorder point, else its category's, else the service default.
+type LowStockItem struct {
+	ProductID    uint64
+	Name         string
+	CategoryID   uint64
+	Stock        int
+	ReorderPoint int
+	Level        StockAlertLevel
+}
+
+// StockAlert is emitted when a product enters a low or out-of-stock level.
+type StockAlert struct {
+	Item     LowStockItem
+	Previous StockAlertLevel
+	At       time.Time
+}
+
+// StockNotifier delivers stock alerts to whoever needs to reorder.
+type StockNotifier interface {
+	Notify(ctx context.Context, alert StockAlert) error
+}
+
+type StockAlertRepository interface {
+	// SetProductReorderPoint sets or, when point is nil, clears a product's
+	// own reorder point.
+	SetProductReorderPoint(ctx context.Context, productID uint64, point *int) error
+	// SetCategoryReorderPoint sets or clears the reorder point inherited by
+	// products of a category that have none of their own.
+	SetCategoryReorderPoint(ctx context.Context, categoryID uint64, point *int) error
+	// ListLowStock returns products at or below their effective reorder
+	// point, falling back to defaultPoint.
+	ListLowStock(ctx context.Context, defaultPoint int) ([]*LowStockItem, error)
+	// AlertedLevels returns the level each product was last alerted at, for
+	// the products that have not recovered since.
+	AlertedLevels(ctx context.Context) (map[uint64]StockAlertLevel, error)
+	// RecordAlert remembers the level a product was alerted at.
+	RecordAlert(ctx context.Context, productID uint64, level StockAlertLevel) error
+	// ForgetAlerts drops the alerted levels of products that recovered.
+	ForgetAlerts(ctx context.Context, productIDs []uint64) error
+}
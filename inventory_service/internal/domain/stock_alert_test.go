@@ -0,0 +1,22 @@
+package domain
+
+import "testing"
+
+func TestStockAlertLevelFor(t *testing.T) {
+	cases := []struct {
+		stock, reorderPoint int
+		want                StockAlertLevel
+	}{
+		{10, 5, StockLevelOK},
+		{5, 5, StockLevelLow},
+		{1, 5, StockLevelLow},
+		{0, 5, StockLevelOutOfStock},
+		{0, 0, StockLevelOutOfStock},
+		{1, 0, StockLevelOK},
+	}
+	for _, tc := range cases {
+		if got := StockAlertLevelFor(tc.stock, tc.reorderPoint); got != tc.want {
+			t.Errorf("StockAlertLevelFor(%d, %d) = %s, want %s", tc.stock, tc.reorderPoint, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+var (
+	ErrInvalidMovementReason = invalid("invalid_movement_reason", "invalid stock movement reason")
+	ErrInvalidMovementDelta  = invalid("invalid_movement_delta", "stock movement delta does not match its reason")
+)
+
+type MovementReason string
+
+const (
+	MovementSale        MovementReason = "sale"
+	MovementRestock     MovementReason = "restock"
+	MovementAdjustment  MovementReason = "adjustment"
+	MovementReturn      MovementReason = "return"
+	MovementReservation MovementReason = "reservation"
+	// MovementTransfer moves stock between warehouses, one entry for each
+	// side.
+	MovementTransfer MovementReason = "transfer"
+)
+
+func (r MovementReason) Valid() bool {
+	switch r {
+	case MovementSale, MovementRestock, MovementAdjustment, MovementReturn, MovementReservation, MovementTransfer:
+		return true
+	}
+	return false
+}
+
+// StockMovement is an immutable ledger entry for a single stock change of a
+// product or, when VariantID is set, of one of its variants. The current stock
+// equals the sum of all movement deltas.
+//
+// Entries with a WarehouseID record the product's stock in that warehouse
+// instead, which is kept apart from the product's stock (see StockLevel):
+// the warehouse's stock equals the sum of its entries, and they leave the
+// product's own sum alone.
+type StockMovement struct {
+	ID          uint64
+	ProductID   uint64
+	VariantID   uint64
+	WarehouseID uint64
+	Delta       int
+	Reason      MovementReason
+	ReferenceID string
+	Actor       string
+	CreatedAt   time.Time
+}
+
+// NewStockMovement validates a stock change. Sales and reservations can only
+// take stock out and restocks and returns can only put it back; adjustments
+// go either way.
+func NewStockMovement(productID, variantID uint64, delta int, reason MovementReason, referenceID, actor string) (*StockMovement, error) {
+	if !reason.Valid() || reason == MovementTransfer {
+		return nil, ErrInvalidMovementReason
+	}
+	if delta == 0 {
+		return nil, ErrInvalidQuantity
+	}
+	switch reason {
+	case MovementSale, MovementReservation:
+		if delta > 0 {
+			return nil, ErrInvalidMovementDelta
+		}
+	case MovementRestock, MovementReturn:
+		if delta < 0 {
+			return nil, ErrInvalidMovementDelta
+		}
+	}
+	if actor == "" {
+		actor = SystemActor
+	}
+
+	return &StockMovement{
+		ProductID:   productID,
+		VariantID:   variantID,
+		Delta:       delta,
+		Reason:      reason,
+		ReferenceID: referenceID,
+		Actor:       actor,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// NewWarehouseMovement validates a change of a product's stock in a
+// warehouse, which is either an adjustment or one side of a transfer.
+func NewWarehouseMovement(productID, warehouseID uint64, delta int, reason MovementReason, referenceID, actor string) (*StockMovement, error) {
+	if reason != MovementAdjustment && reason != MovementTransfer {
+		return nil, ErrInvalidMovementReason
+	}
+	if delta == 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if actor == "" {
+		actor = SystemActor
+	}
+
+	return &StockMovement{
+		ProductID:   productID,
+		WarehouseID: warehouseID,
+		Delta:       delta,
+		Reason:      reason,
+		ReferenceID: referenceID,
+		Actor:       actor,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// StockDiscrepancy reports a stock column that disagrees with the sum of its
+// ledger.
+type StockDiscrepancy struct {
+	ProductID     uint64
+	VariantID     uint64
+	WarehouseID   uint64
+	RecordedStock int
+	LedgerStock   int
+}
+
+type StockMovementFilter struct {
+	ProductID uint64
+	Reason    MovementReason
+	Offset    int
+	Limit     int
+}
+
+type StockMovementRepository interface {
+	// Apply changes the product or variant stock and records the movement in
+	// the same transaction. It returns the new stock level.
+	Apply(ctx context.Context, movement *StockMovement) (int, error)
+	List(ctx context.Context, filter StockMovementFilter) ([]*StockMovement, error)
+	// Reconcile compares stock columns, warehouse stock included, with their
+	// ledger. A zero productID checks the whole catalog.
+	Reconcile(ctx context.Context, productID uint64) ([]*StockDiscrepancy, error)
+}
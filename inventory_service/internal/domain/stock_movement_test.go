@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewStockMovementChecksDirection(t *testing.T) {
+	cases := []struct {
+		reason MovementReason
+		delta  int
+		want   error
+	}{
+		{MovementSale, -2, nil},
+		{MovementSale, 2, ErrInvalidMovementDelta},
+		{MovementReservation, 1, ErrInvalidMovementDelta},
+		{MovementRestock, 5, nil},
+		{MovementRestock, -5, ErrInvalidMovementDelta},
+		{MovementReturn, -1, ErrInvalidMovementDelta},
+		{MovementAdjustment, -3, nil},
+		{MovementAdjustment, 3, nil},
+		{MovementAdjustment, 0, ErrInvalidQuantity},
+		{"theft", -1, ErrInvalidMovementReason},
+		// Transfers only move stock between warehouses.
+		{MovementTransfer, 1, ErrInvalidMovementReason},
+	}
+	for _, tc := range cases {
+		if _, err := NewStockMovement(1, 0, tc.delta, tc.reason, "", "alice"); !errors.Is(err, tc.want) {
+			t.Errorf("%s %+d: got error %v, want %v", tc.reason, tc.delta, err, tc.want)
+		}
+	}
+
+	m, err := NewStockMovement(1, 2, -1, MovementSale, "order-7", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Actor != SystemActor || m.VariantID != 2 || m.WarehouseID != 0 {
+		t.Fatalf("got %+v", m)
+	}
+}
+
+func TestNewWarehouseMovement(t *testing.T) {
+	m, err := NewWarehouseMovement(1, 3, -4, MovementTransfer, "transfer:9", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.WarehouseID != 3 || m.VariantID != 0 || m.Delta != -4 {
+		t.Fatalf("got %+v", m)
+	}
+	if _, err := NewWarehouseMovement(1, 3, 4, MovementAdjustment, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewWarehouseMovement(1, 3, -4, MovementSale, "", ""); !errors.Is(err, ErrInvalidMovementReason) {
+		t.Fatalf("got error %v for a warehouse sale", err)
+	}
+	if _, err := NewWarehouseMovement(1, 3, 0, MovementTransfer, "", ""); !errors.Is(err, ErrInvalidQuantity) {
+		t.Fatalf("got error %v for an empty transfer", err)
+	}
+}
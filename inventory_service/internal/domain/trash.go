@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ErrCategoryTrashed is returned when restoring an entity whose category, or
+// parent category, is itself still in the trash.
+var ErrCategoryTrashed = conflict("category_trashed", "category is in the trash and must be restored first")
+
+// PurgeBatchSize bounds the rows hard-deleted in one transaction.
+const PurgeBatchSize = 200
+
+// PurgeResult counts what one purge run removed from the trash.
+type PurgeResult struct {
+	Products   int
+	Categories int
+	// BlockedCategories are past retention but still referenced by a product
+	// or a child category, and stay in the trash until they are not.
+	BlockedCategories int
+}
+
+// PurgedProducts are the products a purge batch deleted, with the media keys
+// of their images, which no longer have a row pointing at them.
+type PurgedProducts struct {
+	Count       int
+	StorageKeys []string
+}
+
+// TrashRepository hard-deletes trashed rows that were deleted before a
+// cutoff. Products go first, so that their categories can follow.
+type TrashRepository interface {
+	PurgeProducts(ctx context.Context, before time.Time, limit int) (*PurgedProducts, error)
+	// PurgeCategories deletes only categories that no product, live or
+	// trashed, and no child category refers to.
+	PurgeCategories(ctx context.Context, before time.Time, limit int) (int, error)
+	CountBlockedCategories(ctx context.Context, before time.Time) (int, error)
+}
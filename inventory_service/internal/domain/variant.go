@@ -0,0 +1,155 @@
+package domain
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strings"
+	"time"
+)
+
+var (
+	ErrVariantNotFound = notFound("variant_not_found", "variant not found")
+	ErrInvalidSKU      = invalid("invalid_sku", "sku must not be empty")
+	ErrDuplicateSKU    = conflict("duplicate_sku", "sku already exists")
+)
+
+// Variant is a sellable version of a product, such as a shirt in one size and
+// colour. It carries its own SKU and stock and may override the product price.
+type Variant struct {
+	id        uint64
+	productID uint64
+	sku       string
+	options   map[string]string
+	price     *money.Money
+	stock     int
+	createdAt time.Time
+	updatedAt time.Time
+	isDeleted bool
+	version   int
+}
+
+func NewVariant(productID uint64, sku string, options map[string]string, price *money.Money, stock int) (*Variant, error) {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil, ErrInvalidSKU
+	}
+	if price != nil && !validPrice(*price) {
+		return nil, ErrInvalidPrice
+	}
+	if stock < 0 {
+		return nil, ErrInvalidStock
+	}
+	if options == nil {
+		options = map[string]string{}
+	}
+
+	now := time.Now()
+	return &Variant{
+		productID: productID,
+		sku:       sku,
+		options:   options,
+		price:     price,
+		stock:     stock,
+		createdAt: now,
+		updatedAt: now,
+		version:   1,
+	}, nil
+}
+
+func (v *Variant) ID() uint64 {
+	return v.id
+}
+
+func (v *Variant) SetID(id uint64) {
+	v.id = id
+}
+
+func (v *Variant) ProductID() uint64 {
+	return v.productID
+}
+
+func (v *Variant) SKU() string {
+	return v.sku
+}
+
+// Options returns the option values that distinguish the variant, for
+// example {"size": "M", "color": "red"}.
+func (v *Variant) Options() map[string]string {
+	return v.options
+}
+
+// Price returns the variant's price override, or nil when it sells at the
+// product price.
+func (v *Variant) Price() *money.Money {
+	return v.price
+}
+
+func (v *Variant) EffectivePrice(product *Product) money.Money {
+	if v.price != nil {
+		return *v.price
+	}
+	return product.Price()
+}
+
+func (v *Variant) Stock() int {
+	return v.stock
+}
+
+func (v *Variant) CreatedAt() time.Time {
+	return v.createdAt
+}
+
+func (v *Variant) UpdatedAt() time.Time {
+	return v.updatedAt
+}
+
+func (v *Variant) IsDeleted() bool {
+	return v.isDeleted
+}
+
+func (v *Variant) Version() int {
+	return v.version
+}
+
+func (v *Variant) SetVersion(version int) {
+	v.version = version
+}
+
+func (v *Variant) SetTimestamps(createdAt, updatedAt time.Time) {
+	v.createdAt = createdAt
+	v.updatedAt = updatedAt
+}
+
+func (v *Variant) Update(sku string, options map[string]string, price *money.Money, stock int) error {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return ErrInvalidSKU
+	}
+	if price != nil && !validPrice(*price) {
+		return ErrInvalidPrice
+	}
+	if stock < 0 {
+		return ErrInvalidStock
+	}
+	if options == nil {
+		options = map[string]string{}
+	}
+
+	v.sku = sku
+	v.options = options
+	v.price = price
+	v.stock = stock
+	v.updatedAt = time.Now()
+	return nil
+}
+
+type VariantRepository interface {
+	Create(ctx context.Context, variant *Variant) error
+	GetByID(ctx context.Context, id uint64) (*Variant, error)
+	ListByProduct(ctx context.Context, productID uint64) ([]*Variant, error)
+	// ListByProducts loads the variants of several products at once, keyed by
+	// product ID.
+	ListByProducts(ctx context.Context, productIDs []uint64) (map[uint64][]*Variant, error)
+	Update(ctx context.Context, variant *Variant) error
+	Delete(ctx context.Context, id uint64, version int) error
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+func mustMoney(t *testing.T, amount string) money.Money {
+	t.Helper()
+	m, err := money.Parse(amount, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestNewVariantValidates(t *testing.T) {
+	negative := mustMoney(t, "-1.00")
+	cases := []struct {
+		name  string
+		sku   string
+		price *money.Money
+		stock int
+		want  error
+	}{
+		{"blank sku", "  ", nil, 0, ErrInvalidSKU},
+		{"negative price", "LAMP-M", &negative, 0, ErrInvalidPrice},
+		{"unset price", "LAMP-M", &money.Money{}, 0, ErrInvalidPrice},
+		{"negative stock", "LAMP-M", nil, -1, ErrInvalidStock},
+	}
+	for _, tc := range cases {
+		if _, err := NewVariant(1, tc.sku, nil, tc.price, tc.stock); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got error %v, want %v", tc.name, err, tc.want)
+		}
+	}
+
+	v, err := NewVariant(1, " LAMP-M ", nil, nil, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.SKU() != "LAMP-M" || v.Options() == nil || v.Version() != 1 {
+		t.Fatalf("got sku %q, options %v, version %d", v.SKU(), v.Options(), v.Version())
+	}
+}
+
+func TestVariantEffectivePrice(t *testing.T) {
+	product, err := NewProduct("Lamp", "", mustMoney(t, "20.00"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := NewVariant(1, "LAMP-M", map[string]string{"size": "M"}, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := v.EffectivePrice(product); got.Decimal() != "20.00" {
+		t.Fatalf("got %s without an override", got.Decimal())
+	}
+
+	override := mustMoney(t, "25.00")
+	if err := v.Update("LAMP-M", v.Options(), &override, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.EffectivePrice(product); got.Decimal() != "25.00" {
+		t.Fatalf("got %s with an override", got.Decimal())
+	}
+	if err := v.Update("", nil, nil, 2); !errors.Is(err, ErrInvalidSKU) {
+		t.Fatalf("got error %v updating to a blank sku", err)
+	}
+}
@@ -0,0 +1,7 @@
+package domain
+
+var (
+	// ErrVersionConflict is returned when an update or delete was based on a
+	// stale version of the entity.
+	ErrVersionConflict = stale("version_conflict", "version conflict")
+)
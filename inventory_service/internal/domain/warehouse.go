@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWarehouseNotFound      = notFound("warehouse_not_found", "warehouse not found")
+	ErrInvalidWarehouseCode   = invalid("invalid_warehouse_code", "warehouse code must not be empty")
+	ErrDuplicateWarehouseCode = conflict("duplicate_warehouse_code", "warehouse code already exists")
+	ErrWarehouseNotEmpty      = conflict("warehouse_not_empty", "warehouse still holds stock")
+	ErrWarehouseInactive      = conflict("warehouse_inactive", "warehouse is not active")
+	ErrInvalidQuantity        = invalid("invalid_quantity", "quantity must be greater than 0")
+	ErrSameWarehouse          = invalid("same_warehouse", "source and destination warehouses must differ")
+	ErrInvalidLocation        = invalid("invalid_location", "latitude must be within ±90 and longitude within ±180")
+)
+
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Valid reports whether the point lies on the globe.
+func (p GeoPoint) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// DistanceKm returns the great-circle distance between two points.
+func (p GeoPoint) DistanceKm(other GeoPoint) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(other.Latitude - p.Latitude)
+	dLon := toRad(other.Longitude - p.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(p.Latitude))*math.Cos(toRad(other.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+type Warehouse struct {
+	id        uint64
+	code      string
+	name      string
+	location  *GeoPoint
+	isActive  bool
+	createdAt time.Time
+	updatedAt time.Time
+	version   int
+}
+
+func NewWarehouse(code, name string, location *GeoPoint) (*Warehouse, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return nil, ErrInvalidWarehouseCode
+	}
+	if location != nil && !location.Valid() {
+		return nil, ErrInvalidLocation
+	}
+
+	now := time.Now()
+	return &Warehouse{
+		code:      code,
+		name:      name,
+		location:  location,
+		isActive:  true,
+		createdAt: now,
+		updatedAt: now,
+		version:   1,
+	}, nil
+}
+
+func (w *Warehouse) ID() uint64 {
+	return w.id
+}
+
+func (w *Warehouse) SetID(id uint64) {
+	w.id = id
+}
+
+func (w *Warehouse) Code() string {
+	return w.code
+}
+
+func (w *Warehouse) Name() string {
+	return w.name
+}
+
+// Location returns the warehouse coordinates, or nil if they are unknown.
+func (w *Warehouse) Location() *GeoPoint {
+	return w.location
+}
+
+func (w *Warehouse) IsActive() bool {
+	return w.isActive
+}
+
+func (w *Warehouse) CreatedAt() time.Time {
+	return w.createdAt
+}
+
+func (w *Warehouse) UpdatedAt() time.Time {
+	return w.updatedAt
+}
+
+func (w *Warehouse) Version() int {
+	return w.version
+}
+
+func (w *Warehouse) SetVersion(version int) {
+	w.version = version
+}
+
+func (w *Warehouse) SetTimestamps(createdAt, updatedAt time.Time) {
+	w.createdAt = createdAt
+	w.updatedAt = updatedAt
+}
+
+// WarehouseChanges is a partial update of a warehouse. Nil fields are left as
+// they are, and so is the location unless LocationSet is true, in which case
+// a nil Location clears it. The code never changes.
+type WarehouseChanges struct {
+	Name        *string
+	LocationSet bool
+	Location    *GeoPoint
+	IsActive    *bool
+}
+
+func (w *Warehouse) Apply(changes WarehouseChanges) error {
+	if changes.LocationSet && changes.Location != nil && !changes.Location.Valid() {
+		return ErrInvalidLocation
+	}
+
+	if changes.Name != nil {
+		w.name = *changes.Name
+	}
+	if changes.LocationSet {
+		w.location = changes.Location
+	}
+	if changes.IsActive != nil {
+		w.isActive = *changes.IsActive
+	}
+	w.updatedAt = time.Now()
+	return nil
+}
+
+// StockLevel is the quantity of a product held in one warehouse.
+//
+// Warehouse stock records where physical units sit. It is kept apart from
+// a product's stock, which is the quantity on sale and is what sales,
+// reservations and catalog edits change: warehouse adjustments and transfers
+// never touch the product's stock, and selling from the product's stock does
+// not say which warehouse shipped. The two are not reconciled with each
+// other; availability and allocation only look at warehouse stock.
+type StockLevel struct {
+	WarehouseID uint64
+	ProductID   uint64
+	Quantity    int
+	UpdatedAt   time.Time
+}
+
+type StockTransfer struct {
+	ID              uint64
+	ProductID       uint64
+	FromWarehouseID uint64
+	ToWarehouseID   uint64
+	Quantity        int
+	Note            string
+	CreatedAt       time.Time
+}
+
+func NewStockTransfer(productID, fromWarehouseID, toWarehouseID uint64, quantity int, note string) (*StockTransfer, error) {
+	if quantity <= 0 {
+		return nil, ErrInvalidQuantity
+	}
+	if fromWarehouseID == toWarehouseID {
+		return nil, ErrSameWarehouse
+	}
+	return &StockTransfer{
+		ProductID:       productID,
+		FromWarehouseID: fromWarehouseID,
+		ToWarehouseID:   toWarehouseID,
+		Quantity:        quantity,
+		Note:            note,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// ProductAvailability is a product's available-to-sell quantity aggregated
+// over all active warehouses, together with the per-warehouse breakdown.
+type ProductAvailability struct {
+	ProductID       uint64
+	AvailableToSell int
+	Levels          []*StockLevel
+}
+
+type WarehouseRepository interface {
+	Create(ctx context.Context, warehouse *Warehouse) error
+	GetByID(ctx context.Context, id uint64) (*Warehouse, error)
+	List(ctx context.Context) ([]*Warehouse, error)
+	Update(ctx context.Context, warehouse *Warehouse) error
+	Delete(ctx context.Context, id uint64, version int) error
+
+	// StockLevels returns the product's stock in every active warehouse.
+	StockLevels(ctx context.Context, productID uint64) ([]*StockLevel, error)
+	// AdjustStock atomically adds delta to the product's stock in a warehouse
+	// and returns the new level.
+	AdjustStock(ctx context.Context, warehouseID, productID uint64, delta int) (int, error)
+	// Transfer moves stock between warehouses in a single transaction.
+	Transfer(ctx context.Context, transfer *StockTransfer) error
+	ListTransfers(ctx context.Context, productID uint64, offset, limit int) ([]*StockTransfer, error)
+}
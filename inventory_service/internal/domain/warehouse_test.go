@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestWarehouseApplyChangesOnlyPresentFields(t *testing.T) {
+	location := &GeoPoint{Latitude: 43.24, Longitude: 76.95}
+	w, err := NewWarehouse("ALA", "Almaty", location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inactive := false
+	if err := w.Apply(WarehouseChanges{IsActive: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "Almaty Central"
+	if err := w.Apply(WarehouseChanges{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Name() != name || w.IsActive() || w.Location() != location || w.Code() != "ALA" {
+		t.Fatalf("got name %q, active %v, location %v", w.Name(), w.IsActive(), w.Location())
+	}
+
+	if err := w.Apply(WarehouseChanges{LocationSet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if w.Location() != nil {
+		t.Fatal("a set nil location did not clear it")
+	}
+
+	err = w.Apply(WarehouseChanges{Name: &name, LocationSet: true, Location: &GeoPoint{Latitude: 91}})
+	if !errors.Is(err, ErrInvalidLocation) {
+		t.Fatalf("got error %v for a latitude of 91", err)
+	}
+}
+
+func TestNewWarehouseValidates(t *testing.T) {
+	if _, err := NewWarehouse(" ", "Nowhere", nil); !errors.Is(err, ErrInvalidWarehouseCode) {
+		t.Fatalf("got error %v for a blank code", err)
+	}
+	if _, err := NewWarehouse("X", "Nowhere", &GeoPoint{Longitude: 181}); !errors.Is(err, ErrInvalidLocation) {
+		t.Fatalf("got error %v for a longitude of 181", err)
+	}
+}
+
+func TestGeoPointDistanceKm(t *testing.T) {
+	almaty := GeoPoint{Latitude: 43.2389, Longitude: 76.8897}
+	astana := GeoPoint{Latitude: 51.1694, Longitude: 71.4491}
+	if d := almaty.DistanceKm(astana); math.Abs(d-971) > 10 {
+		t.Fatalf("got %.0f km between Almaty and Astana", d)
+	}
+	if d := almaty.DistanceKm(almaty); d != 0 {
+		t.Fatalf("got %f km from a point to itself", d)
+	}
+}
@@ -0,0 +1,17 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// ActorMiddleware records the calling user, as forwarded by the gateway in
+// X-User-ID, on the request context for audit records.
+func ActorMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if actor := c.GetHeader("X-User-ID"); actor != "" {
+			c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), actor))
+		}
+		c.Next()
+	}
+}
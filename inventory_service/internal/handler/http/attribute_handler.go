@@ -0,0 +1,151 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type AttributeHandler struct {
+	attributeUseCase *usecase.AttributeUseCase
+}
+
+func NewAttributeHandler(uc *usecase.AttributeUseCase) *AttributeHandler {
+	return &AttributeHandler{
+		attributeUseCase: uc,
+	}
+}
+
+func (h *AttributeHandler) RegisterRoutes(router *gin.Engine) {
+	categories := router.Group("/api/categories")
+	{
+		categories.GET("/:id/attributes", h.ListAttributes)
+		categories.POST("/:id/attributes", h.CreateAttribute)
+		categories.PUT("/:id/attributes/:attributeId", h.UpdateAttribute)
+		categories.DELETE("/:id/attributes/:attributeId", h.DeleteAttribute)
+	}
+}
+
+// ListAttributes returns the schema products of the category must follow,
+// including inherited definitions unless inherited=false.
+func (h *AttributeHandler) ListAttributes(c *gin.Context) {
+	categoryID, ok := h.categoryID(c)
+	if !ok {
+		return
+	}
+	inherited, err := strconv.ParseBool(c.DefaultQuery("inherited", "true"))
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid inherited flag")
+		return
+	}
+
+	definitions, err := h.attributeUseCase.ListAttributes(c.Request.Context(), categoryID, inherited)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]dto.AttributeResponse, len(definitions))
+	for i, d := range definitions {
+		response[i] = *dto.FromAttributeDefinition(d)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func (h *AttributeHandler) CreateAttribute(c *gin.Context) {
+	categoryID, ok := h.categoryID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	definition, err := req.ToAttributeDefinition(categoryID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if err := h.attributeUseCase.CreateAttribute(c.Request.Context(), definition); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromAttributeDefinition(definition))
+}
+
+func (h *AttributeHandler) UpdateAttribute(c *gin.Context) {
+	categoryID, ok := h.categoryID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("attributeId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid attribute ID format")
+		return
+	}
+
+	var req dto.AttributeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	definition, err := h.attributeUseCase.UpdateAttribute(c.Request.Context(), categoryID, id,
+		req.Name, domain.AttributeType(req.Type), req.Unit, req.Required, req.AllowedValues)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAttributeDefinition(definition))
+}
+
+func (h *AttributeHandler) DeleteAttribute(c *gin.Context) {
+	categoryID, ok := h.categoryID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("attributeId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid attribute ID format")
+		return
+	}
+
+	if err := h.attributeUseCase.DeleteAttribute(c.Request.Context(), categoryID, id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *AttributeHandler) categoryID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeError answers with a problem for err, in the attribute wording for
+// the common cases.
+func (h *AttributeHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		writeErrorDetail(c, err, "Category not found")
+	case errors.Is(err, domain.ErrAttributeNotFound):
+		writeErrorDetail(c, err, "Attribute not found")
+	default:
+		writeDomainError(c, err)
+	}
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type BundleHandler struct {
+	bundleUseCase *usecase.BundleUseCase
+}
+
+func NewBundleHandler(uc *usecase.BundleUseCase) *BundleHandler {
+	return &BundleHandler{
+		bundleUseCase: uc,
+	}
+}
+
+func (h *BundleHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/products/:id/bundle", h.GetBundle)
+		v1.PUT("/products/:id/bundle", h.SaveBundle)
+		v1.DELETE("/products/:id/bundle", h.DeleteBundle)
+	}
+}
+
+func (h *BundleHandler) GetBundle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	bundle, err := h.bundleUseCase.GetBundle(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromBundle(bundle))
+}
+
+// SaveBundle makes the product a bundle, or replaces its components. A
+// product that still has stock of its own cannot become a bundle.
+func (h *BundleHandler) SaveBundle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req dto.BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	bundle, err := h.bundleUseCase.SaveBundle(c.Request.Context(), id, domain.BundlePricing(req.Pricing), req.DiscountPercent.String(), req.ToComponents())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromBundle(bundle))
+}
+
+func (h *BundleHandler) DeleteBundle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	if err := h.bundleUseCase.DeleteBundle(c.Request.Context(), id); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BundleHandler) writeError(c *gin.Context, err error) {
+	writeDomainError(c, err)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// CacheHandler reports repository cache statistics. With caching disabled it
+// has no caches and reports an empty list.
+type CacheHandler struct {
+	caches []domain.CacheStatsProvider
+}
+
+func NewCacheHandler(caches ...domain.CacheStatsProvider) *CacheHandler {
+	return &CacheHandler{
+		caches: caches,
+	}
+}
+
+func (h *CacheHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/cache/stats", h.Stats)
+	}
+}
+
+func (h *CacheHandler) Stats(c *gin.Context) {
+	response := make([]dto.CacheStatsResponse, len(h.caches))
+	for i, cache := range h.caches {
+		response[i] = *dto.FromCacheStats(cache.Stats())
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "enabled": len(response) > 0})
+}
@@ -0,0 +1,205 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/adapter/catalogio"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxImportSize bounds an uploaded import file.
+const maxImportSize = 256 << 20
+
+type CatalogHandler struct {
+	catalogUseCase *usecase.CatalogUseCase
+}
+
+func NewCatalogHandler(uc *usecase.CatalogUseCase) *CatalogHandler {
+	return &CatalogHandler{
+		catalogUseCase: uc,
+	}
+}
+
+func (h *CatalogHandler) RegisterRoutes(router *gin.Engine) {
+	catalog := router.Group("/api/v1/catalog")
+	{
+		catalog.POST("/import", h.Import)
+		catalog.GET("/import/:jobId", h.GetImportJob)
+		catalog.GET("/export", h.Export)
+	}
+}
+
+// Import accepts a CSV or NDJSON file, either as the raw request body or as
+// the "file" field of a multipart form, and starts an asynchronous import.
+// The upload is spooled to disk first so that the job can outlive the request.
+func (h *CatalogHandler) Import(c *gin.Context) {
+	entity := domain.CatalogEntity(c.Query("entity"))
+	if !entity.Valid() {
+		writeErrorStatus(c, http.StatusBadRequest, domain.ErrUnknownCatalogEntity)
+		return
+	}
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "dry_run must be a boolean")
+		return
+	}
+
+	body, format, err := h.upload(c)
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	defer body.Close()
+	if !format.Valid() {
+		writeErrorStatus(c, http.StatusBadRequest, domain.ErrUnknownCatalogFormat)
+		return
+	}
+
+	spool, err := os.CreateTemp("", "catalog-import-*")
+	if err != nil {
+		writeDomainError(c, err)
+		return
+	}
+	done := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+	if _, err := io.Copy(spool, http.MaxBytesReader(c.Writer, body, maxImportSize)); err != nil {
+		done()
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		done()
+		writeDomainError(c, err)
+		return
+	}
+
+	var job *domain.ImportJob
+	ctx := c.Request.Context()
+	if entity == domain.CatalogProducts {
+		reader, _ := catalogio.NewProductReader(format, spool)
+		job, err = h.catalogUseCase.StartProductImport(ctx, format, dryRun, reader, done)
+	} else {
+		reader, _ := catalogio.NewCategoryReader(format, spool)
+		job, err = h.catalogUseCase.StartCategoryImport(ctx, format, dryRun, reader, done)
+	}
+	if err != nil {
+		done()
+		writeDomainError(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/catalog/import/"+job.ID)
+	c.JSON(http.StatusAccepted, dto.FromImportJob(job))
+}
+
+// upload returns the uploaded file and its format, taken from the format
+// query parameter or else guessed from the content type or file name.
+func (h *CatalogHandler) upload(c *gin.Context) (io.ReadCloser, domain.CatalogFormat, error) {
+	format := domain.CatalogFormat(c.Query("format"))
+
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", errors.New("multipart upload must carry a file field")
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, "", err
+		}
+		if format == "" {
+			format = formatFromName(header.Filename)
+		}
+		return file, format, nil
+	}
+
+	if format == "" {
+		switch c.ContentType() {
+		case "text/csv":
+			format = domain.CatalogCSV
+		case "application/x-ndjson", "application/ndjson":
+			format = domain.CatalogNDJSON
+		}
+	}
+	return c.Request.Body, format, nil
+}
+
+func formatFromName(name string) domain.CatalogFormat {
+	switch {
+	case strings.HasSuffix(strings.ToLower(name), ".csv"):
+		return domain.CatalogCSV
+	case strings.HasSuffix(strings.ToLower(name), ".ndjson"), strings.HasSuffix(strings.ToLower(name), ".jsonl"):
+		return domain.CatalogNDJSON
+	}
+	return ""
+}
+
+func (h *CatalogHandler) GetImportJob(c *gin.Context) {
+	job, err := h.catalogUseCase.GetImportJob(c.Request.Context(), c.Param("jobId"))
+	if errors.Is(err, domain.ErrImportJobNotFound) {
+		writeErrorStatus(c, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromImportJob(job))
+}
+
+// Export streams the catalog in import format. Once streaming has started
+// the status can no longer change, so a failure part-way through ends the
+// response early and is only logged.
+func (h *CatalogHandler) Export(c *gin.Context) {
+	entity := domain.CatalogEntity(c.Query("entity"))
+	if !entity.Valid() {
+		writeErrorStatus(c, http.StatusBadRequest, domain.ErrUnknownCatalogEntity)
+		return
+	}
+	format := domain.CatalogFormat(c.DefaultQuery("format", string(domain.CatalogCSV)))
+	if !format.Valid() {
+		writeErrorStatus(c, http.StatusBadRequest, domain.ErrUnknownCatalogFormat)
+		return
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == domain.CatalogNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+string(entity)+"."+string(format)+`"`)
+	c.Status(http.StatusOK)
+
+	var err error
+	ctx := c.Request.Context()
+	if entity == domain.CatalogProducts {
+		writer, _ := catalogio.NewProductWriter(format, c.Writer)
+		err = h.catalogUseCase.ExportProducts(ctx, func(product *domain.Product, categoryExternalID string) error {
+			return writer.Write(product, categoryExternalID)
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+	} else {
+		writer, _ := catalogio.NewCategoryWriter(format, c.Writer)
+		err = h.catalogUseCase.ExportCategories(ctx, func(category *domain.Category, parentExternalID string) error {
+			return writer.Write(category, parentExternalID)
+		})
+		if err == nil {
+			err = writer.Flush()
+		}
+	}
+	if err != nil {
+		log.Printf("catalog export of %s aborted: %v", entity, err)
+	}
+}
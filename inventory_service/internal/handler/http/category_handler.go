@@ -1,56 +1,83 @@
 package http
 
 import (
+	"errors"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
 )
 
 type CategoryHandler struct {
-	categoryRepo domain.CategoryRepository
+	categoryUseCase *usecase.CategoryUseCase
+	// deletePolicy applies to deletes that do not name a policy.
+	deletePolicy domain.CategoryDeletePolicy
 }
 
-func NewCategoryHandler(repo domain.CategoryRepository) *CategoryHandler {
+func NewCategoryHandler(categoryUseCase *usecase.CategoryUseCase, deletePolicy domain.CategoryDeletePolicy) *CategoryHandler {
 	return &CategoryHandler{
-		categoryRepo: repo,
+		categoryUseCase: categoryUseCase,
+		deletePolicy:    deletePolicy,
 	}
 }
 
 func (h *CategoryHandler) CreateCategory(c *gin.Context) {
 	var req dto.CategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		ginproblem.WriteBindingError(c, err)
 		return
 	}
 
-	category := req.ToCategory()
-	if err := h.categoryRepo.Create(c.Request.Context(), category); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category: " + err.Error()})
+	category, err := req.ToCategory()
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	if err := h.categoryUseCase.CreateCategory(c.Request.Context(), category); err != nil {
+		h.writeError(c, err)
 		return
 	}
 
+	setETag(c, category.Version())
 	c.JSON(http.StatusCreated, dto.FromCategory(category))
 }
 
 func (h *CategoryHandler) GetCategory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
 		return
 	}
 
-	category, err := h.categoryRepo.GetByID(c.Request.Context(), id)
+	category, err := h.categoryUseCase.GetCategory(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category: " + err.Error()})
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, category.Version())
+	c.JSON(http.StatusOK, dto.FromCategory(category))
+}
+
+// GetCategoryBySlug answers an old slug with a permanent redirect to the
+// category's current one.
+func (h *CategoryHandler) GetCategoryBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	category, current, err := h.categoryUseCase.GetCategoryBySlug(c.Request.Context(), slug)
+	if err != nil {
+		h.writeError(c, err)
 		return
 	}
 	if category == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		redirectToSlug(c, "/api/categories/by-slug/", current)
 		return
 	}
 
+	setETag(c, category.Version())
 	c.JSON(http.StatusOK, dto.FromCategory(category))
 }
 
@@ -67,9 +94,9 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
-	categories, err := h.categoryRepo.List(c.Request.Context(), offset, limit)
+	categories, err := h.categoryUseCase.ListCategories(c.Request.Context(), offset, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch categories: " + err.Error()})
+		h.writeError(c, err)
 		return
 	}
 
@@ -94,66 +121,186 @@ func (h *CategoryHandler) ListCategories(c *gin.Context) {
 func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
 	var req dto.CategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	category, err := h.categoryUseCase.UpdateCategory(c.Request.Context(), id, version, req.ToChanges())
+	if err != nil {
+		h.writeError(c, err)
 		return
 	}
 
-	existingCategory, err := h.categoryRepo.GetByID(c.Request.Context(), id)
+	setETag(c, category.Version())
+	c.JSON(http.StatusOK, dto.FromCategory(category))
+}
+
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category: " + err.Error()})
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
 		return
 	}
-	if existingCategory == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	existingCategory.Update(req.Name, req.Description)
+	options, err := h.parseDeleteOptions(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
 
-	if err := h.categoryRepo.Update(c.Request.Context(), existingCategory); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category: " + err.Error()})
+	if err := h.categoryUseCase.DeleteCategory(c.Request.Context(), id, version, options); err != nil {
+		h.writeError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromCategory(existingCategory))
+	c.Status(http.StatusNoContent)
 }
 
-func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+// parseDeleteOptions reads ?policy=reject|reassign|cascade and, for
+// reassign, ?target_id=.
+func (h *CategoryHandler) parseDeleteOptions(c *gin.Context) (domain.CategoryDeleteOptions, error) {
+	options := domain.CategoryDeleteOptions{Policy: h.deletePolicy}
+	if raw := c.Query("policy"); raw != "" {
+		policy, err := domain.ParseCategoryDeletePolicy(raw)
+		if err != nil {
+			return options, err
+		}
+		options.Policy = policy
+	}
+	if raw := c.Query("target_id"); raw != "" {
+		targetID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return options, domain.ErrReassignTargetNotFound
+		}
+		options.TargetID = targetID
+	}
+	return options, options.Validate()
+}
+
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	h.writeTree(c, 0)
+}
+
+func (h *CategoryHandler) GetCategorySubtree(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
 		return
 	}
 
-	existingCategory, err := h.categoryRepo.GetByID(c.Request.Context(), id)
+	h.writeTree(c, id)
+}
+
+func (h *CategoryHandler) writeTree(c *gin.Context, rootID uint64) {
+	tree, err := h.categoryUseCase.GetCategoryTree(c.Request.Context(), rootID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category: " + err.Error()})
+		h.writeError(c, err)
 		return
 	}
-	if existingCategory == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromCategoryTree(tree)})
+}
+
+func (h *CategoryHandler) GetCategoryPath(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
 		return
 	}
 
-	if err := h.categoryRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category: " + err.Error()})
+	path, err := h.categoryUseCase.GetCategoryPath(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	response := make([]dto.CategoryResponse, len(path))
+	for i, category := range path {
+		response[i] = *dto.FromCategory(category)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func (h *CategoryHandler) MoveCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "Invalid category ID format")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req dto.MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	category, err := h.categoryUseCase.MoveCategory(c.Request.Context(), id, version, req.ParentID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, category.Version())
+	c.JSON(http.StatusOK, dto.FromCategory(category))
+}
+
+// writeError answers with a problem for err, in the category wording for
+// the common cases.
+func (h *CategoryHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		writeErrorDetail(c, err, "Category not found")
+	case errors.Is(err, domain.ErrVersionConflict):
+		writeErrorDetail(c, err, "Category was modified by another request")
+	case errors.Is(err, domain.ErrParentCategoryNotFound):
+		writeErrorDetail(c, err, "Parent category not found")
+	case errors.Is(err, domain.ErrCategoryCycle):
+		writeErrorDetail(c, err, "Category cannot be moved under itself or its descendants")
+	case errors.Is(err, domain.ErrDuplicateExternalID):
+		writeErrorDetail(c, err, "Category external ID already exists")
+	case errors.Is(err, domain.ErrDuplicateSlug):
+		writeErrorDetail(c, err, "Category slug already exists")
+	case errors.Is(err, domain.ErrCategoryHasProducts):
+		writeErrorDetail(c, err, "Category still contains products; delete with policy=reassign or policy=cascade")
+	case errors.Is(err, domain.ErrReassignTargetNotFound):
+		writeErrorDetail(c, err, "Target category not found")
+	default:
+		writeDomainError(c, err)
+	}
 }
 
 func (h *CategoryHandler) RegisterRoutes(router *gin.Engine) {
 	categories := router.Group("/api/categories")
 	{
 		categories.POST("", h.CreateCategory)
+		categories.GET("/tree", h.GetCategoryTree)
+		categories.GET("/by-slug/:slug", h.GetCategoryBySlug)
 		categories.GET("/:id", h.GetCategory)
+		categories.GET("/:id/tree", h.GetCategorySubtree)
+		categories.GET("/:id/path", h.GetCategoryPath)
+		categories.POST("/:id/move", h.MoveCategory)
 		categories.GET("", h.ListCategories)
 		categories.PUT("/:id", h.UpdateCategory)
 		categories.DELETE("/:id", h.DeleteCategory)
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseDeleteOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewCategoryHandler(nil, domain.DeleteCascade)
+	parse := func(query string) (domain.CategoryDeleteOptions, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("DELETE", "/api/v1/categories/1?"+query, nil)
+		return handler.parseDeleteOptions(c)
+	}
+
+	options, err := parse("")
+	if err != nil || options.Policy != domain.DeleteCascade {
+		t.Fatalf("got %+v, %v, want the configured default", options, err)
+	}
+	options, err = parse("policy=reassign&target_id=7")
+	if err != nil || options.Policy != domain.DeleteReassign || options.TargetID != 7 {
+		t.Fatalf("got %+v, %v", options, err)
+	}
+
+	for query, want := range map[string]error{
+		"policy=purge":                  domain.ErrInvalidDeletePolicy,
+		"policy=reassign":               domain.ErrReassignTargetRequired,
+		"policy=reassign&target_id=abc": domain.ErrReassignTargetNotFound,
+	} {
+		if _, err := parse(query); !errors.Is(err, want) {
+			t.Errorf("%s: got error %v, want %v", query, err, want)
+		}
+	}
+}
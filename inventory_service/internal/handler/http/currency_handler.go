@@ -0,0 +1,169 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type CurrencyHandler struct {
+	currencyUseCase *usecase.CurrencyUseCase
+}
+
+func NewCurrencyHandler(uc *usecase.CurrencyUseCase) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyUseCase: uc,
+	}
+}
+
+func (h *CurrencyHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/exchange-rates", h.ListRates)
+		v1.PUT("/exchange-rates/:base/:quote", h.SetRate)
+		v1.DELETE("/exchange-rates/:base/:quote", h.DeleteRate)
+		v1.GET("/products/:id/list-prices", h.ListPrices)
+		v1.PUT("/products/:id/list-prices/:currency", h.SetListPrice)
+		v1.DELETE("/products/:id/list-prices/:currency", h.DeleteListPrice)
+	}
+}
+
+func (h *CurrencyHandler) ListRates(c *gin.Context) {
+	rates, err := h.currencyUseCase.ListRates(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":                 dto.FromExchangeRates(rates),
+		"supported_currencies": h.currencyUseCase.SupportedCurrencies(),
+	})
+}
+
+func (h *CurrencyHandler) SetRate(c *gin.Context) {
+	base, quote, ok := h.parsePair(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ExchangeRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	rate, err := h.currencyUseCase.SetRate(c.Request.Context(), base, quote, req.Rate)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromExchangeRate(rate))
+}
+
+func (h *CurrencyHandler) DeleteRate(c *gin.Context) {
+	base, quote, ok := h.parsePair(c)
+	if !ok {
+		return
+	}
+
+	if err := h.currencyUseCase.DeleteRate(c.Request.Context(), base, quote); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CurrencyHandler) ListPrices(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	prices, err := h.currencyUseCase.ListPrices(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromListPrices(prices)})
+}
+
+func (h *CurrencyHandler) SetListPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	currency, err := h.currencyUseCase.ParseCurrency(c.Param("currency"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	var req dto.ListPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+	price, err := req.ToPrice(currency)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	listPrice, err := h.currencyUseCase.SetListPrice(c.Request.Context(), id, price)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromListPrice(listPrice))
+}
+
+func (h *CurrencyHandler) DeleteListPrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	currency, err := money.ParseCurrency(c.Param("currency"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	if err := h.currencyUseCase.DeleteListPrice(c.Request.Context(), id, currency); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// parsePair reads the :base and :quote path parameters, writing the error
+// response itself when either is not a supported currency.
+func (h *CurrencyHandler) parsePair(c *gin.Context) (money.Currency, money.Currency, bool) {
+	base, err := h.currencyUseCase.ParseCurrency(c.Param("base"))
+	if err != nil {
+		h.writeError(c, err)
+		return "", "", false
+	}
+	quote, err := h.currencyUseCase.ParseCurrency(c.Param("quote"))
+	if err != nil {
+		h.writeError(c, err)
+		return "", "", false
+	}
+	return base, quote, true
+}
+
+func (h *CurrencyHandler) writeError(c *gin.Context, err error) {
+	writeDomainError(c, err)
+}
@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+type AttributeRequest struct {
+	// Code is fixed once created and ignored on update.
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	Type          string   `json:"type" binding:"required"`
+	Unit          string   `json:"unit"`
+	Required      bool     `json:"required"`
+	AllowedValues []string `json:"allowed_values"`
+}
+
+type AttributeResponse struct {
+	ID            uint64    `json:"id"`
+	CategoryID    uint64    `json:"category_id"`
+	Code          string    `json:"code"`
+	Name          string    `json:"name"`
+	Type          string    `json:"type"`
+	Unit          string    `json:"unit,omitempty"`
+	Required      bool      `json:"required"`
+	AllowedValues []string  `json:"allowed_values,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (r *AttributeRequest) ToAttributeDefinition(categoryID uint64) (*domain.AttributeDefinition, error) {
+	return domain.NewAttributeDefinition(categoryID, r.Code, r.Name, domain.AttributeType(r.Type), r.Unit, r.Required, r.AllowedValues)
+}
+
+func FromAttributeDefinition(d *domain.AttributeDefinition) *AttributeResponse {
+	return &AttributeResponse{
+		ID:            d.ID,
+		CategoryID:    d.CategoryID,
+		Code:          d.Code,
+		Name:          d.Name,
+		Type:          string(d.Type),
+		Unit:          d.Unit,
+		Required:      d.Required,
+		AllowedValues: d.AllowedValues,
+		CreatedAt:     d.CreatedAt,
+	}
+}
@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+// BundleRequest defines the components of a bundle. DiscountPercent takes a
+// number or decimal string such as 10 or "12.5" and is required for discount
+// pricing only.
+type BundleRequest struct {
+	Pricing         string                   `json:"pricing" binding:"required"`
+	DiscountPercent json.Number              `json:"discount_percent"`
+	Components      []BundleComponentRequest `json:"components" binding:"required,dive"`
+}
+
+type BundleComponentRequest struct {
+	ProductID uint64 `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,gt=0"`
+}
+
+func (r *BundleRequest) ToComponents() []domain.BundleComponent {
+	components := make([]domain.BundleComponent, len(r.Components))
+	for i, c := range r.Components {
+		components[i] = domain.BundleComponent{ProductID: c.ProductID, Quantity: c.Quantity}
+	}
+	return components
+}
+
+type BundleComponentResponse struct {
+	ProductID uint64      `json:"product_id"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	Price     money.Money `json:"price"`
+	Stock     int         `json:"stock"`
+}
+
+type BundleResponse struct {
+	ProductID       uint64                    `json:"product_id"`
+	Pricing         string                    `json:"pricing"`
+	DiscountPercent string                    `json:"discount_percent,omitempty"`
+	Components      []BundleComponentResponse `json:"components"`
+	// ComponentsTotal is what the components cost bought separately. It is
+	// omitted when they are priced in different currencies.
+	ComponentsTotal *money.Money `json:"components_total,omitempty"`
+	Available       int          `json:"available"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+func FromBundle(b *domain.Bundle) *BundleResponse {
+	response := &BundleResponse{
+		ProductID:       b.ProductID,
+		Pricing:         string(b.Pricing),
+		DiscountPercent: b.DiscountString(),
+		Components:      make([]BundleComponentResponse, len(b.Components)),
+		Available:       b.Available(),
+		UpdatedAt:       b.UpdatedAt,
+	}
+	for i, c := range b.Components {
+		response.Components[i] = BundleComponentResponse{
+			ProductID: c.ProductID,
+			Name:      c.Name,
+			Quantity:  c.Quantity,
+			Price:     c.Price,
+			Stock:     c.Stock,
+		}
+	}
+	if total, err := b.ComponentsTotal(); err == nil {
+		response.ComponentsTotal = &total
+	}
+	return response
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+
+type CacheStatsResponse struct {
+	Name      string  `json:"name"`
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Coalesced uint64  `json:"coalesced"`
+	Evictions uint64  `json:"evictions"`
+	HitRatio  float64 `json:"hit_ratio"`
+	Size      int     `json:"size"`
+	Capacity  int     `json:"capacity"`
+}
+
+func FromCacheStats(s domain.CacheStats) *CacheStatsResponse {
+	return &CacheStatsResponse{
+		Name:      s.Name,
+		Hits:      s.Hits,
+		Misses:    s.Misses,
+		Coalesced: s.Coalesced,
+		Evictions: s.Evictions,
+		HitRatio:  s.HitRatio(),
+		Size:      s.Size,
+		Capacity:  s.Capacity,
+	}
+}
@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+type ImportRowErrorResponse struct {
+	Row     int    `json:"row"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+type ImportJobResponse struct {
+	ID              string                   `json:"id"`
+	Entity          string                   `json:"entity"`
+	Format          string                   `json:"format"`
+	DryRun          bool                     `json:"dry_run"`
+	Status          string                   `json:"status"`
+	Processed       int                      `json:"processed"`
+	Created         int                      `json:"created"`
+	Updated         int                      `json:"updated"`
+	Failed          int                      `json:"failed"`
+	Errors          []ImportRowErrorResponse `json:"errors"`
+	ErrorsTruncated bool                     `json:"errors_truncated,omitempty"`
+	Error           string                   `json:"error,omitempty"`
+	CreatedAt       time.Time                `json:"created_at"`
+	StartedAt       *time.Time               `json:"started_at,omitempty"`
+	FinishedAt      *time.Time               `json:"finished_at,omitempty"`
+}
+
+func FromImportJob(j *domain.ImportJob) *ImportJobResponse {
+	errors := make([]ImportRowErrorResponse, len(j.Errors))
+	for i, e := range j.Errors {
+		errors[i] = ImportRowErrorResponse{Row: e.Row, Key: e.Key, Message: e.Message}
+	}
+	return &ImportJobResponse{
+		ID:              j.ID,
+		Entity:          string(j.Entity),
+		Format:          string(j.Format),
+		DryRun:          j.DryRun,
+		Status:          string(j.Status),
+		Processed:       j.Processed,
+		Created:         j.Created,
+		Updated:         j.Updated,
+		Failed:          j.Failed,
+		Errors:          errors,
+		ErrorsTruncated: j.ErrorsTruncated,
+		Error:           j.Error,
+		CreatedAt:       j.CreatedAt,
+		StartedAt:       j.StartedAt,
+		FinishedAt:      j.FinishedAt,
+	}
+}
@@ -8,27 +8,68 @@ import (
 type CategoryRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
+	// ParentID is only honoured on create; use the move endpoint to
+	// re-parent an existing category.
+	ParentID uint64 `json:"parent_id"`
+	// ExternalID is left unchanged on update when omitted.
+	ExternalID *string `json:"external_id"`
+	// Slug behaves as on products: derived from the name when omitted on
+	// create, derived anew when empty on update.
+	Slug *string `json:"slug"`
+}
+
+type MoveCategoryRequest struct {
+	ParentID uint64 `json:"parent_id"`
 }
 
 type CategoryResponse struct {
-	ID          uint64    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint64  `json:"id"`
+	ExternalID  string  `json:"external_id,omitempty"`
+	Slug        string  `json:"slug"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	ParentID    *uint64 `json:"parent_id"`
+	// ProductCount counts live products assigned directly to the category.
+	ProductCount int       `json:"product_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	Version      int       `json:"version"`
+}
+
+func (r *CategoryRequest) ToCategory() (*domain.Category, error) {
+	category := domain.NewCategory(r.Name, r.Description)
+	category.SetParentID(r.ParentID)
+	if err := category.ApplyIdentifiers(r.ExternalID, r.Slug); err != nil {
+		return nil, err
+	}
+	return category, nil
 }
 
-func (r *CategoryRequest) ToCategory() *domain.Category {
-	return domain.NewCategory(r.Name, r.Description)
+func (r *CategoryRequest) ToChanges() domain.CategoryChanges {
+	return domain.CategoryChanges{
+		Name:        r.Name,
+		Description: r.Description,
+		ExternalID:  r.ExternalID,
+		Slug:        r.Slug,
+	}
 }
 
 func FromCategory(c *domain.Category) *CategoryResponse {
+	var parentID *uint64
+	if c.ParentID() != 0 {
+		id := c.ParentID()
+		parentID = &id
+	}
 	return &CategoryResponse{
-		ID:          c.ID(),
-		Name:        c.Name(),
-		Description: c.Description(),
-		CreatedAt:   c.CreatedAt(),
-		UpdatedAt:   c.UpdatedAt(),
+		ID:           c.ID(),
+		ExternalID:   c.ExternalID(),
+		Name:         c.Name(),
+		Description:  c.Description(),
+		ParentID:     parentID,
+		ProductCount: c.ProductCount(),
+		CreatedAt:    c.CreatedAt(),
+		UpdatedAt:    c.UpdatedAt(),
+		Version:      c.Version(),
 	}
 }
 
@@ -39,3 +80,19 @@ type CategoryListResponse struct {
 		Limit int `json:"limit"`
 	} `json:"meta"`
 }
+
+type CategoryTreeNode struct {
+	CategoryResponse
+	Children []CategoryTreeNode `json:"children"`
+}
+
+func FromCategoryTree(nodes []*domain.CategoryNode) []CategoryTreeNode {
+	result := make([]CategoryTreeNode, len(nodes))
+	for i, node := range nodes {
+		result[i] = CategoryTreeNode{
+			CategoryResponse: *FromCategory(node.Category),
+			Children:         FromCategoryTree(node.Children),
+		}
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strings"
+	"time"
+)
+
+// ExchangeRateRequest sets how many units of the quote currency one unit of
+// the base currency buys, as a decimal string such as "0.92".
+type ExchangeRateRequest struct {
+	Rate string `json:"rate" binding:"required"`
+}
+
+type ExchangeRateResponse struct {
+	Base      money.Currency `json:"base"`
+	Quote     money.Currency `json:"quote"`
+	Rate      string         `json:"rate"`
+	Actor     string         `json:"actor"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+func FromExchangeRate(r *domain.ExchangeRate) *ExchangeRateResponse {
+	rate := r.Rate.FloatString(12)
+	rate = strings.TrimRight(strings.TrimRight(rate, "0"), ".")
+	return &ExchangeRateResponse{
+		Base:      r.Base,
+		Quote:     r.Quote,
+		Rate:      rate,
+		Actor:     r.Actor,
+		UpdatedAt: r.UpdatedAt,
+	}
+}
+
+func FromExchangeRates(rates domain.ExchangeRates) []ExchangeRateResponse {
+	result := make([]ExchangeRateResponse, len(rates))
+	for i, r := range rates {
+		result[i] = *FromExchangeRate(r)
+	}
+	return result
+}
+
+// ListPriceRequest takes the amount, as a decimal string or number, in the
+// currency named by the path.
+type ListPriceRequest struct {
+	Amount json.Number `json:"amount" binding:"required"`
+}
+
+func (r *ListPriceRequest) ToPrice(currency money.Currency) (money.Money, error) {
+	return money.Parse(r.Amount.String(), currency)
+}
+
+type ListPriceResponse struct {
+	ProductID uint64      `json:"product_id"`
+	Price     money.Money `json:"price"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+func FromListPrice(p *domain.ListPrice) *ListPriceResponse {
+	return &ListPriceResponse{
+		ProductID: p.ProductID,
+		Price:     p.Price,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+func FromListPrices(prices []*domain.ListPrice) []ListPriceResponse {
+	result := make([]ListPriceResponse, len(prices))
+	for i, p := range prices {
+		result[i] = *FromListPrice(p)
+	}
+	return result
+}
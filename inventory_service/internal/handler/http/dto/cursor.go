@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+// cursorToken is the opaque pagination token handed to clients. It records
+// the ordering it was issued for so it cannot be replayed against another.
+type cursorToken struct {
+	Sort     domain.ProductSortField `json:"s"`
+	Desc     bool                    `json:"d,omitempty"`
+	Value    string                  `json:"v"`
+	ID       uint64                  `json:"i"`
+	Backward bool                    `json:"b,omitempty"`
+}
+
+func EncodeProductCursor(cursor *domain.ProductCursor, filter domain.ProductFilter) string {
+	if cursor == nil {
+		return ""
+	}
+	data, _ := json.Marshal(cursorToken{
+		Sort:     filter.SortBy,
+		Desc:     filter.SortDesc,
+		Value:    cursor.Value,
+		ID:       cursor.ID,
+		Backward: cursor.Backward,
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeProductCursor(token string, filter domain.ProductFilter) (*domain.ProductCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var t cursorToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	if t.Sort != filter.SortBy || t.Desc != filter.SortDesc {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &domain.ProductCursor{Value: t.Value, ID: t.ID, Backward: t.Backward}, nil
+}
@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"testing"
+)
+
+func TestProductCursorRoundTrip(t *testing.T) {
+	filter := domain.ProductFilter{SortBy: domain.ProductSortPrice, SortDesc: true}
+	cursor := &domain.ProductCursor{Value: "12.50", ID: 42, Backward: true}
+
+	token := EncodeProductCursor(cursor, filter)
+	got, err := DecodeProductCursor(token, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *cursor {
+		t.Fatalf("got %+v, want %+v", got, cursor)
+	}
+
+	if EncodeProductCursor(nil, filter) != "" {
+		t.Fatal("a missing cursor should encode as an empty token")
+	}
+}
+
+func TestProductCursorRejectsOtherOrderings(t *testing.T) {
+	filter := domain.ProductFilter{SortBy: domain.ProductSortPrice, SortDesc: true}
+	token := EncodeProductCursor(&domain.ProductCursor{Value: "12.50", ID: 42}, filter)
+
+	others := []domain.ProductFilter{
+		{SortBy: domain.ProductSortPrice},
+		{SortBy: domain.ProductSortName, SortDesc: true},
+	}
+	for _, other := range others {
+		if _, err := DecodeProductCursor(token, other); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("sort %s desc=%v: got error %v", other.SortBy, other.SortDesc, err)
+		}
+	}
+	for _, garbage := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := DecodeProductCursor(garbage, filter); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("token %q: got error %v", garbage, err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+// SchedulePriceRequest plans a price change. Without ends_at the change is
+// permanent; with it the current price comes back when the window closes.
+type SchedulePriceRequest struct {
+	Price    money.Money `json:"price" binding:"required"`
+	StartsAt time.Time   `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time  `json:"ends_at"`
+}
+
+type PriceChangeResponse struct {
+	Price      money.Money `json:"price"`
+	Source     string      `json:"source"`
+	ScheduleID uint64      `json:"schedule_id,omitempty"`
+	Actor      string      `json:"actor"`
+	ChangedAt  time.Time   `json:"changed_at"`
+}
+
+func FromPriceChange(c *domain.PriceChange) *PriceChangeResponse {
+	return &PriceChangeResponse{
+		Price:      c.Price,
+		Source:     string(c.Source),
+		ScheduleID: c.ScheduleID,
+		Actor:      c.Actor,
+		ChangedAt:  c.ChangedAt,
+	}
+}
+
+type PriceScheduleResponse struct {
+	ID            uint64       `json:"id"`
+	ProductID     uint64       `json:"product_id"`
+	Price         money.Money  `json:"price"`
+	StartsAt      time.Time    `json:"starts_at"`
+	EndsAt        *time.Time   `json:"ends_at,omitempty"`
+	Status        string       `json:"status"`
+	PreviousPrice *money.Money `json:"previous_price,omitempty"`
+	Actor         string       `json:"actor"`
+	CreatedAt     time.Time    `json:"created_at"`
+}
+
+func FromPriceSchedule(s *domain.PriceSchedule) *PriceScheduleResponse {
+	return &PriceScheduleResponse{
+		ID:            s.ID,
+		ProductID:     s.ProductID,
+		Price:         s.Price,
+		StartsAt:      s.StartsAt,
+		EndsAt:        s.EndsAt,
+		Status:        string(s.Status),
+		PreviousPrice: s.PreviousPrice,
+		Actor:         s.Actor,
+		CreatedAt:     s.CreatedAt,
+	}
+}
+
+type PriceTimelineResponse struct {
+	ProductID    uint64                  `json:"product_id"`
+	CurrentPrice money.Money             `json:"current_price"`
+	History      []PriceChangeResponse   `json:"history"`
+	Schedules    []PriceScheduleResponse `json:"schedules"`
+}
+
+func FromPriceTimeline(t *domain.PriceTimeline) *PriceTimelineResponse {
+	response := &PriceTimelineResponse{
+		ProductID:    t.ProductID,
+		CurrentPrice: t.CurrentPrice,
+		History:      make([]PriceChangeResponse, len(t.History)),
+		Schedules:    make([]PriceScheduleResponse, len(t.Schedules)),
+	}
+	for i, c := range t.History {
+		response.History[i] = *FromPriceChange(c)
+	}
+	for i, s := range t.Schedules {
+		response.Schedules[i] = *FromPriceSchedule(s)
+	}
+	return response
+}
+
+type EffectivePriceResponse struct {
+	ProductID uint64      `json:"product_id"`
+	Price     money.Money `json:"price"`
+	At        time.Time   `json:"at"`
+}
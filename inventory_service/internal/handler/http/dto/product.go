@@ -2,49 +2,123 @@ package dto
 
 import (
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
 	"time"
 )
 
 type ProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gte=0"`
-	Stock       int     `json:"stock" binding:"required,gte=0"`
-	CategoryID  uint64  `json:"category_id" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	// Price takes a decimal string or number in the default currency, or an
+	// object with amount or amount_minor and currency.
+	Price      money.Money `json:"price" binding:"required"`
+	Stock      int         `json:"stock" binding:"required,gte=0"`
+	CategoryID uint64      `json:"category_id" binding:"required"`
+	// SKU and ExternalID are left unchanged on update when omitted.
+	SKU        *string `json:"sku"`
+	ExternalID *string `json:"external_id"`
+	// Slug is derived from the name on create when omitted, and left
+	// unchanged on update. An empty slug on update derives it anew.
+	Slug *string `json:"slug"`
+	// Attributes are validated against the category's schema. When omitted
+	// on update the current values are kept.
+	Attributes map[string]interface{} `json:"attributes"`
 }
 
 type ProductResponse struct {
-	ID          uint64    `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	CategoryID  uint64    `json:"category_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint64      `json:"id"`
+	SKU         string      `json:"sku,omitempty"`
+	ExternalID  string      `json:"external_id,omitempty"`
+	Slug        string      `json:"slug"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	// BasePrice and PriceSource are set when the price was requested in
+	// another currency; BasePrice is then the product's own price.
+	BasePrice   *money.Money           `json:"base_price,omitempty"`
+	PriceSource string                 `json:"price_source,omitempty"`
+	Stock       int                    `json:"stock"`
+	CategoryID  uint64                 `json:"category_id"`
+	Attributes  map[string]interface{} `json:"attributes"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Version     int                    `json:"version"`
+
+	Variants []VariantResponse      `json:"variants"`
+	Images   []ProductImageResponse `json:"images"`
 }
 
 func (r *ProductRequest) ToProduct() (*domain.Product, error) {
-	return domain.NewProduct(r.Name, r.Description, r.Price, r.Stock, r.CategoryID)
+	product, err := domain.NewProduct(r.Name, r.Description, r.Price, r.Stock, r.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := product.ApplyIdentifiers(r.SKU, r.ExternalID, r.Slug); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+func (r *ProductRequest) ToChanges() domain.ProductChanges {
+	return domain.ProductChanges{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price,
+		Stock:       r.Stock,
+		CategoryID:  r.CategoryID,
+		SKU:         r.SKU,
+		ExternalID:  r.ExternalID,
+		Slug:        r.Slug,
+		Attributes:  r.Attributes,
+	}
 }
 
 func FromProduct(p *domain.Product) *ProductResponse {
 	return &ProductResponse{
 		ID:          p.ID(),
+		SKU:         p.SKU(),
+		ExternalID:  p.ExternalID(),
+		Slug:        p.Slug(),
 		Name:        p.Name(),
 		Description: p.Description(),
 		Price:       p.Price(),
 		Stock:       p.Stock(),
 		CategoryID:  p.CategoryID(),
+		Attributes:  p.Attributes(),
 		CreatedAt:   p.CreatedAt(),
 		UpdatedAt:   p.UpdatedAt(),
+		Version:     p.Version(),
+		Variants:    []VariantResponse{},
+		Images:      []ProductImageResponse{},
 	}
 }
 
 type ProductListResponse struct {
 	Data []ProductResponse `json:"data"`
 	Meta struct {
-		Page  int `json:"page"`
-		Limit int `json:"limit"`
+		Page       int    `json:"page,omitempty"`
+		Limit      int    `json:"limit"`
+		Total      int    `json:"total"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
 	} `json:"meta"`
+	Facets *ProductFacetsResponse `json:"facets,omitempty"`
+}
+
+// ProductBatchRequest names products by ID, by SKU or both.
+type ProductBatchRequest struct {
+	IDs  []uint64 `json:"ids"`
+	SKUs []string `json:"skus"`
+}
+
+func (r *ProductBatchRequest) ToBatch() domain.ProductBatch {
+	return domain.ProductBatch{IDs: r.IDs, SKUs: r.SKUs}
+}
+
+// ProductBatchResponse lists the products found and the IDs and SKUs that
+// matched no live product.
+type ProductBatchResponse struct {
+	Data        []ProductResponse `json:"data"`
+	MissingIDs  []uint64          `json:"missing_ids"`
+	MissingSKUs []string          `json:"missing_skus"`
 }
@@ -0,0 +1,64 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+)
+
+// ProductFacetsResponse only carries the facets that were requested.
+type ProductFacetsResponse struct {
+	Categories []CategoryFacetResponse `json:"categories,omitempty"`
+	Prices     []PriceFacetResponse    `json:"prices,omitempty"`
+	// PriceCurrency is the only currency the price buckets count.
+	PriceCurrency string                              `json:"price_currency,omitempty"`
+	Stock         *StockFacetResponse                 `json:"stock,omitempty"`
+	Attributes    map[string][]AttributeFacetResponse `json:"attributes,omitempty"`
+}
+
+type CategoryFacetResponse struct {
+	CategoryID uint64 `json:"category_id"`
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+}
+
+// PriceFacetResponse covers prices from Min up to, but not including, Max.
+type PriceFacetResponse struct {
+	Min   money.Money  `json:"min"`
+	Max   *money.Money `json:"max,omitempty"`
+	Count int          `json:"count"`
+}
+
+type StockFacetResponse struct {
+	InStock    int `json:"in_stock"`
+	OutOfStock int `json:"out_of_stock"`
+}
+
+type AttributeFacetResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+func ToProductFacetsResponse(facets *domain.ProductFacets) *ProductFacetsResponse {
+	response := &ProductFacetsResponse{}
+	for _, f := range facets.Categories {
+		response.Categories = append(response.Categories, CategoryFacetResponse{CategoryID: f.CategoryID, Name: f.Name, Count: f.Count})
+	}
+	for _, f := range facets.Prices {
+		response.Prices = append(response.Prices, PriceFacetResponse{Min: f.Min, Max: f.Max, Count: f.Count})
+	}
+	if len(facets.Prices) > 0 {
+		response.PriceCurrency = string(facets.PriceCurrency)
+	}
+	if facets.Stock != nil {
+		response.Stock = &StockFacetResponse{InStock: facets.Stock.InStock, OutOfStock: facets.Stock.OutOfStock}
+	}
+	if len(facets.Attributes) > 0 {
+		response.Attributes = make(map[string][]AttributeFacetResponse, len(facets.Attributes))
+		for code, values := range facets.Attributes {
+			for _, v := range values {
+				response.Attributes[code] = append(response.Attributes[code], AttributeFacetResponse{Value: v.Value, Count: v.Count})
+			}
+		}
+	}
+	return response
+}
@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+type ReorderImagesRequest struct {
+	ImageIDs []uint64 `json:"image_ids" binding:"required"`
+}
+
+type ProductImageResponse struct {
+	ID          uint64            `json:"id"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+	ContentType string            `json:"content_type"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	SizeBytes   int64             `json:"size_bytes"`
+	Position    int               `json:"position"`
+	IsPrimary   bool              `json:"is_primary"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// FromProductImage converts an image, resolving storage keys to URLs with
+// url.
+func FromProductImage(i *domain.ProductImage, url func(key string) string) *ProductImageResponse {
+	thumbnails := make(map[string]string, len(i.ThumbnailKeys))
+	for name, key := range i.ThumbnailKeys {
+		thumbnails[name] = url(key)
+	}
+	return &ProductImageResponse{
+		ID:          i.ID,
+		URL:         url(i.StorageKey),
+		Thumbnails:  thumbnails,
+		ContentType: i.ContentType,
+		Width:       i.Width,
+		Height:      i.Height,
+		SizeBytes:   i.SizeBytes,
+		Position:    i.Position,
+		IsPrimary:   i.IsPrimary,
+		CreatedAt:   i.CreatedAt,
+	}
+}
+
+func FromProductImages(images []*domain.ProductImage, url func(key string) string) []ProductImageResponse {
+	responses := make([]ProductImageResponse, len(images))
+	for i, image := range images {
+		responses[i] = *FromProductImage(image, url)
+	}
+	return responses
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+
+type ProductSearchHit struct {
+	Product    ProductResponse `json:"product"`
+	Rank       float64         `json:"rank"`
+	Highlights struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"highlights"`
+}
+
+type ProductSearchResponse struct {
+	Data []ProductSearchHit `json:"data"`
+	Meta struct {
+		Query    string `json:"query"`
+		Language string `json:"language"`
+		Page     int    `json:"page"`
+		Limit    int    `json:"limit"`
+	} `json:"meta"`
+}
+
+func FromProductSearchResult(r *domain.ProductSearchResult) ProductSearchHit {
+	hit := ProductSearchHit{
+		Product: *FromProduct(r.Product),
+		Rank:    r.Rank,
+	}
+	hit.Highlights.Name = r.NameHighlight
+	hit.Highlights.Description = r.DescriptionHighlight
+	return hit
+}
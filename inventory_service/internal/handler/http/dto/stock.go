@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+// StockRequest adjusts stock by a signed quantity, on the product itself or,
+// when VariantID is set, on one of its variants.
+type StockRequest struct {
+	Quantity    int    `json:"quantity" binding:"required"`
+	VariantID   uint64 `json:"variant_id"`
+	Reason      string `json:"reason"`
+	ReferenceID string `json:"reference_id"`
+}
+
+type StockResponse struct {
+	ProductID uint64 `json:"product_id"`
+	VariantID uint64 `json:"variant_id,omitempty"`
+	Stock     int    `json:"stock"`
+}
+
+type StockMovementResponse struct {
+	ID          uint64    `json:"id"`
+	ProductID   uint64    `json:"product_id"`
+	VariantID   uint64    `json:"variant_id,omitempty"`
+	WarehouseID uint64    `json:"warehouse_id,omitempty"`
+	Delta       int       `json:"delta"`
+	Reason      string    `json:"reason"`
+	ReferenceID string    `json:"reference_id,omitempty"`
+	Actor       string    `json:"actor"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func FromStockMovement(m *domain.StockMovement) *StockMovementResponse {
+	return &StockMovementResponse{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		WarehouseID: m.WarehouseID,
+		Delta:       m.Delta,
+		Reason:      string(m.Reason),
+		ReferenceID: m.ReferenceID,
+		Actor:       m.Actor,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+type StockDiscrepancyResponse struct {
+	ProductID     uint64 `json:"product_id"`
+	VariantID     uint64 `json:"variant_id,omitempty"`
+	WarehouseID   uint64 `json:"warehouse_id,omitempty"`
+	RecordedStock int    `json:"recorded_stock"`
+	LedgerStock   int    `json:"ledger_stock"`
+	Difference    int    `json:"difference"`
+}
+
+func FromStockDiscrepancy(d *domain.StockDiscrepancy) *StockDiscrepancyResponse {
+	return &StockDiscrepancyResponse{
+		ProductID:     d.ProductID,
+		VariantID:     d.VariantID,
+		WarehouseID:   d.WarehouseID,
+		RecordedStock: d.RecordedStock,
+		LedgerStock:   d.LedgerStock,
+		Difference:    d.RecordedStock - d.LedgerStock,
+	}
+}
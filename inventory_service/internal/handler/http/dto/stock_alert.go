@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+
+// ReorderPointRequest sets a reorder point; a null value clears it so that the
+// category or service default applies instead.
+type ReorderPointRequest struct {
+	ReorderPoint *int `json:"reorder_point"`
+}
+
+type LowStockResponse struct {
+	ProductID    uint64 `json:"product_id"`
+	Name         string `json:"name"`
+	CategoryID   uint64 `json:"category_id,omitempty"`
+	Stock        int    `json:"stock"`
+	ReorderPoint int    `json:"reorder_point"`
+	Level        string `json:"level"`
+}
+
+func FromLowStockItem(item *domain.LowStockItem) *LowStockResponse {
+	return &LowStockResponse{
+		ProductID:    item.ProductID,
+		Name:         item.Name,
+		CategoryID:   item.CategoryID,
+		Stock:        item.Stock,
+		ReorderPoint: item.ReorderPoint,
+		Level:        string(item.Level),
+	}
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+)
+
+// TrashedProductResponse is a product in the trash. PurgeAt is the earliest
+// time the purge job may delete it for good.
+type TrashedProductResponse struct {
+	ProductResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashedCategoryResponse struct {
+	CategoryResponse
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+type TrashListMeta struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+type TrashedProductListResponse struct {
+	Data []TrashedProductResponse `json:"data"`
+	Meta TrashListMeta            `json:"meta"`
+}
+
+type TrashedCategoryListResponse struct {
+	Data []TrashedCategoryResponse `json:"data"`
+	Meta TrashListMeta             `json:"meta"`
+}
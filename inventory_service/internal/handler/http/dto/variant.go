@@ -0,0 +1,52 @@
+package dto
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+type VariantRequest struct {
+	SKU     string            `json:"sku" binding:"required"`
+	Options map[string]string `json:"options"`
+	Price   *money.Money      `json:"price"`
+	Stock   int               `json:"stock" binding:"gte=0"`
+}
+
+type VariantResponse struct {
+	ID        uint64            `json:"id"`
+	ProductID uint64            `json:"product_id"`
+	SKU       string            `json:"sku"`
+	Options   map[string]string `json:"options"`
+	Price     *money.Money      `json:"price"`
+	Stock     int               `json:"stock"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+	Version   int               `json:"version"`
+}
+
+func (r *VariantRequest) ToVariant(productID uint64) (*domain.Variant, error) {
+	return domain.NewVariant(productID, r.SKU, r.Options, r.Price, r.Stock)
+}
+
+func FromVariant(v *domain.Variant) *VariantResponse {
+	return &VariantResponse{
+		ID:        v.ID(),
+		ProductID: v.ProductID(),
+		SKU:       v.SKU(),
+		Options:   v.Options(),
+		Price:     v.Price(),
+		Stock:     v.Stock(),
+		CreatedAt: v.CreatedAt(),
+		UpdatedAt: v.UpdatedAt(),
+		Version:   v.Version(),
+	}
+}
+
+func FromVariants(variants []*domain.Variant) []VariantResponse {
+	result := make([]VariantResponse, len(variants))
+	for i, v := range variants {
+		result[i] = *FromVariant(v)
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package dto
+
+import (
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+type Location struct {
+	Latitude  float64 `json:"latitude" binding:"gte=-90,lte=90"`
+	Longitude float64 `json:"longitude" binding:"gte=-180,lte=180"`
+}
+
+type WarehouseRequest struct {
+	Code     string    `json:"code" binding:"required"`
+	Name     string    `json:"name" binding:"required"`
+	Location *Location `json:"location"`
+	IsActive *bool     `json:"is_active"`
+}
+
+// WarehouseUpdateRequest is a PATCH body: only the fields it contains change.
+// A null location clears the warehouse's location.
+type WarehouseUpdateRequest struct {
+	Name     *string          `json:"name" binding:"omitempty,min=1"`
+	Location OptionalLocation `json:"location"`
+	IsActive *bool            `json:"is_active"`
+}
+
+// OptionalLocation tells a location left out of a request from one sent as
+// null. Its range is checked by the domain.
+type OptionalLocation struct {
+	Set   bool
+	Value *Location `binding:"-"`
+}
+
+func (l *OptionalLocation) UnmarshalJSON(data []byte) error {
+	l.Set = true
+	return json.Unmarshal(data, &l.Value)
+}
+
+type WarehouseResponse struct {
+	ID        uint64    `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Location  *Location `json:"location"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int       `json:"version"`
+}
+
+func (l *Location) ToGeoPoint() *domain.GeoPoint {
+	if l == nil {
+		return nil
+	}
+	return &domain.GeoPoint{Latitude: l.Latitude, Longitude: l.Longitude}
+}
+
+func (r *WarehouseRequest) ToWarehouse() (*domain.Warehouse, error) {
+	warehouse, err := domain.NewWarehouse(r.Code, r.Name, r.Location.ToGeoPoint())
+	if err != nil {
+		return nil, err
+	}
+	if err := warehouse.Apply(domain.WarehouseChanges{IsActive: r.IsActive}); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (r *WarehouseUpdateRequest) ToChanges() domain.WarehouseChanges {
+	return domain.WarehouseChanges{
+		Name:        r.Name,
+		LocationSet: r.Location.Set,
+		Location:    r.Location.Value.ToGeoPoint(),
+		IsActive:    r.IsActive,
+	}
+}
+
+func FromWarehouse(w *domain.Warehouse) *WarehouseResponse {
+	var location *Location
+	if w.Location() != nil {
+		location = &Location{Latitude: w.Location().Latitude, Longitude: w.Location().Longitude}
+	}
+	return &WarehouseResponse{
+		ID:        w.ID(),
+		Code:      w.Code(),
+		Name:      w.Name(),
+		Location:  location,
+		IsActive:  w.IsActive(),
+		CreatedAt: w.CreatedAt(),
+		UpdatedAt: w.UpdatedAt(),
+		Version:   w.Version(),
+	}
+}
+
+type WarehouseStockRequest struct {
+	ProductID uint64 `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required"`
+}
+
+type StockLevelResponse struct {
+	WarehouseID uint64    `json:"warehouse_id"`
+	Quantity    int       `json:"quantity"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type AvailabilityResponse struct {
+	ProductID       uint64               `json:"product_id"`
+	AvailableToSell int                  `json:"available_to_sell"`
+	Warehouses      []StockLevelResponse `json:"warehouses"`
+}
+
+func FromAvailability(a *domain.ProductAvailability) *AvailabilityResponse {
+	response := &AvailabilityResponse{
+		ProductID:       a.ProductID,
+		AvailableToSell: a.AvailableToSell,
+		Warehouses:      make([]StockLevelResponse, len(a.Levels)),
+	}
+	for i, level := range a.Levels {
+		response.Warehouses[i] = StockLevelResponse{
+			WarehouseID: level.WarehouseID,
+			Quantity:    level.Quantity,
+			UpdatedAt:   level.UpdatedAt,
+		}
+	}
+	return response
+}
+
+type StockTransferRequest struct {
+	ProductID       uint64 `json:"product_id" binding:"required"`
+	FromWarehouseID uint64 `json:"from_warehouse_id" binding:"required"`
+	ToWarehouseID   uint64 `json:"to_warehouse_id" binding:"required"`
+	Quantity        int    `json:"quantity" binding:"required,gt=0"`
+	Note            string `json:"note"`
+}
+
+type StockTransferResponse struct {
+	ID              uint64    `json:"id"`
+	ProductID       uint64    `json:"product_id"`
+	FromWarehouseID uint64    `json:"from_warehouse_id"`
+	ToWarehouseID   uint64    `json:"to_warehouse_id"`
+	Quantity        int       `json:"quantity"`
+	Note            string    `json:"note"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func FromStockTransfer(t *domain.StockTransfer) *StockTransferResponse {
+	return &StockTransferResponse{
+		ID:              t.ID,
+		ProductID:       t.ProductID,
+		FromWarehouseID: t.FromWarehouseID,
+		ToWarehouseID:   t.ToWarehouseID,
+		Quantity:        t.Quantity,
+		Note:            t.Note,
+		CreatedAt:       t.CreatedAt,
+	}
+}
+
+type AllocationLine struct {
+	WarehouseID uint64 `json:"warehouse_id"`
+	Quantity    int    `json:"quantity"`
+}
+
+type AllocationResponse struct {
+	ProductID   uint64           `json:"product_id"`
+	Strategy    string           `json:"strategy"`
+	Allocations []AllocationLine `json:"allocations"`
+}
+
+func FromAllocations(productID uint64, strategy string, allocations []domain.Allocation) *AllocationResponse {
+	response := &AllocationResponse{
+		ProductID:   productID,
+		Strategy:    strategy,
+		Allocations: make([]AllocationLine, len(allocations)),
+	}
+	for i, a := range allocations {
+		response.Allocations[i] = AllocationLine{WarehouseID: a.WarehouseID, Quantity: a.Quantity}
+	}
+	return response
+}
@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWarehouseUpdateRequestChanges(t *testing.T) {
+	cases := []struct {
+		body        string
+		name        bool
+		locationSet bool
+		location    bool
+		isActive    bool
+	}{
+		{`{}`, false, false, false, false},
+		{`{"name": "North"}`, true, false, false, false},
+		{`{"location": null}`, false, true, false, false},
+		{`{"location": {"latitude": 1, "longitude": 2}, "is_active": false}`, false, true, true, true},
+	}
+	for _, tc := range cases {
+		var req WarehouseUpdateRequest
+		if err := json.Unmarshal([]byte(tc.body), &req); err != nil {
+			t.Fatal(err)
+		}
+		changes := req.ToChanges()
+		if (changes.Name != nil) != tc.name || changes.LocationSet != tc.locationSet ||
+			(changes.Location != nil) != tc.location || (changes.IsActive != nil) != tc.isActive {
+			t.Errorf("%s: got %+v", tc.body, changes)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"net/http"
+)
+
+// errorStatus maps an error to the status for its domain kind. Handlers
+// special-case the few errors that need another status or message and fall
+// back to this; errors of no known kind are the server's fault.
+func errorStatus(err error) int {
+	switch domain.KindOf(err) {
+	case domain.KindInvalid:
+		return http.StatusBadRequest
+	case domain.KindNotFound:
+		return http.StatusNotFound
+	case domain.KindConflict:
+		return http.StatusConflict
+	case domain.KindStale:
+		return http.StatusPreconditionFailed
+	case domain.KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	}
+
+	// Amounts in a request are parsed by the shared money package, whose
+	// errors carry no kind.
+	if errors.Is(err, money.ErrInvalidCurrency) ||
+		errors.Is(err, money.ErrCurrencyMismatch) ||
+		errors.Is(err, money.ErrInvalidAmount) ||
+		errors.Is(err, money.ErrPrecision) ||
+		errors.Is(err, money.ErrOverflow) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
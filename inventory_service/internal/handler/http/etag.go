@@ -0,0 +1,42 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// requireIfMatch reads the expected entity version from the If-Match header.
+// It aborts with 428 when the header is missing or is "*", since a write must
+// name the version it was based on, and with 412 when it cannot refer to any
+// version of the entity. If-Match compares strongly, so weak tags never
+// match.
+func requireIfMatch(c *gin.Context) (int, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		writeDetail(c, http.StatusPreconditionRequired, "If-Match header is required")
+		return 0, false
+	}
+	if header == "*" {
+		writeDetail(c, http.StatusPreconditionRequired, "If-Match must name the version being changed, not *")
+		return 0, false
+	}
+
+	tag := header
+	if unquoted, err := strconv.Unquote(tag); err == nil {
+		tag = unquoted
+	}
+
+	version, err := strconv.Atoi(tag)
+	if err != nil || version < 1 || strings.HasPrefix(header, "W/") {
+		writeDetail(c, http.StatusPreconditionFailed, "If-Match does not match the current version")
+		return 0, false
+	}
+
+	return version, true
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cases := []struct {
+		header  string
+		status  int
+		version int
+	}{
+		{"", http.StatusPreconditionRequired, 0},
+		{`"3"`, http.StatusOK, 3},
+		{`W/"3"`, http.StatusPreconditionFailed, 0},
+		{"3", http.StatusOK, 3},
+		{`"0"`, http.StatusPreconditionFailed, 0},
+		{`"abc"`, http.StatusPreconditionFailed, 0},
+		{"*", http.StatusPreconditionRequired, 0},
+	}
+	for _, tc := range cases {
+		var version int
+		router := gin.New()
+		router.PUT("/test", func(c *gin.Context) {
+			v, ok := requireIfMatch(c)
+			if !ok {
+				return
+			}
+			version = v
+			setETag(c, v+1)
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/test", nil)
+		if tc.header != "" {
+			req.Header.Set("If-Match", tc.header)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tc.status || version != tc.version {
+			t.Errorf("If-Match %q: got status %d, version %d", tc.header, recorder.Code, version)
+		}
+		if tc.status == http.StatusOK {
+			if etag := recorder.Header().Get("ETag"); etag != `"4"` {
+				t.Errorf("If-Match %q: got ETag %q", tc.header, etag)
+			}
+		}
+	}
+}
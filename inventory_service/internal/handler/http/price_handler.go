@@ -0,0 +1,122 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type PriceHandler struct {
+	priceUseCase *usecase.PriceUseCase
+}
+
+func NewPriceHandler(uc *usecase.PriceUseCase) *PriceHandler {
+	return &PriceHandler{
+		priceUseCase: uc,
+	}
+}
+
+func (h *PriceHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/products/:id/prices", h.GetTimeline)
+		v1.GET("/products/:id/prices/effective", h.GetEffectivePrice)
+		v1.POST("/products/:id/prices/schedules", h.SchedulePrice)
+		v1.DELETE("/products/:id/prices/schedules/:scheduleId", h.CancelSchedule)
+	}
+}
+
+func (h *PriceHandler) GetTimeline(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	timeline, err := h.priceUseCase.Timeline(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromPriceTimeline(timeline))
+}
+
+// GetEffectivePrice answers what the price was, or will be, at ?at=; it
+// defaults to now.
+func (h *PriceHandler) GetEffectivePrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		if at, err = time.Parse(time.RFC3339, raw); err != nil {
+			writeDetail(c, http.StatusBadRequest, "invalid at, expected an RFC 3339 timestamp")
+			return
+		}
+	}
+
+	price, err := h.priceUseCase.PriceAt(c.Request.Context(), id, at)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.EffectivePriceResponse{ProductID: id, Price: price, At: at})
+}
+
+func (h *PriceHandler) SchedulePrice(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req dto.SchedulePriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	schedule, err := h.priceUseCase.SchedulePrice(c.Request.Context(), id, req.Price, req.StartsAt, req.EndsAt)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromPriceSchedule(schedule))
+}
+
+// CancelSchedule drops a pending schedule, or ends an active one early and
+// restores the price it replaced.
+func (h *PriceHandler) CancelSchedule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	scheduleID, err := strconv.ParseUint(c.Param("scheduleId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid schedule ID")
+		return
+	}
+
+	schedule, err := h.priceUseCase.CancelSchedule(c.Request.Context(), id, scheduleID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromPriceSchedule(schedule))
+}
+
+func (h *PriceHandler) writeError(c *gin.Context, err error) {
+	writeDomainError(c, err)
+}
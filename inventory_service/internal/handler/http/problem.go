@@ -0,0 +1,45 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// writeDetail answers with a problem that has the status's generic code.
+func writeDetail(c *gin.Context, status int, detail string) {
+	ginproblem.Write(c, problem.New(status, detail))
+}
+
+// writeDomainError answers with the status of err's kind and err's code.
+func writeDomainError(c *gin.Context, err error) {
+	writeErrorAs(c, errorStatus(err), err, err.Error())
+}
+
+// writeErrorDetail is writeDomainError with a message of the handler's own.
+func writeErrorDetail(c *gin.Context, err error, detail string) {
+	writeErrorAs(c, errorStatus(err), err, detail)
+}
+
+// writeErrorStatus is writeDomainError for an error that needs another
+// status than its kind's.
+func writeErrorStatus(c *gin.Context, status int, err error) {
+	writeErrorAs(c, status, err, err.Error())
+}
+
+// writeErrorAs answers with a problem for err. Server errors are logged and
+// their detail is only shown in verbose mode.
+func writeErrorAs(c *gin.Context, status int, err error, detail string) {
+	if status >= http.StatusInternalServerError {
+		ginproblem.WriteInternal(c, status, err)
+		return
+	}
+
+	p := problem.New(status, detail)
+	if code := domain.CodeOf(err); code != "" {
+		p.Code = code
+	}
+	ginproblem.Write(c, p)
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveProblem(t *testing.T, handler gin.HandlerFunc, body string) (*httptest.ResponseRecorder, problem.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ginproblem.RequestID(), ginproblem.Recovery())
+	router.POST("/test", handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/test", strings.NewReader(body))
+	req.Header.Set(problem.RequestIDHeader, "req-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, problem.ContentType) {
+		t.Fatalf("got content type %q", got)
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.RequestID != "req-1" || p.Instance != "/test" || p.Status != recorder.Code {
+		t.Fatalf("got request id %q, instance %q, status %d", p.RequestID, p.Instance, p.Status)
+	}
+	return recorder, p
+}
+
+func TestBindingErrorListsFields(t *testing.T) {
+	bind := func(c *gin.Context) {
+		var req dto.ProductRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ginproblem.WriteBindingError(c, err)
+		}
+	}
+
+	_, p := serveProblem(t, bind, `{"description": "no name", "price": "10.00", "stock": -1, "category_id": 1}`)
+	if p.Status != http.StatusBadRequest || p.Code != problem.CodeValidationFailed {
+		t.Fatalf("got status %d, code %q", p.Status, p.Code)
+	}
+	fields := map[string]string{}
+	for _, e := range p.Errors {
+		fields[e.Field] = e.Code
+	}
+	if fields["name"] != "required" || fields["stock"] != "gte" {
+		t.Fatalf("got field errors %+v", p.Errors)
+	}
+
+	_, p = serveProblem(t, bind, `{"name": "Lamp", "price": "10.00", "stock": "many", "category_id": 1}`)
+	if len(p.Errors) != 1 || p.Errors[0].Field != "stock" || p.Errors[0].Code != "type" {
+		t.Fatalf("got field errors %+v", p.Errors)
+	}
+
+	_, p = serveProblem(t, bind, `{"name": `)
+	if p.Code != problem.CodeMalformedBody {
+		t.Fatalf("got code %q", p.Code)
+	}
+}
+
+func TestDomainErrorsMapByKind(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{domain.ErrProductNotFound, http.StatusNotFound, "product_not_found"},
+		{domain.ErrDuplicateSKU, http.StatusConflict, "duplicate_sku"},
+		{domain.ErrVersionConflict, http.StatusPreconditionFailed, "version_conflict"},
+		{domain.ErrProductCategoryNotFound, http.StatusUnprocessableEntity, "product_category_not_found"},
+		{domain.ErrInvalidSlug, http.StatusBadRequest, "invalid_slug"},
+	}
+	for _, tc := range cases {
+		err := tc.err
+		_, p := serveProblem(t, func(c *gin.Context) { writeDomainError(c, err) }, "")
+		if p.Status != tc.status || p.Code != tc.code || p.Detail != err.Error() {
+			t.Errorf("%v: got status %d, code %q, detail %q", err, p.Status, p.Code, p.Detail)
+		}
+	}
+}
+
+func TestInternalErrorsHideDetailUnlessVerbose(t *testing.T) {
+	defer problem.SetVerbose(problem.Verbose())
+	fail := func(c *gin.Context) { writeDomainError(c, errors.New("pq: connection refused")) }
+
+	problem.SetVerbose(false)
+	_, p := serveProblem(t, fail, "")
+	if p.Status != http.StatusInternalServerError || p.Detail != "" || strings.Contains(p.Title, "pq") {
+		t.Fatalf("leaked %q / %q", p.Title, p.Detail)
+	}
+
+	problem.SetVerbose(true)
+	if _, p = serveProblem(t, fail, ""); p.Detail != "pq: connection refused" {
+		t.Fatalf("got detail %q in verbose mode", p.Detail)
+	}
+
+	problem.SetVerbose(false)
+	_, p = serveProblem(t, func(c *gin.Context) { panic("boom") }, "")
+	if p.Status != http.StatusInternalServerError || p.Detail != "" {
+		t.Fatalf("got %d %q for a panic", p.Status, p.Detail)
+	}
+}
@@ -0,0 +1,215 @@
+package http
+
+import (
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseProductFilter reads the listing filters shared by the list and search
+// endpoints. The returned page is zero when the request uses a cursor.
+func parseProductFilter(c *gin.Context) (domain.ProductFilter, int, error) {
+	var filter domain.ProductFilter
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	filter.Limit = limit
+	filter.Offset = (page - 1) * limit
+
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid category_id")
+		}
+		filter.CategoryIDs = append(filter.CategoryIDs, id)
+	}
+	if raw := c.Query("category_ids"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				return filter, 0, fmt.Errorf("invalid category_ids")
+			}
+			filter.CategoryIDs = append(filter.CategoryIDs, id)
+		}
+	}
+
+	var err error
+	if raw := c.Query("include_descendants"); raw != "" {
+		if filter.IncludeDescendants, err = strconv.ParseBool(raw); err != nil {
+			return filter, 0, fmt.Errorf("invalid include_descendants")
+		}
+	}
+	// The currency parameter only converts the prices shown, so the bounds
+	// take their own.
+	if raw := c.Query("price_currency"); raw != "" {
+		if filter.Currency, err = money.ParseCurrency(raw); err != nil {
+			return filter, 0, fmt.Errorf("invalid price_currency")
+		}
+	}
+	if filter.MinPrice, err = parsePriceQuery(c, "min_price", filter.PriceCurrency()); err != nil {
+		return filter, 0, err
+	}
+	if filter.MaxPrice, err = parsePriceQuery(c, "max_price", filter.PriceCurrency()); err != nil {
+		return filter, 0, err
+	}
+	if raw := c.Query("in_stock"); raw != "" {
+		if filter.InStockOnly, err = strconv.ParseBool(raw); err != nil {
+			return filter, 0, fmt.Errorf("invalid in_stock")
+		}
+	}
+	if filter.CreatedFrom, err = parseTimeQuery(c, "created_from", false); err != nil {
+		return filter, 0, err
+	}
+	if filter.CreatedTo, err = parseTimeQuery(c, "created_to", true); err != nil {
+		return filter, 0, err
+	}
+	if filter.UpdatedFrom, err = parseTimeQuery(c, "updated_from", false); err != nil {
+		return filter, 0, err
+	}
+	if filter.UpdatedTo, err = parseTimeQuery(c, "updated_to", true); err != nil {
+		return filter, 0, err
+	}
+
+	if filter.Attributes, err = parseAttributeFilters(c.Request.URL.RawQuery); err != nil {
+		return filter, 0, err
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		filter.SortDesc = strings.HasPrefix(sort, "-")
+		filter.SortBy = domain.ProductSortField(strings.TrimPrefix(sort, "-"))
+		switch strings.ToLower(c.Query("order")) {
+		case "":
+		case "asc":
+			filter.SortDesc = false
+		case "desc":
+			filter.SortDesc = true
+		default:
+			return filter, 0, fmt.Errorf("invalid order")
+		}
+	}
+	if err := filter.Validate(); err != nil {
+		return filter, 0, err
+	}
+
+	if token := c.Query("cursor"); token != "" {
+		if filter.Cursor, err = dto.DecodeProductCursor(token, filter); err != nil {
+			return filter, 0, err
+		}
+		filter.Offset = 0
+		page = 0
+	}
+
+	return filter, page, nil
+}
+
+// parseAttributeFilters reads terms such as attr.ram_gb>=16 from the raw
+// query string, since standard query parsing would split them at the first
+// '=' and lose the operator.
+func parseAttributeFilters(rawQuery string) ([]domain.AttributeFilter, error) {
+	var filters []domain.AttributeFilter
+	for _, term := range strings.Split(rawQuery, "&") {
+		term, err := url.QueryUnescape(term)
+		if err != nil {
+			continue
+		}
+		filter, ok, err := domain.ParseAttributeFilter(term)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, term)
+		}
+		if ok {
+			filters = append(filters, filter)
+		}
+	}
+	return filters, nil
+}
+
+// parseFacetRequest reads the facets parameter: a comma-separated list of
+// category, price, stock, attributes and attr.<code>, or true for all of
+// them. Price bucket bounds can be overridden with price_buckets=0,50,100;
+// they are amounts in currency, the filter's, and count products priced in
+// it.
+func parseFacetRequest(c *gin.Context, currency money.Currency) (domain.FacetRequest, error) {
+	var request domain.FacetRequest
+
+	raw := c.Query("facets")
+	if raw == "" || raw == "false" {
+		return request, nil
+	}
+	if raw == "true" {
+		raw = "category,price,stock,attributes"
+	}
+	for _, part := range strings.Split(raw, ",") {
+		switch part = strings.TrimSpace(part); {
+		case part == "category":
+			request.Categories = true
+		case part == "price":
+			request.Price = true
+		case part == "stock":
+			request.Stock = true
+		case part == "attributes":
+			request.AllAttributes = true
+		case strings.HasPrefix(part, "attr."):
+			request.AttributeCodes = append(request.AttributeCodes, strings.TrimPrefix(part, "attr."))
+		default:
+			return request, domain.ErrUnknownFacet
+		}
+	}
+
+	if raw := c.Query("price_buckets"); raw != "" {
+		for _, part := range strings.Split(raw, ",") {
+			bound, err := money.Parse(strings.TrimSpace(part), currency)
+			if err != nil {
+				return request, domain.ErrInvalidPriceBucket
+			}
+			request.PriceBuckets = append(request.PriceBuckets, bound)
+		}
+	}
+
+	return request, request.Validate(currency)
+}
+
+// parsePriceQuery reads a decimal price bound in currency.
+func parsePriceQuery(c *gin.Context, key string, currency money.Currency) (*money.Money, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	value, err := money.Parse(raw, currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return &value, nil
+}
+
+// parseTimeQuery accepts RFC 3339 timestamps or plain dates. A plain date used
+// as an upper bound covers the whole day.
+func parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
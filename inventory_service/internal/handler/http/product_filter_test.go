@@ -0,0 +1,127 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/gin-gonic/gin"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func parseFilterQuery(t *testing.T, query string) (domain.ProductFilter, int, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/products?"+query, nil)
+	return parseProductFilter(c)
+}
+
+func TestParseProductFilter(t *testing.T) {
+	filter, page, err := parseFilterQuery(t, "page=3&limit=20&category_ids=1,2&include_descendants=true&in_stock=true&sort=-price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != 3 || filter.Offset != 40 || filter.Limit != 20 {
+		t.Fatalf("got page %d, offset %d, limit %d", page, filter.Offset, filter.Limit)
+	}
+	if len(filter.CategoryIDs) != 2 || !filter.IncludeDescendants || !filter.InStockOnly {
+		t.Fatalf("got %+v", filter)
+	}
+	if filter.SortBy != domain.ProductSortPrice || !filter.SortDesc {
+		t.Fatalf("got sort %q desc=%v", filter.SortBy, filter.SortDesc)
+	}
+
+	filter, _, err = parseFilterQuery(t, "sort=name&order=desc&limit=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.SortBy != domain.ProductSortName || !filter.SortDesc || filter.Limit != 10 {
+		t.Fatalf("got sort %q desc=%v, limit %d", filter.SortBy, filter.SortDesc, filter.Limit)
+	}
+
+	for _, query := range []string{"category_ids=1,x", "in_stock=maybe", "sort=name&order=up", "sort=color"} {
+		if _, _, err := parseFilterQuery(t, query); err == nil {
+			t.Errorf("%s: expected an error", query)
+		}
+	}
+}
+
+func TestParseProductFilterCursorReplacesOffset(t *testing.T) {
+	ordering := domain.ProductFilter{SortBy: domain.ProductSortName}
+	token := dto.EncodeProductCursor(&domain.ProductCursor{Value: "Lamp", ID: 9}, ordering)
+
+	filter, page, err := parseFilterQuery(t, "sort=name&page=4&cursor="+url.QueryEscape(token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page != 0 || filter.Offset != 0 || filter.Cursor == nil || filter.Cursor.ID != 9 {
+		t.Fatalf("got page %d, offset %d, cursor %+v", page, filter.Offset, filter.Cursor)
+	}
+
+	if _, _, err := parseFilterQuery(t, "sort=-name&cursor="+url.QueryEscape(token)); err == nil {
+		t.Fatal("a cursor issued for another ordering was accepted")
+	}
+}
+
+func TestParseFacetRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string) (domain.FacetRequest, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/products?"+query, nil)
+		return parseFacetRequest(c, "USD")
+	}
+
+	request, err := parse("")
+	if err != nil || !request.Empty() {
+		t.Fatalf("got %+v, %v without facets", request, err)
+	}
+
+	request, err = parse("facets=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !request.Categories || !request.Price || !request.Stock || !request.AllAttributes {
+		t.Fatalf("got %+v for all facets", request)
+	}
+
+	request, err = parse("facets=price,attr.ram_gb&price_buckets=0,49.99,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Categories || !request.Price || len(request.AttributeCodes) != 1 || request.AttributeCodes[0] != "ram_gb" {
+		t.Fatalf("got %+v", request)
+	}
+	if len(request.PriceBuckets) != 3 || request.PriceBuckets[1].Minor() != 4999 || request.PriceBuckets[2].Currency() != "USD" {
+		t.Fatalf("got buckets %v", request.PriceBuckets)
+	}
+
+	for query, want := range map[string]error{
+		"facets=colour":                    domain.ErrUnknownFacet,
+		"facets=price&price_buckets=a,b":   domain.ErrInvalidPriceBucket,
+		"facets=price&price_buckets=50,0":  domain.ErrInvalidPriceBucket,
+		"facets=price&price_buckets=0.001": domain.ErrInvalidPriceBucket,
+		"facets=attr.Bad-Code":             domain.ErrInvalidAttributeCode,
+	} {
+		if _, err := parse(query); !errors.Is(err, want) {
+			t.Errorf("%s: got error %v, want %v", query, err, want)
+		}
+	}
+}
+
+func TestParseProductFilterPriceCurrency(t *testing.T) {
+	filter, _, err := parseFilterQuery(t, "min_price=5&max_price=12.5&price_currency=eur&currency=USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.Currency != "EUR" || filter.MinPrice == nil || filter.MinPrice.String() != "5.00 EUR" || filter.MaxPrice.Minor() != 1250 {
+		t.Fatalf("got currency %q, prices %v to %v", filter.Currency, filter.MinPrice, filter.MaxPrice)
+	}
+	if _, _, err := parseFilterQuery(t, "min_price=0.005"); err == nil {
+		t.Fatal("a price bound finer than the currency was accepted")
+	}
+	if _, _, err := parseFilterQuery(t, "price_currency=euro"); err == nil {
+		t.Fatal("an invalid price currency was accepted")
+	}
+}
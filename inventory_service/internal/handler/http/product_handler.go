@@ -1,20 +1,28 @@
 package http
 
 import (
+	"errors"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"sort"
 	"strconv"
 )
 
 type ProductHandler struct {
-	productRepo domain.ProductRepository
+	productUseCase  *usecase.ProductUseCase
+	currencyUseCase *usecase.CurrencyUseCase
 }
 
-func NewProductHandler(repo domain.ProductRepository) *ProductHandler {
+func NewProductHandler(productUseCase *usecase.ProductUseCase, currencyUseCase *usecase.CurrencyUseCase) *ProductHandler {
 	return &ProductHandler{
-		productRepo: repo,
+		productUseCase:  productUseCase,
+		currencyUseCase: currencyUseCase,
 	}
 }
 
@@ -22,6 +30,10 @@ func (h *ProductHandler) RegisterRoutes(router *gin.Engine) {
 	v1 := router.Group("/api/v1")
 	{
 		v1.POST("/products", h.CreateProduct)
+		v1.POST("/products/batch", h.GetProductBatch)
+		v1.GET("/products/search", h.SearchProducts)
+		v1.GET("/products/by-slug/:slug", h.GetProductBySlug)
+		v1.GET("/products/by-sku/:sku", h.GetProductBySKU)
 		v1.GET("/products/:id", h.GetProduct)
 		v1.PATCH("/products/:id", h.UpdateProduct)
 		v1.DELETE("/products/:id", h.DeleteProduct)
@@ -32,89 +44,177 @@ func (h *ProductHandler) RegisterRoutes(router *gin.Engine) {
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req dto.ProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ginproblem.WriteBindingError(c, err)
 		return
 	}
 
 	product, err := req.ToProduct()
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
-
-	if err := h.productRepo.Create(c.Request.Context(), product); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.productUseCase.CreateProduct(c.Request.Context(), product, req.Attributes); err != nil {
+		h.writeError(c, err)
 		return
 	}
 
+	setETag(c, product.Version())
 	c.JSON(http.StatusCreated, dto.FromProduct(product))
 }
 
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+	currency, err := h.requestedCurrency(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	product, err := h.productUseCase.GetProduct(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
 		return
 	}
 
-	product, err := h.productRepo.GetByID(c.Request.Context(), id)
+	h.writeProduct(c, product, currency)
+}
+
+// GetProductBySlug answers an old slug with a permanent redirect to the
+// product's current one.
+func (h *ProductHandler) GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	currency, err := h.requestedCurrency(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	product, current, err := h.productUseCase.GetProductBySlug(c.Request.Context(), slug)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 	if product == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+		redirectToSlug(c, "/api/v1/products/by-slug/", current)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromProduct(product))
+	h.writeProduct(c, product, currency)
 }
 
-func (h *ProductHandler) UpdateProduct(c *gin.Context) {
-	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+func (h *ProductHandler) GetProductBySKU(c *gin.Context) {
+	currency, err := h.requestedCurrency(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		h.writeError(c, err)
 		return
 	}
 
-	var req dto.ProductRequest
+	product, err := h.productUseCase.GetProductBySKU(c.Request.Context(), c.Param("sku"))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	h.writeProduct(c, product, currency)
+}
+
+// GetProductBatch looks up many products in one request, for carts and the
+// order flow. IDs and SKUs that match no live product are listed as missing
+// rather than failing the request.
+func (h *ProductHandler) GetProductBatch(c *gin.Context) {
+	var req dto.ProductBatchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+	currency, err := h.requestedCurrency(c)
+	if err != nil {
+		h.writeError(c, err)
 		return
 	}
 
-	product, err := h.productRepo.GetByID(c.Request.Context(), id)
+	result, err := h.productUseCase.GetProductBatch(c.Request.Context(), req.ToBatch())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
-	if product == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "product not found"})
+
+	data, err := h.toResponses(c, result.Products, currency)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ProductBatchResponse{
+		Data:        data,
+		MissingIDs:  result.MissingIDs,
+		MissingSKUs: result.MissingSKUs,
+	})
+}
+
+func (h *ProductHandler) writeProduct(c *gin.Context, product *domain.Product, currency money.Currency) {
+	response, err := h.toResponse(c, product, currency)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, product.Version())
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
 		return
 	}
 
-	if err := product.Update(req.Name, req.Description, req.Price, req.Stock, req.CategoryID); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	version, ok := requireIfMatch(c)
+	if !ok {
 		return
 	}
 
-	if err := h.productRepo.Update(c.Request.Context(), product); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var req dto.ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.FromProduct(product))
+	product, err := h.productUseCase.UpdateProduct(c.Request.Context(), id, version, req.ToChanges())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response, err := h.toResponse(c, product, "")
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, product.Version())
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product ID"})
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
 		return
 	}
 
-	if err := h.productRepo.Delete(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.productUseCase.DeleteProduct(c.Request.Context(), id, version); err != nil {
+		h.writeError(c, err)
 		return
 	}
 
@@ -122,33 +222,205 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 }
 
 func (h *ProductHandler) ListProducts(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	categoryID, _ := strconv.ParseUint(c.Query("category_id"), 10, 64)
+	filter, page, err := parseProductFilter(c)
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	facetRequest, err := parseFacetRequest(c, filter.PriceCurrency())
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	currency, err := h.requestedCurrency(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	result, err := h.productUseCase.ListProducts(c.Request.Context(), filter)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
 
-	if page < 1 {
-		page = 1
+	data, err := h.toResponses(c, result.Products, currency)
+	if err != nil {
+		h.writeError(c, err)
+		return
 	}
-	if limit < 1 {
-		limit = 10
+
+	response := dto.ProductListResponse{Data: data}
+	response.Meta.Page = page
+	response.Meta.Limit = filter.Limit
+	response.Meta.Total = result.Total
+	response.Meta.NextCursor = dto.EncodeProductCursor(result.Next, filter)
+	response.Meta.PrevCursor = dto.EncodeProductCursor(result.Prev, filter)
+
+	if !facetRequest.Empty() {
+		facets, err := h.productUseCase.ProductFacets(c.Request.Context(), filter, facetRequest)
+		if err != nil {
+			h.writeError(c, err)
+			return
+		}
+		response.Facets = dto.ToProductFacetsResponse(facets)
 	}
 
-	offset := (page - 1) * limit
+	c.JSON(http.StatusOK, response)
+}
 
-	products, err := h.productRepo.List(c.Request.Context(), categoryID, offset, limit)
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	filter, page, err := parseProductFilter(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	if filter.Cursor != nil {
+		writeDetail(c, http.StatusBadRequest, "search results are paged by page number")
 		return
 	}
 
-	response := dto.ProductListResponse{
-		Data: make([]dto.ProductResponse, len(products)),
+	query := domain.ProductSearchQuery{
+		Text:     c.Query("q"),
+		Language: c.Query("lang"),
+		Filter:   filter,
 	}
-	for i, p := range products {
-		response.Data[i] = *dto.FromProduct(p)
+	currency, err := h.requestedCurrency(c)
+	if err != nil {
+		h.writeError(c, err)
+		return
 	}
+
+	results, err := h.productUseCase.SearchProducts(c.Request.Context(), query)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	products := make([]*domain.Product, len(results))
+	for i, r := range results {
+		products[i] = r.Product
+	}
+	data, err := h.toResponses(c, products, currency)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := dto.ProductSearchResponse{
+		Data: make([]dto.ProductSearchHit, len(results)),
+	}
+	for i, r := range results {
+		response.Data[i] = dto.FromProductSearchResult(r)
+		response.Data[i].Product = data[i]
+	}
+	response.Meta.Query = query.Text
+	response.Meta.Language = query.Language
 	response.Meta.Page = page
-	response.Meta.Limit = limit
+	response.Meta.Limit = filter.Limit
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *ProductHandler) toResponse(c *gin.Context, product *domain.Product, currency money.Currency) (*dto.ProductResponse, error) {
+	responses, err := h.toResponses(c, []*domain.Product{product}, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+// toResponses converts products and attaches their variants and images, each
+// loaded in one query for the whole page. Given a currency, prices are
+// expressed in it.
+func (h *ProductHandler) toResponses(c *gin.Context, products []*domain.Product, currency money.Currency) ([]dto.ProductResponse, error) {
+	ids := make([]uint64, len(products))
+	for i, p := range products {
+		ids[i] = p.ID()
+	}
+	var localizer *domain.PriceLocalizer
+	if currency != "" {
+		var err error
+		if localizer, err = h.currencyUseCase.Localizer(c.Request.Context(), currency, ids); err != nil {
+			return nil, err
+		}
+	}
+	details, err := h.productUseCase.GetProductDetails(c.Request.Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ProductResponse, len(products))
+	for i, p := range products {
+		responses[i] = *dto.FromProduct(p)
+		responses[i].Variants = dto.FromVariants(details.Variants[p.ID()])
+		responses[i].Images = dto.FromProductImages(details.Images[p.ID()], h.productUseCase.ImageURL)
+		if localizer != nil {
+			if err := localize(&responses[i], p, localizer); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return responses, nil
+}
+
+// requestedCurrency returns the currency the client wants prices in, or an
+// empty one for the products' own. The ?currency= parameter takes precedence
+// over the Accept-Currency header.
+func (h *ProductHandler) requestedCurrency(c *gin.Context) (money.Currency, error) {
+	c.Header("Vary", "Accept-Currency")
+	if code := c.Query("currency"); code != "" {
+		return h.currencyUseCase.ParseCurrency(code)
+	}
+	if header := c.GetHeader("Accept-Currency"); header != "" {
+		return h.currencyUseCase.NegotiateCurrency(header)
+	}
+	return "", nil
+}
+
+func localize(response *dto.ProductResponse, product *domain.Product, localizer *domain.PriceLocalizer) error {
+	price, err := localizer.Product(product)
+	if err != nil {
+		return err
+	}
+	response.Price = price.Price
+	response.PriceSource = string(price.Source)
+	if price.Source != domain.PriceFromBase {
+		base := product.Price()
+		response.BasePrice = &base
+	}
+
+	for i, v := range response.Variants {
+		if v.Price == nil {
+			continue
+		}
+		converted, err := localizer.Convert(*v.Price)
+		if err != nil {
+			return err
+		}
+		response.Variants[i].Price = &converted.Price
+	}
+	return nil
+}
+
+// writeError answers with a problem for err. Attribute errors list the
+// failing fields, and a missing exchange rate is the request's problem rather
+// than a missing resource.
+func (h *ProductHandler) writeError(c *gin.Context, err error) {
+	var attributeErr *domain.AttributeValidationError
+	switch {
+	case errors.As(err, &attributeErr):
+		p := problem.New(http.StatusUnprocessableEntity, "invalid attributes").WithCode(attributeErr.Code())
+		for code, message := range attributeErr.Fields {
+			p.Errors = append(p.Errors, problem.FieldError{Field: "attributes." + code, Code: "attribute", Message: message})
+		}
+		sort.Slice(p.Errors, func(i, j int) bool { return p.Errors[i].Field < p.Errors[j].Field })
+		ginproblem.Write(c, p)
+	case errors.Is(err, domain.ErrVersionConflict):
+		writeErrorDetail(c, err, "product was modified by another request")
+	case errors.Is(err, domain.ErrExchangeRateNotFound):
+		writeErrorStatus(c, http.StatusUnprocessableEntity, err)
+	default:
+		writeDomainError(c, err)
+	}
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// allowedImageTypes are the content types a client may declare for an
+// upload. The processor checks the actual bytes as well.
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// multipartOverhead allows for the form boundaries, headers and other fields
+// that come with an image no larger than the upload limit.
+const multipartOverhead = 1 << 20
+
+type ProductImageHandler struct {
+	imageUseCase  *usecase.ProductImageUseCase
+	storage       domain.MediaStorage
+	maxUploadSize int64
+}
+
+func NewProductImageHandler(uc *usecase.ProductImageUseCase, storage domain.MediaStorage, maxUploadSize int64) *ProductImageHandler {
+	return &ProductImageHandler{
+		imageUseCase:  uc,
+		storage:       storage,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+func (h *ProductImageHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.POST("/products/:id/images", h.UploadImage)
+		v1.GET("/products/:id/images", h.ListImages)
+		v1.PUT("/products/:id/images/order", h.ReorderImages)
+		v1.POST("/products/:id/images/:imageId/primary", h.SetPrimaryImage)
+		v1.DELETE("/products/:id/images/:imageId", h.DeleteImage)
+	}
+}
+
+// UploadImage accepts a multipart form with the image in "file" and an
+// optional "primary" flag.
+func (h *ProductImageHandler) UploadImage(c *gin.Context) {
+	productID, ok := h.productID(c)
+	if !ok {
+		return
+	}
+
+	// Bound the whole body before it is parsed, since the form is buffered in
+	// memory and temporary files before the use case sees the image.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxUploadSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		h.writeError(c, domain.ErrImageTooLarge)
+		return
+	}
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "multipart upload must carry a file field")
+		return
+	}
+	if declared := header.Header.Get("Content-Type"); declared != "" && !allowedImageTypes[declared] {
+		writeErrorStatus(c, http.StatusUnsupportedMediaType, domain.ErrUnsupportedImageType)
+		return
+	}
+	primary, _ := strconv.ParseBool(c.PostForm("primary"))
+
+	file, err := header.Open()
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	image, err := h.imageUseCase.UploadImage(c.Request.Context(), productID, file, primary)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromProductImage(image, h.storage.URL))
+}
+
+func (h *ProductImageHandler) ListImages(c *gin.Context) {
+	productID, ok := h.productID(c)
+	if !ok {
+		return
+	}
+
+	images, err := h.imageUseCase.ListImages(c.Request.Context(), productID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromProductImages(images, h.storage.URL)})
+}
+
+func (h *ProductImageHandler) ReorderImages(c *gin.Context) {
+	productID, ok := h.productID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	images, err := h.imageUseCase.ReorderImages(c.Request.Context(), productID, req.ImageIDs)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromProductImages(images, h.storage.URL)})
+}
+
+func (h *ProductImageHandler) SetPrimaryImage(c *gin.Context) {
+	productID, ok := h.productID(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid image ID")
+		return
+	}
+
+	images, err := h.imageUseCase.SetPrimaryImage(c.Request.Context(), productID, imageID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromProductImages(images, h.storage.URL)})
+}
+
+func (h *ProductImageHandler) DeleteImage(c *gin.Context) {
+	productID, ok := h.productID(c)
+	if !ok {
+		return
+	}
+	imageID, err := strconv.ParseUint(c.Param("imageId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid image ID")
+		return
+	}
+
+	if err := h.imageUseCase.DeleteImage(c.Request.Context(), productID, imageID); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ProductImageHandler) productID(c *gin.Context) (uint64, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeError answers with a problem for err. Uploads the service cannot
+// store get the statuses HTTP has for them.
+func (h *ProductImageHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrUnsupportedImageType):
+		writeErrorStatus(c, http.StatusUnsupportedMediaType, err)
+	case errors.Is(err, domain.ErrImageTooLarge):
+		writeErrorStatus(c, http.StatusRequestEntityTooLarge, err)
+	default:
+		writeDomainError(c, err)
+	}
+}
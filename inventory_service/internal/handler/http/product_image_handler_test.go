@@ -0,0 +1,34 @@
+package http
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadImageLimitsBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewProductImageHandler(nil, nil, 1024).RegisterRoutes(router)
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "huge.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write(bytes.Repeat([]byte{0}, 1024+multipartOverhead))
+	form.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/products/1/images", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body)
+	}
+}
@@ -0,0 +1,18 @@
+package http
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/url"
+)
+
+// redirectToSlug sends a permanent redirect from a retired slug to the
+// current one under prefix, keeping the query string.
+func redirectToSlug(c *gin.Context, prefix, slug string) {
+	location := prefix + url.PathEscape(slug)
+	if c.Request.URL.RawQuery != "" {
+		location += "?" + c.Request.URL.RawQuery
+	}
+	c.Header("Location", location)
+	c.JSON(http.StatusMovedPermanently, gin.H{"slug": slug, "location": location})
+}
@@ -0,0 +1,85 @@
+package http
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type StockAlertHandler struct {
+	alertUseCase *usecase.StockAlertUseCase
+}
+
+func NewStockAlertHandler(uc *usecase.StockAlertUseCase) *StockAlertHandler {
+	return &StockAlertHandler{
+		alertUseCase: uc,
+	}
+}
+
+func (h *StockAlertHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/products/low-stock", h.ListLowStock)
+		v1.PUT("/products/:id/reorder-point", h.SetProductReorderPoint)
+	}
+	router.PUT("/api/categories/:id/reorder-point", h.SetCategoryReorderPoint)
+}
+
+// ListLowStock lists products at or below their reorder point, optionally
+// narrowed to one level with ?level=low_stock or ?level=out_of_stock.
+func (h *StockAlertHandler) ListLowStock(c *gin.Context) {
+	items, err := h.alertUseCase.ListLowStock(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	level := domain.StockAlertLevel(c.Query("level"))
+	response := []dto.LowStockResponse{}
+	for _, item := range items {
+		if level != "" && item.Level != level {
+			continue
+		}
+		response = append(response, *dto.FromLowStockItem(item))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "meta": gin.H{"total": len(response)}})
+}
+
+func (h *StockAlertHandler) SetProductReorderPoint(c *gin.Context) {
+	h.setReorderPoint(c, "invalid product ID", h.alertUseCase.SetProductReorderPoint)
+}
+
+func (h *StockAlertHandler) SetCategoryReorderPoint(c *gin.Context) {
+	h.setReorderPoint(c, "invalid category ID", h.alertUseCase.SetCategoryReorderPoint)
+}
+
+func (h *StockAlertHandler) setReorderPoint(c *gin.Context, badID string, set func(ctx context.Context, id uint64, point *int) error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, badID)
+		return
+	}
+
+	var req dto.ReorderPointRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	if err := set(c.Request.Context(), id, req.ReorderPoint); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "reorder_point": req.ReorderPoint})
+}
+
+func (h *StockAlertHandler) writeError(c *gin.Context, err error) {
+	writeDomainError(c, err)
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type StockHandler struct {
+	stockUseCase *usecase.StockUseCase
+}
+
+func NewStockHandler(uc *usecase.StockUseCase) *StockHandler {
+	return &StockHandler{
+		stockUseCase: uc,
+	}
+}
+
+func (h *StockHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.POST("/products/:id/stock", h.AdjustStock)
+		v1.GET("/products/:id/stock-movements", h.ListMovements)
+		v1.GET("/stock/reconciliation", h.Reconcile)
+	}
+}
+
+func (h *StockHandler) AdjustStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req dto.StockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+	reason := domain.MovementReason(req.Reason)
+	if reason == "" {
+		reason = domain.MovementAdjustment
+	}
+
+	stock, err := h.stockUseCase.AdjustStock(c.Request.Context(), id, req.VariantID, req.Quantity, reason, req.ReferenceID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.StockResponse{ProductID: id, VariantID: req.VariantID, Stock: stock})
+}
+
+func (h *StockHandler) ListMovements(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	movements, err := h.stockUseCase.ListMovements(c.Request.Context(), domain.StockMovementFilter{
+		ProductID: id,
+		Reason:    domain.MovementReason(c.Query("reason")),
+		Offset:    (page - 1) * limit,
+		Limit:     limit,
+	})
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]dto.StockMovementResponse, len(movements))
+	for i, m := range movements {
+		response[i] = *dto.FromStockMovement(m)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "meta": gin.H{"page": page, "limit": limit}})
+}
+
+// Reconcile lists every stock column that disagrees with its ledger,
+// optionally narrowed to one product.
+func (h *StockHandler) Reconcile(c *gin.Context) {
+	var productID uint64
+	if raw := c.Query("product_id"); raw != "" {
+		var err error
+		if productID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			writeDetail(c, http.StatusBadRequest, "invalid product ID")
+			return
+		}
+	}
+
+	discrepancies, err := h.stockUseCase.Reconcile(c.Request.Context(), productID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]dto.StockDiscrepancyResponse, len(discrepancies))
+	for i, d := range discrepancies {
+		response[i] = *dto.FromStockDiscrepancy(d)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "consistent": len(response) == 0})
+}
+
+func (h *StockHandler) writeError(c *gin.Context, err error) {
+	writeDomainError(c, err)
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// TrashHandler exposes soft-deleted products and categories. Restoring takes
+// the version shown in the trash listing as If-Match.
+type TrashHandler struct {
+	trashUseCase *usecase.TrashUseCase
+}
+
+func NewTrashHandler(uc *usecase.TrashUseCase) *TrashHandler {
+	return &TrashHandler{
+		trashUseCase: uc,
+	}
+}
+
+func (h *TrashHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.GET("/products/trash", h.ListProducts)
+		v1.POST("/products/:id/restore", h.RestoreProduct)
+	}
+	categories := router.Group("/api/categories")
+	{
+		categories.GET("/trash", h.ListCategories)
+		categories.POST("/:id/restore", h.RestoreCategory)
+	}
+}
+
+func (h *TrashHandler) ListProducts(c *gin.Context) {
+	page, limit := trashPage(c)
+
+	products, err := h.trashUseCase.ListProducts(c.Request.Context(), (page-1)*limit, limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := dto.TrashedProductListResponse{
+		Data: make([]dto.TrashedProductResponse, len(products)),
+		Meta: dto.TrashListMeta{Page: page, Limit: limit},
+	}
+	for i, p := range products {
+		response.Data[i] = dto.TrashedProductResponse{
+			ProductResponse: *dto.FromProduct(p),
+			DeletedAt:       p.DeletedAt(),
+			PurgeAt:         h.trashUseCase.PurgeAt(p.DeletedAt()),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TrashHandler) ListCategories(c *gin.Context) {
+	page, limit := trashPage(c)
+
+	categories, err := h.trashUseCase.ListCategories(c.Request.Context(), (page-1)*limit, limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := dto.TrashedCategoryListResponse{
+		Data: make([]dto.TrashedCategoryResponse, len(categories)),
+		Meta: dto.TrashListMeta{Page: page, Limit: limit},
+	}
+	for i, category := range categories {
+		response.Data[i] = dto.TrashedCategoryResponse{
+			CategoryResponse: *dto.FromCategory(category),
+			DeletedAt:        category.DeletedAt(),
+			PurgeAt:          h.trashUseCase.PurgeAt(category.DeletedAt()),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *TrashHandler) RestoreProduct(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.trashUseCase.RestoreProduct(c.Request.Context(), id, version)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, product.Version())
+	c.JSON(http.StatusOK, dto.FromProduct(product))
+}
+
+func (h *TrashHandler) RestoreCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid category ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	category, err := h.trashUseCase.RestoreCategory(c.Request.Context(), id, version)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, category.Version())
+	c.JSON(http.StatusOK, dto.FromCategory(category))
+}
+
+func trashPage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit
+}
+
+// writeError answers with a problem for err. Not-found means not in the
+// trash here.
+func (h *TrashHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrProductNotFound):
+		writeErrorDetail(c, err, "product not found in trash")
+	case errors.Is(err, domain.ErrCategoryNotFound):
+		writeErrorDetail(c, err, "category not found in trash")
+	case errors.Is(err, domain.ErrVersionConflict):
+		writeErrorDetail(c, err, "If-Match does not match the current version")
+	default:
+		writeDomainError(c, err)
+	}
+}
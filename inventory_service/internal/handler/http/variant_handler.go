@@ -0,0 +1,175 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type VariantHandler struct {
+	variantRepo domain.VariantRepository
+}
+
+func NewVariantHandler(repo domain.VariantRepository) *VariantHandler {
+	return &VariantHandler{
+		variantRepo: repo,
+	}
+}
+
+func (h *VariantHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.POST("/products/:id/variants", h.CreateVariant)
+		v1.GET("/products/:id/variants", h.ListVariants)
+		v1.GET("/products/:id/variants/:variantId", h.GetVariant)
+		v1.PATCH("/products/:id/variants/:variantId", h.UpdateVariant)
+		v1.DELETE("/products/:id/variants/:variantId", h.DeleteVariant)
+	}
+}
+
+func (h *VariantHandler) CreateVariant(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	var req dto.VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	variant, err := req.ToVariant(productID)
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.variantRepo.Create(c.Request.Context(), variant); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, variant.Version())
+	c.JSON(http.StatusCreated, dto.FromVariant(variant))
+}
+
+func (h *VariantHandler) ListVariants(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	variants, err := h.variantRepo.ListByProduct(c.Request.Context(), productID)
+	if err != nil {
+		writeDomainError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dto.FromVariants(variants)})
+}
+
+func (h *VariantHandler) GetVariant(c *gin.Context) {
+	variant, ok := h.loadVariant(c)
+	if !ok {
+		return
+	}
+
+	setETag(c, variant.Version())
+	c.JSON(http.StatusOK, dto.FromVariant(variant))
+}
+
+func (h *VariantHandler) UpdateVariant(c *gin.Context) {
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req dto.VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	variant, ok := h.loadVariant(c)
+	if !ok {
+		return
+	}
+	if variant.Version() != version {
+		writeDetail(c, http.StatusPreconditionFailed, "variant was modified by another request")
+		return
+	}
+
+	if err := variant.Update(req.SKU, req.Options, req.Price, req.Stock); err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.variantRepo.Update(c.Request.Context(), variant); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, variant.Version())
+	c.JSON(http.StatusOK, dto.FromVariant(variant))
+}
+
+func (h *VariantHandler) DeleteVariant(c *gin.Context) {
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	variant, ok := h.loadVariant(c)
+	if !ok {
+		return
+	}
+
+	if err := h.variantRepo.Delete(c.Request.Context(), variant.ID(), version); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadVariant fetches the variant named in the path and checks that it
+// belongs to the product in the path.
+func (h *VariantHandler) loadVariant(c *gin.Context) (*domain.Variant, bool) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return nil, false
+	}
+	variantID, err := strconv.ParseUint(c.Param("variantId"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid variant ID")
+		return nil, false
+	}
+
+	variant, err := h.variantRepo.GetByID(c.Request.Context(), variantID)
+	if err != nil {
+		writeDomainError(c, err)
+		return nil, false
+	}
+	if variant == nil || variant.ProductID() != productID {
+		writeDetail(c, http.StatusNotFound, "variant not found")
+		return nil, false
+	}
+
+	return variant, true
+}
+
+func (h *VariantHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrVersionConflict) {
+		writeErrorDetail(c, err, "variant was modified by another request")
+		return
+	}
+	writeDomainError(c, err)
+}
@@ -0,0 +1,271 @@
+package http
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type WarehouseHandler struct {
+	warehouseUseCase *usecase.WarehouseUseCase
+}
+
+func NewWarehouseHandler(uc *usecase.WarehouseUseCase) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseUseCase: uc,
+	}
+}
+
+func (h *WarehouseHandler) RegisterRoutes(router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	{
+		v1.POST("/warehouses", h.CreateWarehouse)
+		v1.GET("/warehouses", h.ListWarehouses)
+		v1.GET("/warehouses/:id", h.GetWarehouse)
+		v1.PATCH("/warehouses/:id", h.UpdateWarehouse)
+		v1.DELETE("/warehouses/:id", h.DeleteWarehouse)
+		v1.POST("/warehouses/:id/stock", h.AdjustWarehouseStock)
+
+		v1.POST("/stock-transfers", h.CreateTransfer)
+		v1.GET("/products/:id/stock-transfers", h.ListTransfers)
+		v1.GET("/products/:id/availability", h.GetAvailability)
+		v1.GET("/products/:id/allocation", h.GetAllocation)
+	}
+}
+
+func (h *WarehouseHandler) CreateWarehouse(c *gin.Context) {
+	var req dto.WarehouseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	warehouse, err := req.ToWarehouse()
+	if err != nil {
+		writeErrorStatus(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.warehouseUseCase.CreateWarehouse(c.Request.Context(), warehouse); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, warehouse.Version())
+	c.JSON(http.StatusCreated, dto.FromWarehouse(warehouse))
+}
+
+func (h *WarehouseHandler) ListWarehouses(c *gin.Context) {
+	warehouses, err := h.warehouseUseCase.ListWarehouses(c.Request.Context())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]dto.WarehouseResponse, len(warehouses))
+	for i, w := range warehouses {
+		response[i] = *dto.FromWarehouse(w)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response})
+}
+
+func (h *WarehouseHandler) GetWarehouse(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid warehouse ID")
+		return
+	}
+
+	warehouse, err := h.warehouseUseCase.GetWarehouse(c.Request.Context(), id)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, warehouse.Version())
+	c.JSON(http.StatusOK, dto.FromWarehouse(warehouse))
+}
+
+func (h *WarehouseHandler) UpdateWarehouse(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid warehouse ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	var req dto.WarehouseUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	warehouse, err := h.warehouseUseCase.UpdateWarehouse(c.Request.Context(), id, version, req.ToChanges())
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	setETag(c, warehouse.Version())
+	c.JSON(http.StatusOK, dto.FromWarehouse(warehouse))
+}
+
+func (h *WarehouseHandler) DeleteWarehouse(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid warehouse ID")
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	if err := h.warehouseUseCase.DeleteWarehouse(c.Request.Context(), id, version); err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WarehouseHandler) AdjustWarehouseStock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid warehouse ID")
+		return
+	}
+
+	var req dto.WarehouseStockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	quantity, err := h.warehouseUseCase.AdjustStock(c.Request.Context(), id, req.ProductID, req.Quantity)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.StockLevelResponse{WarehouseID: id, Quantity: quantity})
+}
+
+func (h *WarehouseHandler) CreateTransfer(c *gin.Context) {
+	var req dto.StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		ginproblem.WriteBindingError(c, err)
+		return
+	}
+
+	transfer, err := h.warehouseUseCase.Transfer(
+		c.Request.Context(), req.ProductID, req.FromWarehouseID, req.ToWarehouseID, req.Quantity, req.Note)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.FromStockTransfer(transfer))
+}
+
+func (h *WarehouseHandler) ListTransfers(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	transfers, err := h.warehouseUseCase.ListTransfers(c.Request.Context(), productID, (page-1)*limit, limit)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	response := make([]dto.StockTransferResponse, len(transfers))
+	for i, t := range transfers {
+		response[i] = *dto.FromStockTransfer(t)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": response, "meta": gin.H{"page": page, "limit": limit}})
+}
+
+func (h *WarehouseHandler) GetAvailability(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	availability, err := h.warehouseUseCase.Availability(c.Request.Context(), productID)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAvailability(availability))
+}
+
+// GetAllocation previews which warehouses would fulfil a quantity of the
+// product under the requested strategy.
+func (h *WarehouseHandler) GetAllocation(c *gin.Context) {
+	productID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid product ID")
+		return
+	}
+
+	quantity, err := strconv.Atoi(c.DefaultQuery("quantity", "1"))
+	if err != nil {
+		writeDetail(c, http.StatusBadRequest, "invalid quantity")
+		return
+	}
+	allowSplit, _ := strconv.ParseBool(c.DefaultQuery("split", "false"))
+
+	req := domain.AllocationRequest{ProductID: productID, Quantity: quantity, AllowSplit: allowSplit}
+	if c.Query("lat") != "" || c.Query("lon") != "" {
+		lat, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, lonErr := strconv.ParseFloat(c.Query("lon"), 64)
+		if latErr != nil || lonErr != nil {
+			writeDetail(c, http.StatusBadRequest, "lat and lon must both be valid coordinates")
+			return
+		}
+		req.Destination = &domain.GeoPoint{Latitude: lat, Longitude: lon}
+	}
+
+	strategy := c.DefaultQuery("strategy", domain.AllocationNearest)
+	allocations, err := h.warehouseUseCase.Allocate(c.Request.Context(), req, strategy)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.FromAllocations(productID, strategy, allocations))
+}
+
+func (h *WarehouseHandler) writeError(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrVersionConflict) {
+		writeErrorDetail(c, err, "warehouse was modified by another request")
+		return
+	}
+	writeDomainError(c, err)
+}
@@ -0,0 +1,37 @@
+package http
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/handler/http/dto"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWarehouseUpdateRequestBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bind := func(body string) (dto.WarehouseUpdateRequest, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		var req dto.WarehouseUpdateRequest
+		err := c.ShouldBindJSON(&req)
+		return req, err
+	}
+
+	// Neither the code nor any other field is required.
+	if _, err := bind(`{"is_active": true}`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bind(`{"name": ""}`); err == nil {
+		t.Fatal("an empty name was accepted")
+	}
+	req, err := bind(`{"location": null}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := req.ToChanges(); !changes.LocationSet || changes.Location != nil {
+		t.Fatalf("got %+v for a null location", changes)
+	}
+}
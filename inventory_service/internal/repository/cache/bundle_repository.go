@@ -0,0 +1,27 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+// bundleRepository invalidates a product as it becomes a bundle, changes
+// components or stops being one, since its stock and price change with that.
+type bundleRepository struct {
+	domain.BundleRepository
+	products *Cache
+}
+
+func NewBundleRepository(next domain.BundleRepository, products *Cache) domain.BundleRepository {
+	return &bundleRepository{BundleRepository: next, products: products}
+}
+
+func (r *bundleRepository) Save(ctx context.Context, bundle *domain.Bundle) error {
+	defer r.products.Invalidate(bundle.ProductID)
+	return r.BundleRepository.Save(ctx, bundle)
+}
+
+func (r *bundleRepository) Delete(ctx context.Context, productID uint64) error {
+	defer r.products.Invalidate(productID)
+	return r.BundleRepository.Delete(ctx, productID)
+}
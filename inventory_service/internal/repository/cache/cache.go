@@ -0,0 +1,166 @@
+// Package cache decorates repositories with an in-process read-through cache.
+// Entries are bounded by count, evicted least recently used first, and expire
+// after a TTL, which also bounds how stale an entry can get when the row is
+// changed by another replica. Every repository that writes product rows in
+// this process is decorated too, so that its writes invalidate what they
+// touch.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"sync"
+	"time"
+)
+
+// Cache maps IDs to loaded values. It is safe for concurrent use.
+type Cache struct {
+	name     string
+	capacity int
+	ttl      time.Duration
+
+	mu      sync.Mutex
+	entries map[uint64]*list.Element
+	order   *list.List // front is most recently used
+	calls   map[uint64]*call
+	// generation changes on every invalidation, so that a load which started
+	// before it does not store what it read.
+	generation uint64
+	stats      domain.CacheStats
+}
+
+type entry struct {
+	key     uint64
+	value   interface{}
+	expires time.Time
+}
+
+// call is a load in flight; concurrent misses for the same key wait for it
+// instead of loading again.
+type call struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	// canceled is set when the load failed because the loading caller's
+	// context was done, which says nothing about the key.
+	canceled bool
+}
+
+func New(name string, capacity int, ttl time.Duration) *Cache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Cache{
+		name:     name,
+		capacity: capacity,
+		ttl:      ttl,
+		entries:  make(map[uint64]*list.Element),
+		order:    list.New(),
+		calls:    make(map[uint64]*call),
+	}
+}
+
+// Get returns the cached value for key, or calls load once for all
+// concurrent callers and caches a non-nil result. A caller waiting on
+// another's load shares its result, error included, unless the load was cut
+// short by the other caller's context; then it loads again itself.
+func (c *Cache) Get(ctx context.Context, key uint64, load func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	c.mu.Lock()
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		if time.Now().Before(e.expires) {
+			c.order.MoveToFront(element)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return e.value, nil
+		}
+		c.remove(element)
+	}
+	if pending, ok := c.calls[key]; ok {
+		c.stats.Hits++
+		c.stats.Coalesced++
+		c.mu.Unlock()
+		select {
+		case <-pending.done:
+			if pending.canceled && ctx.Err() == nil {
+				return c.Get(ctx, key, load)
+			}
+			return pending.value, pending.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	c.stats.Misses++
+	pending := &call{done: make(chan struct{})}
+	c.calls[key] = pending
+	generation := c.generation
+	c.mu.Unlock()
+
+	pending.value, pending.err = load(ctx)
+	pending.canceled = pending.err != nil && ctx.Err() != nil
+
+	c.mu.Lock()
+	delete(c.calls, key)
+	if pending.err == nil && pending.value != nil && generation == c.generation {
+		c.store(key, pending.value)
+	}
+	c.mu.Unlock()
+	close(pending.done)
+
+	return pending.value, pending.err
+}
+
+// Invalidate drops the given keys.
+func (c *Cache) Invalidate(keys ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if element, ok := c.entries[key]; ok {
+			c.remove(element)
+		}
+	}
+}
+
+// Purge drops every entry, for writes that touch more rows than they name.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.entries = make(map[uint64]*list.Element)
+	c.order.Init()
+}
+
+func (c *Cache) Stats() domain.CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Name = c.name
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	return stats
+}
+
+func (c *Cache) store(key uint64, value interface{}) {
+	expires := time.Now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		element.Value = &entry{key: key, value: value, expires: expires}
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *Cache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*entry).key)
+}
@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func loadValue(value interface{}, loads *int) func(context.Context) (interface{}, error) {
+	return func(context.Context) (interface{}, error) {
+		*loads++
+		return value, nil
+	}
+}
+
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestCacheCoalescesConcurrentMisses(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	loads := 0
+	load := func(context.Context) (interface{}, error) {
+		mu.Lock()
+		loads++
+		mu.Unlock()
+		<-release
+		return "lamp", nil
+	}
+
+	const callers = 5
+	var wg sync.WaitGroup
+	results := make([]interface{}, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.Get(context.Background(), 1, load)
+		}(i)
+	}
+	waitFor(t, func() bool { return c.Stats().Coalesced == callers-1 })
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Fatalf("loaded %d times, want once", loads)
+	}
+	for i, result := range results {
+		if result != "lamp" {
+			t.Fatalf("caller %d got %v", i, result)
+		}
+	}
+	if stats := c.Stats(); stats.Misses != 1 || stats.Hits != callers-1 || stats.Size != 1 {
+		t.Fatalf("got stats %+v", stats)
+	}
+}
+
+func TestCacheWaiterLoadsAgainWhenTheLeaderIsCanceled(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	leaderDone := make(chan error)
+	go func() {
+		_, err := c.Get(leaderCtx, 1, func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		leaderDone <- err
+	}()
+	<-started
+
+	waiterDone := make(chan interface{})
+	go func() {
+		value, err := c.Get(context.Background(), 1, func(context.Context) (interface{}, error) {
+			return "lamp", nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+		waiterDone <- value
+	}()
+	waitFor(t, func() bool { return c.Stats().Coalesced == 1 })
+	cancel()
+
+	if err := <-leaderDone; !errors.Is(err, context.Canceled) {
+		t.Fatalf("leader got error %v", err)
+	}
+	if value := <-waiterDone; value != "lamp" {
+		t.Fatalf("waiter got %v, want its own load", value)
+	}
+}
+
+func TestCacheWaiterSharesTheLoadError(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	release := make(chan struct{})
+	failure := errors.New("database down")
+	go c.Get(context.Background(), 1, func(context.Context) (interface{}, error) {
+		<-release
+		return nil, failure
+	})
+	waitFor(t, func() bool { return c.Stats().Misses == 1 })
+
+	done := make(chan error)
+	go func() {
+		_, err := c.Get(context.Background(), 1, func(context.Context) (interface{}, error) {
+			t.Error("the waiter loaded again")
+			return nil, nil
+		})
+		done <- err
+	}()
+	waitFor(t, func() bool { return c.Stats().Coalesced == 1 })
+	close(release)
+	if err := <-done; !errors.Is(err, failure) {
+		t.Fatalf("got error %v, want the load's", err)
+	}
+	if c.Stats().Size != 0 {
+		t.Fatal("a failed load was cached")
+	}
+}
+
+func TestCacheInvalidateDuringLoadKeepsTheStaleValueOut(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	loading := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Get(context.Background(), 1, func(context.Context) (interface{}, error) {
+			close(loading)
+			<-release
+			return "old", nil
+		})
+	}()
+	<-loading
+	c.Invalidate(1)
+	close(release)
+	<-done
+
+	loads := 0
+	value, err := c.Get(context.Background(), 1, loadValue("new", &loads))
+	if err != nil || value != "new" || loads != 1 {
+		t.Fatalf("got %v, %v after %d loads, want the value read after the invalidation", value, err, loads)
+	}
+}
+
+func TestCacheEvictsTheLeastRecentlyUsed(t *testing.T) {
+	c := New("products", 2, time.Minute)
+	ctx := context.Background()
+	loads := 0
+	c.Get(ctx, 1, loadValue("one", &loads))
+	c.Get(ctx, 2, loadValue("two", &loads))
+	c.Get(ctx, 1, loadValue("one", &loads))
+	c.Get(ctx, 3, loadValue("three", &loads))
+	if loads != 3 {
+		t.Fatalf("loaded %d times, want 3", loads)
+	}
+
+	c.Get(ctx, 1, loadValue("one", &loads))
+	c.Get(ctx, 3, loadValue("three", &loads))
+	if loads != 3 {
+		t.Fatal("a recently used entry was evicted")
+	}
+	c.Get(ctx, 2, loadValue("two", &loads))
+	if loads != 4 {
+		t.Fatal("the least recently used entry was kept")
+	}
+	if stats := c.Stats(); stats.Evictions != 2 || stats.Size != 2 || stats.Capacity != 2 {
+		t.Fatalf("got stats %+v", stats)
+	}
+}
+
+func TestCacheExpiresAfterTheTTL(t *testing.T) {
+	c := New("products", 10, 0)
+	loads := 0
+	c.Get(context.Background(), 1, loadValue("lamp", &loads))
+	c.Get(context.Background(), 1, loadValue("lamp", &loads))
+	if loads != 2 {
+		t.Fatalf("loaded %d times, want an expired entry loaded again", loads)
+	}
+}
+
+func TestCacheSkipsMissingValues(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	loads := 0
+	c.Get(context.Background(), 1, loadValue(nil, &loads))
+	c.Get(context.Background(), 1, loadValue(nil, &loads))
+	if loads != 2 || c.Stats().Size != 0 {
+		t.Fatalf("loaded %d times with %d entries, want a missing row not cached", loads, c.Stats().Size)
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	c := New("products", 10, time.Minute)
+	loads := 0
+	c.Get(context.Background(), 1, loadValue("one", &loads))
+	c.Get(context.Background(), 2, loadValue("two", &loads))
+	c.Purge()
+	if c.Stats().Size != 0 {
+		t.Fatal("entries survived a purge")
+	}
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+// categoryRepository caches GetByID. A category's product count is only as
+// fresh as the TTL, since product writes do not invalidate categories.
+type categoryRepository struct {
+	domain.CategoryRepository
+	categories *Cache
+	products   *Cache
+}
+
+// NewCategoryRepository wraps next. products, when not nil, is the product
+// cache to purge when a category delete moves or trashes products.
+func NewCategoryRepository(next domain.CategoryRepository, categories, products *Cache) domain.CategoryRepository {
+	return &categoryRepository{CategoryRepository: next, categories: categories, products: products}
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id uint64) (*domain.Category, error) {
+	value, err := r.categories.Get(ctx, id, func(ctx context.Context) (interface{}, error) {
+		category, err := r.CategoryRepository.GetByID(ctx, id)
+		if category == nil || err != nil {
+			return nil, err
+		}
+		return category, nil
+	})
+	if value == nil || err != nil {
+		return nil, err
+	}
+	return value.(*domain.Category).Clone(), nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	defer r.categories.Invalidate(category.ID())
+	return r.CategoryRepository.Update(ctx, category)
+}
+
+func (r *categoryRepository) Move(ctx context.Context, category *domain.Category) error {
+	defer r.categories.Invalidate(category.ID())
+	return r.CategoryRepository.Move(ctx, category)
+}
+
+func (r *categoryRepository) Restore(ctx context.Context, id uint64, version int) error {
+	defer r.categories.Invalidate(id)
+	return r.CategoryRepository.Restore(ctx, id, version)
+}
+
+// Delete takes the whole subtree to the trash, so every category goes.
+func (r *categoryRepository) Delete(ctx context.Context, id uint64, version int, options domain.CategoryDeleteOptions) error {
+	defer r.categories.Purge()
+	if r.products != nil && options.Policy != domain.DeleteReject {
+		defer r.products.Purge()
+	}
+	return r.CategoryRepository.Delete(ctx, id, version, options)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+// priceRepository invalidates products whose price a schedule changed. A
+// schedule can take effect as it is created or cancelled, so those drop
+// the product and its bundles; due schedules touch rows they do not name,
+// so applying them drops every product.
+type priceRepository struct {
+	domain.PriceRepository
+	products *Cache
+	keys     productKeys
+}
+
+func NewPriceRepository(next domain.PriceRepository, products *Cache, bundles domain.BundleRepository) domain.PriceRepository {
+	return &priceRepository{PriceRepository: next, products: products, keys: productKeys{products: products, bundles: bundles}}
+}
+
+func (r *priceRepository) CreateSchedule(ctx context.Context, schedule *domain.PriceSchedule) error {
+	defer r.keys.invalidate(ctx, schedule.ProductID)
+	return r.PriceRepository.CreateSchedule(ctx, schedule)
+}
+
+func (r *priceRepository) CancelSchedule(ctx context.Context, productID, scheduleID uint64) (*domain.PriceSchedule, error) {
+	defer r.keys.invalidate(ctx, productID)
+	return r.PriceRepository.CancelSchedule(ctx, productID, scheduleID)
+}
+
+func (r *priceRepository) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	applied, err := r.PriceRepository.ApplyDue(ctx, now)
+	if applied > 0 {
+		r.products.Purge()
+	}
+	return applied, err
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+// productRepository caches GetByID. Everything else goes straight to the
+// wrapped repository; writes through it invalidate the product they touch.
+type productRepository struct {
+	domain.ProductRepository
+	products *Cache
+	keys     productKeys
+}
+
+// NewProductRepository wraps next. bundles, when not nil, finds the bundles
+// whose derived stock and price a write changes along with the product.
+func NewProductRepository(next domain.ProductRepository, products *Cache, bundles domain.BundleRepository) domain.ProductRepository {
+	return &productRepository{ProductRepository: next, products: products, keys: productKeys{products: products, bundles: bundles}}
+}
+
+// GetByID hands out a copy, so that callers updating the product in memory
+// do not change the cached one.
+func (r *productRepository) GetByID(ctx context.Context, id uint64) (*domain.Product, error) {
+	value, err := r.products.Get(ctx, id, func(ctx context.Context) (interface{}, error) {
+		product, err := r.ProductRepository.GetByID(ctx, id)
+		if product == nil || err != nil {
+			return nil, err
+		}
+		return product, nil
+	})
+	if value == nil || err != nil {
+		return nil, err
+	}
+	return value.(*domain.Product).Clone(), nil
+}
+
+func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
+	defer r.keys.invalidate(ctx, product.ID())
+	return r.ProductRepository.Update(ctx, product)
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uint64, version int) error {
+	defer r.keys.invalidate(ctx, id)
+	return r.ProductRepository.Delete(ctx, id, version)
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uint64, version int) error {
+	defer r.keys.invalidate(ctx, id)
+	return r.ProductRepository.Restore(ctx, id, version)
+}
+
+// productKeys invalidates cached products. A bundle's stock and price follow
+// its components, so a write to a product also drops the products linked to
+// it; when those cannot be looked up, every product goes.
+type productKeys struct {
+	products *Cache
+	bundles  domain.BundleRepository
+}
+
+func (k productKeys) invalidate(ctx context.Context, ids ...uint64) {
+	keys := append([]uint64(nil), ids...)
+	if k.bundles != nil {
+		for _, id := range ids {
+			linked, err := k.bundles.LinkedProducts(ctx, id)
+			if err != nil {
+				k.products.Purge()
+				return
+			}
+			keys = append(keys, linked...)
+		}
+	}
+	k.products.Invalidate(keys...)
+}
@@ -0,0 +1,30 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+	"strconv"
+)
+
+// invalidatingPublisher drops cached products as their events pass through
+// the outbox relay. Writes in this process invalidate as they commit; this
+// catches those of other replicas whose events this relay happens to publish,
+// usually well within the TTL.
+type invalidatingPublisher struct {
+	next     outbox.Publisher
+	products *Cache
+}
+
+func NewInvalidatingPublisher(next outbox.Publisher, products *Cache) outbox.Publisher {
+	return &invalidatingPublisher{next: next, products: products}
+}
+
+func (p *invalidatingPublisher) Publish(ctx context.Context, event outbox.Event) error {
+	if event.AggregateType == domain.AggregateProduct {
+		if id, err := strconv.ParseUint(event.AggregateID, 10, 64); err == nil {
+			p.products.Invalidate(id)
+		}
+	}
+	return p.next.Publish(ctx, event)
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/repotest"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+	"time"
+)
+
+// The decorated repositories must behave like the ones they wrap.
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := memory.NewStore()
+		products := New("products", 100, time.Minute)
+		return repotest.Repositories{
+			Products:   NewProductRepository(memory.NewProductRepository(store), products, linkedBundles{}),
+			Categories: NewCategoryRepository(memory.NewCategoryRepository(store), New("categories", 100, time.Minute), products),
+		}
+	})
+}
+
+// linkedBundles links products the way bundles do; a missing key fails the
+// lookup.
+type linkedBundles map[uint64][]uint64
+
+func (b linkedBundles) Get(ctx context.Context, productID uint64) (*domain.Bundle, error) {
+	return nil, nil
+}
+
+func (b linkedBundles) Save(ctx context.Context, bundle *domain.Bundle) error {
+	return nil
+}
+
+func (b linkedBundles) Delete(ctx context.Context, productID uint64) error {
+	return nil
+}
+
+func (b linkedBundles) LinkedProducts(ctx context.Context, productID uint64) ([]uint64, error) {
+	linked, ok := b[productID]
+	if !ok {
+		return nil, errors.New("lookup failed")
+	}
+	return linked, nil
+}
+
+type noMovements struct {
+	domain.StockMovementRepository
+}
+
+func (noMovements) Apply(ctx context.Context, movement *domain.StockMovement) (int, error) {
+	return 0, nil
+}
+
+func cached(c *Cache, keys ...uint64) map[uint64]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found := make(map[uint64]bool)
+	for _, key := range keys {
+		if _, ok := c.entries[key]; ok {
+			found[key] = true
+		}
+	}
+	return found
+}
+
+func fill(c *Cache, keys ...uint64) {
+	for _, key := range keys {
+		c.Get(context.Background(), key, func(context.Context) (interface{}, error) { return key, nil })
+	}
+}
+
+func TestProductWritesInvalidateLinkedBundles(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	products := New("products", 100, time.Minute)
+	repo := NewProductRepository(memory.NewProductRepository(store), products, linkedBundles{})
+
+	price, err := money.Parse("10.00", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lamp, err := domain.NewProduct("Lamp", "", price, 3, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, lamp); err != nil {
+		t.Fatal(err)
+	}
+
+	repo = NewProductRepository(memory.NewProductRepository(store), products, linkedBundles{lamp.ID(): {50}})
+	if _, err := repo.GetByID(ctx, lamp.ID()); err != nil {
+		t.Fatal(err)
+	}
+	fill(products, 50, 60)
+
+	if err := lamp.Update("Lamp", "", price, 4, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Update(ctx, lamp); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(products, lamp.ID(), 50, 60); got[lamp.ID()] || got[50] || !got[60] {
+		t.Fatalf("got cached %v, want the product and its bundle dropped", got)
+	}
+	stored, err := repo.GetByID(ctx, lamp.ID())
+	if err != nil || stored.Stock() != 4 {
+		t.Fatalf("got %+v, %v after the update", stored, err)
+	}
+}
+
+func TestStockMovementsInvalidateTheProductAndItsBundles(t *testing.T) {
+	products := New("products", 100, time.Minute)
+	repo := NewStockMovementRepository(noMovements{}, products, linkedBundles{1: {2}})
+	fill(products, 1, 2, 3)
+
+	if _, err := repo.Apply(context.Background(), &domain.StockMovement{ProductID: 1, Delta: -1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(products, 1, 2, 3); len(got) != 1 || !got[3] {
+		t.Fatalf("got cached %v, want only the unrelated product", got)
+	}
+
+	// A failed lookup cannot tell which bundles moved, so all go.
+	fill(products, 1, 2)
+	if _, err := repo.Apply(context.Background(), &domain.StockMovement{ProductID: 4, Delta: -1}); err != nil {
+		t.Fatal(err)
+	}
+	if products.Stats().Size != 0 {
+		t.Fatalf("kept %d entries after a failed lookup", products.Stats().Size)
+	}
+}
+
+func TestBundleWritesInvalidateTheBundle(t *testing.T) {
+	products := New("products", 100, time.Minute)
+	repo := NewBundleRepository(linkedBundles{}, products)
+	fill(products, 1, 2)
+
+	if err := repo.Save(context.Background(), &domain.Bundle{ProductID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(products, 1, 2); got[1] || !got[2] {
+		t.Fatalf("got cached %v after saving bundle 1", got)
+	}
+	fill(products, 1)
+	if err := repo.Delete(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(products, 1); got[1] {
+		t.Fatal("bundle 1 stayed cached after it was dissolved")
+	}
+}
+
+type dueSchedules struct {
+	domain.PriceRepository
+	applied int
+}
+
+func (r dueSchedules) CancelSchedule(ctx context.Context, productID, scheduleID uint64) (*domain.PriceSchedule, error) {
+	return &domain.PriceSchedule{ProductID: productID}, nil
+}
+
+func (r dueSchedules) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	return r.applied, nil
+}
+
+func TestPriceWritesInvalidateProducts(t *testing.T) {
+	products := New("products", 100, time.Minute)
+	fill(products, 1, 2, 3)
+
+	repo := NewPriceRepository(dueSchedules{}, products, linkedBundles{1: {2}})
+	if _, err := repo.CancelSchedule(context.Background(), 1, 9); err != nil {
+		t.Fatal(err)
+	}
+	if got := cached(products, 1, 2, 3); len(got) != 1 || !got[3] {
+		t.Fatalf("got cached %v after cancelling a schedule of product 1", got)
+	}
+
+	if _, err := repo.ApplyDue(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if products.Stats().Size != 1 {
+		t.Fatal("applying no schedules purged the cache")
+	}
+	repo = NewPriceRepository(dueSchedules{applied: 2}, products, linkedBundles{})
+	if _, err := repo.ApplyDue(context.Background(), time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if products.Stats().Size != 0 {
+		t.Fatal("applied schedules left products cached")
+	}
+}
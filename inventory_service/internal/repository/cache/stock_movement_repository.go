@@ -0,0 +1,22 @@
+package cache
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+// stockMovementRepository invalidates the product, and the bundles linked to
+// it, whose stock a movement changed.
+type stockMovementRepository struct {
+	domain.StockMovementRepository
+	keys productKeys
+}
+
+func NewStockMovementRepository(next domain.StockMovementRepository, products *Cache, bundles domain.BundleRepository) domain.StockMovementRepository {
+	return &stockMovementRepository{StockMovementRepository: next, keys: productKeys{products: products, bundles: bundles}}
+}
+
+func (r *stockMovementRepository) Apply(ctx context.Context, movement *domain.StockMovement) (int, error) {
+	defer r.keys.invalidate(ctx, movement.ProductID)
+	return r.StockMovementRepository.Apply(ctx, movement)
+}
@@ -0,0 +1,432 @@
+package memory
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"sort"
+	"time"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) domain.CategoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (r *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if category.ParentID() != 0 && s.liveCategory(category.ParentID()) == nil {
+		return domain.ErrParentCategoryNotFound
+	}
+	if err := s.checkCategoryExternalID(0, category.ExternalID()); err != nil {
+		return err
+	}
+	slug, err := s.categorySlugs().assign(0, category.Slug(), category.Name())
+	if err != nil {
+		return err
+	}
+	s.categorySlugs().claim(slug)
+
+	s.lastCategoryID++
+	at := now()
+	category.SetID(s.lastCategoryID)
+	category.SetSlug(slug)
+	category.SetVersion(1)
+	category.SetTimestamps(at, at)
+	s.categories[category.ID()] = category.Clone()
+	return nil
+}
+
+func (r *categoryRepository) GetByID(ctx context.Context, id uint64) (*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.readCategory(s.liveCategory(id)), nil
+}
+
+func (r *categoryRepository) GetByExternalID(ctx context.Context, externalID string) (*domain.Category, error) {
+	return r.getBy(func(c *domain.Category) bool { return c.ExternalID() == externalID })
+}
+
+func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
+	return r.getBy(func(c *domain.Category) bool { return c.Slug() == slug })
+}
+
+func (r *categoryRepository) getBy(match func(c *domain.Category) bool) (*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, category := range s.categories {
+		if category.DeletedAt().IsZero() && match(category) {
+			return s.readCategory(category), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *categoryRepository) SlugRedirect(ctx context.Context, slug string) (string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.categoryRedirects[slug]; ok {
+		if category := s.liveCategory(id); category != nil {
+			return category.Slug(), nil
+		}
+	}
+	return "", nil
+}
+
+func (r *categoryRepository) List(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories := s.selectCategories(func(c *domain.Category) bool { return c.DeletedAt().IsZero() })
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if !a.CreatedAt().Equal(b.CreatedAt()) {
+			return a.CreatedAt().After(b.CreatedAt())
+		}
+		return a.ID() > b.ID()
+	})
+	return page(categories, offset, limit), nil
+}
+
+func (r *categoryRepository) ListDeleted(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	categories := s.selectCategories(func(c *domain.Category) bool { return !c.DeletedAt().IsZero() })
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if !a.DeletedAt().Equal(b.DeletedAt()) {
+			return a.DeletedAt().After(b.DeletedAt())
+		}
+		return a.ID() > b.ID()
+	})
+	return page(categories, offset, limit), nil
+}
+
+func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.versionedCategory(category.ID(), category.Version(), false)
+	if err != nil {
+		return err
+	}
+	if err := s.checkCategoryExternalID(category.ID(), category.ExternalID()); err != nil {
+		return err
+	}
+	slug, err := s.categorySlugs().assign(category.ID(), category.Slug(), category.Name())
+	if err != nil {
+		return err
+	}
+	s.categorySlugs().move(category.ID(), stored.Slug(), slug)
+
+	// Update writes the category's own fields; its parent only changes
+	// through Move.
+	updated := stored.Clone()
+	updated.Update(category.Name(), category.Description())
+	updated.SetExternalID(category.ExternalID())
+	updated.SetSlug(slug)
+	updated.SetVersion(stored.Version() + 1)
+	updated.SetTimestamps(stored.CreatedAt(), now())
+	s.categories[category.ID()] = updated
+
+	category.SetSlug(slug)
+	category.SetVersion(updated.Version())
+	category.SetTimestamps(category.CreatedAt(), updated.UpdatedAt())
+	return nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uint64, version int, options domain.CategoryDeleteOptions) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.versionedCategory(id, version, false); err != nil {
+		return err
+	}
+	subtree := s.subtree(id)
+	at := now()
+
+	var affected []*domain.Product
+	for _, product := range s.products {
+		if !product.IsDeleted() && subtree[product.CategoryID()] {
+			affected = append(affected, product)
+		}
+	}
+
+	switch options.Policy {
+	case domain.DeleteReject:
+		if len(affected) > 0 {
+			return domain.ErrCategoryHasProducts
+		}
+	case domain.DeleteReassign:
+		if subtree[options.TargetID] {
+			return domain.ErrInvalidReassignTarget
+		}
+		if s.liveCategory(options.TargetID) == nil {
+			return domain.ErrReassignTargetNotFound
+		}
+		for _, product := range affected {
+			moved := product.Clone()
+			if err := moved.Update(product.Name(), product.Description(), product.Price(), product.Stock(), options.TargetID); err != nil {
+				return err
+			}
+			moved.SetVersion(product.Version() + 1)
+			moved.SetTimestamps(product.CreatedAt(), at)
+			s.products[product.ID()] = moved
+		}
+	case domain.DeleteCascade:
+		for _, product := range affected {
+			trashed := product.Clone()
+			trashed.MarkDeleted(at)
+			trashed.SetVersion(product.Version() + 1)
+			s.products[product.ID()] = trashed
+		}
+	default:
+		return domain.ErrInvalidDeletePolicy
+	}
+
+	for categoryID := range subtree {
+		trashed := s.categories[categoryID].Clone()
+		trashed.MarkDeleted(at)
+		trashed.SetVersion(trashed.Version() + 1)
+		s.categories[categoryID] = trashed
+	}
+	return nil
+}
+
+func (r *categoryRepository) Restore(ctx context.Context, id uint64, version int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.versionedCategory(id, version, true)
+	if err != nil {
+		return err
+	}
+	if stored.ParentID() != 0 && s.liveCategory(stored.ParentID()) == nil {
+		return domain.ErrCategoryTrashed
+	}
+
+	// Descendants deleted by the same delete share its deletion time and
+	// come back with the category, as in the Postgres repository.
+	subtree := map[uint64]bool{id: true}
+	for grown := true; grown; {
+		grown = false
+		for _, category := range s.categories {
+			if subtree[category.ParentID()] && !subtree[category.ID()] && category.DeletedAt().Equal(stored.DeletedAt()) {
+				subtree[category.ID()] = true
+				grown = true
+			}
+		}
+	}
+	for categoryID := range subtree {
+		if err := s.checkCategoryExternalID(categoryID, s.categories[categoryID].ExternalID()); err != nil {
+			return err
+		}
+	}
+
+	at := now()
+	for categoryID := range subtree {
+		s.categories[categoryID] = restoredCategory(s.categories[categoryID], at)
+	}
+	return nil
+}
+
+func (r *categoryRepository) Tree(ctx context.Context, rootID uint64) ([]*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	included := make(map[uint64]bool)
+	if rootID != 0 {
+		if s.liveCategory(rootID) != nil {
+			included = s.subtree(rootID)
+		}
+	} else {
+		for id, category := range s.categories {
+			if category.DeletedAt().IsZero() && s.liveCategory(category.ParentID()) == nil {
+				for descendant := range s.subtree(id) {
+					included[descendant] = true
+				}
+			}
+		}
+	}
+
+	categories := s.selectCategories(func(c *domain.Category) bool { return included[c.ID()] })
+	sort.Slice(categories, func(i, j int) bool {
+		a, b := categories[i], categories[j]
+		if a.Name() != b.Name() {
+			return a.Name() < b.Name()
+		}
+		return a.ID() < b.ID()
+	})
+	return categories, nil
+}
+
+func (r *categoryRepository) Path(ctx context.Context, id uint64) ([]*domain.Category, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var path []*domain.Category
+	for category := s.liveCategory(id); category != nil; {
+		path = append([]*domain.Category{s.readCategory(category)}, path...)
+		if category.ParentID() == 0 {
+			break
+		}
+		category = s.liveCategory(category.ParentID())
+	}
+	return path, nil
+}
+
+func (r *categoryRepository) Move(ctx context.Context, category *domain.Category) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if category.ParentID() != 0 {
+		if s.liveCategory(category.ParentID()) == nil {
+			return domain.ErrParentCategoryNotFound
+		}
+		for ancestor := s.categories[category.ParentID()]; ancestor != nil; ancestor = s.categories[ancestor.ParentID()] {
+			if ancestor.ID() == category.ID() {
+				return domain.ErrCategoryCycle
+			}
+			if ancestor.ParentID() == 0 {
+				break
+			}
+		}
+	}
+
+	stored, err := s.versionedCategory(category.ID(), category.Version(), false)
+	if err != nil {
+		return err
+	}
+	moved := stored.Clone()
+	moved.SetParentID(category.ParentID())
+	moved.SetVersion(stored.Version() + 1)
+	moved.SetTimestamps(stored.CreatedAt(), now())
+	s.categories[category.ID()] = moved
+
+	category.SetVersion(moved.Version())
+	category.SetTimestamps(category.CreatedAt(), moved.UpdatedAt())
+	return nil
+}
+
+// liveCategory returns the stored category with id unless it is missing or
+// in the trash. Callers must not hand it out without cloning.
+func (s *Store) liveCategory(id uint64) *domain.Category {
+	category := s.categories[id]
+	if category == nil || !category.DeletedAt().IsZero() {
+		return nil
+	}
+	return category
+}
+
+// versionedCategory finds the category a versioned write applies to, telling
+// a missing category from a stale version as missOrConflictIn does.
+func (s *Store) versionedCategory(id uint64, version int, deleted bool) (*domain.Category, error) {
+	category := s.categories[id]
+	if category == nil || category.DeletedAt().IsZero() == deleted {
+		return nil, domain.ErrCategoryNotFound
+	}
+	if category.Version() != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return category, nil
+}
+
+// checkCategoryExternalID keeps external IDs unique among live categories.
+func (s *Store) checkCategoryExternalID(id uint64, externalID string) error {
+	if externalID == "" {
+		return nil
+	}
+	for _, category := range s.categories {
+		if category.ID() != id && category.DeletedAt().IsZero() && category.ExternalID() == externalID {
+			return domain.ErrDuplicateExternalID
+		}
+	}
+	return nil
+}
+
+// subtree returns the IDs of the category and its live descendants.
+func (s *Store) subtree(id uint64) map[uint64]bool {
+	subtree := map[uint64]bool{id: true}
+	for grown := true; grown; {
+		grown = false
+		for _, category := range s.categories {
+			if category.DeletedAt().IsZero() && subtree[category.ParentID()] && !subtree[category.ID()] {
+				subtree[category.ID()] = true
+				grown = true
+			}
+		}
+	}
+	return subtree
+}
+
+// readCategory copies a stored category with its current product count, or
+// returns nil for nil.
+func (s *Store) readCategory(category *domain.Category) *domain.Category {
+	if category == nil {
+		return nil
+	}
+	count := 0
+	for _, product := range s.products {
+		if !product.IsDeleted() && product.CategoryID() == category.ID() {
+			count++
+		}
+	}
+	read := category.Clone()
+	read.SetProductCount(count)
+	return read
+}
+
+func (s *Store) selectCategories(match func(c *domain.Category) bool) []*domain.Category {
+	var categories []*domain.Category
+	for _, category := range s.categories {
+		if match(category) {
+			categories = append(categories, s.readCategory(category))
+		}
+	}
+	return categories
+}
+
+// restoredCategory rebuilds a trashed category as a live one, since a
+// category's deletion time cannot be cleared once set.
+func restoredCategory(c *domain.Category, at time.Time) *domain.Category {
+	restored := domain.NewCategory(c.Name(), c.Description())
+	restored.SetID(c.ID())
+	restored.SetExternalID(c.ExternalID())
+	restored.SetSlug(c.Slug())
+	restored.SetParentID(c.ParentID())
+	restored.SetVersion(c.Version() + 1)
+	restored.SetTimestamps(c.CreatedAt(), at)
+	return restored
+}
+
+// page applies an offset and limit to a sorted result.
+func page[T any](items []T, offset, limit int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,16 @@
+package memory
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/repotest"
+	"testing"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		store := NewStore()
+		return repotest.Repositories{
+			Products:   NewProductRepository(store),
+			Categories: NewCategoryRepository(store),
+		}
+	})
+}
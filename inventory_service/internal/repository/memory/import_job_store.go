@@ -0,0 +1,55 @@
+package memory
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"sync"
+	"time"
+)
+
+// importJobRetention is how long finished jobs stay available for polling.
+const importJobRetention = 24 * time.Hour
+
+type importJobStore struct {
+	mu   sync.RWMutex
+	jobs map[string]domain.ImportJob
+}
+
+// NewImportJobStore keeps import jobs in process memory, so job status does
+// not survive a restart or span replicas.
+func NewImportJobStore() domain.ImportJobStore {
+	return &importJobStore{jobs: make(map[string]domain.ImportJob)}
+}
+
+func (s *importJobStore) Save(ctx context.Context, job *domain.ImportJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = copyImportJob(job)
+
+	cutoff := time.Now().Add(-importJobRetention)
+	for id, j := range s.jobs {
+		if j.FinishedAt != nil && j.FinishedAt.Before(cutoff) {
+			delete(s.jobs, id)
+		}
+	}
+	return nil
+}
+
+func (s *importJobStore) Get(ctx context.Context, id string) (*domain.ImportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, nil
+	}
+	snapshot := copyImportJob(&job)
+	return &snapshot, nil
+}
+
+func copyImportJob(job *domain.ImportJob) domain.ImportJob {
+	snapshot := *job
+	snapshot.Errors = append([]domain.ImportRowError{}, job.Errors...)
+	return snapshot
+}
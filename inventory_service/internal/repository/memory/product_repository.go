@@ -0,0 +1,622 @@
+package memory
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type productRepository struct {
+	store *Store
+}
+
+func NewProductRepository(store *Store) domain.ProductRepository {
+	return &productRepository{store: store}
+}
+
+func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkProductIdentifiers(0, product.SKU(), product.ExternalID()); err != nil {
+		return err
+	}
+	slug, err := s.productSlugs().assign(0, product.Slug(), product.Name())
+	if err != nil {
+		return err
+	}
+	s.productSlugs().claim(slug)
+
+	s.lastProductID++
+	at := now()
+	product.SetID(s.lastProductID)
+	product.SetSlug(slug)
+	product.SetVersion(1)
+	product.SetTimestamps(at, at)
+	s.products[product.ID()] = product.Clone()
+	return nil
+}
+
+func (r *productRepository) GetByID(ctx context.Context, id uint64) (*domain.Product, error) {
+	return r.getBy(func(p *domain.Product) bool { return p.ID() == id })
+}
+
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	return r.getBy(func(p *domain.Product) bool { return p.SKU() == sku })
+}
+
+func (r *productRepository) GetByExternalID(ctx context.Context, externalID string) (*domain.Product, error) {
+	return r.getBy(func(p *domain.Product) bool { return p.ExternalID() == externalID })
+}
+
+func (r *productRepository) GetBySlug(ctx context.Context, slug string) (*domain.Product, error) {
+	return r.getBy(func(p *domain.Product) bool { return p.Slug() == slug })
+}
+
+func (r *productRepository) GetBatch(ctx context.Context, batch domain.ProductBatch) ([]*domain.Product, error) {
+	ids := make(map[uint64]bool, len(batch.IDs))
+	for _, id := range batch.IDs {
+		ids[id] = true
+	}
+	skus := make(map[string]bool, len(batch.SKUs))
+	for _, sku := range batch.SKUs {
+		skus[sku] = true
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var products []*domain.Product
+	for _, product := range s.products {
+		if !product.IsDeleted() && (ids[product.ID()] || product.SKU() != "" && skus[product.SKU()]) {
+			products = append(products, product.Clone())
+		}
+	}
+	return products, nil
+}
+
+func (r *productRepository) getBy(match func(p *domain.Product) bool) (*domain.Product, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, product := range s.products {
+		if !product.IsDeleted() && match(product) {
+			return product.Clone(), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *productRepository) SlugRedirect(ctx context.Context, slug string) (string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.productRedirects[slug]; ok {
+		if product := s.products[id]; product != nil && !product.IsDeleted() {
+			return product.Slug(), nil
+		}
+	}
+	return "", nil
+}
+
+func (r *productRepository) List(ctx context.Context, filter domain.ProductFilter) (*domain.ProductPage, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	products := s.filterProducts(filter)
+	page := &domain.ProductPage{Total: len(products)}
+
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	// As in the Postgres listing, walking backwards reads the preceding
+	// products in reverse order and flips them afterwards.
+	descending := filter.SortDesc != backward
+	less := func(a, b *domain.Product) bool {
+		c := compareSortValues(filter.SortBy, filter.SortValue(a), filter.SortValue(b))
+		if c == 0 {
+			return a.ID() < b.ID()
+		}
+		return c < 0
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if descending {
+			return less(products[j], products[i])
+		}
+		return less(products[i], products[j])
+	})
+
+	if filter.Cursor != nil {
+		var after []*domain.Product
+		for _, product := range products {
+			c := compareSortValues(filter.SortBy, filter.SortValue(product), filter.Cursor.Value)
+			if c == 0 {
+				c = compare(product.ID(), filter.Cursor.ID)
+			}
+			if descending && c < 0 || !descending && c > 0 {
+				after = append(after, product)
+			}
+		}
+		products = after
+	} else if filter.Offset < len(products) {
+		products = products[filter.Offset:]
+	} else {
+		products = nil
+	}
+
+	hasMore := len(products) > filter.Limit
+	if hasMore {
+		products = products[:filter.Limit]
+	}
+	if backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
+		}
+	}
+	page.Products = products
+
+	if len(products) == 0 {
+		return page, nil
+	}
+	first, last := products[0], products[len(products)-1]
+	if hasMore || backward {
+		page.Next = &domain.ProductCursor{Value: filter.SortValue(last), ID: last.ID()}
+	}
+	if (backward && hasMore) || (!backward && (filter.Cursor != nil || filter.Offset > 0)) {
+		page.Prev = &domain.ProductCursor{Value: filter.SortValue(first), ID: first.ID(), Backward: true}
+	}
+	return page, nil
+}
+
+func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.versionedProduct(product.ID(), product.Version(), false)
+	if err != nil {
+		return err
+	}
+	if err := s.checkProductIdentifiers(product.ID(), product.SKU(), product.ExternalID()); err != nil {
+		return err
+	}
+	slug, err := s.productSlugs().assign(product.ID(), product.Slug(), product.Name())
+	if err != nil {
+		return err
+	}
+	s.productSlugs().move(product.ID(), stored.Slug(), slug)
+
+	updated := product.Clone()
+	updated.SetSlug(slug)
+	updated.SetVersion(stored.Version() + 1)
+	updated.SetTimestamps(stored.CreatedAt(), now())
+	s.products[product.ID()] = updated
+
+	product.SetSlug(slug)
+	product.SetVersion(updated.Version())
+	product.SetTimestamps(product.CreatedAt(), updated.UpdatedAt())
+	return nil
+}
+
+func (r *productRepository) Delete(ctx context.Context, id uint64, version int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.versionedProduct(id, version, false)
+	if err != nil {
+		return err
+	}
+	trashed := stored.Clone()
+	trashed.MarkDeleted(now())
+	trashed.SetVersion(stored.Version() + 1)
+	s.products[id] = trashed
+	return nil
+}
+
+func (r *productRepository) ListDeleted(ctx context.Context, offset, limit int) ([]*domain.Product, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var products []*domain.Product
+	for _, product := range s.products {
+		if product.IsDeleted() {
+			products = append(products, product.Clone())
+		}
+	}
+	sort.Slice(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		if !a.DeletedAt().Equal(b.DeletedAt()) {
+			return a.DeletedAt().After(b.DeletedAt())
+		}
+		return a.ID() > b.ID()
+	})
+	return page(products, offset, limit), nil
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uint64, version int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.versionedProduct(id, version, true)
+	if err != nil {
+		return err
+	}
+	if category := s.categories[stored.CategoryID()]; category != nil && !category.DeletedAt().IsZero() {
+		return domain.ErrCategoryTrashed
+	}
+	if err := s.checkProductIdentifiers(id, stored.SKU(), stored.ExternalID()); err != nil {
+		return err
+	}
+
+	restored, err := domain.NewProduct(stored.Name(), stored.Description(), stored.Price(), stored.Stock(), stored.CategoryID())
+	if err != nil {
+		return err
+	}
+	restored.SetID(id)
+	restored.SetIdentifiers(stored.SKU(), stored.ExternalID())
+	restored.SetSlug(stored.Slug())
+	restored.SetAttributes(stored.Clone().Attributes())
+	restored.SetVersion(stored.Version() + 1)
+	restored.SetTimestamps(stored.CreatedAt(), now())
+	s.products[id] = restored
+	return nil
+}
+
+// Search matches every term as a word prefix of the name or description,
+// without stemming. Rank counts the matched words, name matches counting
+// double, and no highlights are produced.
+func (r *productRepository) Search(ctx context.Context, q domain.ProductSearchQuery) ([]*domain.ProductSearchResult, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	terms := q.Terms()
+	var results []*domain.ProductSearchResult
+	for _, product := range s.filterProducts(q.Filter) {
+		name := domain.ProductSearchQuery{Text: product.Name()}.Terms()
+		description := domain.ProductSearchQuery{Text: product.Description()}.Terms()
+
+		rank := 0.0
+		for _, term := range terms {
+			hits := 2*prefixHits(name, term) + prefixHits(description, term)
+			if hits == 0 {
+				rank = 0
+				break
+			}
+			rank += float64(hits)
+		}
+		if rank > 0 {
+			results = append(results, &domain.ProductSearchResult{Product: product, Rank: rank})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Product.ID() < results[j].Product.ID()
+	})
+	return page(results, q.Filter.Offset, q.Filter.Limit), nil
+}
+
+func prefixHits(words []string, term string) int {
+	hits := 0
+	for _, word := range words {
+		if strings.HasPrefix(word, term) {
+			hits++
+		}
+	}
+	return hits
+}
+
+// Facets counts every facet against the filter minus its own dimension, as
+// the Postgres repository does.
+func (r *productRepository) Facets(ctx context.Context, filter domain.ProductFilter, request domain.FacetRequest) (*domain.ProductFacets, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	facets := &domain.ProductFacets{}
+	if request.Categories {
+		counts := make(map[uint64]int)
+		for _, product := range s.filterProducts(filter.WithoutCategories()) {
+			if product.CategoryID() != 0 {
+				counts[product.CategoryID()]++
+			}
+		}
+		for id, count := range counts {
+			facet := domain.CategoryFacet{CategoryID: id, Count: count}
+			if category := s.categories[id]; category != nil {
+				facet.Name = category.Name()
+			}
+			facets.Categories = append(facets.Categories, facet)
+		}
+		sort.Slice(facets.Categories, func(i, j int) bool {
+			a, b := facets.Categories[i], facets.Categories[j]
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.CategoryID < b.CategoryID
+		})
+	}
+	if request.Price {
+		var priced []*domain.Product
+		for _, product := range s.filterProducts(filter.WithoutPrice()) {
+			if product.Price().Currency() == filter.PriceCurrency() {
+				priced = append(priced, product)
+			}
+		}
+		facets.Prices = priceFacets(priced, filter.PriceCurrency(), request.PriceBuckets)
+		facets.PriceCurrency = filter.PriceCurrency()
+	}
+	if request.Stock {
+		facets.Stock = &domain.StockFacet{}
+		for _, product := range s.filterProducts(filter.WithoutStock()) {
+			if product.Stock() > 0 {
+				facets.Stock.InStock++
+			} else {
+				facets.Stock.OutOfStock++
+			}
+		}
+	}
+	if request.AllAttributes || len(request.AttributeCodes) > 0 {
+		facets.Attributes = s.attributeFacets(filter, request)
+	}
+	return facets, nil
+}
+
+// priceFacets buckets products priced in currency, which the bounds are in
+// too.
+func priceFacets(products []*domain.Product, currency money.Currency, bounds []money.Money) []domain.PriceFacet {
+	var facets []domain.PriceFacet
+	if !bounds[0].IsZero() {
+		zero, _ := money.Zero(currency)
+		max := bounds[0]
+		facets = append(facets, domain.PriceFacet{Min: zero, Max: &max})
+	}
+	for i := range bounds {
+		facet := domain.PriceFacet{Min: bounds[i]}
+		if i+1 < len(bounds) {
+			max := bounds[i+1]
+			facet.Max = &max
+		}
+		facets = append(facets, facet)
+	}
+
+	for _, product := range products {
+		for i := len(facets) - 1; i >= 0; i-- {
+			if product.Price().Minor() >= facets[i].Min.Minor() {
+				facets[i].Count++
+				break
+			}
+		}
+	}
+	return facets
+}
+
+// attributeFacets counts the values of the requested codes, each under the
+// filter without its own condition.
+func (s *Store) attributeFacets(filter domain.ProductFilter, request domain.FacetRequest) map[string][]domain.AttributeFacet {
+	codes := make(map[string]bool)
+	for _, code := range request.AttributeCodes {
+		codes[code] = true
+	}
+	if request.AllAttributes {
+		for _, product := range s.filterProducts(filter) {
+			for code := range product.Attributes() {
+				codes[code] = true
+			}
+		}
+		for _, a := range filter.Attributes {
+			codes[a.Code] = true
+		}
+	}
+
+	facets := make(map[string][]domain.AttributeFacet)
+	for code := range codes {
+		counts := make(map[string]int)
+		for _, product := range s.filterProducts(filter.WithoutAttribute(code)) {
+			if value, ok := attributeText(product.Attributes()[code]); ok {
+				counts[value]++
+			}
+		}
+		if len(counts) == 0 {
+			continue
+		}
+		var values []domain.AttributeFacet
+		for value, count := range counts {
+			values = append(values, domain.AttributeFacet{Value: value, Count: count})
+		}
+		sort.Slice(values, func(i, j int) bool {
+			if values[i].Count != values[j].Count {
+				return values[i].Count > values[j].Count
+			}
+			return values[i].Value < values[j].Value
+		})
+		if len(values) > domain.MaxFacetValues {
+			values = values[:domain.MaxFacetValues]
+		}
+		facets[code] = values
+	}
+	return facets
+}
+
+// filterProducts returns copies of the live products the filter selects,
+// in no particular order.
+func (s *Store) filterProducts(f domain.ProductFilter) []*domain.Product {
+	var categories map[uint64]bool
+	if len(f.CategoryIDs) > 0 {
+		categories = make(map[uint64]bool)
+		for _, id := range f.CategoryIDs {
+			if !f.IncludeDescendants {
+				categories[id] = true
+				continue
+			}
+			if s.liveCategory(id) != nil {
+				for descendant := range s.subtree(id) {
+					categories[descendant] = true
+				}
+			}
+		}
+	}
+
+	var products []*domain.Product
+	for _, product := range s.products {
+		if product.IsDeleted() ||
+			categories != nil && !categories[product.CategoryID()] ||
+			f.HasPriceBounds() && product.Price().Currency() != f.PriceCurrency() ||
+			f.MinPrice != nil && product.Price().Minor() < f.MinPrice.Minor() ||
+			f.MaxPrice != nil && product.Price().Minor() > f.MaxPrice.Minor() ||
+			f.InStockOnly && product.Stock() <= 0 ||
+			f.CreatedFrom != nil && product.CreatedAt().Before(*f.CreatedFrom) ||
+			f.CreatedTo != nil && product.CreatedAt().After(*f.CreatedTo) ||
+			f.UpdatedFrom != nil && product.UpdatedAt().Before(*f.UpdatedFrom) ||
+			f.UpdatedTo != nil && product.UpdatedAt().After(*f.UpdatedTo) {
+			continue
+		}
+		matched := true
+		for _, a := range f.Attributes {
+			if !attributeMatches(product.Attributes(), a) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			products = append(products, product.Clone())
+		}
+	}
+	return products
+}
+
+// attributeMatches mirrors attributeCondition: equality compares the value
+// as text, so 16 matches "16", and the ordering operators only consider
+// numbers.
+func attributeMatches(attributes map[string]interface{}, a domain.AttributeFilter) bool {
+	value, present := attributes[a.Code]
+	switch a.Operator {
+	case domain.AttributeEq, domain.AttributeNe:
+		text, ok := attributeText(value)
+		equal := false
+		for _, v := range a.Values {
+			if ok && text == v {
+				equal = true
+			}
+		}
+		if a.Operator == domain.AttributeNe {
+			return present && !equal
+		}
+		return equal
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int:
+		number = float64(v)
+	default:
+		return false
+	}
+	bound, _ := strconv.ParseFloat(a.Values[0], 64)
+	switch a.Operator {
+	case domain.AttributeGt:
+		return number > bound
+	case domain.AttributeGte:
+		return number >= bound
+	case domain.AttributeLt:
+		return number < bound
+	default:
+		return number <= bound
+	}
+}
+
+// attributeText renders a scalar attribute value the way Postgres prints it
+// as text; lists and missing values have no text form.
+func attributeText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return strconv.FormatBool(v), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
+
+// compareSortValues orders two values of a ProductFilter sort field, in the
+// form SortValue gives them.
+func compareSortValues(field domain.ProductSortField, a, b string) int {
+	switch field {
+	case domain.ProductSortPrice:
+		x, _ := new(big.Rat).SetString(a)
+		y, _ := new(big.Rat).SetString(b)
+		if x == nil || y == nil {
+			return strings.Compare(a, b)
+		}
+		return x.Cmp(y)
+	case domain.ProductSortStock:
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return compare(x, y)
+	case domain.ProductSortName:
+		return strings.Compare(a, b)
+	default:
+		x, _ := time.Parse(time.RFC3339Nano, a)
+		y, _ := time.Parse(time.RFC3339Nano, b)
+		return x.Compare(y)
+	}
+}
+
+func compare[T int | uint64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// versionedProduct finds the product a versioned write applies to, telling a
+// missing product from a stale version as missOrConflictIn does.
+func (s *Store) versionedProduct(id uint64, version int, deleted bool) (*domain.Product, error) {
+	product := s.products[id]
+	if product == nil || product.IsDeleted() != deleted {
+		return nil, domain.ErrProductNotFound
+	}
+	if product.Version() != version {
+		return nil, domain.ErrVersionConflict
+	}
+	return product, nil
+}
+
+// checkProductIdentifiers keeps SKUs and external IDs unique among live
+// products.
+func (s *Store) checkProductIdentifiers(id uint64, sku, externalID string) error {
+	for _, product := range s.products {
+		if product.ID() == id || product.IsDeleted() {
+			continue
+		}
+		if sku != "" && product.SKU() == sku {
+			return domain.ErrDuplicateSKU
+		}
+		if externalID != "" && product.ExternalID() == externalID {
+			return domain.ErrDuplicateExternalID
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package memory implements the catalog repositories in process memory. It
+// follows the Postgres repositories' semantics, soft deletes, versions, slugs
+// and their redirects, but keeps no ledgers or outbox events, so it suits
+// tests and local runs rather than production.
+package memory
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store holds the catalog shared by the product and category repositories,
+// which need each other's rows for category filters and delete policies.
+type Store struct {
+	mu                sync.Mutex
+	products          map[uint64]*domain.Product
+	categories        map[uint64]*domain.Category
+	productRedirects  map[string]uint64
+	categoryRedirects map[string]uint64
+	lastProductID     uint64
+	lastCategoryID    uint64
+}
+
+func NewStore() *Store {
+	return &Store{
+		products:          make(map[uint64]*domain.Product),
+		categories:        make(map[uint64]*domain.Category),
+		productRedirects:  make(map[string]uint64),
+		categoryRedirects: make(map[string]uint64),
+	}
+}
+
+// now truncates to microseconds, the precision Postgres keeps, so that
+// timestamps read back the same from either store.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+// slugs is the memory counterpart of the Postgres slugTable: the slugs rows
+// use now and the slugs that redirect to them.
+type slugs struct {
+	current   func() map[uint64]string
+	redirects map[string]uint64
+	fallback  string
+}
+
+func (s *Store) productSlugs() slugs {
+	return slugs{
+		current: func() map[uint64]string {
+			current := make(map[uint64]string, len(s.products))
+			for id, product := range s.products {
+				current[id] = product.Slug()
+			}
+			return current
+		},
+		redirects: s.productRedirects,
+		fallback:  "product",
+	}
+}
+
+func (s *Store) categorySlugs() slugs {
+	return slugs{
+		current: func() map[uint64]string {
+			current := make(map[uint64]string, len(s.categories))
+			for id, category := range s.categories {
+				current[id] = category.Slug()
+			}
+			return current
+		},
+		redirects: s.categoryRedirects,
+		fallback:  "category",
+	}
+}
+
+// assign settles the slug a row is saved with, as slugTable.assign does, and
+// reports ErrDuplicateSlug when a requested slug belongs to another row.
+func (s slugs) assign(id uint64, requested, name string) (string, error) {
+	current := s.current()
+	if requested != "" {
+		for owner, slug := range current {
+			if slug == requested && owner != id {
+				return "", domain.ErrDuplicateSlug
+			}
+		}
+		return requested, nil
+	}
+
+	base := domain.Slugify(name)
+	if base == "" {
+		base = s.fallback
+	}
+	taken := make(map[string]bool)
+	for owner, slug := range current {
+		if owner != id {
+			taken[slug] = true
+		}
+	}
+	for slug, owner := range s.redirects {
+		if owner != id {
+			taken[slug] = true
+		}
+	}
+
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug, nil
+}
+
+// move leaves previous redirecting to id, and current redirecting nowhere.
+func (s slugs) move(id uint64, previous, current string) {
+	if previous == current {
+		return
+	}
+	delete(s.redirects, current)
+	s.redirects[previous] = id
+}
+
+// claim drops any redirect from a slug a new row is created with.
+func (s slugs) claim(slug string) {
+	delete(s.redirects, slug)
+}
@@ -0,0 +1,197 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+const attributeColumns = `a.id, a.category_id, a.code, a.name, a.type, COALESCE(a.unit, ''), a.required, a.allowed_values, a.created_at`
+
+type attributeRepository struct {
+	db *sql.DB
+}
+
+func NewAttributeRepository(db *sql.DB) domain.AttributeRepository {
+	return &attributeRepository{db: db}
+}
+
+func scanAttribute(row rowScanner) (*domain.AttributeDefinition, error) {
+	d := &domain.AttributeDefinition{}
+	var attrType string
+	var allowedValues []byte
+	err := row.Scan(&d.ID, &d.CategoryID, &d.Code, &d.Name, &attrType, &d.Unit, &d.Required, &allowedValues, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	d.Type = domain.AttributeType(attrType)
+	if err := json.Unmarshal(allowedValues, &d.AllowedValues); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *attributeRepository) Create(ctx context.Context, d *domain.AttributeDefinition) error {
+	allowedValues, err := json.Marshal(nonNilStrings(d.AllowedValues))
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Hold the tree lock so a concurrent move cannot bring two definitions
+	// with the same code into one lineage.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+		return err
+	}
+
+	var categoryExists, codeTaken bool
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = $1 AND is_deleted = false
+			UNION ALL
+			SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+		), descendants AS (
+			SELECT id FROM categories WHERE id = $1 AND is_deleted = false
+			UNION ALL
+			SELECT c.id FROM categories c JOIN descendants d ON c.parent_id = d.id WHERE c.is_deleted = false
+		)
+		SELECT EXISTS(SELECT 1 FROM ancestors),
+			EXISTS(
+				SELECT 1 FROM category_attributes
+				WHERE code = $2 AND category_id IN (SELECT id FROM ancestors UNION SELECT id FROM descendants)
+			)`
+	if err := tx.QueryRowContext(ctx, query, d.CategoryID, d.Code).Scan(&categoryExists, &codeTaken); err != nil {
+		return err
+	}
+	if !categoryExists {
+		return domain.ErrCategoryNotFound
+	}
+	if codeTaken {
+		return domain.ErrDuplicateAttributeCode
+	}
+
+	query = `
+		INSERT INTO category_attributes (category_id, code, name, type, unit, required, allowed_values, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		d.CategoryID,
+		d.Code,
+		d.Name,
+		string(d.Type),
+		nullableString(d.Unit),
+		d.Required,
+		allowedValues,
+	).Scan(&d.ID, &d.CreatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateAttributeCode
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *attributeRepository) GetByID(ctx context.Context, id uint64) (*domain.AttributeDefinition, error) {
+	query := `SELECT ` + attributeColumns + ` FROM category_attributes a WHERE a.id = $1`
+
+	d, err := scanAttribute(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (r *attributeRepository) Update(ctx context.Context, d *domain.AttributeDefinition) error {
+	allowedValues, err := json.Marshal(nonNilStrings(d.AllowedValues))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE category_attributes
+		SET name = $1, type = $2, unit = $3, required = $4, allowed_values = $5
+		WHERE id = $6`
+
+	result, err := r.db.ExecContext(ctx, query, d.Name, string(d.Type), nullableString(d.Unit), d.Required, allowedValues, d.ID)
+	if err != nil {
+		return err
+	}
+	return attributeAffected(result)
+}
+
+func (r *attributeRepository) Delete(ctx context.Context, id uint64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM category_attributes WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return attributeAffected(result)
+}
+
+func (r *attributeRepository) ListForCategory(ctx context.Context, categoryID uint64, inherited bool) ([]*domain.AttributeDefinition, error) {
+	query := `
+		SELECT ` + attributeColumns + `
+		FROM category_attributes a
+		WHERE a.category_id = $1
+		ORDER BY a.code`
+	if inherited {
+		query = `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id, 0 AS depth FROM categories WHERE id = $1 AND is_deleted = false
+				UNION ALL
+				SELECT c.id, c.parent_id, a.depth + 1 FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT ` + attributeColumns + `
+			FROM category_attributes a
+			JOIN ancestors anc ON anc.id = a.category_id
+			ORDER BY anc.depth DESC, a.code`
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	definitions := []*domain.AttributeDefinition{}
+	for rows.Next() {
+		d, err := scanAttribute(rows)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, d)
+	}
+
+	return definitions, rows.Err()
+}
+
+func attributeAffected(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrAttributeNotFound
+	}
+	return nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
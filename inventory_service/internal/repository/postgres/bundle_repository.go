@@ -0,0 +1,394 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+)
+
+type bundleRepository struct {
+	db *sql.DB
+}
+
+func NewBundleRepository(db *sql.DB) domain.BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (r *bundleRepository) Get(ctx context.Context, productID uint64) (*domain.Bundle, error) {
+	return loadBundle(ctx, r.db, productID)
+}
+
+// loadBundle reads a live bundle with its components, or nil when the product
+// is not one. Trashed components count as out of stock.
+func loadBundle(ctx context.Context, q querier, productID uint64) (*domain.Bundle, error) {
+	bundle := &domain.Bundle{ProductID: productID}
+	var pricing, discount string
+	query := `
+		SELECT b.pricing, COALESCE(b.discount_percent::text, ''), b.updated_at
+		FROM bundles b
+		JOIN products p ON p.id = b.product_id
+		WHERE b.product_id = $1 AND p.is_deleted = false`
+	err := q.QueryRowContext(ctx, query, productID).Scan(&pricing, &discount, &bundle.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	bundle.Pricing = domain.BundlePricing(pricing)
+	if discount != "" {
+		if bundle.Discount, err = domain.ParseBundleDiscount(discount); err != nil {
+			return nil, err
+		}
+	}
+
+	query = `
+		SELECT bc.component_id, bc.quantity, p.name, p.price, p.currency, CASE WHEN p.is_deleted THEN 0 ELSE p.stock END
+		FROM bundle_components bc
+		JOIN products p ON p.id = bc.component_id
+		WHERE bc.bundle_id = $1
+		ORDER BY bc.component_id`
+	rows, err := q.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c domain.BundleComponent
+		var price, currency string
+		if err := rows.Scan(&c.ProductID, &c.Quantity, &c.Name, &price, &currency, &c.Stock); err != nil {
+			return nil, err
+		}
+		if c.Price, err = parseMoney(price, currency); err != nil {
+			return nil, err
+		}
+		bundle.Components = append(bundle.Components, c)
+	}
+
+	return bundle, rows.Err()
+}
+
+// Save locks the bundle product and shares its components, so that neither
+// side can become part of another bundle while this one is written.
+func (r *bundleRepository) Save(ctx context.Context, bundle *domain.Bundle) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var stock int
+	var currency string
+	var isBundle, isComponent bool
+	query := `
+		SELECT p.stock, p.currency,
+		       EXISTS(SELECT 1 FROM bundles WHERE product_id = p.id),
+		       EXISTS(SELECT 1 FROM bundle_components WHERE component_id = p.id)
+		FROM products p
+		WHERE p.id = $1 AND p.is_deleted = false
+		FOR UPDATE OF p`
+	err = tx.QueryRowContext(ctx, query, bundle.ProductID).Scan(&stock, &currency, &isBundle, &isComponent)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if isComponent {
+		return domain.ErrNestedBundle
+	}
+	// A product's own stock would silently disappear behind the derived one.
+	if !isBundle && stock != 0 {
+		return domain.ErrBundleHasStock
+	}
+
+	ids := make([]int64, len(bundle.Components))
+	quantities := make([]int64, len(bundle.Components))
+	for i, c := range bundle.Components {
+		ids[i] = int64(c.ProductID)
+		quantities[i] = int64(c.Quantity)
+	}
+
+	query = `
+		SELECT p.id, EXISTS(SELECT 1 FROM bundles WHERE product_id = p.id)
+		FROM products p
+		WHERE p.id = ANY($1) AND p.is_deleted = false
+		ORDER BY p.id
+		FOR SHARE OF p`
+	rows, err := tx.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	found := 0
+	nested := false
+	for rows.Next() {
+		var id uint64
+		var componentIsBundle bool
+		if err := rows.Scan(&id, &componentIsBundle); err != nil {
+			rows.Close()
+			return err
+		}
+		found++
+		nested = nested || componentIsBundle
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if found != len(ids) {
+		return domain.ErrBundleComponentNotFound
+	}
+	if nested {
+		return domain.ErrNestedBundle
+	}
+
+	var discount interface{}
+	if bundle.Discount != nil {
+		discount = bundle.DiscountString()
+	}
+	query = `
+		INSERT INTO bundles (product_id, pricing, discount_percent, created_at, updated_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (product_id) DO UPDATE
+		SET pricing = EXCLUDED.pricing, discount_percent = EXCLUDED.discount_percent, updated_at = EXCLUDED.updated_at`
+	if _, err := tx.ExecContext(ctx, query, bundle.ProductID, string(bundle.Pricing), discount); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM bundle_components WHERE bundle_id = $1`, bundle.ProductID); err != nil {
+		return err
+	}
+	query = `
+		INSERT INTO bundle_components (bundle_id, component_id, quantity)
+		SELECT $1, c.id, c.quantity
+		FROM unnest($2::bigint[], $3::int[]) AS c(id, quantity)`
+	if _, err := tx.ExecContext(ctx, query, bundle.ProductID, pq.Array(ids), pq.Array(quantities)); err != nil {
+		return err
+	}
+
+	var available int
+	query = `
+		UPDATE products
+		SET stock = bundle_stock(id), version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING stock`
+	if err := tx.QueryRowContext(ctx, query, bundle.ProductID).Scan(&available); err != nil {
+		return err
+	}
+	if err := writeBundleStockChanged(ctx, tx, bundle.ProductID, stock, available); err != nil {
+		return err
+	}
+	if bundle.Pricing == domain.BundlePricingDiscount {
+		if err := setBundlePrice(ctx, tx, bundle.ProductID); err != nil {
+			return err
+		}
+	}
+
+	saved, err := loadBundle(ctx, tx, bundle.ProductID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	*bundle = *saved
+	return nil
+}
+
+// Delete dissolves the bundle. The product is left without stock of its own,
+// and its ledger is brought in line so that it reconciles again.
+func (r *bundleRepository) Delete(ctx context.Context, productID uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM bundles WHERE product_id = $1`, productID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrBundleNotFound
+	}
+
+	var stock, ledger int
+	query := `
+		SELECT stock, (SELECT COALESCE(SUM(delta), 0) FROM stock_movements WHERE product_id = $1 AND variant_id IS NULL)
+		FROM products
+		WHERE id = $1`
+	if err := tx.QueryRowContext(ctx, query, productID).Scan(&stock, &ledger); err != nil {
+		return err
+	}
+	query = `UPDATE products SET stock = 0, version = version + 1, updated_at = NOW() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, productID); err != nil {
+		return err
+	}
+	// The ledger entry publishes the change itself; without one the derived
+	// stock just drops to zero.
+	if ledger != 0 {
+		err = recordStockChange(ctx, tx, productID, 0, -ledger, domain.MovementAdjustment)
+	} else {
+		err = writeBundleStockChanged(ctx, tx, productID, stock, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *bundleRepository) LinkedProducts(ctx context.Context, productID uint64) ([]uint64, error) {
+	query := `
+		SELECT bundle_id FROM bundle_components WHERE component_id = $1
+		UNION
+		SELECT component_id FROM bundle_components WHERE bundle_id = $1
+		ORDER BY 1`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// bundleComponentsFor returns the components of a live bundle in lock order,
+// or nil when productID is not a bundle. The bundle definition is shared for
+// the rest of tx.
+func bundleComponentsFor(ctx context.Context, tx *sql.Tx, productID uint64) ([]domain.BundleComponent, error) {
+	query := `
+		SELECT bc.component_id, bc.quantity
+		FROM bundles b
+		JOIN products p ON p.id = b.product_id AND p.is_deleted = false
+		JOIN bundle_components bc ON bc.bundle_id = b.product_id
+		WHERE b.product_id = $1
+		ORDER BY bc.component_id
+		FOR SHARE OF b`
+	rows, err := tx.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var components []domain.BundleComponent
+	for rows.Next() {
+		var c domain.BundleComponent
+		if err := rows.Scan(&c.ProductID, &c.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, c)
+	}
+	return components, rows.Err()
+}
+
+// bundleReference links a component movement to the bundle it was sold in,
+// keeping the caller's reference when there is one.
+func bundleReference(bundleID uint64, referenceID string) string {
+	if referenceID == "" {
+		return fmt.Sprintf("bundle:%d", bundleID)
+	}
+	return fmt.Sprintf("bundle:%d:%s", bundleID, referenceID)
+}
+
+// refreshBundlePrices re-derives the price of every discount bundle that
+// contains componentID, after the component's price changed inside tx.
+func refreshBundlePrices(ctx context.Context, tx *sql.Tx, componentID uint64) error {
+	query := `
+		SELECT b.product_id
+		FROM bundles b
+		JOIN bundle_components bc ON bc.bundle_id = b.product_id
+		WHERE bc.component_id = $1 AND b.pricing = 'discount'
+		ORDER BY b.product_id`
+	rows, err := tx.QueryContext(ctx, query, componentID)
+	if err != nil {
+		return err
+	}
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := setBundlePrice(ctx, tx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setBundlePrice sets a discount bundle's price from its components and
+// records the change. Components must be priced in the bundle's currency.
+func setBundlePrice(ctx context.Context, tx *sql.Tx, bundleID uint64) error {
+	var price, currency string
+	query := `SELECT price, currency FROM products WHERE id = $1 FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, bundleID).Scan(&price, &currency); err != nil {
+		return err
+	}
+	previous, err := parseMoney(price, currency)
+	if err != nil {
+		return err
+	}
+
+	bundle, err := loadBundle(ctx, tx, bundleID)
+	if err != nil || bundle == nil {
+		return err
+	}
+	for _, c := range bundle.Components {
+		if c.Price.Currency() != previous.Currency() {
+			return domain.ErrCurrencyMismatch
+		}
+	}
+	derived, err := bundle.DiscountedPrice()
+	if err != nil {
+		return err
+	}
+	if derived.Equal(previous) {
+		return nil
+	}
+
+	query = `UPDATE products SET price = $1, version = version + 1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, derived.Decimal(), bundleID); err != nil {
+		return err
+	}
+	return insertPriceChange(ctx, tx, &domain.PriceChange{
+		ProductID: bundleID,
+		Price:     derived,
+		Source:    domain.PriceChangeBundle,
+		Actor:     domain.ActorFromContext(ctx),
+	})
+}
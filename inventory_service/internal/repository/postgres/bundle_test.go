@@ -0,0 +1,126 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strconv"
+	"testing"
+)
+
+func TestBundleStockFollowsComponents(t *testing.T) {
+	db := openTestDB(t)
+	emptyCatalog(t, db)
+	ctx := context.Background()
+	products := NewProductRepository(db)
+	bundles := NewBundleRepository(db)
+	movements := NewStockMovementRepository(db)
+
+	create := func(name string, stock int) *domain.Product {
+		t.Helper()
+		price, err := money.Parse("10.00", "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		product, err := domain.NewProduct(name, "", price, stock, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := products.Create(ctx, product); err != nil {
+			t.Fatal(err)
+		}
+		return product
+	}
+	lamp := create("Lamp", 10)
+	bulb := create("Bulb", 3)
+	kit := create("Lamp Kit", 0)
+
+	bundle, err := domain.NewBundle(kit.ID(), domain.BundlePricingFixed, "", []domain.BundleComponent{
+		{ProductID: lamp.ID(), Quantity: 2},
+		{ProductID: bulb.ID(), Quantity: 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bundles.Save(ctx, bundle); err != nil {
+		t.Fatal(err)
+	}
+	saved := mustGet(t, products, kit.ID())
+	if saved.Stock() != 3 {
+		t.Fatalf("got bundle stock %d, want 3", saved.Stock())
+	}
+
+	sale, err := domain.NewStockMovement(bulb.ID(), 0, -1, domain.MovementSale, "order-1", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := movements.Apply(ctx, sale); err != nil {
+		t.Fatal(err)
+	}
+	sold := mustGet(t, products, kit.ID())
+	if sold.Stock() != 2 || sold.Version() != saved.Version()+1 {
+		t.Fatalf("got stock %d at version %d after a component sale, was version %d", sold.Stock(), sold.Version(), saved.Version())
+	}
+
+	if err := bundles.Delete(ctx, kit.ID()); err != nil {
+		t.Fatal(err)
+	}
+
+	events := bundleStockEvents(t, db, kit.ID())
+	want := []domain.StockChanged{{Delta: 3, Stock: 3}, {Delta: -1, Stock: 2}, {Delta: -2, Stock: 0}}
+	if len(events) != len(want) {
+		t.Fatalf("got %d stock events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		if e.ProductID != kit.ID() || e.Reason != domain.StockChangeBundle || e.MovementID != 0 ||
+			e.Delta != want[i].Delta || e.Stock != want[i].Stock {
+			t.Errorf("event %d: got %+v, want delta %d and stock %d", i, e, want[i].Delta, want[i].Stock)
+		}
+	}
+}
+
+func mustGet(t *testing.T, products domain.ProductRepository, id uint64) *domain.Product {
+	t.Helper()
+	product, err := products.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product == nil {
+		t.Fatalf("product %d not found", id)
+	}
+	return product
+}
+
+func bundleStockEvents(t *testing.T, db *sql.DB, bundleID uint64) []domain.StockChanged {
+	t.Helper()
+	query := `
+		SELECT payload FROM outbox_events
+		WHERE aggregate_type = $1 AND aggregate_id = $2 AND event_type = $3
+		ORDER BY id`
+	rows, err := db.Query(query, domain.AggregateProduct, strconv.FormatUint(bundleID, 10), domain.EventProductStockChanged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var events []domain.StockChanged
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			t.Fatal(err)
+		}
+		var event domain.StockChanged
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return events
+}
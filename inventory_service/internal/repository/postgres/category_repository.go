@@ -4,8 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+	"time"
 )
 
+const categoryColumns = `c.id, c.name, c.description, c.parent_id, c.created_at, c.updated_at, c.version, c.external_id, c.deleted_at, c.slug`
+
+// categoryProductCount counts a category's live products. It follows
+// categoryColumns wherever a category is read back.
+const categoryProductCount = `(SELECT COUNT(*) FROM products cp WHERE cp.category_id = c.id AND cp.is_deleted = false)`
+
+// categoryTreeLock serializes changes to the tree's shape, so that two
+// concurrent moves cannot together introduce a cycle that neither would on
+// its own, and a category cannot be created under a parent being moved or
+// trashed.
+const categoryTreeLock = 7101
+
 type categoryRepository struct {
 	db *sql.DB
 }
@@ -14,53 +28,122 @@ func NewCategoryRepository(db *sql.DB) domain.CategoryRepository {
 	return &categoryRepository{db: db}
 }
 
+func scanCategory(row rowScanner) (*domain.Category, error) {
+	var id uint64
+	var name string
+	var description sql.NullString
+	var parentID sql.NullInt64
+	var createdAt, updatedAt time.Time
+	var version int
+	var externalID sql.NullString
+	var deletedAt sql.NullTime
+	var slug string
+	var productCount int
+
+	if err := row.Scan(&id, &name, &description, &parentID, &createdAt, &updatedAt, &version, &externalID, &deletedAt, &slug, &productCount); err != nil {
+		return nil, err
+	}
+
+	category := domain.NewCategory(name, description.String)
+	category.SetID(id)
+	category.SetExternalID(externalID.String)
+	category.SetSlug(slug)
+	category.SetParentID(uint64(parentID.Int64))
+	category.SetVersion(version)
+	category.SetProductCount(productCount)
+	if deletedAt.Valid {
+		category.MarkDeleted(deletedAt.Time)
+	}
+	category.SetTimestamps(createdAt, updatedAt)
+	return category, nil
+}
+
 func (r *categoryRepository) Create(ctx context.Context, category *domain.Category) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Under the tree lock the parent cannot be moved or trashed between the
+	// check and the insert.
+	if category.ParentID() != 0 {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+			return err
+		}
+		var live bool
+		query := `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND is_deleted = false)`
+		if err := tx.QueryRowContext(ctx, query, category.ParentID()).Scan(&live); err != nil {
+			return err
+		}
+		if !live {
+			return domain.ErrParentCategoryNotFound
+		}
+	}
+
+	slug, err := categorySlugs.assign(ctx, tx, 0, category.Slug(), category.Name())
+	if err != nil {
+		return err
+	}
+	if err := categorySlugs.claim(ctx, tx, slug); err != nil {
+		return err
+	}
+
 	query := `
-		INSERT INTO categories (name, description, created_at, updated_at)
-		VALUES ($1, $2, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO categories (name, description, parent_id, external_id, slug, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at, version`
 
 	var id uint64
-	var createdAt, updatedAt string
-	err := r.db.QueryRowContext(
+	var createdAt, updatedAt time.Time
+	var version int
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		category.Name(),
 		category.Description(),
-	).Scan(&id, &createdAt, &updatedAt)
+		nullableID(category.ParentID()),
+		nullableString(category.ExternalID()),
+		slug,
+	).Scan(&id, &createdAt, &updatedAt, &version)
 	if err != nil {
+		return categoryConflict(err)
+	}
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
 	category.SetID(id)
+	category.SetSlug(slug)
+	category.SetVersion(version)
+	category.SetTimestamps(createdAt, updatedAt)
 	return nil
 }
 
 func (r *categoryRepository) GetByID(ctx context.Context, id uint64) (*domain.Category, error) {
-	category := &domain.Category{}
+	query := `
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM categories c
+		WHERE c.id = $1 AND c.is_deleted = false`
 
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) GetByExternalID(ctx context.Context, externalID string) (*domain.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
-		FROM categories
-		WHERE id = $1 AND is_deleted = false`
-
-	var createdAt, updatedAt string
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		func(id uint64) error {
-			category.SetID(id)
-			return nil
-		},
-		func(name string) error {
-			category.Update(name, category.Description())
-			return nil
-		},
-		func(description string) error {
-			category.Update(category.Name(), description)
-			return nil
-		},
-		&createdAt,
-		&updatedAt,
-	)
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM categories c
+		WHERE c.external_id = $1 AND c.is_deleted = false`
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, externalID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -73,10 +156,10 @@ func (r *categoryRepository) GetByID(ctx context.Context, id uint64) (*domain.Ca
 
 func (r *categoryRepository) List(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
 	query := `
-		SELECT id, name, description, created_at, updated_at
-		FROM categories
-		WHERE is_deleted = false
-		ORDER BY created_at DESC
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM categories c
+		WHERE c.is_deleted = false
+		ORDER BY c.created_at DESC
 		LIMIT $1 OFFSET $2`
 
 	rows, err := r.db.QueryContext(ctx, query, limit, offset)
@@ -87,24 +170,7 @@ func (r *categoryRepository) List(ctx context.Context, offset, limit int) ([]*do
 
 	var categories []*domain.Category
 	for rows.Next() {
-		category := &domain.Category{}
-		var createdAt, updatedAt string
-		err := rows.Scan(
-			func(id uint64) error {
-				category.SetID(id)
-				return nil
-			},
-			func(name string) error {
-				category.Update(name, category.Description())
-				return nil
-			},
-			func(description string) error {
-				category.Update(category.Name(), description)
-				return nil
-			},
-			&createdAt,
-			&updatedAt,
-		)
+		category, err := scanCategory(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -115,50 +181,422 @@ func (r *categoryRepository) List(ctx context.Context, offset, limit int) ([]*do
 }
 
 func (r *categoryRepository) Update(ctx context.Context, category *domain.Category) error {
-	query := `
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previousSlug string
+	query := `SELECT slug FROM categories WHERE id = $1 AND version = $2 AND is_deleted = false FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, category.ID(), category.Version()).Scan(&previousSlug)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, category.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	slug, err := categorySlugs.assign(ctx, tx, category.ID(), category.Slug(), category.Name())
+	if err != nil {
+		return err
+	}
+
+	query = `
 		UPDATE categories
-		SET name = $1, description = $2, updated_at = NOW()
-		WHERE id = $3 AND is_deleted = false
-		RETURNING updated_at`
+		SET name = $1, description = $2, external_id = $3, slug = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $5
+		RETURNING version, updated_at`
 
-	var updatedAt string
-	err := r.db.QueryRowContext(
+	var updatedAt time.Time
+	var version int
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		category.Name(),
 		category.Description(),
+		nullableString(category.ExternalID()),
+		slug,
 		category.ID(),
-	).Scan(&updatedAt)
+	).Scan(&version, &updatedAt)
+	if err != nil {
+		return categoryConflict(err)
+	}
+	if err := categorySlugs.move(ctx, tx, category.ID(), previousSlug, slug); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
-	if err == sql.ErrNoRows {
-		return sql.ErrNoRows
+	category.SetSlug(slug)
+	category.SetVersion(version)
+	category.SetTimestamps(category.CreatedAt(), updatedAt)
+	return nil
+}
+
+func (r *categoryRepository) Delete(ctx context.Context, id uint64, version int, options domain.CategoryDeleteOptions) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The subtree must not change shape while it is being deleted.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+		return err
+	}
+
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND version = $2 AND is_deleted = false)`
+	if err := tx.QueryRowContext(ctx, query, id, version).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return r.missOrConflict(ctx, id)
 	}
+
+	// Locking the subtree rows waits out, and then blocks, products being
+	// created in or moved into it, since those take a key share lock on
+	// their category.
+	query = `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.is_deleted = false
+		)
+		SELECT c.id FROM categories c JOIN subtree s ON c.id = s.id
+		FOR UPDATE OF c`
+
+	rows, err := tx.QueryContext(ctx, query, id)
 	if err != nil {
 		return err
 	}
+	var ids []int64
+	for rows.Next() {
+		var categoryID int64
+		if err := rows.Scan(&categoryID); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, categoryID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	return nil
+	switch options.Policy {
+	case domain.DeleteReject:
+		var hasProducts bool
+		query = `SELECT EXISTS(SELECT 1 FROM products WHERE category_id = ANY($1) AND is_deleted = false)`
+		if err := tx.QueryRowContext(ctx, query, pq.Array(ids)).Scan(&hasProducts); err != nil {
+			return err
+		}
+		if hasProducts {
+			return domain.ErrCategoryHasProducts
+		}
+	case domain.DeleteReassign:
+		for _, categoryID := range ids {
+			if uint64(categoryID) == options.TargetID {
+				return domain.ErrInvalidReassignTarget
+			}
+		}
+		var targetDeleted bool
+		query = `SELECT is_deleted FROM categories WHERE id = $1 FOR SHARE`
+		err := tx.QueryRowContext(ctx, query, options.TargetID).Scan(&targetDeleted)
+		if err == sql.ErrNoRows || targetDeleted {
+			return domain.ErrReassignTargetNotFound
+		}
+		if err != nil {
+			return err
+		}
+		query = `
+			UPDATE products
+			SET category_id = $1, version = version + 1, updated_at = NOW()
+			WHERE category_id = ANY($2) AND is_deleted = false
+			RETURNING id, version, COALESCE(category_id, 0), updated_at`
+		rows, err := tx.QueryContext(ctx, query, options.TargetID, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		if err := writeLifecycleEvents(ctx, tx, domain.EventProductCategoryChanged, rows); err != nil {
+			return err
+		}
+	case domain.DeleteCascade:
+		query = `
+			UPDATE products
+			SET is_deleted = true, deleted_at = NOW(), version = version + 1, updated_at = NOW()
+			WHERE category_id = ANY($1) AND is_deleted = false
+			RETURNING id, version, COALESCE(category_id, 0), deleted_at`
+		rows, err := tx.QueryContext(ctx, query, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		if err := writeLifecycleEvents(ctx, tx, domain.EventProductDeleted, rows); err != nil {
+			return err
+		}
+	default:
+		return domain.ErrInvalidDeletePolicy
+	}
+
+	query = `
+		UPDATE categories
+		SET is_deleted = true, deleted_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = ANY($1)
+		RETURNING id, version, COALESCE(parent_id, 0), deleted_at`
+	rows, err = tx.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	if err := writeCategoryLifecycleEvents(ctx, tx, domain.EventCategoryDeleted, rows); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *categoryRepository) Delete(ctx context.Context, id uint64) error {
+func (r *categoryRepository) GetBySlug(ctx context.Context, slug string) (*domain.Category, error) {
 	query := `
-		UPDATE categories
-		SET is_deleted = true, updated_at = NOW()
-		WHERE id = $1 AND is_deleted = false`
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM categories c
+		WHERE c.slug = $1 AND c.is_deleted = false`
+
+	category, err := scanCategory(r.db.QueryRowContext(ctx, query, slug))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+func (r *categoryRepository) SlugRedirect(ctx context.Context, slug string) (string, error) {
+	return categorySlugs.redirect(ctx, r.db, slug)
+}
+
+func (r *categoryRepository) ListDeleted(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM categories c
+		WHERE c.is_deleted = true
+		ORDER BY c.deleted_at DESC, c.id DESC
+		LIMIT $1 OFFSET $2`
+
+	return r.queryCategories(ctx, query, limit, offset)
+}
+
+func (r *categoryRepository) Restore(ctx context.Context, id uint64, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	// The subtree must not change shape while it is being restored.
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+		return err
+	}
+
+	var parentID sql.NullInt64
+	var deletedAt time.Time
+	query := `SELECT parent_id, deleted_at FROM categories WHERE id = $1 AND version = $2 AND is_deleted = true FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, id, version).Scan(&parentID, &deletedAt)
+	if err == sql.ErrNoRows {
+		return r.missOrConflictIn(ctx, id, true)
+	}
 	if err != nil {
 		return err
 	}
 
-	rows, err := result.RowsAffected()
+	if parentID.Valid {
+		var parentDeleted bool
+		query = `SELECT is_deleted FROM categories WHERE id = $1 FOR SHARE`
+		if err := tx.QueryRowContext(ctx, query, parentID.Int64).Scan(&parentDeleted); err != nil {
+			return err
+		}
+		if parentDeleted {
+			return domain.ErrCategoryTrashed
+		}
+	}
+
+	// Descendants deleted by the same delete share its deleted_at and come
+	// back with the category. Those deleted on their own before it stay in
+	// the trash, and so do their descendants.
+	query = `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+			WHERE c.is_deleted = true AND c.deleted_at = $2
+		)
+		UPDATE categories
+		SET is_deleted = false, deleted_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id IN (SELECT id FROM subtree)
+		RETURNING id, version, COALESCE(parent_id, 0), updated_at`
+	rows, err := tx.QueryContext(ctx, query, id, deletedAt)
+	if err != nil {
+		return categoryConflict(err)
+	}
+	if err := writeCategoryLifecycleEvents(ctx, tx, domain.EventCategoryRestored, rows); err != nil {
+		return categoryConflict(err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *categoryRepository) Tree(ctx context.Context, rootID uint64) ([]*domain.Category, error) {
+	root := `(c.parent_id IS NULL OR NOT EXISTS (
+		SELECT 1 FROM categories pc WHERE pc.id = c.parent_id AND pc.is_deleted = false))`
+	var args []interface{}
+	if rootID != 0 {
+		root = "c.id = $1"
+		args = append(args, rootID)
+	}
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT ` + categoryColumns + `
+			FROM categories c
+			WHERE ` + root + ` AND c.is_deleted = false
+			UNION ALL
+			SELECT ` + categoryColumns + `
+			FROM categories c
+			JOIN tree t ON c.parent_id = t.id
+			WHERE c.is_deleted = false
+		)
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM tree c
+		ORDER BY c.name, c.id`
+
+	return r.queryCategories(ctx, query, args...)
+}
+
+func (r *categoryRepository) Path(ctx context.Context, id uint64) ([]*domain.Category, error) {
+	query := `
+		WITH RECURSIVE path AS (
+			SELECT ` + categoryColumns + `, 0 AS depth
+			FROM categories c
+			WHERE c.id = $1 AND c.is_deleted = false
+			UNION ALL
+			SELECT ` + categoryColumns + `, p.depth + 1
+			FROM categories c
+			JOIN path p ON c.id = p.parent_id
+			WHERE c.is_deleted = false
+		)
+		SELECT ` + categoryColumns + `, ` + categoryProductCount + `
+		FROM path c
+		ORDER BY c.depth DESC`
+
+	return r.queryCategories(ctx, query, id)
+}
+
+func (r *categoryRepository) Move(ctx context.Context, category *domain.Category) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if rows == 0 {
-		return sql.ErrNoRows
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, categoryTreeLock); err != nil {
+		return err
+	}
+
+	if category.ParentID() != 0 {
+		// The new parent must be live and must not sit inside the subtree
+		// being moved.
+		query := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM categories WHERE id = $1 AND is_deleted = false
+				UNION ALL
+				SELECT c.id, c.parent_id FROM categories c JOIN ancestors a ON c.id = a.parent_id
+			)
+			SELECT COUNT(*), COUNT(*) FILTER (WHERE id = $2) FROM ancestors`
+
+		var depth, hits int
+		if err := tx.QueryRowContext(ctx, query, category.ParentID(), category.ID()).Scan(&depth, &hits); err != nil {
+			return err
+		}
+		if depth == 0 {
+			return domain.ErrParentCategoryNotFound
+		}
+		if hits > 0 {
+			return domain.ErrCategoryCycle
+		}
 	}
 
+	query := `
+		UPDATE categories
+		SET parent_id = $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND version = $3 AND is_deleted = false
+		RETURNING version, updated_at`
+
+	var updatedAt time.Time
+	var version int
+	err = tx.QueryRowContext(ctx, query, nullableID(category.ParentID()), category.ID(), category.Version()).
+		Scan(&version, &updatedAt)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, category.ID())
+	}
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	category.SetVersion(version)
+	category.SetTimestamps(category.CreatedAt(), updatedAt)
 	return nil
 }
+
+func (r *categoryRepository) queryCategories(ctx context.Context, query string, args ...interface{}) ([]*domain.Category, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*domain.Category
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	return categories, rows.Err()
+}
+
+// categoryConflict maps unique violations on the category identifiers to
+// their domain errors.
+func categoryConflict(err error) error {
+	switch {
+	case isUniqueViolationOn(err, "idx_categories_slug"):
+		return domain.ErrDuplicateSlug
+	case isUniqueViolation(err):
+		return domain.ErrDuplicateExternalID
+	}
+	return err
+}
+
+func (r *categoryRepository) missOrConflict(ctx context.Context, id uint64) error {
+	return r.missOrConflictIn(ctx, id, false)
+}
+
+// missOrConflictIn is missOrConflict for live categories or, with deleted,
+// for categories in the trash.
+func (r *categoryRepository) missOrConflictIn(ctx context.Context, id uint64, deleted bool) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND is_deleted = $2)`
+	if err := r.db.QueryRowContext(ctx, query, id, deleted).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrCategoryNotFound
+	}
+	return domain.ErrVersionConflict
+}
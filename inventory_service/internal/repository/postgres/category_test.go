@@ -0,0 +1,66 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"testing"
+)
+
+func TestCategorySubtreeEvents(t *testing.T) {
+	db := openTestDB(t)
+	emptyCatalog(t, db)
+	ctx := context.Background()
+	categories := NewCategoryRepository(db)
+
+	create := func(name string, parentID uint64) *domain.Category {
+		t.Helper()
+		category := domain.NewCategory(name, "")
+		category.SetParentID(parentID)
+		if err := categories.Create(ctx, category); err != nil {
+			t.Fatal(err)
+		}
+		return category
+	}
+	root := create("Electronics", 0)
+	phones := create("Phones", root.ID())
+
+	if err := categories.Delete(ctx, root.ID(), root.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteReject}); err != nil {
+		t.Fatal(err)
+	}
+	if err := categories.Restore(ctx, root.ID(), root.Version()+1); err != nil {
+		t.Fatal(err)
+	}
+
+	query := `
+		SELECT aggregate_id::bigint, event_type FROM outbox_events
+		WHERE aggregate_type = $1
+		ORDER BY id`
+	rows, err := db.Query(query, domain.AggregateCategory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	counts := make(map[string]map[uint64]int)
+	for rows.Next() {
+		var id uint64
+		var eventType string
+		if err := rows.Scan(&id, &eventType); err != nil {
+			t.Fatal(err)
+		}
+		if counts[eventType] == nil {
+			counts[eventType] = make(map[uint64]int)
+		}
+		counts[eventType][id]++
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, eventType := range []string{domain.EventCategoryDeleted, domain.EventCategoryRestored} {
+		if got := counts[eventType]; len(got) != 2 || got[root.ID()] != 1 || got[phones.ID()] != 1 {
+			t.Errorf("got %s events %v, want one for each category", eventType, got)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+//go:build integration
+
+package postgres
+
+import (
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/repotest"
+	_ "github.com/lib/pq"
+	"os"
+	"testing"
+)
+
+// TestRepositoryContract runs against the database INVENTORY_TEST_DATABASE_URL
+// points at, which must have the migrations applied. Every case empties the
+// catalog first, so it must not be a database anyone else uses.
+func TestRepositoryContract(t *testing.T) {
+	db := openTestDB(t)
+	repotest.Run(t, func(t *testing.T) repotest.Repositories {
+		emptyCatalog(t, db)
+		return repotest.Repositories{
+			Products:   NewProductRepository(db),
+			Categories: NewCategoryRepository(db),
+		}
+	})
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("INVENTORY_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("INVENTORY_TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// emptyCatalog truncates the catalog. CASCADE empties every table that
+// refers to it, ledgers and redirects included.
+func emptyCatalog(t *testing.T, db *sql.DB) {
+	t.Helper()
+	if _, err := db.Exec(`TRUNCATE categories, products, outbox_events RESTART IDENTITY CASCADE`); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,18 @@
+package postgres
+
+import (
+	"errors"
+	"github.com/lib/pq"
+)
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// isUniqueViolationOn reports a unique violation of the named index or
+// constraint.
+func isUniqueViolationOn(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
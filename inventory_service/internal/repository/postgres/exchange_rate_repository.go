@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"math/big"
+)
+
+// rateScale is the number of decimal places exchange rates are stored with.
+const rateScale = 12
+
+type exchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) domain.ExchangeRateRepository {
+	return &exchangeRateRepository{db: db}
+}
+
+func (r *exchangeRateRepository) List(ctx context.Context) (domain.ExchangeRates, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate, actor, updated_at
+		FROM exchange_rates
+		ORDER BY base_currency, quote_currency`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := domain.ExchangeRates{}
+	for rows.Next() {
+		rate := &domain.ExchangeRate{}
+		var base, quote, value string
+		if err := rows.Scan(&base, &quote, &value, &rate.Actor, &rate.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rate.Base, rate.Quote = money.Currency(base), money.Currency(quote)
+		var ok bool
+		if rate.Rate, ok = new(big.Rat).SetString(value); !ok {
+			return nil, domain.ErrInvalidExchangeRate
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func (r *exchangeRateRepository) Set(ctx context.Context, rate *domain.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, actor, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (base_currency, quote_currency)
+		DO UPDATE SET rate = EXCLUDED.rate, actor = EXCLUDED.actor, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at`
+
+	return r.db.QueryRowContext(ctx, query, string(rate.Base), string(rate.Quote),
+		rate.Rate.FloatString(rateScale), rate.Actor).Scan(&rate.UpdatedAt)
+}
+
+func (r *exchangeRateRepository) Delete(ctx context.Context, base, quote money.Currency) error {
+	query := `DELETE FROM exchange_rates WHERE base_currency = $1 AND quote_currency = $2`
+	result, err := r.db.ExecContext(ctx, query, string(base), string(quote))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrExchangeRateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import "testing"
+
+func TestHighlightHTMLEscapesProductText(t *testing.T) {
+	cases := map[string]string{
+		"Desk " + highlightStart + "Lamp" + highlightStop:                        "Desk <mark>Lamp</mark>",
+		`<script>alert("x")</script> ` + highlightStart + "lamp" + highlightStop: `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; <mark>lamp</mark>`,
+		"Tom & " + highlightStart + "Jerry" + highlightStop + "'s":               "Tom &amp; <mark>Jerry</mark>&#39;s",
+		"no match": "no match",
+	}
+	for fragment, want := range cases {
+		if got := highlightHTML(fragment); got != want {
+			t.Errorf("highlightHTML(%q) = %q, want %q", fragment, got, want)
+		}
+	}
+}
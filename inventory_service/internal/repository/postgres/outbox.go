@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+)
+
+// writeProductEvent adds a product event to the outbox inside tx, so that it
+// is published if and only if the change it describes commits. Callers hold
+// the product's row lock, which keeps the product's events in commit order.
+func writeProductEvent(ctx context.Context, tx *sql.Tx, eventType string, productID uint64, payload interface{}) error {
+	event, err := domain.NewProductEvent(eventType, productID, payload)
+	if err != nil {
+		return err
+	}
+	return outbox.Write(ctx, tx, event)
+}
+
+// writeBundleStockChanged publishes a change of a bundle's derived stock.
+// Nothing is written when the stock stayed the same.
+func writeBundleStockChanged(ctx context.Context, tx *sql.Tx, bundleID uint64, previous, stock int) error {
+	if previous == stock {
+		return nil
+	}
+	payload := domain.NewBundleStockChanged(bundleID, previous, stock, domain.ActorFromContext(ctx))
+	return writeProductEvent(ctx, tx, domain.EventProductStockChanged, bundleID, payload)
+}
+
+// writeLifecycleEvents writes one lifecycle event per product row returned
+// by a bulk update as (id, version, category_id, updated_at).
+func writeLifecycleEvents(ctx context.Context, tx *sql.Tx, eventType string, rows *sql.Rows) error {
+	var payloads []*domain.ProductLifecycle
+	for rows.Next() {
+		payload := &domain.ProductLifecycle{Actor: domain.ActorFromContext(ctx)}
+		if err := rows.Scan(&payload.ID, &payload.Version, &payload.CategoryID, &payload.At); err != nil {
+			rows.Close()
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, payload := range payloads {
+		if err := writeProductEvent(ctx, tx, eventType, payload.ID, payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCategoryLifecycleEvents writes one lifecycle event per category row
+// returned by a bulk update as (id, version, parent_id, changed_at).
+func writeCategoryLifecycleEvents(ctx context.Context, tx *sql.Tx, eventType string, rows *sql.Rows) error {
+	var payloads []*domain.CategoryLifecycle
+	for rows.Next() {
+		payload := &domain.CategoryLifecycle{Actor: domain.ActorFromContext(ctx)}
+		if err := rows.Scan(&payload.ID, &payload.Version, &payload.ParentID, &payload.At); err != nil {
+			rows.Close()
+			return err
+		}
+		payloads = append(payloads, payload)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, payload := range payloads {
+		event, err := domain.NewCategoryEvent(eventType, payload.ID, payload)
+		if err != nil {
+			return err
+		}
+		if err := outbox.Write(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/lib/pq"
+)
+
+type priceListRepository struct {
+	db *sql.DB
+}
+
+func NewPriceListRepository(db *sql.DB) domain.PriceListRepository {
+	return &priceListRepository{db: db}
+}
+
+func (r *priceListRepository) ListForProduct(ctx context.Context, productID uint64) ([]*domain.ListPrice, error) {
+	query := `
+		SELECT product_id, price, currency, updated_at
+		FROM product_list_prices
+		WHERE product_id = $1
+		ORDER BY currency`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []*domain.ListPrice{}
+	for rows.Next() {
+		p := &domain.ListPrice{}
+		var price, currency string
+		if err := rows.Scan(&p.ProductID, &price, &currency, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if p.Price, err = parseMoney(price, currency); err != nil {
+			return nil, err
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
+
+func (r *priceListRepository) ListForProducts(ctx context.Context, productIDs []uint64, currency money.Currency) (map[uint64]money.Money, error) {
+	prices := make(map[uint64]money.Money)
+	if len(productIDs) == 0 {
+		return prices, nil
+	}
+
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+	query := `SELECT product_id, price FROM product_list_prices WHERE product_id = ANY($1) AND currency = $2`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), string(currency))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID uint64
+		var price string
+		if err := rows.Scan(&productID, &price); err != nil {
+			return nil, err
+		}
+		if prices[productID], err = parseMoney(price, string(currency)); err != nil {
+			return nil, err
+		}
+	}
+
+	return prices, rows.Err()
+}
+
+func (r *priceListRepository) Set(ctx context.Context, price *domain.ListPrice) error {
+	query := `
+		INSERT INTO product_list_prices (product_id, currency, price, updated_at)
+		SELECT $1, $2, $3, NOW()
+		WHERE EXISTS (SELECT 1 FROM products WHERE id = $1 AND is_deleted = false)
+		ON CONFLICT (product_id, currency) DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at
+		RETURNING updated_at`
+
+	err := r.db.QueryRowContext(ctx, query, price.ProductID, string(price.Price.Currency()), price.Price.Decimal()).Scan(&price.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	return err
+}
+
+func (r *priceListRepository) Delete(ctx context.Context, productID uint64, currency money.Currency) error {
+	query := `DELETE FROM product_list_prices WHERE product_id = $1 AND currency = $2`
+	result, err := r.db.ExecContext(ctx, query, productID, string(currency))
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrListPriceNotFound
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+type priceRepository struct {
+	db *sql.DB
+}
+
+func NewPriceRepository(db *sql.DB) domain.PriceRepository {
+	return &priceRepository{db: db}
+}
+
+const priceScheduleColumns = `id, product_id, price, currency, starts_at, ends_at, status, previous_price, actor, created_at`
+
+func scanPriceSchedule(row rowScanner) (*domain.PriceSchedule, error) {
+	s := &domain.PriceSchedule{}
+	var price, currency, status string
+	var endsAt sql.NullTime
+	var previous sql.NullString
+	err := row.Scan(&s.ID, &s.ProductID, &price, &currency, &s.StartsAt, &endsAt, &status, &previous, &s.Actor, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if s.Price, err = parseMoney(price, currency); err != nil {
+		return nil, err
+	}
+	s.Status = domain.PriceScheduleStatus(status)
+	if endsAt.Valid {
+		s.EndsAt = &endsAt.Time
+	}
+	if previous.Valid {
+		previousPrice, err := parseMoney(previous.String, currency)
+		if err != nil {
+			return nil, err
+		}
+		s.PreviousPrice = &previousPrice
+	}
+	return s, nil
+}
+
+func (r *priceRepository) ListChanges(ctx context.Context, productID uint64) ([]*domain.PriceChange, error) {
+	query := `
+		SELECT id, product_id, price, currency, source, COALESCE(schedule_id, 0), actor, changed_at
+		FROM price_changes
+		WHERE product_id = $1
+		ORDER BY changed_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*domain.PriceChange
+	for rows.Next() {
+		c := &domain.PriceChange{}
+		var price, currency, source string
+		if err := rows.Scan(&c.ID, &c.ProductID, &price, &currency, &source, &c.ScheduleID, &c.Actor, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		var err error
+		if c.Price, err = parseMoney(price, currency); err != nil {
+			return nil, err
+		}
+		c.Source = domain.PriceChangeSource(source)
+		changes = append(changes, c)
+	}
+
+	return changes, rows.Err()
+}
+
+func (r *priceRepository) ListSchedules(ctx context.Context, productID uint64, openOnly bool) ([]*domain.PriceSchedule, error) {
+	query := `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE product_id = $1`
+	if openOnly {
+		query += ` AND status IN ('pending', 'active')`
+	}
+	query += ` ORDER BY starts_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []*domain.PriceSchedule
+	for rows.Next() {
+		s, err := scanPriceSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+
+	return schedules, rows.Err()
+}
+
+func (r *priceRepository) CreateSchedule(ctx context.Context, schedule *domain.PriceSchedule) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the product serialises schedule creation per product, so two
+	// overlapping schedules cannot both pass the check below.
+	var currency string
+	var derived bool
+	query := `
+		SELECT p.currency, EXISTS(SELECT 1 FROM bundles b WHERE b.product_id = p.id AND b.pricing = 'discount')
+		FROM products p
+		WHERE p.id = $1 AND p.is_deleted = false
+		FOR UPDATE OF p`
+	err = tx.QueryRowContext(ctx, query, schedule.ProductID).Scan(&currency, &derived)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if derived {
+		return domain.ErrBundlePriceDerived
+	}
+	if money.Currency(currency) != schedule.Price.Currency() {
+		return domain.ErrCurrencyMismatch
+	}
+
+	query = `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE product_id = $1 AND status IN ('pending', 'active')`
+	rows, err := tx.QueryContext(ctx, query, schedule.ProductID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		existing, err := scanPriceSchedule(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		if existing.Overlaps(schedule) {
+			rows.Close()
+			return domain.ErrPriceScheduleOverlap
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var endsAt interface{}
+	if schedule.EndsAt != nil {
+		endsAt = *schedule.EndsAt
+	}
+	query = `
+		INSERT INTO price_schedules (product_id, price, currency, starts_at, ends_at, status, actor, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(ctx, query, schedule.ProductID, schedule.Price.Decimal(), string(schedule.Price.Currency()), schedule.StartsAt, endsAt,
+		string(schedule.Status), schedule.Actor).Scan(&schedule.ID, &schedule.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *priceRepository) CancelSchedule(ctx context.Context, productID, scheduleID uint64) (*domain.PriceSchedule, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + priceScheduleColumns + ` FROM price_schedules WHERE id = $1 AND product_id = $2 FOR UPDATE`
+	schedule, err := scanPriceSchedule(tx.QueryRowContext(ctx, query, scheduleID, productID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrPriceScheduleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !schedule.Open() {
+		return nil, domain.ErrPriceScheduleClosed
+	}
+
+	if schedule.Status == domain.PriceScheduleActive {
+		if err := restorePrice(ctx, tx, schedule); err != nil {
+			return nil, err
+		}
+	}
+	if err := setScheduleStatus(ctx, tx, schedule, domain.PriceScheduleCanceled); err != nil {
+		return nil, err
+	}
+
+	return schedule, tx.Commit()
+}
+
+// ApplyDue handles one due schedule per transaction, locking it with SKIP
+// LOCKED so that several service instances can run the scheduler at once.
+func (r *priceRepository) ApplyDue(ctx context.Context, now time.Time) (int, error) {
+	applied := 0
+	for {
+		ok, err := r.applyNext(ctx, now)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			return applied, nil
+		}
+		applied++
+	}
+}
+
+func (r *priceRepository) applyNext(ctx context.Context, now time.Time) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + priceScheduleColumns + `
+		FROM price_schedules
+		WHERE (status = 'pending' AND starts_at <= $1) OR (status = 'active' AND ends_at <= $1)
+		ORDER BY CASE WHEN status = 'active' THEN ends_at ELSE starts_at END, id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED`
+	schedule, err := scanPriceSchedule(tx.QueryRowContext(ctx, query, now))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	next, _ := schedule.Transition(now)
+	switch {
+	case schedule.Status == domain.PriceScheduleActive:
+		if err := restorePrice(ctx, tx, schedule); err != nil {
+			return false, err
+		}
+		return true, r.finish(ctx, tx, schedule, next)
+	case next == domain.PriceScheduleExpired:
+		return true, r.finish(ctx, tx, schedule, next)
+	}
+
+	var price, currency string
+	query = `SELECT price, currency FROM products WHERE id = $1 AND is_deleted = false FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, schedule.ProductID).Scan(&price, &currency)
+	if err == sql.ErrNoRows {
+		// The product is gone; there is nothing left to reprice.
+		return true, r.finish(ctx, tx, schedule, domain.PriceScheduleCanceled)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	previous, err := parseMoney(price, currency)
+	if err != nil {
+		return false, err
+	}
+
+	if err := setProductPrice(ctx, tx, schedule, schedule.Price); err != nil {
+		return false, err
+	}
+	schedule.PreviousPrice = &previous
+	_, err = tx.ExecContext(ctx, `UPDATE price_schedules SET previous_price = $1 WHERE id = $2`, previous.Decimal(), schedule.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, r.finish(ctx, tx, schedule, next)
+}
+
+func (r *priceRepository) finish(ctx context.Context, tx *sql.Tx, schedule *domain.PriceSchedule, status domain.PriceScheduleStatus) error {
+	if err := setScheduleStatus(ctx, tx, schedule, status); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setScheduleStatus(ctx context.Context, tx *sql.Tx, schedule *domain.PriceSchedule, status domain.PriceScheduleStatus) error {
+	_, err := tx.ExecContext(ctx, `UPDATE price_schedules SET status = $1, updated_at = NOW() WHERE id = $2`, string(status), schedule.ID)
+	if err != nil {
+		return err
+	}
+	schedule.Status = status
+	return nil
+}
+
+// restorePrice puts back the price an active schedule replaced, unless the
+// price was changed by hand while the schedule was in force.
+func restorePrice(ctx context.Context, tx *sql.Tx, schedule *domain.PriceSchedule) error {
+	if schedule.PreviousPrice == nil {
+		return nil
+	}
+	var price, currency string
+	query := `SELECT price, currency FROM products WHERE id = $1 AND is_deleted = false FOR UPDATE`
+	err := tx.QueryRowContext(ctx, query, schedule.ProductID).Scan(&price, &currency)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	current, err := parseMoney(price, currency)
+	if err != nil {
+		return err
+	}
+	if !current.Equal(schedule.Price) {
+		return nil
+	}
+	return setProductPrice(ctx, tx, schedule, *schedule.PreviousPrice)
+}
+
+func setProductPrice(ctx context.Context, tx *sql.Tx, schedule *domain.PriceSchedule, price money.Money) error {
+	query := `UPDATE products SET price = $1, version = version + 1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.ExecContext(ctx, query, price.Decimal(), schedule.ProductID); err != nil {
+		return err
+	}
+	err := insertPriceChange(ctx, tx, &domain.PriceChange{
+		ProductID:  schedule.ProductID,
+		Price:      price,
+		Source:     domain.PriceChangeScheduled,
+		ScheduleID: schedule.ID,
+		Actor:      schedule.Actor,
+	})
+	if err != nil {
+		return err
+	}
+	return refreshBundlePrices(ctx, tx, schedule.ProductID)
+}
+
+// insertPriceChange appends to the price history inside tx, so that it
+// commits or rolls back together with the price it describes, and publishes
+// the change through the outbox.
+func insertPriceChange(ctx context.Context, tx *sql.Tx, change *domain.PriceChange) error {
+	query := `
+		INSERT INTO price_changes (product_id, price, currency, source, schedule_id, actor, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		RETURNING id, changed_at`
+
+	err := tx.QueryRowContext(ctx, query, change.ProductID, change.Price.Decimal(), string(change.Price.Currency()), string(change.Source),
+		nullableID(change.ScheduleID), change.Actor).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		return err
+	}
+	return writeProductEvent(ctx, tx, domain.EventProductPriceChanged, change.ProductID, domain.NewPriceChanged(change))
+}
+
+// recordPriceChange writes a manual history entry for a price column that
+// was set directly, and re-derives the bundles priced off it. Nothing happens
+// when the price did not change.
+func recordPriceChange(ctx context.Context, tx *sql.Tx, productID uint64, previous, price money.Money) error {
+	if previous.Equal(price) {
+		return nil
+	}
+	err := insertPriceChange(ctx, tx, &domain.PriceChange{
+		ProductID: productID,
+		Price:     price,
+		Source:    domain.PriceChangeManual,
+		Actor:     domain.ActorFromContext(ctx),
+	})
+	if err != nil {
+		return err
+	}
+	return refreshBundlePrices(ctx, tx, productID)
+}
@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/lib/pq"
+)
+
+// Facets computes the requested counts over live products. Every facet is
+// counted against the filter minus its own dimension.
+func (r *productRepository) Facets(ctx context.Context, filter domain.ProductFilter, request domain.FacetRequest) (*domain.ProductFacets, error) {
+	facets := &domain.ProductFacets{}
+	var err error
+
+	if request.Categories {
+		if facets.Categories, err = r.categoryFacets(ctx, filter.WithoutCategories()); err != nil {
+			return nil, err
+		}
+	}
+	if request.Price {
+		if facets.Prices, err = r.priceFacets(ctx, filter.WithoutPrice(), request.PriceBuckets); err != nil {
+			return nil, err
+		}
+		facets.PriceCurrency = filter.PriceCurrency()
+	}
+	if request.Stock {
+		if facets.Stock, err = r.stockFacet(ctx, filter.WithoutStock()); err != nil {
+			return nil, err
+		}
+	}
+	if request.AllAttributes || len(request.AttributeCodes) > 0 {
+		if facets.Attributes, err = r.attributeFacets(ctx, filter, request); err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+func (r *productRepository) categoryFacets(ctx context.Context, filter domain.ProductFilter) ([]domain.CategoryFacet, error) {
+	b := &queryBuilder{}
+	applyProductFilter(b, filter)
+	b.where("p.category_id IS NOT NULL")
+
+	query := `SELECT p.category_id, COALESCE(c.name, ''), COUNT(*) FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id` + b.whereClause() + `
+		GROUP BY p.category_id, c.name
+		ORDER BY COUNT(*) DESC, p.category_id`
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facets []domain.CategoryFacet
+	for rows.Next() {
+		var facet domain.CategoryFacet
+		var id int64
+		if err := rows.Scan(&id, &facet.Name, &facet.Count); err != nil {
+			return nil, err
+		}
+		facet.CategoryID = uint64(id)
+		facets = append(facets, facet)
+	}
+	return facets, rows.Err()
+}
+
+// priceFacets returns one entry per bucket, including empty ones, so that
+// the sidebar keeps a stable shape while filters change. Only products in
+// the filter's currency are counted.
+func (r *productRepository) priceFacets(ctx context.Context, filter domain.ProductFilter, bounds []money.Money) ([]domain.PriceFacet, error) {
+	b := &queryBuilder{}
+	applyProductFilter(b, filter)
+	b.where("p.currency = " + b.arg(string(filter.PriceCurrency())))
+
+	// width_bucket yields 0 below the first bound and len(bounds) at or
+	// above the last one. The bounds are compared as decimals, like the
+	// column.
+	decimals := make([]string, len(bounds))
+	for i, bound := range bounds {
+		decimals[i] = bound.Decimal()
+	}
+	query := `SELECT width_bucket(p.price, ` + b.arg(pq.Array(decimals)) + `::numeric[]), COUNT(*)
+		FROM products p` + b.whereClause() + ` GROUP BY 1`
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		counts[bucket] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var facets []domain.PriceFacet
+	if !bounds[0].IsZero() {
+		zero, err := money.Zero(filter.PriceCurrency())
+		if err != nil {
+			return nil, err
+		}
+		max := bounds[0]
+		facets = append(facets, domain.PriceFacet{Min: zero, Max: &max, Count: counts[0]})
+	}
+	for i := 1; i <= len(bounds); i++ {
+		facet := domain.PriceFacet{Min: bounds[i-1], Count: counts[i]}
+		if i < len(bounds) {
+			max := bounds[i]
+			facet.Max = &max
+		}
+		facets = append(facets, facet)
+	}
+	return facets, nil
+}
+
+func (r *productRepository) stockFacet(ctx context.Context, filter domain.ProductFilter) (*domain.StockFacet, error) {
+	b := &queryBuilder{}
+	applyProductFilter(b, filter)
+
+	query := `SELECT COUNT(*) FILTER (WHERE p.stock > 0), COUNT(*) FILTER (WHERE p.stock <= 0)
+		FROM products p` + b.whereClause()
+	facet := &domain.StockFacet{}
+	if err := r.db.QueryRowContext(ctx, query, b.args...).Scan(&facet.InStock, &facet.OutOfStock); err != nil {
+		return nil, err
+	}
+	return facet, nil
+}
+
+// attributeFacets counts values for codes without an active filter in one
+// pass under the full filter, then counts each filtered code separately with
+// its own condition removed.
+func (r *productRepository) attributeFacets(ctx context.Context, filter domain.ProductFilter, request domain.FacetRequest) (map[string][]domain.AttributeFacet, error) {
+	filtered := make(map[string]bool)
+	for _, a := range filter.Attributes {
+		filtered[a.Code] = true
+	}
+	requested := make(map[string]bool)
+	for _, code := range request.AttributeCodes {
+		requested[code] = true
+	}
+
+	facets := make(map[string][]domain.AttributeFacet)
+	collect := func(filter domain.ProductFilter, restrict func(b *queryBuilder) string) error {
+		b := &queryBuilder{}
+		applyProductFilter(b, filter)
+		b.where("jsonb_typeof(kv.value) IN ('string', 'number', 'boolean')")
+		b.where(restrict(b))
+		return r.queryAttributeFacets(ctx, b, facets)
+	}
+
+	var unfiltered []string
+	for code := range requested {
+		if !filtered[code] {
+			unfiltered = append(unfiltered, code)
+		}
+	}
+	// A nil array would bind as NULL and hide every row.
+	skip := []string{}
+	for code := range filtered {
+		skip = append(skip, code)
+	}
+
+	if request.AllAttributes {
+		err := collect(filter, func(b *queryBuilder) string {
+			return "NOT kv.key = ANY(" + b.arg(pq.Array(skip)) + ")"
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else if len(unfiltered) > 0 {
+		err := collect(filter, func(b *queryBuilder) string {
+			return "kv.key = ANY(" + b.arg(pq.Array(unfiltered)) + ")"
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for code := range filtered {
+		if !request.AllAttributes && !requested[code] {
+			continue
+		}
+		code := code
+		err := collect(filter.WithoutAttribute(code), func(b *queryBuilder) string {
+			return "kv.key = " + b.arg(code)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+func (r *productRepository) queryAttributeFacets(ctx context.Context, b *queryBuilder, facets map[string][]domain.AttributeFacet) error {
+	query := fmt.Sprintf(`SELECT code, value, n FROM (
+			SELECT kv.key AS code, kv.value #>> '{}' AS value, COUNT(*) AS n,
+				ROW_NUMBER() OVER (PARTITION BY kv.key ORDER BY COUNT(*) DESC, kv.value #>> '{}') AS rank
+			FROM products p CROSS JOIN LATERAL jsonb_each(p.attributes) kv%s
+			GROUP BY kv.key, kv.value #>> '{}'
+		) ranked
+		WHERE rank <= %d
+		ORDER BY code, n DESC, value`, b.whereClause(), domain.MaxFacetValues)
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		var value sql.NullString
+		var facet domain.AttributeFacet
+		if err := rows.Scan(&code, &value, &facet.Count); err != nil {
+			return err
+		}
+		facet.Value = value.String
+		facets[code] = append(facets[code], facet)
+	}
+	return rows.Err()
+}
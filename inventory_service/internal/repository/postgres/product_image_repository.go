@@ -0,0 +1,289 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+)
+
+const productImageColumns = `i.id, i.product_id, i.storage_key, i.content_type, i.width, i.height, i.size_bytes, i.position, i.is_primary, i.thumbnail_keys, i.created_at`
+
+type productImageRepository struct {
+	db *sql.DB
+}
+
+func NewProductImageRepository(db *sql.DB) domain.ProductImageRepository {
+	return &productImageRepository{db: db}
+}
+
+func scanProductImage(row rowScanner) (*domain.ProductImage, error) {
+	image := &domain.ProductImage{}
+	var thumbnails []byte
+	err := row.Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.StorageKey,
+		&image.ContentType,
+		&image.Width,
+		&image.Height,
+		&image.SizeBytes,
+		&image.Position,
+		&image.IsPrimary,
+		&thumbnails,
+		&image.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(thumbnails, &image.ThumbnailKeys); err != nil {
+		return nil, err
+	}
+	return image, nil
+}
+
+func (r *productImageRepository) Create(ctx context.Context, image *domain.ProductImage) error {
+	thumbnails, err := json.Marshal(image.ThumbnailKeys)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the product serializes uploads, so positions and the primary
+	// flag are assigned without races.
+	if err := lockProduct(ctx, tx, image.ProductID); err != nil {
+		return err
+	}
+
+	var hasPrimary bool
+	query := `
+		SELECT COALESCE(MAX(position), 0) + 1, COALESCE(BOOL_OR(is_primary), false)
+		FROM product_images
+		WHERE product_id = $1`
+	if err := tx.QueryRowContext(ctx, query, image.ProductID).Scan(&image.Position, &hasPrimary); err != nil {
+		return err
+	}
+
+	if !hasPrimary {
+		image.IsPrimary = true
+	} else if image.IsPrimary {
+		if _, err := tx.ExecContext(ctx, `UPDATE product_images SET is_primary = false WHERE product_id = $1 AND is_primary`, image.ProductID); err != nil {
+			return err
+		}
+	}
+
+	query = `
+		INSERT INTO product_images (product_id, storage_key, content_type, width, height, size_bytes, position, is_primary, thumbnail_keys, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		image.ProductID,
+		image.StorageKey,
+		image.ContentType,
+		image.Width,
+		image.Height,
+		image.SizeBytes,
+		image.Position,
+		image.IsPrimary,
+		thumbnails,
+	).Scan(&image.ID, &image.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productImageRepository) GetByID(ctx context.Context, productID, imageID uint64) (*domain.ProductImage, error) {
+	query := `
+		SELECT ` + productImageColumns + `
+		FROM product_images i
+		WHERE i.id = $1 AND i.product_id = $2`
+
+	image, err := scanProductImage(r.db.QueryRowContext(ctx, query, imageID, productID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return image, nil
+}
+
+func (r *productImageRepository) ListByProduct(ctx context.Context, productID uint64) ([]*domain.ProductImage, error) {
+	images, err := r.ListByProducts(ctx, []uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+	return images[productID], nil
+}
+
+func (r *productImageRepository) ListByProducts(ctx context.Context, productIDs []uint64) (map[uint64][]*domain.ProductImage, error) {
+	result := make(map[uint64][]*domain.ProductImage, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT ` + productImageColumns + `
+		FROM product_images i
+		WHERE i.product_id = ANY($1)
+		ORDER BY i.product_id, i.position, i.id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[image.ProductID] = append(result[image.ProductID], image)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *productImageRepository) Reorder(ctx context.Context, productID uint64, imageIDs []uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT id FROM product_images WHERE product_id = $1`, productID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[uint64]bool)
+	for rows.Next() {
+		var id uint64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(imageIDs) != len(existing) {
+		return domain.ErrInvalidImageOrder
+	}
+	ids := make([]int64, len(imageIDs))
+	for i, id := range imageIDs {
+		if !existing[id] {
+			return domain.ErrInvalidImageOrder
+		}
+		delete(existing, id)
+		ids[i] = int64(id)
+	}
+
+	query := `
+		UPDATE product_images i
+		SET position = o.position
+		FROM unnest($1::bigint[]) WITH ORDINALITY AS o(id, position)
+		WHERE i.id = o.id AND i.product_id = $2`
+	if _, err := tx.ExecContext(ctx, query, pq.Array(ids), productID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productImageRepository) SetPrimary(ctx context.Context, productID, imageID uint64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE product_images SET is_primary = false WHERE product_id = $1 AND is_primary`, productID); err != nil {
+		return err
+	}
+	result, err := tx.ExecContext(ctx, `UPDATE product_images SET is_primary = true WHERE id = $1 AND product_id = $2`, imageID, productID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return domain.ErrImageNotFound
+	}
+
+	return tx.Commit()
+}
+
+func (r *productImageRepository) Delete(ctx context.Context, productID, imageID uint64) (*domain.ProductImage, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := lockProduct(ctx, tx, productID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		DELETE FROM product_images i
+		WHERE i.id = $1 AND i.product_id = $2
+		RETURNING ` + productImageColumns
+	image, err := scanProductImage(tx.QueryRowContext(ctx, query, imageID, productID))
+	if err == sql.ErrNoRows {
+		return nil, domain.ErrImageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if image.IsPrimary {
+		query = `
+			UPDATE product_images SET is_primary = true
+			WHERE id = (SELECT id FROM product_images WHERE product_id = $1 ORDER BY position, id LIMIT 1)`
+		if _, err := tx.ExecContext(ctx, query, productID); err != nil {
+			return nil, err
+		}
+	}
+
+	return image, tx.Commit()
+}
+
+// lockProduct takes a row lock on a live product for the rest of tx.
+func lockProduct(ctx context.Context, tx *sql.Tx, productID uint64) error {
+	var id uint64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 AND is_deleted = false FOR UPDATE`, productID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	return err
+}
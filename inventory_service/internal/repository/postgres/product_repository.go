@@ -3,10 +3,28 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+	"html"
+	"strconv"
+	"strings"
+	"time"
 )
 
+const productColumns = `p.id, p.name, p.description, p.price, p.stock, p.category_id, p.created_at, p.updated_at, p.is_deleted, p.version, p.sku, p.external_id, p.attributes, p.currency, p.deleted_at, p.slug`
+
+// productSortColumns maps sort fields to their column and the type their
+// cursor value is cast to.
+var productSortColumns = map[domain.ProductSortField][2]string{
+	domain.ProductSortCreatedAt: {"p.created_at", "timestamp"},
+	domain.ProductSortUpdatedAt: {"p.updated_at", "timestamp"},
+	domain.ProductSortPrice:     {"p.price", "numeric"},
+	domain.ProductSortName:      {"p.name", "text"},
+	domain.ProductSortStock:     {"p.stock", "integer"},
+}
+
 type productRepository struct {
 	db *sql.DB
 }
@@ -15,69 +33,158 @@ func NewProductRepository(db *sql.DB) domain.ProductRepository {
 	return &productRepository{db: db}
 }
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanProduct reads productColumns, followed by any extra selected columns
+// into the given destinations.
+func scanProduct(row rowScanner, extra ...interface{}) (*domain.Product, error) {
+	var id uint64
+	var name string
+	var description sql.NullString
+	var price, currency string
+	var stock int
+	var categoryID sql.NullInt64
+	var createdAt, updatedAt time.Time
+	var isDeleted bool
+	var version int
+	var sku, externalID sql.NullString
+	var attributes []byte
+	var deletedAt sql.NullTime
+	var slug string
+
+	dest := []interface{}{
+		&id,
+		&name,
+		&description,
+		&price,
+		&stock,
+		&categoryID,
+		&createdAt,
+		&updatedAt,
+		&isDeleted,
+		&version,
+		&sku,
+		&externalID,
+		&attributes,
+		&currency,
+		&deletedAt,
+		&slug,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	amount, err := parseMoney(price, currency)
+	if err != nil {
+		return nil, err
+	}
+	product, err := domain.NewProduct(name, description.String, amount, stock, uint64(categoryID.Int64))
+	if err != nil {
+		return nil, err
+	}
+	product.SetID(id)
+	product.SetIdentifiers(sku.String, externalID.String)
+	product.SetSlug(slug)
+	var attributeValues map[string]interface{}
+	if err := json.Unmarshal(attributes, &attributeValues); err != nil {
+		return nil, err
+	}
+	product.SetAttributes(attributeValues)
+	product.SetVersion(version)
+	product.SetTimestamps(createdAt, updatedAt)
+	if isDeleted {
+		product.MarkDeleted(deletedAt.Time)
+		product.SetTimestamps(createdAt, updatedAt)
+	}
+
+	return product, nil
+}
+
 func (r *productRepository) Create(ctx context.Context, product *domain.Product) error {
 	var id uint64
-	var createdAt, updatedAt sql.NullTime
+	var createdAt, updatedAt time.Time
+	var version int
+
+	attributes, err := json.Marshal(product.Attributes())
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	slug, err := productSlugs.assign(ctx, tx, 0, product.Slug(), product.Name())
+	if err != nil {
+		return err
+	}
+	if err := productSlugs.claim(ctx, tx, slug); err != nil {
+		return err
+	}
 
 	query := `
-		INSERT INTO products (name, description, price, stock, category_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		RETURNING id, created_at, updated_at`
+		INSERT INTO products (name, description, price, currency, stock, category_id, sku, external_id, attributes, slug, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
+		RETURNING id, created_at, updated_at, version`
 
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		product.Name(),
 		product.Description(),
-		product.Price(),
+		product.Price().Decimal(),
+		string(product.Price().Currency()),
 		product.Stock(),
-		product.CategoryID(),
-	).Scan(&id, &createdAt, &updatedAt)
+		nullableID(product.CategoryID()),
+		nullableString(product.SKU()),
+		nullableString(product.ExternalID()),
+		attributes,
+		slug,
+	).Scan(&id, &createdAt, &updatedAt, &version)
 
 	if err != nil {
-		return err
+		return identifierConflict(err)
 	}
 
-	// Create a new product with the returned ID and timestamps
-	newProduct, err := domain.NewProduct(
-		product.Name(),
-		product.Description(),
-		product.Price(),
-		product.Stock(),
-		product.CategoryID(),
-	)
+	snapshot := domain.NewProductSnapshot(product, domain.ActorFromContext(ctx))
+	snapshot.ID, snapshot.Slug, snapshot.Version = id, slug, version
+	if err := writeProductEvent(ctx, tx, domain.EventProductCreated, id, snapshot); err != nil {
+		return err
+	}
+	if err := recordStockChange(ctx, tx, id, 0, product.Stock(), domain.MovementRestock); err != nil {
+		return err
+	}
+	err = insertPriceChange(ctx, tx, &domain.PriceChange{
+		ProductID: id,
+		Price:     product.Price(),
+		Source:    domain.PriceChangeManual,
+		Actor:     domain.ActorFromContext(ctx),
+	})
 	if err != nil {
 		return err
 	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
 
-	*product = *newProduct
+	product.SetID(id)
+	product.SetSlug(slug)
+	product.SetVersion(version)
+	product.SetTimestamps(createdAt, updatedAt)
 	return nil
 }
 
 func (r *productRepository) GetByID(ctx context.Context, id uint64) (*domain.Product, error) {
-	var name, description string
-	var price float64
-	var stock int
-	var categoryID uint64
-	var createdAt, updatedAt sql.NullTime
-	var isDeleted bool
-
 	query := `
-		SELECT id, name, description, price, stock, category_id, created_at, updated_at, is_deleted
-		FROM products
-		WHERE id = $1 AND is_deleted = false`
+		SELECT ` + productColumns + `
+		FROM products p
+		WHERE p.id = $1 AND p.is_deleted = false`
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&id,
-		&name,
-		&description,
-		&price,
-		&stock,
-		&categoryID,
-		&createdAt,
-		&updatedAt,
-		&isDeleted,
-	)
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -85,7 +192,63 @@ func (r *productRepository) GetByID(ctx context.Context, id uint64) (*domain.Pro
 		return nil, err
 	}
 
-	product, err := domain.NewProduct(name, description, price, stock, categoryID)
+	return product, nil
+}
+
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	return r.getBy(ctx, "p.sku", sku)
+}
+
+func (r *productRepository) GetByExternalID(ctx context.Context, externalID string) (*domain.Product, error) {
+	return r.getBy(ctx, "p.external_id", externalID)
+}
+
+func (r *productRepository) GetBySlug(ctx context.Context, slug string) (*domain.Product, error) {
+	return r.getBy(ctx, "p.slug", slug)
+}
+
+func (r *productRepository) SlugRedirect(ctx context.Context, slug string) (string, error) {
+	return productSlugs.redirect(ctx, r.db, slug)
+}
+
+func (r *productRepository) GetBatch(ctx context.Context, batch domain.ProductBatch) ([]*domain.Product, error) {
+	ids := make([]int64, len(batch.IDs))
+	for i, id := range batch.IDs {
+		ids[i] = int64(id)
+	}
+	query := `
+		SELECT ` + productColumns + `
+		FROM products p
+		WHERE (p.id = ANY($1) OR p.sku = ANY($2)) AND p.is_deleted = false`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), pq.Array(batch.SKUs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*domain.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *productRepository) getBy(ctx context.Context, column, value string) (*domain.Product, error) {
+	query := `
+		SELECT ` + productColumns + `
+		FROM products p
+		WHERE ` + column + ` = $1 AND p.is_deleted = false`
+
+	product, err := scanProduct(r.db.QueryRowContext(ctx, query, value))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -93,25 +256,43 @@ func (r *productRepository) GetByID(ctx context.Context, id uint64) (*domain.Pro
 	return product, nil
 }
 
-func (r *productRepository) List(ctx context.Context, categoryID uint64, offset, limit int) ([]*domain.Product, error) {
-	var args []interface{}
-	argPosition := 1
+func (r *productRepository) List(ctx context.Context, filter domain.ProductFilter) (*domain.ProductPage, error) {
+	b := &queryBuilder{}
+	applyProductFilter(b, filter)
 
-	query := `
-		SELECT id, name, description, price, stock, category_id, created_at, updated_at, is_deleted
-		FROM products
-		WHERE is_deleted = false`
+	page := &domain.ProductPage{}
+	countQuery := `SELECT COUNT(*) FROM products p` + b.whereClause()
+	if err := r.db.QueryRowContext(ctx, countQuery, b.args...).Scan(&page.Total); err != nil {
+		return nil, err
+	}
 
-	if categoryID > 0 {
-		query += fmt.Sprintf(" AND category_id = $%d", argPosition)
-		args = append(args, categoryID)
-		argPosition++
+	sort := productSortColumns[filter.SortBy]
+	column, cast := sort[0], sort[1]
+	backward := filter.Cursor != nil && filter.Cursor.Backward
+	// Walking backwards reads the preceding rows in reverse order and flips
+	// them afterwards, so the comparison and direction both invert.
+	descending := filter.SortDesc != backward
+
+	if filter.Cursor != nil {
+		op := ">"
+		if descending {
+			op = "<"
+		}
+		b.where(fmt.Sprintf("(%s, p.id) %s (%s::%s, %s)",
+			column, op, b.arg(filter.Cursor.Value), cast, b.arg(filter.Cursor.ID)))
 	}
 
-	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argPosition, argPosition+1)
-	args = append(args, limit, offset)
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	query := `SELECT ` + productColumns + ` FROM products p` + b.whereClause() +
+		fmt.Sprintf(" ORDER BY %s %s, p.id %s LIMIT %s", column, direction, direction, b.arg(filter.Limit+1))
+	if filter.Cursor == nil {
+		query += " OFFSET " + b.arg(filter.Offset)
+	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
 	if err != nil {
 		return nil, err
 	}
@@ -119,85 +300,445 @@ func (r *productRepository) List(ctx context.Context, categoryID uint64, offset,
 
 	var products []*domain.Product
 	for rows.Next() {
-		var id uint64
-		var name, description string
-		var price float64
-		var stock int
-		var catID uint64
-		var createdAt, updatedAt sql.NullTime
-		var isDeleted bool
-
-		err := rows.Scan(
-			&id,
-			&name,
-			&description,
-			&price,
-			&stock,
-			&catID,
-			&createdAt,
-			&updatedAt,
-			&isDeleted,
-		)
+		product, err := scanProduct(rows)
 		if err != nil {
 			return nil, err
 		}
+		products = append(products, product)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-		product, err := domain.NewProduct(name, description, price, stock, catID)
-		if err != nil {
-			return nil, err
+	hasMore := len(products) > filter.Limit
+	if hasMore {
+		products = products[:filter.Limit]
+	}
+	if backward {
+		for i, j := 0, len(products)-1; i < j; i, j = i+1, j-1 {
+			products[i], products[j] = products[j], products[i]
 		}
-		products = append(products, product)
 	}
+	page.Products = products
 
-	return products, rows.Err()
+	if len(products) == 0 {
+		return page, nil
+	}
+	first, last := products[0], products[len(products)-1]
+	if hasMore || backward {
+		page.Next = &domain.ProductCursor{Value: filter.SortValue(last), ID: last.ID()}
+	}
+	if (backward && hasMore) || (!backward && (filter.Cursor != nil || filter.Offset > 0)) {
+		page.Prev = &domain.ProductCursor{Value: filter.SortValue(first), ID: first.ID(), Backward: true}
+	}
+
+	return page, nil
+}
+
+func applyProductFilter(b *queryBuilder, f domain.ProductFilter) {
+	b.where("p.is_deleted = false")
+	if len(f.CategoryIDs) > 0 {
+		ids := make([]int64, len(f.CategoryIDs))
+		for i, id := range f.CategoryIDs {
+			ids[i] = int64(id)
+		}
+		if f.IncludeDescendants {
+			b.where(`p.category_id IN (
+				WITH RECURSIVE subtree AS (
+					SELECT id FROM categories WHERE id = ANY(` + b.arg(pq.Array(ids)) + `) AND is_deleted = false
+					UNION ALL
+					SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.is_deleted = false
+				)
+				SELECT id FROM subtree)`)
+		} else {
+			b.where("p.category_id = ANY(" + b.arg(pq.Array(ids)) + ")")
+		}
+	}
+	if f.HasPriceBounds() {
+		b.where("p.currency = " + b.arg(string(f.PriceCurrency())))
+	}
+	if f.MinPrice != nil {
+		b.where("p.price >= " + b.arg(f.MinPrice.Decimal()) + "::numeric")
+	}
+	if f.MaxPrice != nil {
+		b.where("p.price <= " + b.arg(f.MaxPrice.Decimal()) + "::numeric")
+	}
+	if f.InStockOnly {
+		b.where("p.stock > 0")
+	}
+	if f.CreatedFrom != nil {
+		b.where("p.created_at >= " + b.arg(*f.CreatedFrom))
+	}
+	if f.CreatedTo != nil {
+		b.where("p.created_at <= " + b.arg(*f.CreatedTo))
+	}
+	if f.UpdatedFrom != nil {
+		b.where("p.updated_at >= " + b.arg(*f.UpdatedFrom))
+	}
+	if f.UpdatedTo != nil {
+		b.where("p.updated_at <= " + b.arg(*f.UpdatedTo))
+	}
+	for _, a := range f.Attributes {
+		b.where(attributeCondition(b, a))
+	}
+}
+
+// attributeCondition renders one attribute filter. Equality goes through
+// JSONB containment so that it can use the GIN index; a value that looks like
+// a number or boolean also matches the typed JSON form. Ordering comparisons
+// only consider numeric values.
+func attributeCondition(b *queryBuilder, a domain.AttributeFilter) string {
+	code := b.arg(a.Code)
+
+	switch a.Operator {
+	case domain.AttributeEq, domain.AttributeNe:
+		var alternatives []string
+		for _, v := range a.Values {
+			for _, document := range attributeDocuments(a.Code, v) {
+				alternatives = append(alternatives, "p.attributes @> "+b.arg(document)+"::jsonb")
+			}
+		}
+		match := "(" + strings.Join(alternatives, " OR ") + ")"
+		if a.Operator == domain.AttributeNe {
+			return "(p.attributes ? " + code + " AND NOT " + match + ")"
+		}
+		return match
+	default:
+		value, _ := strconv.ParseFloat(a.Values[0], 64)
+		return fmt.Sprintf("(CASE WHEN jsonb_typeof(p.attributes -> %s) = 'number' THEN (p.attributes ->> %s)::numeric END) %s %s",
+			code, code, a.Operator, b.arg(value))
+	}
+}
+
+// attributeDocuments builds the containment documents a filter value matches.
+func attributeDocuments(code, value string) []string {
+	candidates := []interface{}{value}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, f)
+	} else if value == "true" || value == "false" {
+		candidates = append(candidates, value == "true")
+	}
+
+	documents := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		document, _ := json.Marshal(map[string]interface{}{code: candidate})
+		documents[i] = string(document)
+	}
+	return documents
 }
 
 func (r *productRepository) Update(ctx context.Context, product *domain.Product) error {
-	var updatedAt sql.NullTime
+	var updatedAt time.Time
+	var version, previousStock int
+	var previousPrice, previousSlug string
+
+	attributes, err := json.Marshal(product.Attributes())
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
+	var bundlePricing sql.NullString
 	query := `
+		SELECT p.stock, p.price, p.slug, b.pricing
+		FROM products p
+		LEFT JOIN bundles b ON b.product_id = p.id
+		WHERE p.id = $1 AND p.version = $2 AND p.is_deleted = false
+		FOR UPDATE OF p`
+	err = tx.QueryRowContext(ctx, query, product.ID(), product.Version()).Scan(&previousStock, &previousPrice, &previousSlug, &bundlePricing)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, product.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	previous, err := parseMoney(previousPrice, string(product.Price().Currency()))
+	if err != nil {
+		return err
+	}
+	// A bundle's stock follows its components, so the stock in the update is
+	// ignored; a discount bundle's price follows them too.
+	if bundlePricing.Valid {
+		if domain.BundlePricing(bundlePricing.String) == domain.BundlePricingDiscount && !previous.Equal(product.Price()) {
+			return domain.ErrBundlePriceDerived
+		}
+		if err := product.UpdateStock(previousStock - product.Stock()); err != nil {
+			return err
+		}
+	}
+
+	slug, err := productSlugs.assign(ctx, tx, product.ID(), product.Slug(), product.Name())
+	if err != nil {
+		return err
+	}
+
+	query = `
 		UPDATE products
-		SET name = $1, description = $2, price = $3, stock = $4, category_id = $5, updated_at = NOW()
-		WHERE id = $6 AND is_deleted = false
-		RETURNING updated_at`
+		SET name = $1, description = $2, price = $3, stock = $4, category_id = $5,
+		    sku = $6, external_id = $7, attributes = $8, slug = $9, version = version + 1, updated_at = NOW()
+		WHERE id = $10 AND version = $11 AND is_deleted = false
+		RETURNING version, updated_at`
 
-	err := r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		product.Name(),
 		product.Description(),
-		product.Price(),
+		product.Price().Decimal(),
 		product.Stock(),
-		product.CategoryID(),
+		nullableID(product.CategoryID()),
+		nullableString(product.SKU()),
+		nullableString(product.ExternalID()),
+		attributes,
+		slug,
 		product.ID(),
-	).Scan(&updatedAt)
+		product.Version(),
+	).Scan(&version, &updatedAt)
 
 	if err != nil {
+		return identifierConflict(err)
+	}
+	if err := productSlugs.move(ctx, tx, product.ID(), previousSlug, slug); err != nil {
+		return err
+	}
+	snapshot := domain.NewProductSnapshot(product, domain.ActorFromContext(ctx))
+	snapshot.Slug, snapshot.Version = slug, version
+	if err := writeProductEvent(ctx, tx, domain.EventProductUpdated, product.ID(), snapshot); err != nil {
+		return err
+	}
+
+	delta := product.Stock() - previousStock
+	if err := recordStockChange(ctx, tx, product.ID(), 0, delta, domain.MovementAdjustment); err != nil {
+		return err
+	}
+	if err := recordPriceChange(ctx, tx, product.ID(), previous, product.Price()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
 		return err
 	}
 
+	product.SetSlug(slug)
+	product.SetVersion(version)
+	product.SetTimestamps(product.CreatedAt(), updatedAt)
 	return nil
 }
 
-func (r *productRepository) Delete(ctx context.Context, id uint64) error {
+func (r *productRepository) Delete(ctx context.Context, id uint64, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		UPDATE products
-		SET is_deleted = true, updated_at = NOW()
-		WHERE id = $1 AND is_deleted = false`
+		SET is_deleted = true, deleted_at = NOW(), version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2 AND is_deleted = false
+		RETURNING version, deleted_at, COALESCE(category_id, 0)`
+
+	event := &domain.ProductLifecycle{ID: id, Actor: domain.ActorFromContext(ctx)}
+	err = tx.QueryRowContext(ctx, query, id, version).Scan(&event.Version, &event.At, &event.CategoryID)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeProductEvent(ctx, tx, domain.EventProductDeleted, id, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *productRepository) ListDeleted(ctx context.Context, offset, limit int) ([]*domain.Product, error) {
+	query := `
+		SELECT ` + productColumns + `
+		FROM products p
+		WHERE p.is_deleted = true
+		ORDER BY p.deleted_at DESC, p.id DESC
+		LIMIT $1 OFFSET $2`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var products []*domain.Product
+	for rows.Next() {
+		product, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *productRepository) Restore(ctx context.Context, id uint64, version int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	rows, err := result.RowsAffected()
+	var categoryID sql.NullInt64
+	query := `SELECT category_id FROM products WHERE id = $1 AND version = $2 AND is_deleted = true FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, id, version).Scan(&categoryID)
+	if err == sql.ErrNoRows {
+		return r.missOrConflictIn(ctx, id, true)
+	}
 	if err != nil {
 		return err
 	}
 
-	if rows == 0 {
-		return sql.ErrNoRows
+	// The share lock keeps the category from being deleted until the
+	// product is live again.
+	if categoryID.Valid {
+		var categoryDeleted bool
+		query = `SELECT is_deleted FROM categories WHERE id = $1 FOR SHARE`
+		if err := tx.QueryRowContext(ctx, query, categoryID.Int64).Scan(&categoryDeleted); err != nil {
+			return err
+		}
+		if categoryDeleted {
+			return domain.ErrCategoryTrashed
+		}
+	}
+
+	query = `
+		UPDATE products
+		SET is_deleted = false, deleted_at = NULL, version = version + 1, updated_at = NOW()
+		WHERE id = $1
+		RETURNING version, updated_at`
+	event := &domain.ProductLifecycle{ID: id, CategoryID: uint64(categoryID.Int64), Actor: domain.ActorFromContext(ctx)}
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&event.Version, &event.At); err != nil {
+		return identifierConflict(err)
+	}
+	if err := writeProductEvent(ctx, tx, domain.EventProductRestored, id, event); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit()
+}
+
+func (r *productRepository) Search(ctx context.Context, q domain.ProductSearchQuery) ([]*domain.ProductSearchResult, error) {
+	vector, ok := searchVectors[q.Language]
+	if !ok {
+		return nil, domain.ErrUnsupportedSearchLanguage
+	}
+
+	// Each term matches the language's vector once stemmed like it, or the
+	// simple vector as typed, so that a partly typed word still matches
+	// when its stem is shorter. Results are ranked and highlighted by the
+	// stemmed terms.
+	b := &queryBuilder{}
+	language := b.arg(q.Language)
+	var stemmed, typed []string
+	for _, term := range q.Terms() {
+		t := b.arg(term + ":*")
+		stemmed = append(stemmed, "to_tsquery("+language+"::regconfig, "+t+")")
+		typed = append(typed, "to_tsquery('simple', "+t+")")
+		b.where("(p." + vector + " @@ to_tsquery(" + language + "::regconfig, " + t + ") OR p.search_simple @@ to_tsquery('simple', " + t + "))")
+	}
+	nameOptions := b.arg(highlightOptions + ", HighlightAll=true")
+	descriptionOptions := b.arg(highlightOptions + ", MaxFragments=2, MaxWords=20, MinWords=5")
+	applyProductFilter(b, q.Filter)
+
+	query := `
+		WITH q AS (SELECT ` + strings.Join(stemmed, " && ") + ` AS query, ` + strings.Join(typed, " && ") + ` AS typed)
+		SELECT ` + productColumns + `,
+			greatest(ts_rank_cd(p.` + vector + `, q.query), ts_rank_cd(p.search_simple, q.typed)) AS rank,
+			ts_headline(` + language + `::regconfig, translate(p.name, ` + highlightMarks + `, ''), q.query, ` + nameOptions + `),
+			ts_headline(` + language + `::regconfig, translate(coalesce(p.description, ''), ` + highlightMarks + `, ''), q.query, ` + descriptionOptions + `)
+		FROM products p, q` + b.whereClause()
+	query += fmt.Sprintf(" ORDER BY rank DESC, p.id LIMIT %s OFFSET %s", b.arg(q.Filter.Limit), b.arg(q.Filter.Offset))
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.ProductSearchResult
+	for rows.Next() {
+		result := &domain.ProductSearchResult{}
+		result.Product, err = scanProduct(rows, &result.Rank, &result.NameHighlight, &result.DescriptionHighlight)
+		if err != nil {
+			return nil, err
+		}
+		result.NameHighlight = highlightHTML(result.NameHighlight)
+		result.DescriptionHighlight = highlightHTML(result.DescriptionHighlight)
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// searchVectors names the products column that holds the text search vector
+// built with each supported configuration.
+var searchVectors = map[string]string{
+	"english": "search_english",
+	"russian": "search_russian",
+	"simple":  "search_simple",
+}
+
+// ts_headline marks matches with control characters rather than HTML, which
+// it would leave next to unescaped product text. highlightMarks strips them
+// from the text first so that every one comes from ts_headline.
+const (
+	highlightStart   = "\x01"
+	highlightStop    = "\x02"
+	highlightOptions = "StartSel=" + highlightStart + ", StopSel=" + highlightStop
+	highlightMarks   = "chr(1) || chr(2)"
+)
+
+// highlightHTML escapes a ts_headline fragment and wraps its matches in
+// <mark>.
+func highlightHTML(fragment string) string {
+	fragment = html.EscapeString(fragment)
+	fragment = strings.ReplaceAll(fragment, highlightStart, "<mark>")
+	return strings.ReplaceAll(fragment, highlightStop, "</mark>")
+}
+
+// identifierConflict maps unique violations on the product identifiers to
+// their domain errors.
+func identifierConflict(err error) error {
+	switch {
+	case isUniqueViolationOn(err, "idx_products_sku"):
+		return domain.ErrDuplicateSKU
+	case isUniqueViolationOn(err, "idx_products_external_id"):
+		return domain.ErrDuplicateExternalID
+	case isUniqueViolationOn(err, "idx_products_slug"):
+		return domain.ErrDuplicateSlug
+	}
+	return err
+}
+
+// missOrConflict tells apart a versioned write that matched no row because
+// the product is gone from one that lost a race with a concurrent writer.
+func (r *productRepository) missOrConflict(ctx context.Context, id uint64) error {
+	return r.missOrConflictIn(ctx, id, false)
+}
+
+// missOrConflictIn is missOrConflict for live products or, with deleted, for
+// products in the trash.
+func (r *productRepository) missOrConflictIn(ctx context.Context, id uint64, deleted bool) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_deleted = $2)`
+	if err := r.db.QueryRowContext(ctx, query, id, deleted).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrProductNotFound
+	}
+	return domain.ErrVersionConflict
 }
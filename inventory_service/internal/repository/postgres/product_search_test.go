@@ -0,0 +1,72 @@
+//go:build integration
+
+package postgres
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+func TestSearchStemsDocumentsLikeTerms(t *testing.T) {
+	db := openTestDB(t)
+	emptyCatalog(t, db)
+	ctx := context.Background()
+	products := NewProductRepository(db)
+
+	create := func(name, description string) *domain.Product {
+		t.Helper()
+		price, err := money.Parse("10.00", "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		product, err := domain.NewProduct(name, description, price, 1, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := products.Create(ctx, product); err != nil {
+			t.Fatal(err)
+		}
+		return product
+	}
+	battery := create("Battery", "Charges quickly")
+	books := create("Детские книги", "Сказки с картинками")
+
+	search := func(text, language string) []*domain.ProductSearchResult {
+		t.Helper()
+		query := domain.ProductSearchQuery{Text: text, Language: language, Filter: domain.ProductFilter{Limit: 10}}
+		if err := query.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		results, err := products.Search(ctx, query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	cases := []struct {
+		text, language string
+		want           *domain.Product
+	}{
+		{"batteries charging", "english", battery},
+		{"batt", "english", battery},
+		{"книгами", "russian", books},
+		{"сказкам", "russian", books},
+	}
+	for _, tc := range cases {
+		results := search(tc.text, tc.language)
+		if len(results) != 1 || results[0].Product.ID() != tc.want.ID() {
+			t.Errorf("%s search for %q: got %d results, want %q", tc.language, tc.text, len(results), tc.want.Name())
+			continue
+		}
+		if results[0].Rank <= 0 || results[0].NameHighlight == "" {
+			t.Errorf("%s search for %q: got rank %v, highlight %q", tc.language, tc.text, results[0].Rank, results[0].NameHighlight)
+		}
+	}
+
+	if results := search("batteries", "simple"); len(results) != 0 {
+		t.Fatalf("got %d results for an unstemmed inflection", len(results))
+	}
+}
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strings"
+)
+
+// queryBuilder collects WHERE conditions together with their positional
+// arguments so that optional filters can be composed safely.
+type queryBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg registers a query argument and returns its placeholder.
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *queryBuilder) where(condition string) {
+	b.conditions = append(b.conditions, condition)
+}
+
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// nullableID stores an unset (zero) reference as NULL.
+func nullableID(id uint64) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return int64(id)
+}
+
+// nullableInt stores an absent optional integer as NULL.
+func nullableInt(v *int) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// nullableString stores an empty optional string as NULL, which keeps it out
+// of partial unique indexes.
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// parseMoney reads a NUMERIC column, as text so that it never passes through
+// a float, together with its currency column.
+func parseMoney(amount, currency string) (money.Money, error) {
+	return money.Parse(amount, money.Currency(currency))
+}
+
+// nullableMoney stores an absent optional amount as NULL.
+func nullableMoney(m *money.Money) interface{} {
+	if m == nil {
+		return nil
+	}
+	return m.Decimal()
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"reflect"
+	"testing"
+)
+
+func TestQueryBuilderNumbersArguments(t *testing.T) {
+	b := &queryBuilder{}
+	if clause := b.whereClause(); clause != "" {
+		t.Fatalf("got %q without conditions", clause)
+	}
+	b.where("a = " + b.arg(1))
+	b.where("b = " + b.arg("x"))
+	if clause := b.whereClause(); clause != " WHERE a = $1 AND b = $2" {
+		t.Fatalf("got %q", clause)
+	}
+	if !reflect.DeepEqual(b.args, []interface{}{1, "x"}) {
+		t.Fatalf("got args %v", b.args)
+	}
+}
+
+func TestApplyProductFilter(t *testing.T) {
+	minPrice, err := money.Parse("10.5", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &queryBuilder{}
+	b.arg("before")
+	applyProductFilter(b, domain.ProductFilter{
+		CategoryIDs: []uint64{3, 4},
+		MinPrice:    &minPrice,
+		Currency:    "EUR",
+		InStockOnly: true,
+	})
+
+	// A price bound only compares prices in its own currency, as a decimal.
+	want := []string{
+		"p.is_deleted = false",
+		"p.category_id = ANY($2)",
+		"p.currency = $3",
+		"p.price >= $4::numeric",
+		"p.stock > 0",
+	}
+	if !reflect.DeepEqual(b.conditions, want) {
+		t.Fatalf("got conditions %q", b.conditions)
+	}
+	if len(b.args) != 4 || b.args[2] != "EUR" || b.args[3] != "10.50" {
+		t.Fatalf("got args %v", b.args)
+	}
+}
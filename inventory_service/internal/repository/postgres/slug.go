@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"strconv"
+)
+
+// slugTable names where an entity keeps its current slug and the slugs it
+// used to have.
+type slugTable struct {
+	table     string
+	redirects string
+	owner     string
+	// fallback is the slug of an entity whose name spells nothing.
+	fallback string
+}
+
+var (
+	productSlugs  = slugTable{table: "products", redirects: "product_slug_redirects", owner: "product_id", fallback: "product"}
+	categorySlugs = slugTable{table: "categories", redirects: "category_slug_redirects", owner: "category_id", fallback: "category"}
+)
+
+// assign settles the slug a row is saved with: the requested one if any,
+// otherwise one derived from name. A derived slug gets the lowest numeric
+// suffix that no other row uses, currently or as a redirect. id is 0 for a
+// row that is not inserted yet.
+func (s slugTable) assign(ctx context.Context, tx *sql.Tx, id uint64, requested, name string) (string, error) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	base := domain.Slugify(name)
+	if base == "" {
+		base = s.fallback
+	}
+
+	query := `
+		SELECT slug FROM ` + s.table + ` WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2
+		UNION
+		SELECT slug FROM ` + s.redirects + ` WHERE (slug = $1 OR slug LIKE $1 || '-%') AND ` + s.owner + ` <> $2`
+
+	rows, err := tx.QueryContext(ctx, query, base, id)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", err
+		}
+		taken[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	slug := base
+	for n := 2; taken[slug]; n++ {
+		slug = base + "-" + strconv.Itoa(n)
+	}
+	return slug, nil
+}
+
+// move records that the row with id went from slug previous to slug current.
+// The current slug stops redirecting anywhere else, and the previous one now
+// redirects to this row.
+func (s slugTable) move(ctx context.Context, tx *sql.Tx, id uint64, previous, current string) error {
+	if previous == current {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM `+s.redirects+` WHERE slug = $1`, current); err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO ` + s.redirects + ` (slug, ` + s.owner + `, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (slug) DO UPDATE SET ` + s.owner + ` = EXCLUDED.` + s.owner + `, created_at = NOW()`
+	_, err := tx.ExecContext(ctx, query, previous, id)
+	return err
+}
+
+// claim drops any redirect from a slug a new row is created with.
+func (s slugTable) claim(ctx context.Context, tx *sql.Tx, slug string) error {
+	_, err := tx.ExecContext(ctx, `DELETE FROM `+s.redirects+` WHERE slug = $1`, slug)
+	return err
+}
+
+// redirect returns the current slug of the live row that used to have slug.
+func (s slugTable) redirect(ctx context.Context, db *sql.DB, slug string) (string, error) {
+	query := `
+		SELECT t.slug
+		FROM ` + s.redirects + ` r
+		JOIN ` + s.table + ` t ON t.id = r.` + s.owner + `
+		WHERE r.slug = $1 AND t.is_deleted = false`
+
+	var current string
+	err := db.QueryRowContext(ctx, query, slug).Scan(&current)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return current, err
+}
@@ -0,0 +1,121 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+)
+
+type stockAlertRepository struct {
+	db *sql.DB
+}
+
+func NewStockAlertRepository(db *sql.DB) domain.StockAlertRepository {
+	return &stockAlertRepository{db: db}
+}
+
+func (r *stockAlertRepository) SetProductReorderPoint(ctx context.Context, productID uint64, point *int) error {
+	query := `
+		UPDATE products
+		SET reorder_point = $1, updated_at = NOW()
+		WHERE id = $2 AND is_deleted = false`
+
+	return r.exec(ctx, query, domain.ErrProductNotFound, nullableInt(point), productID)
+}
+
+func (r *stockAlertRepository) SetCategoryReorderPoint(ctx context.Context, categoryID uint64, point *int) error {
+	query := `
+		UPDATE categories
+		SET reorder_point = $1, updated_at = NOW()
+		WHERE id = $2 AND is_deleted = false`
+
+	return r.exec(ctx, query, domain.ErrCategoryNotFound, nullableInt(point), categoryID)
+}
+
+func (r *stockAlertRepository) ListLowStock(ctx context.Context, defaultPoint int) ([]*domain.LowStockItem, error) {
+	query := `
+		SELECT p.id, p.name, COALESCE(p.category_id, 0), p.stock,
+			COALESCE(p.reorder_point, c.reorder_point, $1) AS reorder_point
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id AND c.is_deleted = false
+		WHERE p.is_deleted = false
+			AND p.stock <= COALESCE(p.reorder_point, c.reorder_point, $1)
+		ORDER BY p.stock, p.id`
+
+	rows, err := r.db.QueryContext(ctx, query, defaultPoint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*domain.LowStockItem{}
+	for rows.Next() {
+		item := &domain.LowStockItem{}
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.CategoryID, &item.Stock, &item.ReorderPoint); err != nil {
+			return nil, err
+		}
+		item.Level = domain.StockAlertLevelFor(item.Stock, item.ReorderPoint)
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (r *stockAlertRepository) AlertedLevels(ctx context.Context) (map[uint64]domain.StockAlertLevel, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT product_id, level FROM stock_alert_states`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	levels := make(map[uint64]domain.StockAlertLevel)
+	for rows.Next() {
+		var productID uint64
+		var level string
+		if err := rows.Scan(&productID, &level); err != nil {
+			return nil, err
+		}
+		levels[productID] = domain.StockAlertLevel(level)
+	}
+
+	return levels, rows.Err()
+}
+
+func (r *stockAlertRepository) RecordAlert(ctx context.Context, productID uint64, level domain.StockAlertLevel) error {
+	query := `
+		INSERT INTO stock_alert_states (product_id, level, alerted_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (product_id) DO UPDATE SET level = EXCLUDED.level, alerted_at = EXCLUDED.alerted_at`
+
+	_, err := r.db.ExecContext(ctx, query, productID, string(level))
+	return err
+}
+
+func (r *stockAlertRepository) ForgetAlerts(ctx context.Context, productIDs []uint64) error {
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM stock_alert_states WHERE product_id = ANY($1)`, pq.Array(ids))
+	return err
+}
+
+func (r *stockAlertRepository) exec(ctx context.Context, query string, notFound error, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type stockMovementRepository struct {
+	db *sql.DB
+}
+
+func NewStockMovementRepository(db *sql.DB) domain.StockMovementRepository {
+	return &stockMovementRepository{db: db}
+}
+
+func (r *stockMovementRepository) Apply(ctx context.Context, movement *domain.StockMovement) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if movement.VariantID == 0 {
+		components, err := bundleComponentsFor(ctx, tx, movement.ProductID)
+		if err != nil {
+			return 0, err
+		}
+		if len(components) > 0 {
+			return r.applyBundle(ctx, tx, movement, components)
+		}
+	}
+
+	var query string
+	var args []interface{}
+	if movement.VariantID == 0 {
+		query = `
+			UPDATE products
+			SET stock = stock + $1, version = version + 1, updated_at = NOW()
+			WHERE id = $2 AND is_deleted = false AND stock + $1 >= 0
+			RETURNING stock`
+		args = []interface{}{movement.Delta, movement.ProductID}
+	} else {
+		query = `
+			UPDATE product_variants
+			SET stock = stock + $1, version = version + 1, updated_at = NOW()
+			WHERE id = $2 AND product_id = $3 AND is_deleted = false AND stock + $1 >= 0
+			RETURNING stock`
+		args = []interface{}{movement.Delta, movement.VariantID, movement.ProductID}
+	}
+
+	var stock int
+	err = tx.QueryRowContext(ctx, query, args...).Scan(&stock)
+	if err == sql.ErrNoRows {
+		return 0, r.missOrInsufficient(ctx, movement)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return 0, err
+	}
+
+	return stock, tx.Commit()
+}
+
+// applyBundle sells, reserves or returns whole bundles by moving the stock of
+// every component, locked in product ID order. Each component gets its own
+// ledger entry referencing the bundle.
+func (r *stockMovementRepository) applyBundle(ctx context.Context, tx *sql.Tx, movement *domain.StockMovement, components []domain.BundleComponent) (int, error) {
+	if movement.Reason == domain.MovementRestock || movement.Reason == domain.MovementAdjustment {
+		return 0, domain.ErrBundleStockDerived
+	}
+
+	query := `
+		UPDATE products
+		SET stock = stock + $1, version = version + 1, updated_at = NOW()
+		WHERE id = $2 AND is_deleted = false AND stock + $1 >= 0
+		RETURNING id`
+	for _, c := range components {
+		delta := movement.Delta * c.Quantity
+		var id uint64
+		err := tx.QueryRowContext(ctx, query, delta, c.ProductID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return 0, domain.ErrInsufficientStock
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		componentMovement := *movement
+		componentMovement.ProductID = c.ProductID
+		componentMovement.Delta = delta
+		componentMovement.ReferenceID = bundleReference(movement.ProductID, movement.ReferenceID)
+		if err := insertStockMovement(ctx, tx, &componentMovement); err != nil {
+			return 0, err
+		}
+	}
+
+	// The bundle's own stock column catches up when the transaction commits.
+	var stock int
+	if err := tx.QueryRowContext(ctx, `SELECT bundle_stock($1)`, movement.ProductID).Scan(&stock); err != nil {
+		return 0, err
+	}
+	return stock, tx.Commit()
+}
+
+func (r *stockMovementRepository) List(ctx context.Context, filter domain.StockMovementFilter) ([]*domain.StockMovement, error) {
+	b := &queryBuilder{}
+	b.where("product_id = " + b.arg(filter.ProductID))
+	if filter.Reason != "" {
+		b.where("reason = " + b.arg(string(filter.Reason)))
+	}
+
+	query := `
+		SELECT id, product_id, COALESCE(variant_id, 0), COALESCE(warehouse_id, 0), delta, reason, COALESCE(reference_id, ''), actor, created_at
+		FROM stock_movements` + b.whereClause() + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ` + b.arg(filter.Limit) + ` OFFSET ` + b.arg(filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*domain.StockMovement
+	for rows.Next() {
+		m := &domain.StockMovement{}
+		var reason string
+		err := rows.Scan(&m.ID, &m.ProductID, &m.VariantID, &m.WarehouseID, &m.Delta, &reason, &m.ReferenceID, &m.Actor, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		m.Reason = domain.MovementReason(reason)
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+func (r *stockMovementRepository) Reconcile(ctx context.Context, productID uint64) ([]*domain.StockDiscrepancy, error) {
+	query := `
+		SELECT p.id, 0, 0, p.stock, COALESCE(SUM(m.delta), 0)
+		FROM products p
+		LEFT JOIN stock_movements m ON m.product_id = p.id AND m.variant_id IS NULL AND m.warehouse_id IS NULL
+		WHERE p.is_deleted = false AND ($1 = 0 OR p.id = $1)
+		  AND NOT EXISTS (SELECT 1 FROM bundles b WHERE b.product_id = p.id)
+		GROUP BY p.id
+		HAVING p.stock <> COALESCE(SUM(m.delta), 0)
+		UNION ALL
+		SELECT v.product_id, v.id, 0, v.stock, COALESCE(SUM(m.delta), 0)
+		FROM product_variants v
+		LEFT JOIN stock_movements m ON m.variant_id = v.id
+		WHERE v.is_deleted = false AND ($1 = 0 OR v.product_id = $1)
+		GROUP BY v.id
+		HAVING v.stock <> COALESCE(SUM(m.delta), 0)
+		UNION ALL
+		SELECT s.product_id, 0, s.warehouse_id, s.quantity, COALESCE(SUM(m.delta), 0)
+		FROM warehouse_stock s
+		JOIN products p ON p.id = s.product_id AND p.is_deleted = false
+		LEFT JOIN stock_movements m ON m.product_id = s.product_id AND m.warehouse_id = s.warehouse_id
+		WHERE ($1 = 0 OR s.product_id = $1)
+		GROUP BY s.product_id, s.warehouse_id
+		HAVING s.quantity <> COALESCE(SUM(m.delta), 0)
+		ORDER BY 1, 2, 3`
+
+	rows, err := r.db.QueryContext(ctx, query, int64(productID))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discrepancies := []*domain.StockDiscrepancy{}
+	for rows.Next() {
+		d := &domain.StockDiscrepancy{}
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.WarehouseID, &d.RecordedStock, &d.LedgerStock); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+
+	return discrepancies, rows.Err()
+}
+
+func (r *stockMovementRepository) missOrInsufficient(ctx context.Context, movement *domain.StockMovement) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_deleted = false)`
+	if err := r.db.QueryRowContext(ctx, query, movement.ProductID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrProductNotFound
+	}
+
+	if movement.VariantID != 0 {
+		query = `SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = $1 AND product_id = $2 AND is_deleted = false)`
+		if err := r.db.QueryRowContext(ctx, query, movement.VariantID, movement.ProductID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return domain.ErrVariantNotFound
+		}
+	}
+
+	return domain.ErrInsufficientStock
+}
+
+// insertStockMovement appends a movement to the ledger inside tx, so that it
+// commits or rolls back together with the stock change it describes, and
+// publishes a change of product or variant stock through the outbox.
+func insertStockMovement(ctx context.Context, tx *sql.Tx, movement *domain.StockMovement) error {
+	var referenceID interface{}
+	if movement.ReferenceID != "" {
+		referenceID = movement.ReferenceID
+	}
+
+	query := `
+		INSERT INTO stock_movements (product_id, variant_id, warehouse_id, delta, reason, reference_id, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING id, created_at`
+
+	err := tx.QueryRowContext(
+		ctx,
+		query,
+		movement.ProductID,
+		nullableID(movement.VariantID),
+		nullableID(movement.WarehouseID),
+		movement.Delta,
+		string(movement.Reason),
+		referenceID,
+		movement.Actor,
+	).Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		return err
+	}
+	// Consumers follow the stock on sale, which warehouse entries leave
+	// alone.
+	if movement.WarehouseID != 0 {
+		return nil
+	}
+
+	var stock int
+	if movement.VariantID == 0 {
+		err = tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE id = $1`, movement.ProductID).Scan(&stock)
+	} else {
+		err = tx.QueryRowContext(ctx, `SELECT stock FROM product_variants WHERE id = $1`, movement.VariantID).Scan(&stock)
+	}
+	if err != nil {
+		return err
+	}
+	return writeProductEvent(ctx, tx, domain.EventProductStockChanged, movement.ProductID, domain.NewStockChanged(movement, stock))
+}
+
+// recordStockChange writes an implicit movement for a stock column that was
+// set directly, such as on create or a full update. Nothing is recorded when
+// the stock did not change.
+func recordStockChange(ctx context.Context, tx *sql.Tx, productID, variantID uint64, delta int, reason domain.MovementReason) error {
+	if delta == 0 {
+		return nil
+	}
+	movement, err := domain.NewStockMovement(productID, variantID, delta, reason, "", domain.ActorFromContext(ctx))
+	if err != nil {
+		return err
+	}
+	return insertStockMovement(ctx, tx, movement)
+}
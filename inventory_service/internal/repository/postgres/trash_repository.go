@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/lib/pq"
+	"time"
+)
+
+type trashRepository struct {
+	db *sql.DB
+}
+
+func NewTrashRepository(db *sql.DB) domain.TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// PurgeProducts deletes one batch of products together with everything that
+// cascades from them, ledgers included. Rows locked by a concurrent restore
+// are skipped and picked up by a later run if they are still in the trash.
+// Components of a bundle wait until the bundle itself is gone.
+func (r *trashRepository) PurgeProducts(ctx context.Context, before time.Time, limit int) (*domain.PurgedProducts, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The ledger triggers only let rows go inside a purge.
+	if _, err := tx.ExecContext(ctx, `SET LOCAL inventory.purge = 'on'`); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id FROM products
+		WHERE is_deleted = true AND deleted_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM bundle_components bc WHERE bc.component_id = products.id)
+		ORDER BY deleted_at, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	purged := &domain.PurgedProducts{}
+	if len(ids) == 0 {
+		return purged, nil
+	}
+
+	query = `SELECT ` + productImageColumns + ` FROM product_images i WHERE i.product_id = ANY($1)`
+	rows, err = tx.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		image, err := scanProductImage(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		purged.StorageKeys = append(purged.StorageKeys, image.StorageKeys()...)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	purged.Count = int(count)
+	return purged, nil
+}
+
+// PurgeCategories deletes one batch of unreferenced categories. A subtree is
+// removed leaves first, one level per call.
+func (r *trashRepository) PurgeCategories(ctx context.Context, before time.Time, limit int) (int, error) {
+	query := `
+		DELETE FROM categories
+		WHERE id IN (
+			SELECT c.id FROM categories c
+			WHERE c.is_deleted = true AND c.deleted_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)
+			  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = c.id)
+			ORDER BY c.deleted_at, c.id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)`
+
+	result, err := r.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *trashRepository) CountBlockedCategories(ctx context.Context, before time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM categories c
+		WHERE c.is_deleted = true AND c.deleted_at < $1
+		  AND (EXISTS (SELECT 1 FROM products p WHERE p.category_id = c.id)
+		       OR EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = c.id))`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, before).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
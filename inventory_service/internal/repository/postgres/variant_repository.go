@@ -0,0 +1,296 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/lib/pq"
+	"time"
+)
+
+// Variant price overrides are in the currency of their product.
+const variantColumns = `v.id, v.product_id, v.sku, v.options, v.price, v.stock, v.created_at, v.updated_at, v.version,
+	(SELECT currency FROM products WHERE id = v.product_id)`
+
+type variantRepository struct {
+	db *sql.DB
+}
+
+func NewVariantRepository(db *sql.DB) domain.VariantRepository {
+	return &variantRepository{db: db}
+}
+
+func scanVariant(row rowScanner) (*domain.Variant, error) {
+	var id, productID uint64
+	var sku string
+	var options []byte
+	var price sql.NullString
+	var currency string
+	var stock int
+	var createdAt, updatedAt time.Time
+	var version int
+
+	if err := row.Scan(&id, &productID, &sku, &options, &price, &stock, &createdAt, &updatedAt, &version, &currency); err != nil {
+		return nil, err
+	}
+
+	var optionValues map[string]string
+	if err := json.Unmarshal(options, &optionValues); err != nil {
+		return nil, err
+	}
+	var priceOverride *money.Money
+	if price.Valid {
+		amount, err := parseMoney(price.String, currency)
+		if err != nil {
+			return nil, err
+		}
+		priceOverride = &amount
+	}
+
+	variant, err := domain.NewVariant(productID, sku, optionValues, priceOverride, stock)
+	if err != nil {
+		return nil, err
+	}
+	variant.SetID(id)
+	variant.SetVersion(version)
+	variant.SetTimestamps(createdAt, updatedAt)
+	return variant, nil
+}
+
+// checkVariantCurrency makes sure a price override is in the product's
+// currency.
+func checkVariantCurrency(ctx context.Context, tx *sql.Tx, variant *domain.Variant) error {
+	if variant.Price() == nil {
+		return nil
+	}
+	var currency string
+	query := `SELECT currency FROM products WHERE id = $1 AND is_deleted = false`
+	err := tx.QueryRowContext(ctx, query, variant.ProductID()).Scan(&currency)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if money.Currency(currency) != variant.Price().Currency() {
+		return domain.ErrCurrencyMismatch
+	}
+	return nil
+}
+
+func (r *variantRepository) Create(ctx context.Context, variant *domain.Variant) error {
+	options, err := json.Marshal(variant.Options())
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkVariantCurrency(ctx, tx, variant); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO product_variants (product_id, sku, options, price, stock, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, NOW(), NOW()
+		WHERE EXISTS (SELECT 1 FROM products WHERE id = $1 AND is_deleted = false)
+		RETURNING id, created_at, updated_at, version`
+
+	var id uint64
+	var createdAt, updatedAt time.Time
+	var version int
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		variant.ProductID(),
+		variant.SKU(),
+		options,
+		nullableMoney(variant.Price()),
+		variant.Stock(),
+	).Scan(&id, &createdAt, &updatedAt, &version)
+	if err == sql.ErrNoRows {
+		return domain.ErrProductNotFound
+	}
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateSKU
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := recordStockChange(ctx, tx, variant.ProductID(), id, variant.Stock(), domain.MovementRestock); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	variant.SetID(id)
+	variant.SetVersion(version)
+	variant.SetTimestamps(createdAt, updatedAt)
+	return nil
+}
+
+func (r *variantRepository) GetByID(ctx context.Context, id uint64) (*domain.Variant, error) {
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants v
+		WHERE v.id = $1 AND v.is_deleted = false`
+
+	variant, err := scanVariant(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return variant, nil
+}
+
+func (r *variantRepository) ListByProduct(ctx context.Context, productID uint64) ([]*domain.Variant, error) {
+	byProduct, err := r.ListByProducts(ctx, []uint64{productID})
+	if err != nil {
+		return nil, err
+	}
+	return byProduct[productID], nil
+}
+
+func (r *variantRepository) ListByProducts(ctx context.Context, productIDs []uint64) (map[uint64][]*domain.Variant, error) {
+	result := make(map[uint64][]*domain.Variant, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		SELECT ` + variantColumns + `
+		FROM product_variants v
+		WHERE v.product_id = ANY($1) AND v.is_deleted = false
+		ORDER BY v.product_id, v.id`
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		variant, err := scanVariant(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[variant.ProductID()] = append(result[variant.ProductID()], variant)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *variantRepository) Update(ctx context.Context, variant *domain.Variant) error {
+	options, err := json.Marshal(variant.Options())
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previousStock int
+	query := `SELECT stock FROM product_variants WHERE id = $1 AND version = $2 AND is_deleted = false FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, variant.ID(), variant.Version()).Scan(&previousStock)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, variant.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := checkVariantCurrency(ctx, tx, variant); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE product_variants
+		SET sku = $1, options = $2, price = $3, stock = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $5 AND version = $6 AND is_deleted = false
+		RETURNING version, updated_at`
+
+	var updatedAt time.Time
+	var version int
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		variant.SKU(),
+		options,
+		nullableMoney(variant.Price()),
+		variant.Stock(),
+		variant.ID(),
+		variant.Version(),
+	).Scan(&version, &updatedAt)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateSKU
+	}
+	if err != nil {
+		return err
+	}
+
+	delta := variant.Stock() - previousStock
+	if err := recordStockChange(ctx, tx, variant.ProductID(), variant.ID(), delta, domain.MovementAdjustment); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	variant.SetVersion(version)
+	variant.SetTimestamps(variant.CreatedAt(), updatedAt)
+	return nil
+}
+
+func (r *variantRepository) Delete(ctx context.Context, id uint64, version int) error {
+	query := `
+		UPDATE product_variants
+		SET is_deleted = true, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2 AND is_deleted = false`
+
+	result, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return r.missOrConflict(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *variantRepository) missOrConflict(ctx context.Context, id uint64) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = $1 AND is_deleted = false)`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrVariantNotFound
+	}
+	return domain.ErrVersionConflict
+}
@@ -0,0 +1,407 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"time"
+)
+
+const warehouseColumns = `w.id, w.code, w.name, w.latitude, w.longitude, w.is_active, w.created_at, w.updated_at, w.version`
+
+type warehouseRepository struct {
+	db *sql.DB
+}
+
+func NewWarehouseRepository(db *sql.DB) domain.WarehouseRepository {
+	return &warehouseRepository{db: db}
+}
+
+func scanWarehouse(row rowScanner) (*domain.Warehouse, error) {
+	var id uint64
+	var code, name string
+	var latitude, longitude sql.NullFloat64
+	var isActive bool
+	var createdAt, updatedAt time.Time
+	var version int
+
+	if err := row.Scan(&id, &code, &name, &latitude, &longitude, &isActive, &createdAt, &updatedAt, &version); err != nil {
+		return nil, err
+	}
+
+	var location *domain.GeoPoint
+	if latitude.Valid && longitude.Valid {
+		location = &domain.GeoPoint{Latitude: latitude.Float64, Longitude: longitude.Float64}
+	}
+
+	warehouse, err := domain.NewWarehouse(code, name, location)
+	if err != nil {
+		return nil, err
+	}
+	if err := warehouse.Apply(domain.WarehouseChanges{IsActive: &isActive}); err != nil {
+		return nil, err
+	}
+	warehouse.SetID(id)
+	warehouse.SetVersion(version)
+	warehouse.SetTimestamps(createdAt, updatedAt)
+	return warehouse, nil
+}
+
+func locationArgs(location *domain.GeoPoint) (interface{}, interface{}) {
+	if location == nil {
+		return nil, nil
+	}
+	return location.Latitude, location.Longitude
+}
+
+func (r *warehouseRepository) Create(ctx context.Context, warehouse *domain.Warehouse) error {
+	latitude, longitude := locationArgs(warehouse.Location())
+
+	query := `
+		INSERT INTO warehouses (code, name, latitude, longitude, is_active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		RETURNING id, created_at, updated_at, version`
+
+	var id uint64
+	var createdAt, updatedAt time.Time
+	var version int
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		warehouse.Code(),
+		warehouse.Name(),
+		latitude,
+		longitude,
+		warehouse.IsActive(),
+	).Scan(&id, &createdAt, &updatedAt, &version)
+	if isUniqueViolation(err) {
+		return domain.ErrDuplicateWarehouseCode
+	}
+	if err != nil {
+		return err
+	}
+
+	warehouse.SetID(id)
+	warehouse.SetVersion(version)
+	warehouse.SetTimestamps(createdAt, updatedAt)
+	return nil
+}
+
+func (r *warehouseRepository) GetByID(ctx context.Context, id uint64) (*domain.Warehouse, error) {
+	query := `
+		SELECT ` + warehouseColumns + `
+		FROM warehouses w
+		WHERE w.id = $1 AND w.is_deleted = false`
+
+	warehouse, err := scanWarehouse(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return warehouse, nil
+}
+
+func (r *warehouseRepository) List(ctx context.Context) ([]*domain.Warehouse, error) {
+	query := `
+		SELECT ` + warehouseColumns + `
+		FROM warehouses w
+		WHERE w.is_deleted = false
+		ORDER BY w.code`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var warehouses []*domain.Warehouse
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, warehouse)
+	}
+
+	return warehouses, rows.Err()
+}
+
+func (r *warehouseRepository) Update(ctx context.Context, warehouse *domain.Warehouse) error {
+	latitude, longitude := locationArgs(warehouse.Location())
+
+	query := `
+		UPDATE warehouses
+		SET name = $1, latitude = $2, longitude = $3, is_active = $4, version = version + 1, updated_at = NOW()
+		WHERE id = $5 AND version = $6 AND is_deleted = false
+		RETURNING version, updated_at`
+
+	var updatedAt time.Time
+	var version int
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		warehouse.Name(),
+		latitude,
+		longitude,
+		warehouse.IsActive(),
+		warehouse.ID(),
+		warehouse.Version(),
+	).Scan(&version, &updatedAt)
+	if err == sql.ErrNoRows {
+		return r.missOrConflict(ctx, warehouse.ID())
+	}
+	if err != nil {
+		return err
+	}
+
+	warehouse.SetVersion(version)
+	warehouse.SetTimestamps(warehouse.CreatedAt(), updatedAt)
+	return nil
+}
+
+func (r *warehouseRepository) Delete(ctx context.Context, id uint64, version int) error {
+	var holdsStock bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouse_stock WHERE warehouse_id = $1 AND quantity > 0)`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&holdsStock); err != nil {
+		return err
+	}
+	if holdsStock {
+		return domain.ErrWarehouseNotEmpty
+	}
+
+	query = `
+		UPDATE warehouses
+		SET is_deleted = true, is_active = false, version = version + 1, updated_at = NOW()
+		WHERE id = $1 AND version = $2 AND is_deleted = false`
+
+	result, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return r.missOrConflict(ctx, id)
+	}
+
+	return nil
+}
+
+func (r *warehouseRepository) StockLevels(ctx context.Context, productID uint64) ([]*domain.StockLevel, error) {
+	query := `
+		SELECT s.warehouse_id, s.product_id, s.quantity, s.updated_at
+		FROM warehouse_stock s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		WHERE s.product_id = $1 AND w.is_active = true AND w.is_deleted = false
+		ORDER BY s.warehouse_id`
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var levels []*domain.StockLevel
+	for rows.Next() {
+		level := &domain.StockLevel{}
+		if err := rows.Scan(&level.WarehouseID, &level.ProductID, &level.Quantity, &level.UpdatedAt); err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+
+	return levels, rows.Err()
+}
+
+func (r *warehouseRepository) AdjustStock(ctx context.Context, warehouseID, productID uint64, delta int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	quantity, err := adjustWarehouseStock(ctx, tx, warehouseID, productID, delta)
+	if err != nil {
+		return 0, err
+	}
+	movement, err := domain.NewWarehouseMovement(productID, warehouseID, delta, domain.MovementAdjustment, "", domain.ActorFromContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	if err := insertStockMovement(ctx, tx, movement); err != nil {
+		return 0, err
+	}
+
+	return quantity, tx.Commit()
+}
+
+func (r *warehouseRepository) Transfer(ctx context.Context, transfer *domain.StockTransfer) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Lock both stock rows in a fixed order so that opposite transfers
+	// between the same pair of warehouses cannot deadlock.
+	query := `
+		SELECT warehouse_id FROM warehouse_stock
+		WHERE product_id = $1 AND warehouse_id IN ($2, $3)
+		ORDER BY warehouse_id
+		FOR UPDATE`
+	rows, err := tx.QueryContext(ctx, query, transfer.ProductID, transfer.FromWarehouseID, transfer.ToWarehouseID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	if _, err := adjustWarehouseStock(ctx, tx, transfer.FromWarehouseID, transfer.ProductID, -transfer.Quantity); err != nil {
+		return err
+	}
+	if _, err := adjustWarehouseStock(ctx, tx, transfer.ToWarehouseID, transfer.ProductID, transfer.Quantity); err != nil {
+		return err
+	}
+
+	query = `
+		INSERT INTO stock_transfers (product_id, from_warehouse_id, to_warehouse_id, quantity, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		RETURNING id, created_at`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		transfer.ProductID,
+		transfer.FromWarehouseID,
+		transfer.ToWarehouseID,
+		transfer.Quantity,
+		transfer.Note,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Both sides go into the ledger under the transfer's ID.
+	reference := fmt.Sprintf("transfer:%d", transfer.ID)
+	sides := []struct {
+		warehouseID uint64
+		delta       int
+	}{
+		{transfer.FromWarehouseID, -transfer.Quantity},
+		{transfer.ToWarehouseID, transfer.Quantity},
+	}
+	for _, side := range sides {
+		movement, err := domain.NewWarehouseMovement(transfer.ProductID, side.warehouseID, side.delta, domain.MovementTransfer, reference, domain.ActorFromContext(ctx))
+		if err != nil {
+			return err
+		}
+		if err := insertStockMovement(ctx, tx, movement); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *warehouseRepository) ListTransfers(ctx context.Context, productID uint64, offset, limit int) ([]*domain.StockTransfer, error) {
+	query := `
+		SELECT id, product_id, from_warehouse_id, to_warehouse_id, quantity, COALESCE(note, ''), created_at
+		FROM stock_transfers
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, productID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transfers []*domain.StockTransfer
+	for rows.Next() {
+		t := &domain.StockTransfer{}
+		err := rows.Scan(&t.ID, &t.ProductID, &t.FromWarehouseID, &t.ToWarehouseID, &t.Quantity, &t.Note, &t.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, t)
+	}
+
+	return transfers, rows.Err()
+}
+
+// adjustWarehouseStock applies delta to a warehouse stock row inside tx,
+// creating the row on first receipt. It refuses inactive warehouses, unknown
+// products and changes that would drive the quantity negative.
+func adjustWarehouseStock(ctx context.Context, tx *sql.Tx, warehouseID, productID uint64, delta int) (int, error) {
+	var active bool
+	query := `SELECT is_active FROM warehouses WHERE id = $1 AND is_deleted = false`
+	err := tx.QueryRowContext(ctx, query, warehouseID).Scan(&active)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrWarehouseNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !active {
+		return 0, domain.ErrWarehouseInactive
+	}
+
+	var productExists bool
+	query = `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND is_deleted = false)`
+	if err := tx.QueryRowContext(ctx, query, productID).Scan(&productExists); err != nil {
+		return 0, err
+	}
+	if !productExists {
+		return 0, domain.ErrProductNotFound
+	}
+
+	if delta < 0 {
+		query = `
+			UPDATE warehouse_stock
+			SET quantity = quantity + $1, updated_at = NOW()
+			WHERE warehouse_id = $2 AND product_id = $3 AND quantity + $1 >= 0
+			RETURNING quantity`
+	} else {
+		query = `
+			INSERT INTO warehouse_stock (warehouse_id, product_id, quantity, updated_at)
+			VALUES ($2, $3, $1, NOW())
+			ON CONFLICT (warehouse_id, product_id)
+			DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = NOW()
+			RETURNING quantity`
+	}
+
+	var quantity int
+	err = tx.QueryRowContext(ctx, query, delta, warehouseID, productID).Scan(&quantity)
+	if err == sql.ErrNoRows {
+		return 0, domain.ErrInsufficientStock
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return quantity, nil
+}
+
+func (r *warehouseRepository) missOrConflict(ctx context.Context, id uint64) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM warehouses WHERE id = $1 AND is_deleted = false)`
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return domain.ErrWarehouseNotFound
+	}
+	return domain.ErrVersionConflict
+}
@@ -0,0 +1,255 @@
+package repotest
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"testing"
+)
+
+var categoryCases = []contractCase{
+	{"create assigns identity and slug", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Home & Garden", 0)
+		if category.ID() == 0 || category.Version() != 1 || category.Slug() != "home-garden" {
+			t.Fatalf("got id %d, version %d, slug %q", category.ID(), category.Version(), category.Slug())
+		}
+		stored := mustGetCategory(t, r, category.ID())
+		if stored.Name() != "Home & Garden" || stored.Slug() != "home-garden" || !stored.DeletedAt().IsZero() {
+			t.Fatalf("read back %q with slug %q", stored.Name(), stored.Slug())
+		}
+
+		second := createCategory(t, r, "Home Garden", 0)
+		if second.Slug() != "home-garden-2" {
+			t.Fatalf("got slug %q, want home-garden-2", second.Slug())
+		}
+	}},
+	{"missing rows read as nil", func(t *testing.T, r Repositories) {
+		category, err := r.Categories.GetByID(ctx, 999)
+		expectNoError(t, err)
+		if category != nil {
+			t.Fatal("got a category for a missing id")
+		}
+		category, err = r.Categories.GetBySlug(ctx, "missing")
+		expectNoError(t, err)
+		if category != nil {
+			t.Fatal("got a category for a missing slug")
+		}
+		category, err = r.Categories.GetByExternalID(ctx, "missing")
+		expectNoError(t, err)
+		if category != nil {
+			t.Fatal("got a category for a missing external id")
+		}
+		slug, err := r.Categories.SlugRedirect(ctx, "missing")
+		expectNoError(t, err)
+		if slug != "" {
+			t.Fatalf("got redirect to %q for a missing slug", slug)
+		}
+	}},
+	{"create checks parent and identifiers", func(t *testing.T, r Repositories) {
+		orphan := domain.NewCategory("Orphan", "")
+		orphan.SetParentID(999)
+		expectError(t, r.Categories.Create(ctx, orphan), domain.ErrParentCategoryNotFound)
+
+		first := domain.NewCategory("First", "")
+		first.SetExternalID("ext-1")
+		first.SetSlug("shared")
+		expectNoError(t, r.Categories.Create(ctx, first))
+
+		sameSlug := domain.NewCategory("Second", "")
+		sameSlug.SetSlug("shared")
+		expectError(t, r.Categories.Create(ctx, sameSlug), domain.ErrDuplicateSlug)
+
+		sameExternalID := domain.NewCategory("Third", "")
+		sameExternalID.SetExternalID("ext-1")
+		expectError(t, r.Categories.Create(ctx, sameExternalID), domain.ErrDuplicateExternalID)
+
+		found, err := r.Categories.GetByExternalID(ctx, "ext-1")
+		expectNoError(t, err)
+		if found == nil || found.ID() != first.ID() {
+			t.Fatal("external id lookup missed the category")
+		}
+	}},
+	{"update is versioned and redirects old slugs", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Phones", 0)
+		stale := mustGetCategory(t, r, category.ID())
+
+		category.Update("Mobile Phones", "Handsets")
+		category.SetSlug("mobile-phones")
+		expectNoError(t, r.Categories.Update(ctx, category))
+		if category.Version() != 2 {
+			t.Fatalf("got version %d, want 2", category.Version())
+		}
+
+		stale.Update("Stale", "")
+		expectError(t, r.Categories.Update(ctx, stale), domain.ErrVersionConflict)
+
+		missing := domain.NewCategory("Missing", "")
+		missing.SetID(999)
+		expectError(t, r.Categories.Update(ctx, missing), domain.ErrCategoryNotFound)
+
+		slug, err := r.Categories.SlugRedirect(ctx, "phones")
+		expectNoError(t, err)
+		if slug != "mobile-phones" {
+			t.Fatalf("got redirect to %q, want mobile-phones", slug)
+		}
+		stored := mustGetCategory(t, r, category.ID())
+		if stored.Name() != "Mobile Phones" || stored.Description() != "Handsets" {
+			t.Fatalf("read back %q, %q", stored.Name(), stored.Description())
+		}
+	}},
+	{"delete rejects a subtree with products", func(t *testing.T, r Repositories) {
+		parent := createCategory(t, r, "Parent", 0)
+		child := createCategory(t, r, "Child", parent.ID())
+		createProduct(t, r, "Lamp", "10.00", 1, child.ID())
+
+		err := r.Categories.Delete(ctx, parent.ID(), parent.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteReject})
+		expectError(t, err, domain.ErrCategoryHasProducts)
+		mustGetCategory(t, r, parent.ID())
+
+		err = r.Categories.Delete(ctx, parent.ID(), parent.Version()+1, domain.CategoryDeleteOptions{Policy: domain.DeleteReject})
+		expectError(t, err, domain.ErrVersionConflict)
+		err = r.Categories.Delete(ctx, 999, 1, domain.CategoryDeleteOptions{Policy: domain.DeleteReject})
+		expectError(t, err, domain.ErrCategoryNotFound)
+	}},
+	{"delete cascades to the subtree and its products", func(t *testing.T, r Repositories) {
+		parent := createCategory(t, r, "Parent", 0)
+		child := createCategory(t, r, "Child", parent.ID())
+		product := createProduct(t, r, "Lamp", "10.00", 1, child.ID())
+
+		err := r.Categories.Delete(ctx, parent.ID(), parent.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteCascade})
+		expectNoError(t, err)
+
+		for _, id := range []uint64{parent.ID(), child.ID()} {
+			category, err := r.Categories.GetByID(ctx, id)
+			expectNoError(t, err)
+			if category != nil {
+				t.Fatalf("category %d is still live", id)
+			}
+		}
+		gone, err := r.Products.GetByID(ctx, product.ID())
+		expectNoError(t, err)
+		if gone != nil {
+			t.Fatal("product is still live")
+		}
+
+		trashed, err := r.Categories.ListDeleted(ctx, 0, 10)
+		expectNoError(t, err)
+		if len(trashed) != 2 {
+			t.Fatalf("got %d trashed categories, want 2", len(trashed))
+		}
+		var trashedChild *domain.Category
+		for _, category := range trashed {
+			if category.DeletedAt().IsZero() {
+				t.Fatalf("trashed category %d has no deletion time", category.ID())
+			}
+			if category.ID() == child.ID() {
+				trashedChild = category
+			}
+		}
+		if trashedChild == nil {
+			t.Fatal("child is not in the trash")
+		}
+
+		// The child cannot come back before its parent does.
+		expectError(t, r.Categories.Restore(ctx, trashedChild.ID(), trashedChild.Version()), domain.ErrCategoryTrashed)
+	}},
+	{"delete reassigns products", func(t *testing.T, r Repositories) {
+		parent := createCategory(t, r, "Parent", 0)
+		child := createCategory(t, r, "Child", parent.ID())
+		target := createCategory(t, r, "Target", 0)
+		product := createProduct(t, r, "Lamp", "10.00", 1, child.ID())
+
+		reassign := func(targetID uint64) error {
+			return r.Categories.Delete(ctx, parent.ID(), parent.Version(),
+				domain.CategoryDeleteOptions{Policy: domain.DeleteReassign, TargetID: targetID})
+		}
+		expectError(t, reassign(child.ID()), domain.ErrInvalidReassignTarget)
+		expectError(t, reassign(999), domain.ErrReassignTargetNotFound)
+		expectNoError(t, reassign(target.ID()))
+
+		moved := mustGetProduct(t, r, product.ID())
+		if moved.CategoryID() != target.ID() || moved.Version() != product.Version()+1 {
+			t.Fatalf("got category %d, version %d", moved.CategoryID(), moved.Version())
+		}
+		if count := mustGetCategory(t, r, target.ID()).ProductCount(); count != 1 {
+			t.Fatalf("got product count %d, want 1", count)
+		}
+	}},
+	{"restore brings a category back", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Seasonal", 0)
+		expectNoError(t, r.Categories.Delete(ctx, category.ID(), category.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteReject}))
+
+		trashed, err := r.Categories.ListDeleted(ctx, 0, 10)
+		expectNoError(t, err)
+		expectIDs(t, categoryIDs(trashed), category.ID())
+		version := trashed[0].Version()
+
+		expectError(t, r.Categories.Restore(ctx, category.ID(), version+1), domain.ErrVersionConflict)
+		expectError(t, r.Categories.Restore(ctx, 999, 1), domain.ErrCategoryNotFound)
+		expectNoError(t, r.Categories.Restore(ctx, category.ID(), version))
+
+		restored := mustGetCategory(t, r, category.ID())
+		if !restored.DeletedAt().IsZero() || restored.Version() != version+1 {
+			t.Fatalf("got deleted at %v, version %d", restored.DeletedAt(), restored.Version())
+		}
+		expectError(t, r.Categories.Restore(ctx, category.ID(), restored.Version()), domain.ErrCategoryNotFound)
+	}},
+	{"restore brings back the subtree deleted with a category", func(t *testing.T, r Repositories) {
+		root := createCategory(t, r, "Electronics", 0)
+		phones := createCategory(t, r, "Phones", root.ID())
+		cases := createCategory(t, r, "Cases", phones.ID())
+		audio := createCategory(t, r, "Audio", root.ID())
+		reject := domain.CategoryDeleteOptions{Policy: domain.DeleteReject}
+
+		// Audio goes to the trash on its own before the rest of the tree.
+		expectNoError(t, r.Categories.Delete(ctx, audio.ID(), audio.Version(), reject))
+		root = mustGetCategory(t, r, root.ID())
+		expectNoError(t, r.Categories.Delete(ctx, root.ID(), root.Version(), reject))
+
+		expectNoError(t, r.Categories.Restore(ctx, root.ID(), root.Version()+1))
+		for _, id := range []uint64{root.ID(), phones.ID(), cases.ID()} {
+			if restored := mustGetCategory(t, r, id); !restored.DeletedAt().IsZero() {
+				t.Fatalf("category %d is still in the trash", id)
+			}
+		}
+		trashed, err := r.Categories.ListDeleted(ctx, 0, 10)
+		expectNoError(t, err)
+		expectIDs(t, categoryIDs(trashed), audio.ID())
+	}},
+	{"tree and path follow the hierarchy", func(t *testing.T, r Repositories) {
+		root := createCategory(t, r, "Electronics", 0)
+		phones := createCategory(t, r, "Phones", root.ID())
+		cases := createCategory(t, r, "Cases", phones.ID())
+		audio := createCategory(t, r, "Audio", root.ID())
+		other := createCategory(t, r, "Books", 0)
+
+		tree, err := r.Categories.Tree(ctx, root.ID())
+		expectNoError(t, err)
+		expectIDs(t, categoryIDs(tree), audio.ID(), cases.ID(), root.ID(), phones.ID())
+
+		all, err := r.Categories.Tree(ctx, 0)
+		expectNoError(t, err)
+		expectIDs(t, categoryIDs(all), audio.ID(), other.ID(), cases.ID(), root.ID(), phones.ID())
+
+		path, err := r.Categories.Path(ctx, cases.ID())
+		expectNoError(t, err)
+		expectIDs(t, categoryIDs(path), root.ID(), phones.ID(), cases.ID())
+	}},
+	{"move rejects cycles and missing parents", func(t *testing.T, r Repositories) {
+		root := createCategory(t, r, "Root", 0)
+		child := createCategory(t, r, "Child", root.ID())
+		other := createCategory(t, r, "Other", 0)
+
+		root.SetParentID(child.ID())
+		expectError(t, r.Categories.Move(ctx, root), domain.ErrCategoryCycle)
+		root.SetParentID(999)
+		expectError(t, r.Categories.Move(ctx, root), domain.ErrParentCategoryNotFound)
+
+		expectNoError(t, child.MoveTo(other.ID()))
+		expectNoError(t, r.Categories.Move(ctx, child))
+		if child.Version() != 2 {
+			t.Fatalf("got version %d, want 2", child.Version())
+		}
+		if parentID := mustGetCategory(t, r, child.ID()).ParentID(); parentID != other.ID() {
+			t.Fatalf("got parent %d, want %d", parentID, other.ID())
+		}
+	}},
+}
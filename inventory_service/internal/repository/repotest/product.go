@@ -0,0 +1,409 @@
+package repotest
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+var productCases = []contractCase{
+	{"create assigns identity and slug", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Lighting", 0)
+		product := newProduct(t, "Desk Lamp", "24.50", 3, category.ID())
+		product.SetIdentifiers("LAMP-1", "ext-lamp")
+		product.SetAttributes(map[string]interface{}{"color": "black"})
+		expectNoError(t, r.Products.Create(ctx, product))
+		if product.ID() == 0 || product.Version() != 1 || product.Slug() != "desk-lamp" {
+			t.Fatalf("got id %d, version %d, slug %q", product.ID(), product.Version(), product.Slug())
+		}
+
+		stored := mustGetProduct(t, r, product.ID())
+		if stored.Name() != "Desk Lamp" || stored.Price().Decimal() != "24.50" || stored.Stock() != 3 ||
+			stored.CategoryID() != category.ID() || stored.SKU() != "LAMP-1" || stored.ExternalID() != "ext-lamp" {
+			t.Fatalf("read back %q at %s, stock %d", stored.Name(), stored.Price(), stored.Stock())
+		}
+		if stored.Attributes()["color"] != "black" || stored.IsDeleted() {
+			t.Fatalf("read back attributes %v, deleted %v", stored.Attributes(), stored.IsDeleted())
+		}
+
+		for name, lookup := range map[string]func() (*domain.Product, error){
+			"sku":         func() (*domain.Product, error) { return r.Products.GetBySKU(ctx, "LAMP-1") },
+			"external id": func() (*domain.Product, error) { return r.Products.GetByExternalID(ctx, "ext-lamp") },
+			"slug":        func() (*domain.Product, error) { return r.Products.GetBySlug(ctx, "desk-lamp") },
+		} {
+			found, err := lookup()
+			expectNoError(t, err)
+			if found == nil || found.ID() != product.ID() {
+				t.Fatalf("%s lookup missed the product", name)
+			}
+		}
+
+		second := createProduct(t, r, "Desk-Lamp", "10.00", 0, category.ID())
+		if second.Slug() != "desk-lamp-2" {
+			t.Fatalf("got slug %q, want desk-lamp-2", second.Slug())
+		}
+	}},
+	{"derived slugs skip suffixes other names spell", func(t *testing.T, r Repositories) {
+		createProduct(t, r, "Lamp 2", "10.00", 0, 0)
+		createProduct(t, r, "Lamp", "10.00", 0, 0)
+		third := createProduct(t, r, "Lamp", "10.00", 0, 0)
+		if third.Slug() != "lamp-3" {
+			t.Fatalf("got slug %q, want lamp-3", third.Slug())
+		}
+		nameless := createProduct(t, r, "!!!", "10.00", 0, 0)
+		if nameless.Slug() != "product" {
+			t.Fatalf("got slug %q for a name that spells nothing", nameless.Slug())
+		}
+	}},
+	{"category zero leaves a product uncategorized", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Lighting", 0)
+		product := createProduct(t, r, "Lamp", "10.00", 1, 0)
+		if stored := mustGetProduct(t, r, product.ID()); stored.CategoryID() != 0 {
+			t.Fatalf("got category %d, want none", stored.CategoryID())
+		}
+
+		expectNoError(t, product.Update(product.Name(), "", product.Price(), 1, category.ID()))
+		expectNoError(t, r.Products.Update(ctx, product))
+		expectNoError(t, product.Update(product.Name(), "", product.Price(), 1, 0))
+		expectNoError(t, r.Products.Update(ctx, product))
+		if stored := mustGetProduct(t, r, product.ID()); stored.CategoryID() != 0 {
+			t.Fatalf("got category %d after moving out, want none", stored.CategoryID())
+		}
+		if count := mustGetCategory(t, r, category.ID()).ProductCount(); count != 0 {
+			t.Fatalf("got product count %d, want 0", count)
+		}
+	}},
+	{"missing rows read as nil", func(t *testing.T, r Repositories) {
+		for name, lookup := range map[string]func() (*domain.Product, error){
+			"id":          func() (*domain.Product, error) { return r.Products.GetByID(ctx, 999) },
+			"sku":         func() (*domain.Product, error) { return r.Products.GetBySKU(ctx, "missing") },
+			"external id": func() (*domain.Product, error) { return r.Products.GetByExternalID(ctx, "missing") },
+			"slug":        func() (*domain.Product, error) { return r.Products.GetBySlug(ctx, "missing") },
+		} {
+			product, err := lookup()
+			expectNoError(t, err)
+			if product != nil {
+				t.Fatalf("got a product for a missing %s", name)
+			}
+		}
+		slug, err := r.Products.SlugRedirect(ctx, "missing")
+		expectNoError(t, err)
+		if slug != "" {
+			t.Fatalf("got redirect to %q for a missing slug", slug)
+		}
+	}},
+	{"identifiers are unique among live products", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Tools", 0)
+		first := newProduct(t, "Hammer", "15.00", 1, category.ID())
+		first.SetIdentifiers("SKU-1", "ext-1")
+		first.SetSlug("hammer")
+		expectNoError(t, r.Products.Create(ctx, first))
+
+		sameSKU := newProduct(t, "Mallet", "15.00", 1, category.ID())
+		sameSKU.SetIdentifiers("SKU-1", "")
+		expectError(t, r.Products.Create(ctx, sameSKU), domain.ErrDuplicateSKU)
+
+		sameExternalID := newProduct(t, "Mallet", "15.00", 1, category.ID())
+		sameExternalID.SetIdentifiers("", "ext-1")
+		expectError(t, r.Products.Create(ctx, sameExternalID), domain.ErrDuplicateExternalID)
+
+		sameSlug := newProduct(t, "Mallet", "15.00", 1, category.ID())
+		sameSlug.SetSlug("hammer")
+		expectError(t, r.Products.Create(ctx, sameSlug), domain.ErrDuplicateSlug)
+
+		// A trashed product gives up its SKU but keeps its slug.
+		expectNoError(t, r.Products.Delete(ctx, first.ID(), first.Version()))
+		expectNoError(t, r.Products.Create(ctx, sameSKU))
+		expectError(t, r.Products.Create(ctx, sameSlug), domain.ErrDuplicateSlug)
+
+		trashed, err := r.Products.ListDeleted(ctx, 0, 10)
+		expectNoError(t, err)
+		expectIDs(t, productIDs(trashed), first.ID())
+		expectError(t, r.Products.Restore(ctx, first.ID(), trashed[0].Version()), domain.ErrDuplicateSKU)
+	}},
+	{"update is versioned and redirects old slugs", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Kitchen", 0)
+		product := createProduct(t, r, "Kettle", "30.00", 2, category.ID())
+		stale := mustGetProduct(t, r, product.ID())
+
+		expectNoError(t, product.Update("Electric Kettle", "1.7 l", product.Price(), 5, category.ID()))
+		product.SetSlug("electric-kettle")
+		expectNoError(t, r.Products.Update(ctx, product))
+		if product.Version() != 2 || product.Slug() != "electric-kettle" {
+			t.Fatalf("got version %d, slug %q", product.Version(), product.Slug())
+		}
+
+		expectError(t, r.Products.Update(ctx, stale), domain.ErrVersionConflict)
+		missing := newProduct(t, "Missing", "1.00", 0, category.ID())
+		missing.SetID(999)
+		expectError(t, r.Products.Update(ctx, missing), domain.ErrProductNotFound)
+
+		stored := mustGetProduct(t, r, product.ID())
+		if stored.Name() != "Electric Kettle" || stored.Stock() != 5 || stored.Version() != 2 {
+			t.Fatalf("read back %q, stock %d, version %d", stored.Name(), stored.Stock(), stored.Version())
+		}
+		slug, err := r.Products.SlugRedirect(ctx, "kettle")
+		expectNoError(t, err)
+		if slug != "electric-kettle" {
+			t.Fatalf("got redirect to %q, want electric-kettle", slug)
+		}
+
+		// A derived slug does not take one that still redirects.
+		other := createProduct(t, r, "Kettle", "20.00", 1, category.ID())
+		if other.Slug() != "kettle-2" {
+			t.Fatalf("got slug %q, want kettle-2", other.Slug())
+		}
+	}},
+	{"delete moves a product to the trash", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Garden", 0)
+		product := newProduct(t, "Rake", "12.00", 4, category.ID())
+		product.SetIdentifiers("RAKE", "")
+		expectNoError(t, r.Products.Create(ctx, product))
+
+		expectError(t, r.Products.Delete(ctx, product.ID(), product.Version()+1), domain.ErrVersionConflict)
+		expectNoError(t, r.Products.Delete(ctx, product.ID(), product.Version()))
+		expectError(t, r.Products.Delete(ctx, product.ID(), product.Version()+1), domain.ErrProductNotFound)
+
+		for name, lookup := range map[string]func() (*domain.Product, error){
+			"id":   func() (*domain.Product, error) { return r.Products.GetByID(ctx, product.ID()) },
+			"sku":  func() (*domain.Product, error) { return r.Products.GetBySKU(ctx, "RAKE") },
+			"slug": func() (*domain.Product, error) { return r.Products.GetBySlug(ctx, "rake") },
+		} {
+			found, err := lookup()
+			expectNoError(t, err)
+			if found != nil {
+				t.Fatalf("%s lookup found a trashed product", name)
+			}
+		}
+		page, err := r.Products.List(ctx, domain.ProductFilter{SortBy: domain.ProductSortCreatedAt, Limit: 10})
+		expectNoError(t, err)
+		if page.Total != 0 || len(page.Products) != 0 {
+			t.Fatalf("listing shows %d trashed products", page.Total)
+		}
+		if count := mustGetCategory(t, r, category.ID()).ProductCount(); count != 0 {
+			t.Fatalf("got product count %d, want 0", count)
+		}
+
+		trashed, err := r.Products.ListDeleted(ctx, 0, 10)
+		expectNoError(t, err)
+		expectIDs(t, productIDs(trashed), product.ID())
+		if !trashed[0].IsDeleted() || trashed[0].DeletedAt().IsZero() || trashed[0].Version() != 2 {
+			t.Fatalf("got deleted %v at %v, version %d", trashed[0].IsDeleted(), trashed[0].DeletedAt(), trashed[0].Version())
+		}
+	}},
+	{"restore brings a product back", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Toys", 0)
+		product := createProduct(t, r, "Kite", "8.00", 1, category.ID())
+		expectNoError(t, r.Products.Delete(ctx, product.ID(), product.Version()))
+
+		expectError(t, r.Products.Restore(ctx, product.ID(), 1), domain.ErrVersionConflict)
+		expectError(t, r.Products.Restore(ctx, 999, 1), domain.ErrProductNotFound)
+		expectNoError(t, r.Products.Restore(ctx, product.ID(), 2))
+
+		restored := mustGetProduct(t, r, product.ID())
+		if restored.IsDeleted() || !restored.DeletedAt().IsZero() || restored.Version() != 3 {
+			t.Fatalf("got deleted %v, version %d", restored.IsDeleted(), restored.Version())
+		}
+		expectError(t, r.Products.Restore(ctx, product.ID(), 3), domain.ErrProductNotFound)
+	}},
+	{"restore waits for a trashed category", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Outdoor", 0)
+		product := createProduct(t, r, "Tent", "120.00", 1, category.ID())
+		err := r.Categories.Delete(ctx, category.ID(), category.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteCascade})
+		expectNoError(t, err)
+
+		expectError(t, r.Products.Restore(ctx, product.ID(), product.Version()+1), domain.ErrCategoryTrashed)
+	}},
+	{"batch finds live products by id or sku", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Pantry", 0)
+		rice := newProduct(t, "Rice", "3.00", 10, category.ID())
+		rice.SetIdentifiers("RICE", "")
+		expectNoError(t, r.Products.Create(ctx, rice))
+		beans := newProduct(t, "Beans", "2.00", 10, category.ID())
+		beans.SetIdentifiers("BEANS", "")
+		expectNoError(t, r.Products.Create(ctx, beans))
+		flour := createProduct(t, r, "Flour", "4.00", 10, category.ID())
+		expectNoError(t, r.Products.Delete(ctx, flour.ID(), flour.Version()))
+
+		batch := domain.ProductBatch{IDs: []uint64{flour.ID(), rice.ID(), 999}, SKUs: []string{"BEANS", "RICE", "NONE"}}
+		expectNoError(t, batch.Validate())
+		found, err := r.Products.GetBatch(ctx, batch)
+		expectNoError(t, err)
+
+		result := batch.Resolve(found)
+		expectIDs(t, productIDs(result.Products), rice.ID(), beans.ID())
+		expectIDs(t, result.MissingIDs, flour.ID(), 999)
+		if len(result.MissingSKUs) != 1 || result.MissingSKUs[0] != "NONE" {
+			t.Fatalf("got missing skus %v, want [NONE]", result.MissingSKUs)
+		}
+	}},
+	{"list filters, sorts and pages", func(t *testing.T, r Repositories) {
+		parent := createCategory(t, r, "Electronics", 0)
+		child := createCategory(t, r, "Phones", parent.ID())
+		other := createCategory(t, r, "Books", 0)
+		cheap := createProduct(t, r, "Cable", "5.00", 10, parent.ID())
+		phone := createProduct(t, r, "Phone", "500.00", 2, child.ID())
+		empty := createProduct(t, r, "Charger", "25.00", 0, child.ID())
+		createProduct(t, r, "Novel", "12.00", 5, other.ID())
+
+		list := func(filter domain.ProductFilter) *domain.ProductPage {
+			t.Helper()
+			expectNoError(t, filter.Validate())
+			page, err := r.Products.List(ctx, filter)
+			expectNoError(t, err)
+			return page
+		}
+
+		page := list(domain.ProductFilter{CategoryIDs: []uint64{parent.ID()}, SortBy: domain.ProductSortPrice, Limit: 10})
+		expectIDs(t, productIDs(page.Products), cheap.ID())
+
+		page = list(domain.ProductFilter{CategoryIDs: []uint64{parent.ID()}, IncludeDescendants: true, SortBy: domain.ProductSortPrice, Limit: 10})
+		expectIDs(t, productIDs(page.Products), cheap.ID(), empty.ID(), phone.ID())
+
+		page = list(domain.ProductFilter{CategoryIDs: []uint64{parent.ID()}, IncludeDescendants: true, InStockOnly: true,
+			SortBy: domain.ProductSortPrice, SortDesc: true, Limit: 10})
+		expectIDs(t, productIDs(page.Products), phone.ID(), cheap.ID())
+
+		bounds := amounts(t, "USD", "10.00", "100.00")
+		page = list(domain.ProductFilter{MinPrice: &bounds[0], MaxPrice: &bounds[1], SortBy: domain.ProductSortName, Limit: 10})
+		if page.Total != 2 {
+			t.Fatalf("got total %d, want 2", page.Total)
+		}
+
+		// Keyset pages walk forward and back over the same rows.
+		filter := domain.ProductFilter{SortBy: domain.ProductSortPrice, Limit: 2}
+		first := list(filter)
+		if first.Total != 4 || first.Next == nil || first.Prev != nil {
+			t.Fatalf("first page: total %d, next %v, prev %v", first.Total, first.Next, first.Prev)
+		}
+		filter.Cursor = first.Next
+		second := list(filter)
+		expectIDs(t, productIDs(second.Products), empty.ID(), phone.ID())
+		if second.Next != nil || second.Prev == nil {
+			t.Fatalf("second page: next %v, prev %v", second.Next, second.Prev)
+		}
+		filter.Cursor = second.Prev
+		back := list(filter)
+		expectIDs(t, productIDs(back.Products), productIDs(first.Products)...)
+
+		filter.Cursor, filter.Offset = nil, 3
+		page = list(filter)
+		expectIDs(t, productIDs(page.Products), phone.ID())
+		if page.Prev == nil {
+			t.Fatal("offset page has no previous cursor")
+		}
+	}},
+	{"search skips trashed products", func(t *testing.T, r Repositories) {
+		category := createCategory(t, r, "Office", 0)
+		keyboard := createProduct(t, r, "Wireless Keyboard", "40.00", 1, category.ID())
+		old := createProduct(t, r, "Keyboard Cover", "5.00", 1, category.ID())
+		createProduct(t, r, "Mouse", "20.00", 1, category.ID())
+		expectNoError(t, r.Products.Delete(ctx, old.ID(), old.Version()))
+
+		query := domain.ProductSearchQuery{Text: "keyboard", Filter: domain.ProductFilter{Limit: 10}}
+		expectNoError(t, query.Validate())
+		results, err := r.Products.Search(ctx, query)
+		expectNoError(t, err)
+		if len(results) != 1 || results[0].Product.ID() != keyboard.ID() {
+			t.Fatalf("got %d results", len(results))
+		}
+	}},
+	{"facets ignore their own dimension", func(t *testing.T, r Repositories) {
+		lighting := createCategory(t, r, "Lighting", 0)
+		office := createCategory(t, r, "Office", 0)
+		lamp := newProduct(t, "Lamp", "20.00", 2, lighting.ID())
+		lamp.SetAttributes(map[string]interface{}{"color": "red"})
+		expectNoError(t, r.Products.Create(ctx, lamp))
+		bulb := newProduct(t, "Bulb", "5.00", 0, lighting.ID())
+		bulb.SetAttributes(map[string]interface{}{"color": "white"})
+		expectNoError(t, r.Products.Create(ctx, bulb))
+		chair := newProduct(t, "Chair", "120.00", 1, office.ID())
+		chair.SetAttributes(map[string]interface{}{"color": "red"})
+		expectNoError(t, r.Products.Create(ctx, chair))
+		trashed := createProduct(t, r, "Old lamp", "20.00", 1, lighting.ID())
+		expectNoError(t, r.Products.Delete(ctx, trashed.ID(), trashed.Version()))
+
+		filter := domain.ProductFilter{
+			CategoryIDs: []uint64{lighting.ID()},
+			InStockOnly: true,
+			Attributes:  []domain.AttributeFilter{{Code: "color", Operator: domain.AttributeEq, Values: []string{"red"}}},
+			Limit:       10,
+		}
+		request := domain.FacetRequest{Categories: true, Price: true, Stock: true, AttributeCodes: []string{"color"}, PriceBuckets: amounts(t, "USD", "10", "100")}
+		expectNoError(t, request.Validate(filter.PriceCurrency()))
+		facets, err := r.Products.Facets(ctx, filter, request)
+		expectNoError(t, err)
+
+		// In stock and red: the lamp and the chair, whatever their category.
+		if len(facets.Categories) != 2 {
+			t.Fatalf("got category facets %+v", facets.Categories)
+		}
+		for _, f := range facets.Categories {
+			if f.Count != 1 || (f.CategoryID == lighting.ID() && f.Name != "Lighting") {
+				t.Fatalf("got category facet %+v", f)
+			}
+		}
+
+		// In lighting and red, whatever its stock: only the lamp.
+		if facets.Stock == nil || facets.Stock.InStock != 1 || facets.Stock.OutOfStock != 0 {
+			t.Fatalf("got stock facet %+v", facets.Stock)
+		}
+
+		// Buckets below the first bound, between bounds and open-ended.
+		if len(facets.Prices) != 3 || !facets.Prices[0].Min.IsZero() || facets.Prices[0].Max.Decimal() != "10.00" || facets.Prices[2].Max != nil {
+			t.Fatalf("got price buckets %+v", facets.Prices)
+		}
+		if counts := []int{facets.Prices[0].Count, facets.Prices[1].Count, facets.Prices[2].Count}; counts[0] != 0 || counts[1] != 1 || counts[2] != 0 {
+			t.Fatalf("got price counts %v", counts)
+		}
+
+		// In lighting and in stock, whatever its color: only red.
+		colors := facets.Attributes["color"]
+		if len(colors) != 1 || colors[0].Value != "red" || colors[0].Count != 1 {
+			t.Fatalf("got color facet %+v", colors)
+		}
+
+		filter.InStockOnly = false
+		filter.Attributes = nil
+		facets, err = r.Products.Facets(ctx, filter, domain.FacetRequest{AttributeCodes: []string{"color"}})
+		expectNoError(t, err)
+		colors = facets.Attributes["color"]
+		if len(colors) != 2 || colors[0].Count != 1 || colors[1].Count != 1 || colors[0].Value != "red" {
+			t.Fatalf("got color facet %+v, want red and white ordered by value", colors)
+		}
+	}},
+	{"price filters and facets stay in one currency", func(t *testing.T, r Repositories) {
+		createProduct(t, r, "Lamp", "20.00", 1, 0)
+		euros, err := money.Parse("20.00", "EUR")
+		expectNoError(t, err)
+		chair, err := domain.NewProduct("Chair", "", euros, 1, 0)
+		expectNoError(t, err)
+		expectNoError(t, r.Products.Create(ctx, chair))
+
+		min := amounts(t, "EUR", "19.99")[0]
+		filter := domain.ProductFilter{MinPrice: &min, Currency: "EUR", SortBy: domain.ProductSortName, Limit: 10}
+		page, err := r.Products.List(ctx, filter)
+		expectNoError(t, err)
+		expectIDs(t, productIDs(page.Products), chair.ID())
+
+		// Without bounds the currency restricts nothing but the price facet.
+		filter.MinPrice = nil
+		page, err = r.Products.List(ctx, filter)
+		expectNoError(t, err)
+		if len(page.Products) != 2 {
+			t.Fatalf("got %d products, want both", len(page.Products))
+		}
+
+		request := domain.FacetRequest{Price: true, Stock: true, PriceBuckets: amounts(t, "EUR", "0", "20.00", "100")}
+		facets, err := r.Products.Facets(ctx, filter, request)
+		expectNoError(t, err)
+		// 20.00 is the lower bound of the second bucket, not the upper
+		// bound of the first.
+		if facets.PriceCurrency != "EUR" || facets.Prices[0].Count != 0 || facets.Prices[1].Count != 1 || facets.Prices[2].Count != 0 {
+			t.Fatalf("got price facets %+v in %s", facets.Prices, facets.PriceCurrency)
+		}
+		if facets.Stock.InStock != 2 {
+			t.Fatalf("got stock facet %+v, want both currencies counted", facets.Stock)
+		}
+	}},
+}
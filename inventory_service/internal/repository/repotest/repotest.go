@@ -0,0 +1,156 @@
+// Package repotest is the contract the catalog repositories share. Each
+// implementation runs it from its own tests, so that behavior callers rely
+// on, such as soft deletes and nil for a missing row, stays the same across
+// them.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+// Repositories are the implementations under test. They must share one
+// store, since category deletes act on products.
+type Repositories struct {
+	Products   domain.ProductRepository
+	Categories domain.CategoryRepository
+}
+
+// Factory returns repositories over an empty catalog. It is called once per
+// case.
+type Factory func(t *testing.T) Repositories
+
+// Run runs the whole contract against the repositories factory builds.
+func Run(t *testing.T, factory Factory) {
+	t.Run("category", func(t *testing.T) { runCases(t, factory, categoryCases) })
+	t.Run("product", func(t *testing.T) { runCases(t, factory, productCases) })
+}
+
+type contractCase struct {
+	name string
+	run  func(t *testing.T, r Repositories)
+}
+
+func runCases(t *testing.T, factory Factory, cases []contractCase) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) { c.run(t, factory(t)) })
+	}
+}
+
+var ctx = context.Background()
+
+func createCategory(t *testing.T, r Repositories, name string, parentID uint64) *domain.Category {
+	t.Helper()
+	category := domain.NewCategory(name, "")
+	category.SetParentID(parentID)
+	if err := r.Categories.Create(ctx, category); err != nil {
+		t.Fatalf("create category %q: %v", name, err)
+	}
+	return category
+}
+
+func newProduct(t *testing.T, name, price string, stock int, categoryID uint64) *domain.Product {
+	t.Helper()
+	amount, err := money.Parse(price, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	product, err := domain.NewProduct(name, "", amount, stock, categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+// amounts parses decimal amounts in currency.
+func amounts(t *testing.T, currency money.Currency, decimals ...string) []money.Money {
+	t.Helper()
+	parsed := make([]money.Money, len(decimals))
+	for i, decimal := range decimals {
+		amount, err := money.Parse(decimal, currency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed[i] = amount
+	}
+	return parsed
+}
+
+func createProduct(t *testing.T, r Repositories, name, price string, stock int, categoryID uint64) *domain.Product {
+	t.Helper()
+	product := newProduct(t, name, price, stock, categoryID)
+	if err := r.Products.Create(ctx, product); err != nil {
+		t.Fatalf("create product %q: %v", name, err)
+	}
+	return product
+}
+
+func mustGetProduct(t *testing.T, r Repositories, id uint64) *domain.Product {
+	t.Helper()
+	product, err := r.Products.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product == nil {
+		t.Fatalf("product %d not found", id)
+	}
+	return product
+}
+
+func mustGetCategory(t *testing.T, r Repositories, id uint64) *domain.Category {
+	t.Helper()
+	category, err := r.Categories.GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if category == nil {
+		t.Fatalf("category %d not found", id)
+	}
+	return category
+}
+
+func expectError(t *testing.T, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("got error %v, want %v", err, want)
+	}
+}
+
+func expectNoError(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func productIDs(products []*domain.Product) []uint64 {
+	ids := make([]uint64, len(products))
+	for i, product := range products {
+		ids[i] = product.ID()
+	}
+	return ids
+}
+
+func categoryIDs(categories []*domain.Category) []uint64 {
+	ids := make([]uint64, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID()
+	}
+	return ids
+}
+
+func expectIDs(t *testing.T, got []uint64, want ...uint64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got ids %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("got ids %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type AttributeUseCase struct {
+	attributeRepo domain.AttributeRepository
+	categoryRepo  domain.CategoryRepository
+}
+
+func NewAttributeUseCase(attributeRepo domain.AttributeRepository, categoryRepo domain.CategoryRepository) *AttributeUseCase {
+	return &AttributeUseCase{
+		attributeRepo: attributeRepo,
+		categoryRepo:  categoryRepo,
+	}
+}
+
+func (u *AttributeUseCase) CreateAttribute(ctx context.Context, definition *domain.AttributeDefinition) error {
+	return u.attributeRepo.Create(ctx, definition)
+}
+
+func (u *AttributeUseCase) ListAttributes(ctx context.Context, categoryID uint64, inherited bool) ([]*domain.AttributeDefinition, error) {
+	category, err := u.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return u.attributeRepo.ListForCategory(ctx, categoryID, inherited)
+}
+
+func (u *AttributeUseCase) UpdateAttribute(ctx context.Context, categoryID, id uint64, name string, attrType domain.AttributeType, unit string, required bool, allowedValues []string) (*domain.AttributeDefinition, error) {
+	definition, err := u.getAttribute(ctx, categoryID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := definition.Update(name, attrType, unit, required, allowedValues); err != nil {
+		return nil, err
+	}
+	if err := u.attributeRepo.Update(ctx, definition); err != nil {
+		return nil, err
+	}
+	return definition, nil
+}
+
+func (u *AttributeUseCase) DeleteAttribute(ctx context.Context, categoryID, id uint64) error {
+	if _, err := u.getAttribute(ctx, categoryID, id); err != nil {
+		return err
+	}
+	return u.attributeRepo.Delete(ctx, id)
+}
+
+func (u *AttributeUseCase) getAttribute(ctx context.Context, categoryID, id uint64) (*domain.AttributeDefinition, error) {
+	definition, err := u.attributeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if definition == nil || definition.CategoryID != categoryID {
+		return nil, domain.ErrAttributeNotFound
+	}
+	return definition, nil
+}
+
+// ApplyAttributes validates attribute values against the schema of the
+// product's category and stores them on the product. When values is nil the
+// product keeps its current values, minus any the schema no longer defines,
+// so that a product moved between categories can still be saved. Callers
+// skip it when neither the values nor the category change, since stored
+// values are not re-checked against later schema changes.
+func (u *AttributeUseCase) ApplyAttributes(ctx context.Context, product *domain.Product, values map[string]interface{}) error {
+	var schema []*domain.AttributeDefinition
+	if product.CategoryID() != 0 {
+		var err error
+		if schema, err = u.attributeRepo.ListForCategory(ctx, product.CategoryID(), true); err != nil {
+			return err
+		}
+	}
+
+	if values == nil {
+		defined := make(map[string]bool, len(schema))
+		for _, d := range schema {
+			defined[d.Code] = true
+		}
+		values = make(map[string]interface{}, len(product.Attributes()))
+		for code, value := range product.Attributes() {
+			if defined[code] {
+				values[code] = value
+			}
+		}
+	}
+
+	normalized, err := domain.ValidateAttributes(schema, values)
+	if err != nil {
+		return err
+	}
+	product.SetAttributes(normalized)
+	return nil
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"testing"
+)
+
+// schemaStore holds attribute definitions per category, without inheritance.
+type schemaStore struct {
+	domain.AttributeRepository
+	definitions map[uint64][]*domain.AttributeDefinition
+}
+
+func (s *schemaStore) ListForCategory(_ context.Context, categoryID uint64, _ bool) ([]*domain.AttributeDefinition, error) {
+	return s.definitions[categoryID], nil
+}
+
+func (s *schemaStore) define(t *testing.T, categoryID uint64, code string, attrType domain.AttributeType, required bool) {
+	t.Helper()
+	definition, err := domain.NewAttributeDefinition(categoryID, code, code, attrType, "", required, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.definitions[categoryID] = append(s.definitions[categoryID], definition)
+}
+
+func TestApplyAttributes(t *testing.T) {
+	ctx := context.Background()
+	schema := &schemaStore{definitions: map[uint64][]*domain.AttributeDefinition{}}
+	schema.define(t, 1, "ram_gb", domain.AttributeNumber, true)
+	schema.define(t, 1, "color", domain.AttributeString, false)
+	attributes := NewAttributeUseCase(schema, memory.NewCategoryRepository(memory.NewStore()))
+
+	product := newTestProduct(t, 1)
+	if err := attributes.ApplyAttributes(ctx, product, map[string]interface{}{"color": "red"}); domain.KindOf(err) != domain.KindUnprocessable {
+		t.Fatalf("got error %v without a required attribute", err)
+	}
+	if err := attributes.ApplyAttributes(ctx, product, map[string]interface{}{"ram_gb": 16.0, "size": "xl"}); domain.KindOf(err) != domain.KindUnprocessable {
+		t.Fatalf("got error %v for an undefined attribute", err)
+	}
+	if err := attributes.ApplyAttributes(ctx, product, map[string]interface{}{"ram_gb": 16.0, "color": "red"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving to a category without the attributes drops them instead of
+	// failing.
+	if err := product.Update(product.Name(), "", product.Price(), product.Stock(), 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := attributes.ApplyAttributes(ctx, product, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(product.Attributes()) != 0 {
+		t.Fatalf("got %v, want no attributes", product.Attributes())
+	}
+}
+
+func TestUpdateProductValidatesChangedAttributesOnly(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	categories := memory.NewCategoryRepository(store)
+	schema := &schemaStore{definitions: map[uint64][]*domain.AttributeDefinition{}}
+	products := NewProductUseCase(memory.NewProductRepository(store), categories, nil, nil, nil, NewAttributeUseCase(schema, categories))
+
+	lamps := domain.NewCategory("Lamps", "")
+	desks := domain.NewCategory("Desks", "")
+	for _, category := range []*domain.Category{lamps, desks} {
+		if err := categories.Create(ctx, category); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schema.define(t, lamps.ID(), "color", domain.AttributeString, false)
+
+	product := newTestProduct(t, lamps.ID())
+	if err := products.CreateProduct(ctx, product, map[string]interface{}{"color": "red"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A required attribute added later does not block edits that leave the
+	// attributes and the category alone.
+	schema.define(t, lamps.ID(), "watts", domain.AttributeNumber, true)
+	changes := domain.ProductChanges{Name: "Desk lamp", Price: product.Price(), Stock: 3, CategoryID: lamps.ID()}
+	updated, err := products.UpdateProduct(ctx, product.ID(), product.Version(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Attributes()["color"] != "red" {
+		t.Fatalf("got attributes %v, want them kept", updated.Attributes())
+	}
+
+	changes.Attributes = map[string]interface{}{"color": "blue"}
+	if _, err := products.UpdateProduct(ctx, product.ID(), updated.Version(), changes); domain.KindOf(err) != domain.KindUnprocessable {
+		t.Fatalf("got error %v for changed attributes missing a required one", err)
+	}
+
+	// A move re-checks the kept values against the new category's schema.
+	schema.define(t, desks.ID(), "width_cm", domain.AttributeNumber, true)
+	moved := domain.ProductChanges{Name: "Desk lamp", Price: product.Price(), Stock: 3, CategoryID: desks.ID()}
+	if _, err := products.UpdateProduct(ctx, product.ID(), updated.Version(), moved); domain.KindOf(err) != domain.KindUnprocessable {
+		t.Fatalf("got error %v moving to a category with a required attribute", err)
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type BundleUseCase struct {
+	bundleRepo  domain.BundleRepository
+	productRepo domain.ProductRepository
+}
+
+func NewBundleUseCase(bundleRepo domain.BundleRepository, productRepo domain.ProductRepository) *BundleUseCase {
+	return &BundleUseCase{
+		bundleRepo:  bundleRepo,
+		productRepo: productRepo,
+	}
+}
+
+// GetBundle returns the bundle definition of a product with the current
+// state of its components.
+func (u *BundleUseCase) GetBundle(ctx context.Context, productID uint64) (*domain.Bundle, error) {
+	bundle, err := u.bundleRepo.Get(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if bundle != nil {
+		return bundle, nil
+	}
+
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return nil, domain.ErrBundleNotFound
+}
+
+// SaveBundle makes a product a bundle of the given components, or replaces
+// the components of an existing bundle.
+func (u *BundleUseCase) SaveBundle(ctx context.Context, productID uint64, pricing domain.BundlePricing, discount string, components []domain.BundleComponent) (*domain.Bundle, error) {
+	bundle, err := domain.NewBundle(productID, pricing, discount, components)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.bundleRepo.Save(ctx, bundle); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// DeleteBundle turns a bundle back into a plain product without stock.
+func (u *BundleUseCase) DeleteBundle(ctx context.Context, productID uint64) error {
+	return u.bundleRepo.Delete(ctx, productID)
+}
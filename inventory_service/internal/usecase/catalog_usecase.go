@@ -0,0 +1,393 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"io"
+	"log"
+)
+
+// importProgressEvery is how many rows pass between job snapshots.
+const importProgressEvery = 100
+
+// exportPageSize is how many products are read per query while exporting.
+const exportPageSize = 500
+
+type CatalogUseCase struct {
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	jobs         domain.ImportJobStore
+
+	attributeUseCase *AttributeUseCase
+}
+
+func NewCatalogUseCase(productRepo domain.ProductRepository, categoryRepo domain.CategoryRepository, attributeUseCase *AttributeUseCase, jobs domain.ImportJobStore) *CatalogUseCase {
+	return &CatalogUseCase{
+		productRepo:      productRepo,
+		categoryRepo:     categoryRepo,
+		jobs:             jobs,
+		attributeUseCase: attributeUseCase,
+	}
+}
+
+// StartProductImport queues an import of product records, upserting each by
+// SKU or, failing that, external ID. The job runs in the background and
+// calls done once it no longer needs the reader.
+func (u *CatalogUseCase) StartProductImport(ctx context.Context, format domain.CatalogFormat, dryRun bool, reader domain.ProductRecordReader, done func()) (*domain.ImportJob, error) {
+	importer := &productImporter{u: u, dryRun: dryRun, categories: map[string]uint64{}, pending: map[string]bool{}}
+	return u.startImport(ctx, domain.CatalogProducts, format, dryRun, done, func(ctx context.Context, job *domain.ImportJob) error {
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil && !u.recordRowError(job, err) {
+				return err
+			}
+			if err == nil {
+				created, err := importer.apply(ctx, record)
+				if err != nil {
+					job.RecordRowError(domain.ImportRowError{Row: record.Row, Key: record.Key(), Message: err.Error()})
+				} else {
+					job.RecordRow(created)
+				}
+			}
+			u.saveProgress(ctx, job)
+		}
+	})
+}
+
+// StartCategoryImport queues an import of category records, upserting each by
+// external ID. Parents are resolved by external ID, so they must come first.
+func (u *CatalogUseCase) StartCategoryImport(ctx context.Context, format domain.CatalogFormat, dryRun bool, reader domain.CategoryRecordReader, done func()) (*domain.ImportJob, error) {
+	importer := &categoryImporter{u: u, dryRun: dryRun, pending: map[string]bool{}}
+	return u.startImport(ctx, domain.CatalogCategories, format, dryRun, done, func(ctx context.Context, job *domain.ImportJob) error {
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil && !u.recordRowError(job, err) {
+				return err
+			}
+			if err == nil {
+				created, err := importer.apply(ctx, record)
+				if err != nil {
+					job.RecordRowError(domain.ImportRowError{Row: record.Row, Key: record.ExternalID, Message: err.Error()})
+				} else {
+					job.RecordRow(created)
+				}
+			}
+			u.saveProgress(ctx, job)
+		}
+	})
+}
+
+func (u *CatalogUseCase) GetImportJob(ctx context.Context, id string) (*domain.ImportJob, error) {
+	job, err := u.jobs.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, domain.ErrImportJobNotFound
+	}
+	return job, nil
+}
+
+func (u *CatalogUseCase) startImport(ctx context.Context, entity domain.CatalogEntity, format domain.CatalogFormat, dryRun bool, done func(), run func(context.Context, *domain.ImportJob) error) (*domain.ImportJob, error) {
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	job := domain.NewImportJob(id, entity, format, dryRun)
+	if err := u.jobs.Save(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// The job outlives the request, but its writes are still attributed to
+	// the caller.
+	jobCtx := domain.WithActor(context.Background(), domain.ActorFromContext(ctx))
+	snapshot := *job
+	go func() {
+		defer done()
+		job.Start()
+		u.saveProgress(jobCtx, job)
+		job.Finish(run(jobCtx, job))
+		if err := u.jobs.Save(jobCtx, job); err != nil {
+			log.Printf("import job %s: saving final state: %v", job.ID, err)
+		}
+	}()
+
+	return &snapshot, nil
+}
+
+// recordRowError adds a reader error to the job when it only affects a
+// single row, and reports whether the import can go on.
+func (u *CatalogUseCase) recordRowError(job *domain.ImportJob, err error) bool {
+	var rowErr *domain.ImportRowError
+	if !errors.As(err, &rowErr) {
+		return false
+	}
+	job.RecordRowError(*rowErr)
+	return true
+}
+
+func (u *CatalogUseCase) saveProgress(ctx context.Context, job *domain.ImportJob) {
+	if job.Processed%importProgressEvery != 0 {
+		return
+	}
+	if err := u.jobs.Save(ctx, job); err != nil {
+		log.Printf("import job %s: saving progress: %v", job.ID, err)
+	}
+}
+
+// randomID returns 32 random hex characters for identifiers that must not be
+// guessable.
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+type productImporter struct {
+	u          *CatalogUseCase
+	dryRun     bool
+	categories map[string]uint64
+	// pending holds keys a dry run would have created, so that a repeated
+	// key later in the file counts as an update as it would for real.
+	pending map[string]bool
+}
+
+func (i *productImporter) apply(ctx context.Context, record *domain.ProductRecord) (bool, error) {
+	if record.SKU == "" && record.ExternalID == "" {
+		return false, domain.ErrMissingImportKey
+	}
+	if record.Name == "" {
+		return false, domain.ErrMissingName
+	}
+
+	categoryID, err := i.categoryID(ctx, record.CategoryExternalID)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := i.find(ctx, record)
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil {
+		stock := 0
+		if record.Stock != nil {
+			stock = *record.Stock
+		}
+		product, err := domain.NewProduct(record.Name, record.Description, record.Price, stock, categoryID)
+		if err != nil {
+			return false, err
+		}
+		product.SetIdentifiers(record.SKU, record.ExternalID)
+		attributes := record.Attributes
+		if attributes == nil {
+			attributes = map[string]interface{}{}
+		}
+		if err := i.u.attributeUseCase.ApplyAttributes(ctx, product, attributes); err != nil {
+			return false, err
+		}
+		if i.dryRun {
+			if i.pending[record.Key()] {
+				return false, nil
+			}
+			i.pending[record.Key()] = true
+			return true, nil
+		}
+		return true, i.u.productRepo.Create(ctx, product)
+	}
+
+	if record.CategoryExternalID == "" {
+		categoryID = existing.CategoryID()
+	}
+	previousCategoryID := existing.CategoryID()
+	stock := existing.Stock()
+	if record.Stock != nil {
+		stock = *record.Stock
+	}
+	if err := existing.Update(record.Name, record.Description, record.Price, stock, categoryID); err != nil {
+		return false, err
+	}
+	sku, externalID := existing.SKU(), existing.ExternalID()
+	if record.SKU != "" {
+		sku = record.SKU
+	}
+	if record.ExternalID != "" {
+		externalID = record.ExternalID
+	}
+	existing.SetIdentifiers(sku, externalID)
+	if record.Attributes != nil || existing.CategoryID() != previousCategoryID {
+		if err := i.u.attributeUseCase.ApplyAttributes(ctx, existing, record.Attributes); err != nil {
+			return false, err
+		}
+	}
+	if i.dryRun {
+		return false, nil
+	}
+	return false, i.u.productRepo.Update(ctx, existing)
+}
+
+func (i *productImporter) find(ctx context.Context, record *domain.ProductRecord) (*domain.Product, error) {
+	if record.SKU != "" {
+		product, err := i.u.productRepo.GetBySKU(ctx, record.SKU)
+		if err != nil || product != nil {
+			return product, err
+		}
+	}
+	if record.ExternalID != "" {
+		return i.u.productRepo.GetByExternalID(ctx, record.ExternalID)
+	}
+	return nil, nil
+}
+
+func (i *productImporter) categoryID(ctx context.Context, externalID string) (uint64, error) {
+	if externalID == "" {
+		return 0, nil
+	}
+	if id, ok := i.categories[externalID]; ok {
+		return id, nil
+	}
+
+	category, err := i.u.categoryRepo.GetByExternalID(ctx, externalID)
+	if err != nil {
+		return 0, err
+	}
+	if category == nil {
+		return 0, domain.ErrCategoryNotFound
+	}
+	i.categories[externalID] = category.ID()
+	return category.ID(), nil
+}
+
+type categoryImporter struct {
+	u       *CatalogUseCase
+	dryRun  bool
+	pending map[string]bool
+}
+
+func (i *categoryImporter) apply(ctx context.Context, record *domain.CategoryRecord) (bool, error) {
+	if record.ExternalID == "" {
+		return false, domain.ErrMissingExternalID
+	}
+	if record.Name == "" {
+		return false, domain.ErrMissingName
+	}
+
+	var parentID uint64
+	if record.ParentExternalID != "" {
+		parent, err := i.u.categoryRepo.GetByExternalID(ctx, record.ParentExternalID)
+		if err != nil {
+			return false, err
+		}
+		switch {
+		case parent != nil:
+			parentID = parent.ID()
+		case !i.pending[record.ParentExternalID]:
+			return false, domain.ErrParentCategoryNotFound
+		}
+	}
+
+	existing, err := i.u.categoryRepo.GetByExternalID(ctx, record.ExternalID)
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil {
+		if i.dryRun {
+			if i.pending[record.ExternalID] {
+				return false, nil
+			}
+			i.pending[record.ExternalID] = true
+			return true, nil
+		}
+		category := domain.NewCategory(record.Name, record.Description)
+		category.SetExternalID(record.ExternalID)
+		category.SetParentID(parentID)
+		return true, i.u.categoryRepo.Create(ctx, category)
+	}
+
+	existing.Update(record.Name, record.Description)
+	if i.dryRun {
+		return false, nil
+	}
+	if err := i.u.categoryRepo.Update(ctx, existing); err != nil {
+		return false, err
+	}
+	if existing.ParentID() != parentID {
+		if err := existing.MoveTo(parentID); err != nil {
+			return false, err
+		}
+		return false, i.u.categoryRepo.Move(ctx, existing)
+	}
+	return false, nil
+}
+
+// ExportCategories calls write for every live category, parents before their
+// children, with the parent's external ID.
+func (u *CatalogUseCase) ExportCategories(ctx context.Context, write func(category *domain.Category, parentExternalID string) error) error {
+	categories, err := u.categoryRepo.Tree(ctx, 0)
+	if err != nil {
+		return err
+	}
+	externalIDs := categoryExternalIDs(categories)
+
+	var walk func(nodes []*domain.CategoryNode) error
+	walk = func(nodes []*domain.CategoryNode) error {
+		for _, node := range nodes {
+			if err := write(node.Category, externalIDs[node.Category.ParentID()]); err != nil {
+				return err
+			}
+			if err := walk(node.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return walk(domain.BuildCategoryTree(categories))
+}
+
+// ExportProducts calls write for every live product, oldest first, with its
+// category's external ID. Products are read a page at a time.
+func (u *CatalogUseCase) ExportProducts(ctx context.Context, write func(product *domain.Product, categoryExternalID string) error) error {
+	categories, err := u.categoryRepo.Tree(ctx, 0)
+	if err != nil {
+		return err
+	}
+	externalIDs := categoryExternalIDs(categories)
+
+	filter := domain.ProductFilter{SortBy: domain.ProductSortCreatedAt, Limit: exportPageSize}
+	for {
+		page, err := u.productRepo.List(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, product := range page.Products {
+			if err := write(product, externalIDs[product.CategoryID()]); err != nil {
+				return err
+			}
+		}
+		if page.Next == nil {
+			return nil
+		}
+		filter.Cursor = page.Next
+	}
+}
+
+func categoryExternalIDs(categories []*domain.Category) map[uint64]string {
+	externalIDs := make(map[uint64]string, len(categories))
+	for _, category := range categories {
+		externalIDs[category.ID()] = category.ExternalID()
+	}
+	return externalIDs
+}
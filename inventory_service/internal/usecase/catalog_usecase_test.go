@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"io"
+	"testing"
+)
+
+// productRecords replays records, failing where an entry is nil as a
+// malformed row would.
+type productRecords struct {
+	records []*domain.ProductRecord
+}
+
+func (r *productRecords) Next() (*domain.ProductRecord, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	if record == nil {
+		return nil, &domain.ImportRowError{Row: 99, Message: "malformed"}
+	}
+	return record, nil
+}
+
+type categoryRecords struct {
+	records []*domain.CategoryRecord
+}
+
+func (r *categoryRecords) Next() (*domain.CategoryRecord, error) {
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	record := r.records[0]
+	r.records = r.records[1:]
+	return record, nil
+}
+
+func newCatalogUseCase() (*CatalogUseCase, domain.ProductRepository, domain.CategoryRepository) {
+	store := memory.NewStore()
+	productRepo := memory.NewProductRepository(store)
+	categoryRepo := memory.NewCategoryRepository(store)
+	attributeUseCase := NewAttributeUseCase(noAttributes{}, categoryRepo)
+	return NewCatalogUseCase(productRepo, categoryRepo, attributeUseCase, memory.NewImportJobStore()), productRepo, categoryRepo
+}
+
+// runImport starts an import and waits for it to finish.
+func runImport(t *testing.T, start func(done func()) (*domain.ImportJob, error), catalog *CatalogUseCase) *domain.ImportJob {
+	t.Helper()
+	finished := make(chan struct{})
+	queued, err := start(func() { close(finished) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-finished
+	job, err := catalog.GetImportJob(context.Background(), queued.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != domain.ImportCompleted {
+		t.Fatalf("got status %s (%s), want completed", job.Status, job.Error)
+	}
+	return job
+}
+
+func productRecord(t *testing.T, sku, name, price string, stock *int, category string) *domain.ProductRecord {
+	t.Helper()
+	amount, err := money.Parse(price, "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &domain.ProductRecord{Row: 2, SKU: sku, Name: name, Price: amount, Stock: stock, CategoryExternalID: category}
+}
+
+func TestProductImport(t *testing.T) {
+	ctx := context.Background()
+	catalog, products, categories := newCatalogUseCase()
+
+	category := domain.NewCategory("Lighting", "")
+	category.SetExternalID("lighting")
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+
+	five := 5
+	records := func() *productRecords {
+		return &productRecords{records: []*domain.ProductRecord{
+			productRecord(t, "LAMP-1", "Desk lamp", "12.00", &five, "lighting"),
+			productRecord(t, "LAMP-2", "Floor lamp", "40.00", nil, ""),
+			productRecord(t, "LAMP-1", "Desk lamp v2", "14.00", nil, ""),
+			nil,
+			productRecord(t, "LAMP-3", "", "1.00", nil, ""),
+			productRecord(t, "LAMP-4", "Wall lamp", "9.00", nil, "missing"),
+		}}
+	}
+
+	dryRun := runImport(t, func(done func()) (*domain.ImportJob, error) {
+		return catalog.StartProductImport(ctx, domain.CatalogCSV, true, records(), done)
+	}, catalog)
+	if dryRun.Processed != 6 || dryRun.Created != 2 || dryRun.Updated != 1 || dryRun.Failed != 3 {
+		t.Fatalf("dry run: got %+v", dryRun)
+	}
+	if existing, _ := products.GetBySKU(ctx, "LAMP-1"); existing != nil {
+		t.Fatal("a dry run created a product")
+	}
+
+	job := runImport(t, func(done func()) (*domain.ImportJob, error) {
+		return catalog.StartProductImport(ctx, domain.CatalogCSV, false, records(), done)
+	}, catalog)
+	if job.Processed != dryRun.Processed || job.Created != dryRun.Created || job.Updated != dryRun.Updated || job.Failed != dryRun.Failed {
+		t.Fatalf("got %+v, want the dry run's counts", job)
+	}
+
+	lamp, err := products.GetBySKU(ctx, "LAMP-1")
+	if err != nil || lamp == nil {
+		t.Fatalf("got %v, %v", lamp, err)
+	}
+	// The second row leaves stock and category as the first one set them.
+	if lamp.Name() != "Desk lamp v2" || lamp.Price().Decimal() != "14.00" || lamp.Stock() != 5 || lamp.CategoryID() != category.ID() {
+		t.Fatalf("got %q at %s with stock %d in category %d", lamp.Name(), lamp.Price().Decimal(), lamp.Stock(), lamp.CategoryID())
+	}
+}
+
+func TestCategoryImportAndExport(t *testing.T) {
+	ctx := context.Background()
+	catalog, _, categories := newCatalogUseCase()
+
+	records := func() *categoryRecords {
+		return &categoryRecords{records: []*domain.CategoryRecord{
+			{Row: 2, ExternalID: "home", Name: "Home"},
+			{Row: 3, ExternalID: "lighting", Name: "Lighting", ParentExternalID: "home"},
+			{Row: 4, ExternalID: "orphan", Name: "Orphan", ParentExternalID: "unknown"},
+		}}
+	}
+
+	dryRun := runImport(t, func(done func()) (*domain.ImportJob, error) {
+		return catalog.StartCategoryImport(ctx, domain.CatalogCSV, true, records(), done)
+	}, catalog)
+	if dryRun.Created != 2 || dryRun.Failed != 1 {
+		t.Fatalf("dry run: got %+v", dryRun)
+	}
+
+	job := runImport(t, func(done func()) (*domain.ImportJob, error) {
+		return catalog.StartCategoryImport(ctx, domain.CatalogCSV, false, records(), done)
+	}, catalog)
+	if job.Created != 2 || job.Failed != 1 || job.Errors[0].Key != "orphan" {
+		t.Fatalf("got %+v", job)
+	}
+
+	home, err := categories.GetByExternalID(ctx, "home")
+	if err != nil || home == nil {
+		t.Fatalf("got %v, %v", home, err)
+	}
+	lighting, err := categories.GetByExternalID(ctx, "lighting")
+	if err != nil || lighting == nil || lighting.ParentID() != home.ID() {
+		t.Fatalf("got %v, %v", lighting, err)
+	}
+
+	var exported []string
+	err = catalog.ExportCategories(ctx, func(category *domain.Category, parentExternalID string) error {
+		exported = append(exported, category.ExternalID()+"<"+parentExternalID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported) != 2 || exported[0] != "home<" || exported[1] != "lighting<home" {
+		t.Fatalf("got %v, want parents before children", exported)
+	}
+}
+
+func TestGetImportJobNotFound(t *testing.T) {
+	catalog, _, _ := newCatalogUseCase()
+	if _, err := catalog.GetImportJob(context.Background(), "missing"); !errors.Is(err, domain.ErrImportJobNotFound) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrImportJobNotFound)
+	}
+}
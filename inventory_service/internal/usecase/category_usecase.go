@@ -15,12 +15,9 @@ func NewCategoryUseCase(repo domain.CategoryRepository) *CategoryUseCase {
 	}
 }
 
-func (u *CategoryUseCase) CreateCategory(ctx context.Context, name, description string) (*domain.Category, error) {
-	category := domain.NewCategory(name, description)
-	if err := u.categoryRepo.Create(ctx, category); err != nil {
-		return nil, err
-	}
-	return category, nil
+// CreateCategory saves a new category. Its parent, if any, must be live.
+func (u *CategoryUseCase) CreateCategory(ctx context.Context, category *domain.Category) error {
+	return u.categoryRepo.Create(ctx, category)
 }
 
 func (u *CategoryUseCase) GetCategory(ctx context.Context, id uint64) (*domain.Category, error) {
@@ -34,6 +31,27 @@ func (u *CategoryUseCase) GetCategory(ctx context.Context, id uint64) (*domain.C
 	return category, nil
 }
 
+// GetCategoryBySlug returns the category with the slug or, when the slug is
+// an old one, the category's current slug instead.
+func (u *CategoryUseCase) GetCategoryBySlug(ctx context.Context, slug string) (*domain.Category, string, error) {
+	category, err := u.categoryRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if category != nil {
+		return category, "", nil
+	}
+
+	current, err := u.categoryRepo.SlugRedirect(ctx, slug)
+	if err != nil {
+		return nil, "", err
+	}
+	if current == "" {
+		return nil, "", domain.ErrCategoryNotFound
+	}
+	return nil, current, nil
+}
+
 func (u *CategoryUseCase) ListCategories(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
 	if limit <= 0 {
 		limit = 10 // Default limit
@@ -48,27 +66,67 @@ func (u *CategoryUseCase) ListCategories(ctx context.Context, offset, limit int)
 	return u.categoryRepo.List(ctx, offset, limit)
 }
 
-func (u *CategoryUseCase) UpdateCategory(ctx context.Context, id uint64, name, description string) error {
-	category, err := u.categoryRepo.GetByID(ctx, id)
+func (u *CategoryUseCase) UpdateCategory(ctx context.Context, id uint64, version int, changes domain.CategoryChanges) (*domain.Category, error) {
+	category, err := u.GetCategory(ctx, id)
 	if err != nil {
+		return nil, err
+	}
+	if category.Version() != version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	if err := category.Apply(changes); err != nil {
+		return nil, err
+	}
+	if err := u.categoryRepo.Update(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (u *CategoryUseCase) DeleteCategory(ctx context.Context, id uint64, version int, options domain.CategoryDeleteOptions) error {
+	if err := options.Validate(); err != nil {
 		return err
 	}
-	if category == nil {
-		return domain.ErrCategoryNotFound
+	return u.categoryRepo.Delete(ctx, id, version, options)
+}
+
+func (u *CategoryUseCase) GetCategoryTree(ctx context.Context, rootID uint64) ([]*domain.CategoryNode, error) {
+	categories, err := u.categoryRepo.Tree(ctx, rootID)
+	if err != nil {
+		return nil, err
 	}
+	if rootID != 0 && len(categories) == 0 {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return domain.BuildCategoryTree(categories), nil
+}
 
-	category.Update(name, description)
-	return u.categoryRepo.Update(ctx, category)
+func (u *CategoryUseCase) GetCategoryPath(ctx context.Context, id uint64) ([]*domain.Category, error) {
+	path, err := u.categoryRepo.Path(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return path, nil
 }
 
-func (u *CategoryUseCase) DeleteCategory(ctx context.Context, id uint64) error {
-	category, err := u.categoryRepo.GetByID(ctx, id)
+func (u *CategoryUseCase) MoveCategory(ctx context.Context, id uint64, version int, parentID uint64) (*domain.Category, error) {
+	category, err := u.GetCategory(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if category == nil {
-		return domain.ErrCategoryNotFound
+	if category.Version() != version {
+		return nil, domain.ErrVersionConflict
 	}
 
-	return u.categoryRepo.Delete(ctx, id)
+	if err := category.MoveTo(parentID); err != nil {
+		return nil, err
+	}
+	if err := u.categoryRepo.Move(ctx, category); err != nil {
+		return nil, err
+	}
+	return category, nil
 }
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"testing"
+)
+
+func newCategoryUseCase() *CategoryUseCase {
+	return NewCategoryUseCase(memory.NewCategoryRepository(memory.NewStore()))
+}
+
+func TestUpdateCategoryChecksVersion(t *testing.T) {
+	ctx := context.Background()
+	categories := newCategoryUseCase()
+
+	category := domain.NewCategory("Lighting", "")
+	if err := categories.CreateCategory(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := domain.CategoryChanges{Name: "Lamps"}
+	if _, err := categories.UpdateCategory(ctx, category.ID(), category.Version()+1, changes); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrVersionConflict)
+	}
+	updated, err := categories.UpdateCategory(ctx, category.ID(), category.Version(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name() != "Lamps" || updated.Version() != category.Version()+1 {
+		t.Fatalf("got %q at version %d", updated.Name(), updated.Version())
+	}
+
+	// The version the update was based on is now stale for further writes.
+	options := domain.CategoryDeleteOptions{Policy: domain.DeleteReject}
+	if err := categories.DeleteCategory(ctx, category.ID(), category.Version(), options); !errors.Is(err, domain.ErrVersionConflict) {
+		t.Fatalf("got error %v deleting a stale version, want %v", err, domain.ErrVersionConflict)
+	}
+	if err := categories.DeleteCategory(ctx, category.ID(), updated.Version(), options); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCategoryTreeAndMoves(t *testing.T) {
+	ctx := context.Background()
+	categories := newCategoryUseCase()
+
+	create := func(name string, parentID uint64) *domain.Category {
+		t.Helper()
+		category := domain.NewCategory(name, "")
+		category.SetParentID(parentID)
+		if err := categories.CreateCategory(ctx, category); err != nil {
+			t.Fatal(err)
+		}
+		return category
+	}
+	home := create("Home", 0)
+	lighting := create("Lighting", home.ID())
+	lamps := create("Lamps", lighting.ID())
+
+	if err := categories.CreateCategory(ctx, func() *domain.Category {
+		c := domain.NewCategory("Stray", "")
+		c.SetParentID(999)
+		return c
+	}()); !errors.Is(err, domain.ErrParentCategoryNotFound) {
+		t.Fatalf("got error %v creating under a missing parent", err)
+	}
+
+	path, err := categories.GetCategoryPath(ctx, lamps.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) != 3 || path[0].ID() != home.ID() || path[2].ID() != lamps.ID() {
+		t.Fatalf("got a path of %d categories", len(path))
+	}
+
+	if _, err := categories.MoveCategory(ctx, home.ID(), home.Version(), lamps.ID()); !errors.Is(err, domain.ErrCategoryCycle) {
+		t.Fatalf("got error %v moving a category under its descendant", err)
+	}
+	moved, err := categories.MoveCategory(ctx, lamps.ID(), lamps.Version(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.ParentID() != 0 {
+		t.Fatalf("got parent %d after moving to the top level", moved.ParentID())
+	}
+
+	tree, err := categories.GetCategoryTree(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tree) != 2 {
+		t.Fatalf("got %d roots, want home and lamps", len(tree))
+	}
+	subtree, err := categories.GetCategoryTree(ctx, home.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subtree) != 1 || len(subtree[0].Children) != 1 || len(subtree[0].Children[0].Children) != 0 {
+		t.Fatal("lamps are still under lighting")
+	}
+	if _, err := categories.GetCategoryTree(ctx, 999); !errors.Is(err, domain.ErrCategoryNotFound) {
+		t.Fatalf("got error %v for a missing subtree", err)
+	}
+}
+
+func TestDeleteCategoryValidatesOptions(t *testing.T) {
+	ctx := context.Background()
+	categories := newCategoryUseCase()
+
+	category := domain.NewCategory("Lighting", "")
+	if err := categories.CreateCategory(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	options := domain.CategoryDeleteOptions{Policy: domain.DeleteReassign}
+	if err := categories.DeleteCategory(ctx, category.ID(), category.Version(), options); !errors.Is(err, domain.ErrReassignTargetRequired) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrReassignTargetRequired)
+	}
+	if _, err := categories.GetCategory(ctx, category.ID()); err != nil {
+		t.Fatalf("the category was deleted despite invalid options: %v", err)
+	}
+}
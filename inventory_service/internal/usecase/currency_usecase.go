@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"strings"
+)
+
+type CurrencyUseCase struct {
+	priceListRepo domain.PriceListRepository
+	rateRepo      domain.ExchangeRateRepository
+	productRepo   domain.ProductRepository
+	supported     []money.Currency
+}
+
+func NewCurrencyUseCase(priceListRepo domain.PriceListRepository, rateRepo domain.ExchangeRateRepository, productRepo domain.ProductRepository, supported []money.Currency) *CurrencyUseCase {
+	return &CurrencyUseCase{
+		priceListRepo: priceListRepo,
+		rateRepo:      rateRepo,
+		productRepo:   productRepo,
+		supported:     supported,
+	}
+}
+
+func (u *CurrencyUseCase) SupportedCurrencies() []money.Currency {
+	return u.supported
+}
+
+// ParseCurrency accepts a supported currency code in any case.
+func (u *CurrencyUseCase) ParseCurrency(code string) (money.Currency, error) {
+	currency, err := money.ParseCurrency(code)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range u.supported {
+		if c == currency {
+			return currency, nil
+		}
+	}
+	return "", domain.ErrUnsupportedCurrency
+}
+
+// NegotiateCurrency picks the first supported currency of a preference list
+// such as an Accept-Currency header, "EUR, USD;q=0.5". Quality values are
+// ignored; the list is taken in the order given.
+func (u *CurrencyUseCase) NegotiateCurrency(header string) (money.Currency, error) {
+	for _, part := range strings.Split(header, ",") {
+		code := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if code == "" || code == "*" {
+			continue
+		}
+		if currency, err := u.ParseCurrency(code); err == nil {
+			return currency, nil
+		}
+	}
+	return "", domain.ErrUnsupportedCurrency
+}
+
+// Localizer loads what is needed to express the given products' prices in
+// currency.
+func (u *CurrencyUseCase) Localizer(ctx context.Context, currency money.Currency, productIDs []uint64) (*domain.PriceLocalizer, error) {
+	listPrices, err := u.priceListRepo.ListForProducts(ctx, productIDs, currency)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := u.rateRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PriceLocalizer{Currency: currency, ListPrices: listPrices, Rates: rates}, nil
+}
+
+func (u *CurrencyUseCase) ListPrices(ctx context.Context, productID uint64) ([]*domain.ListPrice, error) {
+	if _, err := u.loadProduct(ctx, productID); err != nil {
+		return nil, err
+	}
+	return u.priceListRepo.ListForProduct(ctx, productID)
+}
+
+func (u *CurrencyUseCase) SetListPrice(ctx context.Context, productID uint64, price money.Money) (*domain.ListPrice, error) {
+	if !price.IsSet() || price.IsNegative() {
+		return nil, domain.ErrInvalidPrice
+	}
+	if _, err := u.ParseCurrency(string(price.Currency())); err != nil {
+		return nil, err
+	}
+	product, err := u.loadProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Price().Currency() == price.Currency() {
+		return nil, domain.ErrBaseCurrencyListPrice
+	}
+
+	listPrice := &domain.ListPrice{ProductID: productID, Price: price}
+	if err := u.priceListRepo.Set(ctx, listPrice); err != nil {
+		return nil, err
+	}
+	return listPrice, nil
+}
+
+func (u *CurrencyUseCase) DeleteListPrice(ctx context.Context, productID uint64, currency money.Currency) error {
+	return u.priceListRepo.Delete(ctx, productID, currency)
+}
+
+func (u *CurrencyUseCase) ListRates(ctx context.Context) (domain.ExchangeRates, error) {
+	return u.rateRepo.List(ctx)
+}
+
+func (u *CurrencyUseCase) SetRate(ctx context.Context, base, quote money.Currency, rate string) (*domain.ExchangeRate, error) {
+	exchangeRate, err := domain.NewExchangeRate(base, quote, rate, domain.ActorFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := u.rateRepo.Set(ctx, exchangeRate); err != nil {
+		return nil, err
+	}
+	return exchangeRate, nil
+}
+
+func (u *CurrencyUseCase) DeleteRate(ctx context.Context, base, quote money.Currency) error {
+	return u.rateRepo.Delete(ctx, base, quote)
+}
+
+func (u *CurrencyUseCase) loadProduct(ctx context.Context, productID uint64) (*domain.Product, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+// listPrices keeps list prices per product and currency.
+type listPrices map[uint64]map[money.Currency]money.Money
+
+func (l listPrices) ListForProduct(_ context.Context, productID uint64) ([]*domain.ListPrice, error) {
+	var prices []*domain.ListPrice
+	for _, price := range l[productID] {
+		prices = append(prices, &domain.ListPrice{ProductID: productID, Price: price})
+	}
+	return prices, nil
+}
+
+func (l listPrices) ListForProducts(_ context.Context, productIDs []uint64, currency money.Currency) (map[uint64]money.Money, error) {
+	prices := make(map[uint64]money.Money)
+	for _, id := range productIDs {
+		if price, ok := l[id][currency]; ok {
+			prices[id] = price
+		}
+	}
+	return prices, nil
+}
+
+func (l listPrices) Set(_ context.Context, price *domain.ListPrice) error {
+	if l[price.ProductID] == nil {
+		l[price.ProductID] = make(map[money.Currency]money.Money)
+	}
+	l[price.ProductID][price.Price.Currency()] = price.Price
+	return nil
+}
+
+func (l listPrices) Delete(_ context.Context, productID uint64, currency money.Currency) error {
+	delete(l[productID], currency)
+	return nil
+}
+
+// exchangeRates is a fixed set of rates.
+type exchangeRates struct {
+	domain.ExchangeRateRepository
+	rates domain.ExchangeRates
+}
+
+func (r *exchangeRates) List(context.Context) (domain.ExchangeRates, error) {
+	return r.rates, nil
+}
+
+func newCurrencyUseCase(t *testing.T) (*CurrencyUseCase, *domain.Product) {
+	t.Helper()
+	store := memory.NewStore()
+	products := memory.NewProductRepository(store)
+	product := newTestProduct(t, 0)
+	if err := products.Create(context.Background(), product); err != nil {
+		t.Fatal(err)
+	}
+	rate, err := domain.NewExchangeRate("USD", "GBP", "0.5", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	currencies := NewCurrencyUseCase(listPrices{}, &exchangeRates{rates: domain.ExchangeRates{rate}}, products, []money.Currency{"USD", "EUR", "GBP"})
+	return currencies, product
+}
+
+func TestParseAndNegotiateCurrency(t *testing.T) {
+	currencies, _ := newCurrencyUseCase(t)
+
+	if currency, err := currencies.ParseCurrency("eur"); err != nil || currency != "EUR" {
+		t.Fatalf("got %q, %v", currency, err)
+	}
+	if _, err := currencies.ParseCurrency("JPY"); !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrUnsupportedCurrency)
+	}
+	if _, err := currencies.ParseCurrency("euro"); !errors.Is(err, money.ErrInvalidCurrency) {
+		t.Fatalf("got error %v, want %v", err, money.ErrInvalidCurrency)
+	}
+
+	tests := []struct {
+		header string
+		want   money.Currency
+	}{
+		{"EUR", "EUR"},
+		{"JPY, gbp;q=0.5, EUR", "GBP"},
+		{"*, USD", "USD"},
+	}
+	for _, tt := range tests {
+		if got, err := currencies.NegotiateCurrency(tt.header); err != nil || got != tt.want {
+			t.Errorf("%q: got %q, %v, want %q", tt.header, got, err, tt.want)
+		}
+	}
+	if _, err := currencies.NegotiateCurrency("JPY, *"); !errors.Is(err, domain.ErrUnsupportedCurrency) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrUnsupportedCurrency)
+	}
+}
+
+func TestListPricesAndLocalizer(t *testing.T) {
+	ctx := context.Background()
+	currencies, product := newCurrencyUseCase(t)
+	parse := func(amount string, currency money.Currency) money.Money {
+		m, err := money.Parse(amount, currency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name  string
+		id    uint64
+		price money.Money
+		want  error
+	}{
+		{"own currency", product.ID(), parse("12.00", "USD"), domain.ErrBaseCurrencyListPrice},
+		{"unsupported currency", product.ID(), parse("1200", "JPY"), domain.ErrUnsupportedCurrency},
+		{"negative", product.ID(), parse("-1.00", "EUR"), domain.ErrInvalidPrice},
+		{"unset", product.ID(), money.Money{}, domain.ErrInvalidPrice},
+		{"missing product", 999, parse("9.00", "EUR"), domain.ErrProductNotFound},
+	}
+	for _, tt := range tests {
+		if _, err := currencies.SetListPrice(ctx, tt.id, tt.price); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got error %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := currencies.SetListPrice(ctx, product.ID(), parse("9.00", "EUR")); err != nil {
+		t.Fatal(err)
+	}
+
+	// EUR has a list price; GBP is converted from the 10.00 USD base price.
+	for currency, want := range map[money.Currency]string{"EUR": "9.00 EUR", "GBP": "5.00 GBP", "USD": "10.00 USD"} {
+		localizer, err := currencies.Localizer(ctx, currency, []uint64{product.ID()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		price, err := localizer.Product(product)
+		if err != nil || price.Price.String() != want {
+			t.Errorf("%s: got %v, %v, want %s", currency, price.Price, err, want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"time"
+)
+
+type PriceUseCase struct {
+	priceRepo   domain.PriceRepository
+	productRepo domain.ProductRepository
+}
+
+func NewPriceUseCase(priceRepo domain.PriceRepository, productRepo domain.ProductRepository) *PriceUseCase {
+	return &PriceUseCase{
+		priceRepo:   priceRepo,
+		productRepo: productRepo,
+	}
+}
+
+// Timeline returns the price history of a product together with all of its
+// schedules, past and upcoming.
+func (u *PriceUseCase) Timeline(ctx context.Context, productID uint64) (*domain.PriceTimeline, error) {
+	return u.timeline(ctx, productID, false)
+}
+
+// PriceAt returns the price of a product in effect at the given time, which
+// may lie in the past or the future.
+func (u *PriceUseCase) PriceAt(ctx context.Context, productID uint64, at time.Time) (money.Money, error) {
+	timeline, err := u.timeline(ctx, productID, true)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return timeline.PriceAt(at, time.Now())
+}
+
+func (u *PriceUseCase) timeline(ctx context.Context, productID uint64, openOnly bool) (*domain.PriceTimeline, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	history, err := u.priceRepo.ListChanges(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := u.priceRepo.ListSchedules(ctx, productID, openOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PriceTimeline{
+		ProductID:    productID,
+		CurrentPrice: product.Price(),
+		History:      history,
+		Schedules:    schedules,
+	}, nil
+}
+
+func (u *PriceUseCase) SchedulePrice(ctx context.Context, productID uint64, price money.Money, startsAt time.Time, endsAt *time.Time) (*domain.PriceSchedule, error) {
+	schedule, err := domain.NewPriceSchedule(productID, price, startsAt, endsAt, domain.ActorFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := u.priceRepo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+func (u *PriceUseCase) CancelSchedule(ctx context.Context, productID, scheduleID uint64) (*domain.PriceSchedule, error) {
+	return u.priceRepo.CancelSchedule(ctx, productID, scheduleID)
+}
+
+// ApplyDue starts and ends every schedule whose time has come. Schedules
+// whose whole window passed while no scheduler ran expire unapplied.
+func (u *PriceUseCase) ApplyDue(ctx context.Context) (int, error) {
+	return u.priceRepo.ApplyDue(ctx, time.Now())
+}
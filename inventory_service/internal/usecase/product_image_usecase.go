@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"io"
+	"log"
+)
+
+type ProductImageUseCase struct {
+	imageRepo     domain.ProductImageRepository
+	productRepo   domain.ProductRepository
+	storage       domain.MediaStorage
+	processor     domain.ImageProcessor
+	maxUploadSize int64
+}
+
+func NewProductImageUseCase(imageRepo domain.ProductImageRepository, productRepo domain.ProductRepository, storage domain.MediaStorage, processor domain.ImageProcessor, maxUploadSize int64) *ProductImageUseCase {
+	return &ProductImageUseCase{
+		imageRepo:     imageRepo,
+		productRepo:   productRepo,
+		storage:       storage,
+		processor:     processor,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// UploadImage validates the upload, stores it with its thumbnails and appends
+// it to the product's images. Stored files are removed again if any later
+// step fails.
+func (u *ProductImageUseCase) UploadImage(ctx context.Context, productID uint64, r io.Reader, primary bool) (*domain.ProductImage, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, u.maxUploadSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > u.maxUploadSize {
+		return nil, domain.ErrImageTooLarge
+	}
+
+	processed, err := u.processor.Process(data, domain.ThumbnailSizes)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("products/%d/%s", productID, id)
+	image := &domain.ProductImage{
+		ProductID:     productID,
+		StorageKey:    prefix + "/original" + processed.Extension,
+		ContentType:   processed.ContentType,
+		Width:         processed.Width,
+		Height:        processed.Height,
+		SizeBytes:     int64(len(data)),
+		IsPrimary:     primary,
+		ThumbnailKeys: make(map[string]string, len(processed.Thumbnails)),
+	}
+
+	if err := u.storage.Put(ctx, image.StorageKey, bytes.NewReader(processed.Original), processed.ContentType); err != nil {
+		return nil, err
+	}
+	for name, thumbnail := range processed.Thumbnails {
+		key := prefix + "/" + name + processed.ThumbnailExtension
+		image.ThumbnailKeys[name] = key
+		if err := u.storage.Put(ctx, key, bytes.NewReader(thumbnail), processed.ThumbnailContentType); err != nil {
+			u.removeFiles(ctx, image)
+			return nil, err
+		}
+	}
+
+	if err := u.imageRepo.Create(ctx, image); err != nil {
+		u.removeFiles(ctx, image)
+		return nil, err
+	}
+	return image, nil
+}
+
+func (u *ProductImageUseCase) ListImages(ctx context.Context, productID uint64) ([]*domain.ProductImage, error) {
+	product, err := u.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return u.imageRepo.ListByProduct(ctx, productID)
+}
+
+func (u *ProductImageUseCase) ReorderImages(ctx context.Context, productID uint64, imageIDs []uint64) ([]*domain.ProductImage, error) {
+	if err := u.imageRepo.Reorder(ctx, productID, imageIDs); err != nil {
+		return nil, err
+	}
+	return u.imageRepo.ListByProduct(ctx, productID)
+}
+
+func (u *ProductImageUseCase) SetPrimaryImage(ctx context.Context, productID, imageID uint64) ([]*domain.ProductImage, error) {
+	if err := u.imageRepo.SetPrimary(ctx, productID, imageID); err != nil {
+		return nil, err
+	}
+	return u.imageRepo.ListByProduct(ctx, productID)
+}
+
+// DeleteImage removes the image record first and its files afterwards, so a
+// storage failure leaves orphaned files rather than a broken image.
+func (u *ProductImageUseCase) DeleteImage(ctx context.Context, productID, imageID uint64) error {
+	image, err := u.imageRepo.Delete(ctx, productID, imageID)
+	if err != nil {
+		return err
+	}
+	u.removeFiles(ctx, image)
+	return nil
+}
+
+func (u *ProductImageUseCase) removeFiles(ctx context.Context, image *domain.ProductImage) {
+	for _, key := range image.StorageKeys() {
+		if err := u.storage.Delete(ctx, key); err != nil && !errors.Is(err, domain.ErrMediaObjectNotFound) {
+			log.Printf("removing media %s: %v", key, err)
+		}
+	}
+}
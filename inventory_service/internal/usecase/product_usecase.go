@@ -6,72 +6,227 @@ import (
 )
 
 type ProductUseCase struct {
-	productRepo domain.ProductRepository
+	productRepo      domain.ProductRepository
+	categoryRepo     domain.CategoryRepository
+	variantRepo      domain.VariantRepository
+	imageRepo        domain.ProductImageRepository
+	storage          domain.MediaStorage
+	attributeUseCase *AttributeUseCase
 }
 
-func NewProductUseCase(repo domain.ProductRepository) *ProductUseCase {
+func NewProductUseCase(repo domain.ProductRepository, categoryRepo domain.CategoryRepository, variantRepo domain.VariantRepository, imageRepo domain.ProductImageRepository, storage domain.MediaStorage, attributeUseCase *AttributeUseCase) *ProductUseCase {
 	return &ProductUseCase{
-		productRepo: repo,
+		productRepo:      repo,
+		categoryRepo:     categoryRepo,
+		variantRepo:      variantRepo,
+		imageRepo:        imageRepo,
+		storage:          storage,
+		attributeUseCase: attributeUseCase,
 	}
 }
 
-func (u *ProductUseCase) CreateProduct(ctx context.Context, product *domain.Product) error {
-	// Add business logic validation here
-	if product.Price() < 0 {
+// ProductDetails are the variants and images of several products, keyed by
+// product id.
+type ProductDetails struct {
+	Variants map[uint64][]*domain.Variant
+	Images   map[uint64][]*domain.ProductImage
+}
+
+// GetProductDetails loads the variants and images of the products, each in
+// one query however many products there are.
+func (u *ProductUseCase) GetProductDetails(ctx context.Context, productIDs []uint64) (*ProductDetails, error) {
+	variants, err := u.variantRepo.ListByProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	images, err := u.imageRepo.ListByProducts(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &ProductDetails{Variants: variants, Images: images}, nil
+}
+
+// ImageURL returns the address clients fetch a stored image file from.
+func (u *ProductUseCase) ImageURL(key string) string {
+	return u.storage.URL(key)
+}
+
+// CreateProduct files the product under its category, which must be live,
+// and validates attributes against the category's schema. Nil attributes are
+// treated as none.
+func (u *ProductUseCase) CreateProduct(ctx context.Context, product *domain.Product, attributes map[string]interface{}) error {
+	if product.Price().IsNegative() {
 		return domain.ErrInvalidPrice
 	}
 	if product.Stock() < 0 {
 		return domain.ErrInvalidStock
 	}
+	if err := u.requireCategory(ctx, product.CategoryID()); err != nil {
+		return err
+	}
+
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
+	if err := u.attributeUseCase.ApplyAttributes(ctx, product, attributes); err != nil {
+		return err
+	}
 
 	return u.productRepo.Create(ctx, product)
 }
 
 func (u *ProductUseCase) GetProduct(ctx context.Context, id uint64) (*domain.Product, error) {
-	return u.productRepo.GetByID(ctx, id)
+	product, err := u.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
 }
-func (u *ProductUseCase) ListProducts(ctx context.Context, categoryID uint64, offset, limit int) ([]*domain.Product, error) {
-	if limit <= 0 {
-		limit = 10 // Default limit
+
+// GetProductBySlug returns the product with the slug or, when the slug is an
+// old one, the product's current slug instead.
+func (u *ProductUseCase) GetProductBySlug(ctx context.Context, slug string) (*domain.Product, string, error) {
+	product, err := u.productRepo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, "", err
 	}
-	if limit > 100 {
-		limit = 100 // Max limit
+	if product != nil {
+		return product, "", nil
+	}
+
+	current, err := u.productRepo.SlugRedirect(ctx, slug)
+	if err != nil {
+		return nil, "", err
 	}
-	if offset < 0 {
-		offset = 0
+	if current == "" {
+		return nil, "", domain.ErrProductNotFound
 	}
+	return nil, current, nil
+}
 
-	return u.productRepo.List(ctx, categoryID, offset, limit)
+func (u *ProductUseCase) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	product, err := u.productRepo.GetBySKU(ctx, sku)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
 }
 
-func (u *ProductUseCase) UpdateProduct(ctx context.Context, product *domain.Product) error {
-	// Add business logic validation here
-	if product.Price() < 0 {
-		return domain.ErrInvalidPrice
+// GetProductBatch looks up the products a batch names. IDs and SKUs that
+// match no live product are reported as missing rather than failing.
+func (u *ProductUseCase) GetProductBatch(ctx context.Context, batch domain.ProductBatch) (*domain.ProductBatchResult, error) {
+	if err := batch.Validate(); err != nil {
+		return nil, err
 	}
-	if product.Stock() < 0 {
-		return domain.ErrInvalidStock
+	found, err := u.productRepo.GetBatch(ctx, batch)
+	if err != nil {
+		return nil, err
 	}
+	return batch.Resolve(found), nil
+}
 
-	return u.productRepo.Update(ctx, product)
+func (u *ProductUseCase) ListProducts(ctx context.Context, filter domain.ProductFilter) (*domain.ProductPage, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 10 // Default limit
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100 // Max limit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return u.productRepo.List(ctx, filter)
 }
 
-func (u *ProductUseCase) DeleteProduct(ctx context.Context, id uint64) error {
-	return u.productRepo.Delete(ctx, id)
+// ProductFacets counts the products matching filter by the facets request
+// asks for.
+func (u *ProductUseCase) ProductFacets(ctx context.Context, filter domain.ProductFilter, request domain.FacetRequest) (*domain.ProductFacets, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	if err := request.Validate(filter.PriceCurrency()); err != nil {
+		return nil, err
+	}
+	return u.productRepo.Facets(ctx, filter, request)
 }
 
-func (u *ProductUseCase) UpdateStock(ctx context.Context, id uint64, quantity int) error {
-	product, err := u.productRepo.GetByID(ctx, id)
+func (u *ProductUseCase) SearchProducts(ctx context.Context, query domain.ProductSearchQuery) ([]*domain.ProductSearchResult, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+	if query.Filter.Limit <= 0 {
+		query.Filter.Limit = 10 // Default limit
+	}
+	if query.Filter.Limit > 100 {
+		query.Filter.Limit = 100 // Max limit
+	}
+	if query.Filter.Offset < 0 {
+		query.Filter.Offset = 0
+	}
+
+	return u.productRepo.Search(ctx, query)
+}
+
+// UpdateProduct applies changes to the given version of the product. A
+// product moved to another category must land in a live one, and its
+// attributes are checked against the new category's schema. Attributes are
+// otherwise only validated when the changes replace them.
+func (u *ProductUseCase) UpdateProduct(ctx context.Context, id uint64, version int, changes domain.ProductChanges) (*domain.Product, error) {
+	product, err := u.GetProduct(ctx, id)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if product == nil {
-		return domain.ErrProductNotFound
+	if product.Version() != version {
+		return nil, domain.ErrVersionConflict
 	}
 
-	if err := product.UpdateStock(quantity); err != nil {
-		return err
+	previousCategoryID := product.CategoryID()
+	if err := product.Apply(changes); err != nil {
+		return nil, err
+	}
+	if product.CategoryID() != previousCategoryID {
+		if err := u.requireCategory(ctx, product.CategoryID()); err != nil {
+			return nil, err
+		}
+	}
+	if changes.Attributes != nil || product.CategoryID() != previousCategoryID {
+		if err := u.attributeUseCase.ApplyAttributes(ctx, product, changes.Attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.productRepo.Update(ctx, product); err != nil {
+		return nil, err
 	}
+	return product, nil
+}
+
+func (u *ProductUseCase) DeleteProduct(ctx context.Context, id uint64, version int) error {
+	return u.productRepo.Delete(ctx, id, version)
+}
 
-	return u.productRepo.Update(ctx, product)
+// requireCategory checks that a product can be filed under the category.
+// Zero leaves the product uncategorized.
+func (u *ProductUseCase) requireCategory(ctx context.Context, categoryID uint64) error {
+	if categoryID == 0 {
+		return nil
+	}
+	category, err := u.categoryRepo.GetByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return domain.ErrProductCategoryNotFound
+	}
+	return nil
 }
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"testing"
+)
+
+// noAttributes is a schema store in which no category defines attributes.
+type noAttributes struct {
+	domain.AttributeRepository
+}
+
+func (noAttributes) ListForCategory(context.Context, uint64, bool) ([]*domain.AttributeDefinition, error) {
+	return nil, nil
+}
+
+func newProductUseCase(t *testing.T) (*ProductUseCase, domain.CategoryRepository) {
+	t.Helper()
+	store := memory.NewStore()
+	categoryRepo := memory.NewCategoryRepository(store)
+	attributeUseCase := NewAttributeUseCase(noAttributes{}, categoryRepo)
+	return NewProductUseCase(memory.NewProductRepository(store), categoryRepo, nil, nil, nil, attributeUseCase), categoryRepo
+}
+
+func newTestProduct(t *testing.T, categoryID uint64) *domain.Product {
+	t.Helper()
+	price, err := money.Parse("10.00", "USD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	product, err := domain.NewProduct("Lamp", "", price, 1, categoryID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return product
+}
+
+func TestCreateProductRequiresLiveCategory(t *testing.T) {
+	ctx := context.Background()
+	products, categories := newProductUseCase(t)
+
+	err := products.CreateProduct(ctx, newTestProduct(t, 999), nil)
+	if !errors.Is(err, domain.ErrProductCategoryNotFound) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrProductCategoryNotFound)
+	}
+	if kind := domain.KindOf(err); kind != domain.KindUnprocessable {
+		t.Fatalf("got kind %v, want unprocessable", kind)
+	}
+
+	category := domain.NewCategory("Seasonal", "")
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	if err := categories.Delete(ctx, category.ID(), category.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteReject}); err != nil {
+		t.Fatal(err)
+	}
+	err = products.CreateProduct(ctx, newTestProduct(t, category.ID()), nil)
+	if !errors.Is(err, domain.ErrProductCategoryNotFound) {
+		t.Fatalf("got error %v for a trashed category, want %v", err, domain.ErrProductCategoryNotFound)
+	}
+
+	if err := products.CreateProduct(ctx, newTestProduct(t, 0), nil); err != nil {
+		t.Fatalf("uncategorized product: %v", err)
+	}
+}
+
+func TestUpdateProductChecksVersionAndCategory(t *testing.T) {
+	ctx := context.Background()
+	products, categories := newProductUseCase(t)
+
+	category := domain.NewCategory("Lighting", "")
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	product := newTestProduct(t, category.ID())
+	if err := products.CreateProduct(ctx, product, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := domain.ProductChanges{Name: "Desk Lamp", Price: product.Price(), Stock: 2, CategoryID: category.ID()}
+	if _, err := products.UpdateProduct(ctx, 999, 1, changes); !errors.Is(err, domain.ErrProductNotFound) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrProductNotFound)
+	}
+	if _, err := products.UpdateProduct(ctx, product.ID(), product.Version()+1, changes); domain.KindOf(err) != domain.KindStale {
+		t.Fatalf("got error %v, want a stale version", err)
+	}
+
+	moved := changes
+	moved.CategoryID = 999
+	if _, err := products.UpdateProduct(ctx, product.ID(), product.Version(), moved); !errors.Is(err, domain.ErrProductCategoryNotFound) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrProductCategoryNotFound)
+	}
+
+	updated, err := products.UpdateProduct(ctx, product.ID(), product.Version(), changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name() != "Desk Lamp" || updated.Stock() != 2 || updated.Version() != product.Version()+1 {
+		t.Fatalf("got %q, stock %d, version %d", updated.Name(), updated.Stock(), updated.Version())
+	}
+}
+
+func TestGetProductBatch(t *testing.T) {
+	ctx := context.Background()
+	products, _ := newProductUseCase(t)
+
+	lamp := newTestProduct(t, 0)
+	lamp.SetIdentifiers("LAMP", "")
+	if err := products.CreateProduct(ctx, lamp, nil); err != nil {
+		t.Fatal(err)
+	}
+	trashed := newTestProduct(t, 0)
+	if err := products.CreateProduct(ctx, trashed, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := products.DeleteProduct(ctx, trashed.ID(), trashed.Version()); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := products.GetProductBatch(ctx, domain.ProductBatch{
+		IDs:  []uint64{lamp.ID(), trashed.ID(), lamp.ID()},
+		SKUs: []string{"LAMP", " DESK "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Products) != 1 || result.Products[0].ID() != lamp.ID() {
+		t.Fatalf("got %d products, want the live lamp once", len(result.Products))
+	}
+	if len(result.MissingIDs) != 1 || result.MissingIDs[0] != trashed.ID() {
+		t.Fatalf("got missing ids %v, want the trashed product", result.MissingIDs)
+	}
+	if len(result.MissingSKUs) != 1 || result.MissingSKUs[0] != "DESK" {
+		t.Fatalf("got missing skus %v", result.MissingSKUs)
+	}
+
+	if _, err := products.GetProductBatch(ctx, domain.ProductBatch{}); !errors.Is(err, domain.ErrEmptyProductBatch) {
+		t.Fatalf("got error %v for an empty batch", err)
+	}
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type StockAlertUseCase struct {
+	alertRepo           domain.StockAlertRepository
+	defaultReorderPoint int
+}
+
+func NewStockAlertUseCase(repo domain.StockAlertRepository, defaultReorderPoint int) *StockAlertUseCase {
+	return &StockAlertUseCase{
+		alertRepo:           repo,
+		defaultReorderPoint: defaultReorderPoint,
+	}
+}
+
+func (u *StockAlertUseCase) SetProductReorderPoint(ctx context.Context, productID uint64, point *int) error {
+	if point != nil && *point < 0 {
+		return domain.ErrInvalidReorderPoint
+	}
+	return u.alertRepo.SetProductReorderPoint(ctx, productID, point)
+}
+
+func (u *StockAlertUseCase) SetCategoryReorderPoint(ctx context.Context, categoryID uint64, point *int) error {
+	if point != nil && *point < 0 {
+		return domain.ErrInvalidReorderPoint
+	}
+	return u.alertRepo.SetCategoryReorderPoint(ctx, categoryID, point)
+}
+
+func (u *StockAlertUseCase) ListLowStock(ctx context.Context) ([]*domain.LowStockItem, error) {
+	return u.alertRepo.ListLowStock(ctx, u.defaultReorderPoint)
+}
+
+func (u *StockAlertUseCase) AlertedLevels(ctx context.Context) (map[uint64]domain.StockAlertLevel, error) {
+	return u.alertRepo.AlertedLevels(ctx)
+}
+
+func (u *StockAlertUseCase) RecordAlert(ctx context.Context, productID uint64, level domain.StockAlertLevel) error {
+	return u.alertRepo.RecordAlert(ctx, productID, level)
+}
+
+func (u *StockAlertUseCase) ForgetAlerts(ctx context.Context, productIDs []uint64) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+	return u.alertRepo.ForgetAlerts(ctx, productIDs)
+}
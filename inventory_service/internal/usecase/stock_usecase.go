@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type StockUseCase struct {
+	movementRepo domain.StockMovementRepository
+}
+
+func NewStockUseCase(repo domain.StockMovementRepository) *StockUseCase {
+	return &StockUseCase{
+		movementRepo: repo,
+	}
+}
+
+// AdjustStock changes the stock of a product, or of one of its variants when
+// variantID is non-zero, and records the change in the ledger. It returns the
+// new stock level.
+func (u *StockUseCase) AdjustStock(ctx context.Context, productID, variantID uint64, delta int, reason domain.MovementReason, referenceID string) (int, error) {
+	movement, err := domain.NewStockMovement(productID, variantID, delta, reason, referenceID, domain.ActorFromContext(ctx))
+	if err != nil {
+		return 0, err
+	}
+	return u.movementRepo.Apply(ctx, movement)
+}
+
+func (u *StockUseCase) ListMovements(ctx context.Context, filter domain.StockMovementFilter) ([]*domain.StockMovement, error) {
+	if filter.Reason != "" && !filter.Reason.Valid() {
+		return nil, domain.ErrInvalidMovementReason
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 10 // Default limit
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100 // Max limit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return u.movementRepo.List(ctx, filter)
+}
+
+func (u *StockUseCase) Reconcile(ctx context.Context, productID uint64) ([]*domain.StockDiscrepancy, error) {
+	return u.movementRepo.Reconcile(ctx, productID)
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"log"
+	"time"
+)
+
+// TrashUseCase lists and restores soft-deleted products and categories, and
+// hard-deletes them once they have been in the trash for the retention
+// period.
+type TrashUseCase struct {
+	productRepo  domain.ProductRepository
+	categoryRepo domain.CategoryRepository
+	trashRepo    domain.TrashRepository
+	storage      domain.MediaStorage
+	retention    time.Duration
+}
+
+func NewTrashUseCase(productRepo domain.ProductRepository, categoryRepo domain.CategoryRepository, trashRepo domain.TrashRepository, storage domain.MediaStorage, retention time.Duration) *TrashUseCase {
+	return &TrashUseCase{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		trashRepo:    trashRepo,
+		storage:      storage,
+		retention:    retention,
+	}
+}
+
+// PurgeAt is when an entity deleted at deletedAt becomes eligible for
+// purging.
+func (u *TrashUseCase) PurgeAt(deletedAt time.Time) time.Time {
+	return deletedAt.Add(u.retention)
+}
+
+func (u *TrashUseCase) ListProducts(ctx context.Context, offset, limit int) ([]*domain.Product, error) {
+	return u.productRepo.ListDeleted(ctx, offset, limit)
+}
+
+func (u *TrashUseCase) ListCategories(ctx context.Context, offset, limit int) ([]*domain.Category, error) {
+	return u.categoryRepo.ListDeleted(ctx, offset, limit)
+}
+
+func (u *TrashUseCase) RestoreProduct(ctx context.Context, id uint64, version int) (*domain.Product, error) {
+	if err := u.productRepo.Restore(ctx, id, version); err != nil {
+		return nil, err
+	}
+	product, err := u.productRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, domain.ErrProductNotFound
+	}
+	return product, nil
+}
+
+func (u *TrashUseCase) RestoreCategory(ctx context.Context, id uint64, version int) (*domain.Category, error) {
+	if err := u.categoryRepo.Restore(ctx, id, version); err != nil {
+		return nil, err
+	}
+	category, err := u.categoryRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, domain.ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// Purge hard-deletes everything deleted more than the retention period before
+// now. Products go first so that the categories they pointed at can follow;
+// categories still in use are left in the trash.
+func (u *TrashUseCase) Purge(ctx context.Context, now time.Time) (*domain.PurgeResult, error) {
+	before := now.Add(-u.retention)
+	result := &domain.PurgeResult{}
+
+	for {
+		purged, err := u.trashRepo.PurgeProducts(ctx, before, domain.PurgeBatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Products += purged.Count
+		u.removeFiles(ctx, purged.StorageKeys)
+		if purged.Count < domain.PurgeBatchSize {
+			break
+		}
+	}
+
+	for {
+		count, err := u.trashRepo.PurgeCategories(ctx, before, domain.PurgeBatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Categories += count
+		if count == 0 {
+			break
+		}
+	}
+
+	blocked, err := u.trashRepo.CountBlockedCategories(ctx, before)
+	if err != nil {
+		return result, err
+	}
+	result.BlockedCategories = blocked
+	return result, nil
+}
+
+func (u *TrashUseCase) removeFiles(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := u.storage.Delete(ctx, key); err != nil && !errors.Is(err, domain.ErrMediaObjectNotFound) {
+			log.Printf("removing media %s: %v", key, err)
+		}
+	}
+}
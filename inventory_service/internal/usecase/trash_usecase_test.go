@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/repository/memory"
+	"io"
+	"testing"
+	"time"
+)
+
+// trashBin hands out purge batches from fixed totals and records the cutoff
+// it was asked for.
+type trashBin struct {
+	products, categories, blocked int
+	before                        time.Time
+}
+
+func (b *trashBin) PurgeProducts(_ context.Context, before time.Time, limit int) (*domain.PurgedProducts, error) {
+	b.before = before
+	count := limit
+	if b.products < limit {
+		count = b.products
+	}
+	b.products -= count
+	purged := &domain.PurgedProducts{Count: count}
+	for i := 0; i < count; i++ {
+		purged.StorageKeys = append(purged.StorageKeys, "products/image")
+	}
+	return purged, nil
+}
+
+func (b *trashBin) PurgeCategories(_ context.Context, _ time.Time, limit int) (int, error) {
+	count := limit
+	if b.categories < limit {
+		count = b.categories
+	}
+	b.categories -= count
+	return count, nil
+}
+
+func (b *trashBin) CountBlockedCategories(context.Context, time.Time) (int, error) {
+	return b.blocked, nil
+}
+
+// mediaBin counts deletions and fails those of missing objects.
+type mediaBin struct {
+	deleted int
+	missing bool
+}
+
+func (m *mediaBin) Put(context.Context, string, io.Reader, string) error { return nil }
+
+func (m *mediaBin) Delete(context.Context, string) error {
+	m.deleted++
+	if m.missing {
+		return domain.ErrMediaObjectNotFound
+	}
+	return nil
+}
+
+func (m *mediaBin) URL(key string) string { return key }
+
+func TestPurgeEmptiesTheTrashInBatches(t *testing.T) {
+	bin := &trashBin{products: domain.PurgeBatchSize + 5, categories: 3, blocked: 2}
+	media := &mediaBin{missing: true}
+	store := memory.NewStore()
+	trash := NewTrashUseCase(memory.NewProductRepository(store), memory.NewCategoryRepository(store), bin, media, 24*time.Hour)
+
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	result, err := trash.Purge(context.Background(), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Products != domain.PurgeBatchSize+5 || result.Categories != 3 || result.BlockedCategories != 2 {
+		t.Fatalf("got %+v", result)
+	}
+	if !bin.before.Equal(now.Add(-24 * time.Hour)) {
+		t.Fatalf("got cutoff %v, want a day before now", bin.before)
+	}
+	// Media already gone does not stop the purge.
+	if media.deleted != result.Products {
+		t.Fatalf("deleted %d files, want %d", media.deleted, result.Products)
+	}
+	if purgeAt := trash.PurgeAt(now); !purgeAt.Equal(now.Add(24 * time.Hour)) {
+		t.Fatalf("got purge time %v", purgeAt)
+	}
+}
+
+func TestRestoreFromTrash(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	products := memory.NewProductRepository(store)
+	categories := memory.NewCategoryRepository(store)
+	trash := NewTrashUseCase(products, categories, &trashBin{}, &mediaBin{}, time.Hour)
+
+	category := domain.NewCategory("Lighting", "")
+	if err := categories.Create(ctx, category); err != nil {
+		t.Fatal(err)
+	}
+	product := newTestProduct(t, category.ID())
+	if err := products.Create(ctx, product); err != nil {
+		t.Fatal(err)
+	}
+	if err := categories.Delete(ctx, category.ID(), category.Version(), domain.CategoryDeleteOptions{Policy: domain.DeleteCascade}); err != nil {
+		t.Fatal(err)
+	}
+
+	trashedProducts, err := trash.ListProducts(ctx, 0, 10)
+	if err != nil || len(trashedProducts) != 1 {
+		t.Fatalf("got %d trashed products, %v", len(trashedProducts), err)
+	}
+	trashedCategories, err := trash.ListCategories(ctx, 0, 10)
+	if err != nil || len(trashedCategories) != 1 {
+		t.Fatalf("got %d trashed categories, %v", len(trashedCategories), err)
+	}
+
+	// The product waits for its category.
+	if _, err := trash.RestoreProduct(ctx, product.ID(), trashedProducts[0].Version()); !errors.Is(err, domain.ErrCategoryTrashed) {
+		t.Fatalf("got error %v, want %v", err, domain.ErrCategoryTrashed)
+	}
+	restoredCategory, err := trash.RestoreCategory(ctx, category.ID(), trashedCategories[0].Version())
+	if err != nil || !restoredCategory.DeletedAt().IsZero() {
+		t.Fatalf("got %v, %v", restoredCategory, err)
+	}
+	restored, err := trash.RestoreProduct(ctx, product.ID(), trashedProducts[0].Version())
+	if err != nil || restored.IsDeleted() {
+		t.Fatalf("got %v, %v", restored, err)
+	}
+}
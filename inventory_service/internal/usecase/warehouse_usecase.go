@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+)
+
+type WarehouseUseCase struct {
+	warehouseRepo domain.WarehouseRepository
+}
+
+func NewWarehouseUseCase(repo domain.WarehouseRepository) *WarehouseUseCase {
+	return &WarehouseUseCase{
+		warehouseRepo: repo,
+	}
+}
+
+func (u *WarehouseUseCase) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) error {
+	return u.warehouseRepo.Create(ctx, warehouse)
+}
+
+func (u *WarehouseUseCase) GetWarehouse(ctx context.Context, id uint64) (*domain.Warehouse, error) {
+	warehouse, err := u.warehouseRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse == nil {
+		return nil, domain.ErrWarehouseNotFound
+	}
+	return warehouse, nil
+}
+
+func (u *WarehouseUseCase) ListWarehouses(ctx context.Context) ([]*domain.Warehouse, error) {
+	return u.warehouseRepo.List(ctx)
+}
+
+func (u *WarehouseUseCase) UpdateWarehouse(ctx context.Context, id uint64, version int, changes domain.WarehouseChanges) (*domain.Warehouse, error) {
+	warehouse, err := u.GetWarehouse(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if warehouse.Version() != version {
+		return nil, domain.ErrVersionConflict
+	}
+
+	if err := warehouse.Apply(changes); err != nil {
+		return nil, err
+	}
+	if err := u.warehouseRepo.Update(ctx, warehouse); err != nil {
+		return nil, err
+	}
+	return warehouse, nil
+}
+
+func (u *WarehouseUseCase) DeleteWarehouse(ctx context.Context, id uint64, version int) error {
+	return u.warehouseRepo.Delete(ctx, id, version)
+}
+
+func (u *WarehouseUseCase) AdjustStock(ctx context.Context, warehouseID, productID uint64, delta int) (int, error) {
+	if delta == 0 {
+		return 0, domain.ErrInvalidQuantity
+	}
+	return u.warehouseRepo.AdjustStock(ctx, warehouseID, productID, delta)
+}
+
+func (u *WarehouseUseCase) Transfer(ctx context.Context, productID, fromWarehouseID, toWarehouseID uint64, quantity int, note string) (*domain.StockTransfer, error) {
+	transfer, err := domain.NewStockTransfer(productID, fromWarehouseID, toWarehouseID, quantity, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.warehouseRepo.Transfer(ctx, transfer); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (u *WarehouseUseCase) ListTransfers(ctx context.Context, productID uint64, offset, limit int) ([]*domain.StockTransfer, error) {
+	if limit <= 0 {
+		limit = 10 // Default limit
+	}
+	if limit > 100 {
+		limit = 100 // Max limit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	return u.warehouseRepo.ListTransfers(ctx, productID, offset, limit)
+}
+
+// Availability aggregates a product's stock over all active warehouses.
+func (u *WarehouseUseCase) Availability(ctx context.Context, productID uint64) (*domain.ProductAvailability, error) {
+	levels, err := u.warehouseRepo.StockLevels(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := &domain.ProductAvailability{ProductID: productID, Levels: levels}
+	for _, level := range levels {
+		availability.AvailableToSell += level.Quantity
+	}
+	return availability, nil
+}
+
+// Allocate plans which warehouses should fulfil a quantity of a product using
+// the named strategy. Nothing is reserved.
+func (u *WarehouseUseCase) Allocate(ctx context.Context, req domain.AllocationRequest, strategyName string) ([]domain.Allocation, error) {
+	strategy, err := domain.AllocationStrategyByName(strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	warehouses, err := u.warehouseRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*domain.Warehouse, len(warehouses))
+	for _, w := range warehouses {
+		byID[w.ID()] = w
+	}
+
+	levels, err := u.warehouseRepo.StockLevels(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	return strategy.Allocate(req, byID, levels)
+}
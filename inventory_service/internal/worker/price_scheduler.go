@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"log"
+	"time"
+)
+
+// PriceScheduler applies scheduled price changes once they fall due. A change
+// is applied at most one interval late.
+type PriceScheduler struct {
+	priceUseCase *usecase.PriceUseCase
+	interval     time.Duration
+}
+
+func NewPriceScheduler(uc *usecase.PriceUseCase, interval time.Duration) *PriceScheduler {
+	return &PriceScheduler{
+		priceUseCase: uc,
+		interval:     interval,
+	}
+}
+
+// Run applies due changes immediately and then on every interval until ctx
+// is done.
+func (w *PriceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := w.priceUseCase.ApplyDue(ctx)
+		if err != nil {
+			log.Printf("price scheduler failed: %v", err)
+		}
+		if applied > 0 {
+			log.Printf("price scheduler applied %d scheduled price changes", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
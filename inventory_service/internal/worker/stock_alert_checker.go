@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"log"
+	"time"
+)
+
+// StockAlertChecker periodically scans for products at or below their reorder
+// point and notifies once per change of level, so a product that stays low
+// does not raise an alert on every tick. The level each product was alerted
+// at is stored, so restarts do not repeat alerts either.
+type StockAlertChecker struct {
+	alertUseCase *usecase.StockAlertUseCase
+	notifier     domain.StockNotifier
+	interval     time.Duration
+}
+
+func NewStockAlertChecker(uc *usecase.StockAlertUseCase, notifier domain.StockNotifier, interval time.Duration) *StockAlertChecker {
+	return &StockAlertChecker{
+		alertUseCase: uc,
+		notifier:     notifier,
+		interval:     interval,
+	}
+}
+
+// Run checks immediately and then on every interval until ctx is done.
+func (w *StockAlertChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.Check(ctx); err != nil {
+			log.Printf("stock alert check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs a single scan. Products that recovered are forgotten, so running
+// low again later raises a fresh alert. A failed notification is retried on
+// the next scan.
+func (w *StockAlertChecker) Check(ctx context.Context) error {
+	items, err := w.alertUseCase.ListLowStock(ctx)
+	if err != nil {
+		return err
+	}
+	levels, err := w.alertUseCase.AlertedLevels(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	seen := make(map[uint64]bool, len(items))
+	for _, item := range items {
+		seen[item.ProductID] = true
+
+		previous, ok := levels[item.ProductID]
+		if !ok {
+			previous = domain.StockLevelOK
+		}
+		if previous == item.Level {
+			continue
+		}
+
+		alert := domain.StockAlert{Item: *item, Previous: previous, At: now}
+		if err := w.notifier.Notify(ctx, alert); err != nil {
+			log.Printf("stock alert for product %d not delivered: %v", item.ProductID, err)
+			continue
+		}
+		// A failure here repeats the alert on the next scan, which is
+		// better than losing it.
+		if err := w.alertUseCase.RecordAlert(ctx, item.ProductID, item.Level); err != nil {
+			return err
+		}
+	}
+
+	var recovered []uint64
+	for id := range levels {
+		if !seen[id] {
+			recovered = append(recovered, id)
+		}
+	}
+	return w.alertUseCase.ForgetAlerts(ctx, recovered)
+}
@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/adapter/notifier"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"testing"
+	"time"
+)
+
+// alertStore is a StockAlertRepository over a fixed stock table whose
+// alerted levels outlive any one checker, as the database's do.
+type alertStore struct {
+	domain.StockAlertRepository
+	stock   map[uint64]int
+	alerted map[uint64]domain.StockAlertLevel
+}
+
+func (s *alertStore) ListLowStock(ctx context.Context, defaultPoint int) ([]*domain.LowStockItem, error) {
+	var items []*domain.LowStockItem
+	for id, stock := range s.stock {
+		if stock <= defaultPoint {
+			items = append(items, &domain.LowStockItem{
+				ProductID:    id,
+				Stock:        stock,
+				ReorderPoint: defaultPoint,
+				Level:        domain.StockAlertLevelFor(stock, defaultPoint),
+			})
+		}
+	}
+	return items, nil
+}
+
+func (s *alertStore) AlertedLevels(context.Context) (map[uint64]domain.StockAlertLevel, error) {
+	levels := make(map[uint64]domain.StockAlertLevel, len(s.alerted))
+	for id, level := range s.alerted {
+		levels[id] = level
+	}
+	return levels, nil
+}
+
+func (s *alertStore) RecordAlert(ctx context.Context, productID uint64, level domain.StockAlertLevel) error {
+	s.alerted[productID] = level
+	return nil
+}
+
+func (s *alertStore) ForgetAlerts(ctx context.Context, productIDs []uint64) error {
+	for _, id := range productIDs {
+		delete(s.alerted, id)
+	}
+	return nil
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(context.Context, domain.StockAlert) error {
+	return errors.New("webhook unreachable")
+}
+
+func TestStockAlertCheckerAlertsOncePerLevel(t *testing.T) {
+	ctx := context.Background()
+	store := &alertStore{stock: map[uint64]int{1: 3, 2: 50}, alerted: map[uint64]domain.StockAlertLevel{}}
+	alerts := usecase.NewStockAlertUseCase(store, 5)
+	sink := notifier.NewMemoryNotifier()
+
+	check := func(n domain.StockNotifier) {
+		t.Helper()
+		if err := NewStockAlertChecker(alerts, n, time.Minute).Check(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	check(sink)
+	got := sink.Alerts()
+	if len(got) != 1 || got[0].Item.ProductID != 1 || got[0].Item.Level != domain.StockLevelLow || got[0].Previous != domain.StockLevelOK {
+		t.Fatalf("got alerts %+v", got)
+	}
+
+	// A new checker, as after a restart, remembers what was alerted.
+	sink.Reset()
+	check(sink)
+	if len(sink.Alerts()) != 0 {
+		t.Fatalf("re-alerted after a restart: %+v", sink.Alerts())
+	}
+
+	store.stock[1] = 0
+	check(sink)
+	if got := sink.Alerts(); len(got) != 1 || got[0].Item.Level != domain.StockLevelOutOfStock || got[0].Previous != domain.StockLevelLow {
+		t.Fatalf("got alerts %+v", got)
+	}
+
+	// Recovering forgets the product, so running low again alerts afresh.
+	store.stock[1] = 20
+	check(sink)
+	if _, ok := store.alerted[1]; ok {
+		t.Fatal("a recovered product is still remembered")
+	}
+	sink.Reset()
+	store.stock[1] = 4
+	check(sink)
+	if got := sink.Alerts(); len(got) != 1 || got[0].Previous != domain.StockLevelOK {
+		t.Fatalf("got alerts %+v", got)
+	}
+}
+
+func TestStockAlertCheckerRetriesUndelivered(t *testing.T) {
+	ctx := context.Background()
+	store := &alertStore{stock: map[uint64]int{1: 0}, alerted: map[uint64]domain.StockAlertLevel{}}
+	alerts := usecase.NewStockAlertUseCase(store, 5)
+
+	if err := NewStockAlertChecker(alerts, failingNotifier{}, time.Minute).Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.alerted) != 0 {
+		t.Fatal("an undelivered alert was recorded")
+	}
+
+	sink := notifier.NewMemoryNotifier()
+	if err := NewStockAlertChecker(alerts, sink, time.Minute).Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.Alerts()) != 1 {
+		t.Fatalf("got %d alerts on retry", len(sink.Alerts()))
+	}
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"github.com/KaminurOrynbek/e-commerce_microservices/inventory_service/internal/usecase"
+	"log"
+	"time"
+)
+
+// TrashPurger hard-deletes trashed products and categories once their
+// retention period has passed.
+type TrashPurger struct {
+	trashUseCase *usecase.TrashUseCase
+	interval     time.Duration
+}
+
+func NewTrashPurger(uc *usecase.TrashUseCase, interval time.Duration) *TrashPurger {
+	return &TrashPurger{
+		trashUseCase: uc,
+		interval:     interval,
+	}
+}
+
+// Run purges immediately and then on every interval until ctx is done.
+func (w *TrashPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := w.trashUseCase.Purge(ctx, time.Now())
+		if err != nil {
+			log.Printf("trash purge failed: %v", err)
+		}
+		if result.Products > 0 || result.Categories > 0 {
+			log.Printf("trash purge removed %d products and %d categories", result.Products, result.Categories)
+		}
+		if result.BlockedCategories > 0 {
+			log.Printf("trash purge kept %d expired categories that are still referenced", result.BlockedCategories)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_products_search_simple;
+DROP INDEX IF EXISTS idx_products_search_russian;
+DROP INDEX IF EXISTS idx_products_search_english;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS search_simple,
+    DROP COLUMN IF EXISTS search_russian,
+    DROP COLUMN IF EXISTS search_english;
@@ -0,0 +1,23 @@
+-- Each supported text search configuration gets its own vector, so that a
+-- search stems the document with the same configuration as its terms. The
+-- simple vector also matches terms as typed, whatever the language.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS search_english tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) STORED,
+    ADD COLUMN IF NOT EXISTS search_russian tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('russian', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('russian', coalesce(description, '')), 'B')
+        ) STORED,
+    ADD COLUMN IF NOT EXISTS search_simple tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+            setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_products_search_english ON products USING GIN (search_english);
+CREATE INDEX IF NOT EXISTS idx_products_search_russian ON products USING GIN (search_russian);
+CREATE INDEX IF NOT EXISTS idx_products_search_simple ON products USING GIN (search_simple);
@@ -0,0 +1,4 @@
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS parent_id BIGINT REFERENCES categories(id);
+ALTER TABLE categories ADD CONSTRAINT categories_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
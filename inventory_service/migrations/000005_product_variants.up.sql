@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku VARCHAR(64) NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}',
+    price DECIMAL(10,2),
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_variants_sku ON product_variants (sku) WHERE is_deleted = false;
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants (product_id);
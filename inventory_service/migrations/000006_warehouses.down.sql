@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS stock_transfers;
+DROP TABLE IF EXISTS warehouse_stock;
+DROP TABLE IF EXISTS warehouses;
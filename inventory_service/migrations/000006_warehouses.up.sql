@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS warehouses (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_deleted BOOLEAN NOT NULL DEFAULT FALSE,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id),
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity INTEGER NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_product_id ON warehouse_stock (product_id);
+
+CREATE TABLE IF NOT EXISTS stock_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    from_warehouse_id BIGINT NOT NULL REFERENCES warehouses(id),
+    to_warehouse_id BIGINT NOT NULL REFERENCES warehouses(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_transfers_product_id ON stock_transfers (product_id);
@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    variant_id BIGINT REFERENCES product_variants(id),
+    -- Entries for a warehouse record warehouse_stock, not products.stock.
+    warehouse_id BIGINT REFERENCES warehouses(id),
+    delta INTEGER NOT NULL CHECK (delta <> 0),
+    reason VARCHAR(32) NOT NULL CHECK (reason IN ('sale', 'restock', 'adjustment', 'return', 'reservation', 'transfer')),
+    reference_id VARCHAR(255),
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_product_id ON stock_movements (product_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_stock_movements_variant_id ON stock_movements (variant_id) WHERE variant_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stock_movements_warehouse_id ON stock_movements (warehouse_id) WHERE warehouse_id IS NOT NULL;
+
+-- The ledger is append-only: corrections are new movements, never edits.
+CREATE OR REPLACE FUNCTION stock_movements_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stock_movements_no_update
+    BEFORE UPDATE OR DELETE ON stock_movements
+    FOR EACH ROW EXECUTE FUNCTION stock_movements_immutable();
+
+-- Open the ledger with the stock that exists today so that it reconciles.
+INSERT INTO stock_movements (product_id, delta, reason, reference_id, actor)
+SELECT id, stock, 'adjustment', 'opening-balance', 'migration'
+FROM products
+WHERE stock <> 0;
+
+INSERT INTO stock_movements (product_id, variant_id, delta, reason, reference_id, actor)
+SELECT product_id, id, stock, 'adjustment', 'opening-balance', 'migration'
+FROM product_variants
+WHERE stock <> 0;
+
+INSERT INTO stock_movements (product_id, warehouse_id, delta, reason, reference_id, actor)
+SELECT product_id, warehouse_id, quantity, 'adjustment', 'opening-balance', 'migration'
+FROM warehouse_stock
+WHERE quantity <> 0;
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS stock_alert_states;
+
+DROP INDEX IF EXISTS idx_products_stock;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS reorder_point;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
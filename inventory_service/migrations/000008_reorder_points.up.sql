@@ -0,0 +1,12 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS reorder_point INTEGER CHECK (reorder_point >= 0);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS reorder_point INTEGER CHECK (reorder_point >= 0);
+
+CREATE INDEX IF NOT EXISTS idx_products_stock ON products (stock) WHERE is_deleted = false;
+
+-- The level each low product was last alerted at, so that a restart does not
+-- alert again for products that are still low.
+CREATE TABLE IF NOT EXISTS stock_alert_states (
+    product_id BIGINT PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    level VARCHAR(16) NOT NULL,
+    alerted_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_categories_external_id;
+DROP INDEX IF EXISTS idx_products_external_id;
+DROP INDEX IF EXISTS idx_products_sku;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS external_id;
+ALTER TABLE products DROP COLUMN IF EXISTS external_id;
+ALTER TABLE products DROP COLUMN IF EXISTS sku;
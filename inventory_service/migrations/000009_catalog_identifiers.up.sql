@@ -0,0 +1,7 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS sku VARCHAR(64);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS external_id VARCHAR(128);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS external_id VARCHAR(128);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products (sku) WHERE is_deleted = false;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_external_id ON products (external_id) WHERE is_deleted = false;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_external_id ON categories (external_id) WHERE is_deleted = false;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    storage_key VARCHAR(512) NOT NULL,
+    content_type VARCHAR(64) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    position INTEGER NOT NULL,
+    is_primary BOOLEAN NOT NULL DEFAULT FALSE,
+    thumbnail_keys JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_id ON product_images (product_id, position);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_images_primary ON product_images (product_id) WHERE is_primary;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS category_attributes (
+    id BIGSERIAL PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    code VARCHAR(63) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    unit VARCHAR(32),
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    allowed_values JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (category_id, code)
+);
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_products_attributes ON products USING GIN (attributes);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS price_changes;
+DROP FUNCTION IF EXISTS price_changes_immutable();
+DROP TABLE IF EXISTS price_schedules;
@@ -0,0 +1,45 @@
+CREATE TABLE IF NOT EXISTS price_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
+    -- Schedule times are instants chosen by clients in any zone, so they are
+    -- stored with their zone rather than in the server's local time.
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ CHECK (ends_at > starts_at),
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'active', 'completed', 'expired', 'canceled')),
+    previous_price DECIMAL(10,2),
+    actor VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_schedules_product_id ON price_schedules (product_id, starts_at);
+-- The scheduler only ever looks at open schedules.
+CREATE INDEX IF NOT EXISTS idx_price_schedules_open ON price_schedules (starts_at) WHERE status IN ('pending', 'active');
+
+CREATE TABLE IF NOT EXISTS price_changes (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id),
+    price DECIMAL(10,2) NOT NULL,
+    source VARCHAR(16) NOT NULL CHECK (source IN ('manual', 'scheduled')),
+    schedule_id BIGINT REFERENCES price_schedules(id),
+    actor VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_changes_product_id ON price_changes (product_id, changed_at);
+
+CREATE OR REPLACE FUNCTION price_changes_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'price_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER price_changes_no_update
+    BEFORE UPDATE OR DELETE ON price_changes
+    FOR EACH ROW EXECUTE FUNCTION price_changes_immutable();
+
+-- Start each history with the price the product has today.
+INSERT INTO price_changes (product_id, price, source, actor, changed_at)
+SELECT id, price, 'manual', 'migration', created_at
+FROM products;
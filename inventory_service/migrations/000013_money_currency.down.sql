@@ -0,0 +1,11 @@
+ALTER TABLE price_schedules DROP COLUMN IF EXISTS currency;
+ALTER TABLE price_schedules ALTER COLUMN previous_price TYPE DECIMAL(10,2);
+ALTER TABLE price_schedules ALTER COLUMN price TYPE DECIMAL(10,2);
+
+ALTER TABLE price_changes DROP COLUMN IF EXISTS currency;
+ALTER TABLE price_changes ALTER COLUMN price TYPE DECIMAL(10,2);
+
+ALTER TABLE product_variants ALTER COLUMN price TYPE DECIMAL(10,2);
+
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+ALTER TABLE products ALTER COLUMN price TYPE DECIMAL(10,2);
@@ -0,0 +1,13 @@
+-- Amounts are stored with three decimal places so that currencies with a
+-- thousandth minor unit fit; the currency column says how many are used.
+ALTER TABLE products ALTER COLUMN price TYPE DECIMAL(15,3);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE product_variants ALTER COLUMN price TYPE DECIMAL(15,3);
+
+ALTER TABLE price_changes ALTER COLUMN price TYPE DECIMAL(15,3);
+ALTER TABLE price_changes ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE price_schedules ALTER COLUMN price TYPE DECIMAL(15,3);
+ALTER TABLE price_schedules ALTER COLUMN previous_price TYPE DECIMAL(15,3);
+ALTER TABLE price_schedules ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS product_list_prices (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    price DECIMAL(15,3) NOT NULL CHECK (price >= 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_list_prices_currency ON product_list_prices (currency);
+
+-- One unit of base_currency buys rate units of quote_currency. A pair is
+-- also used in reverse when only the opposite direction is on file.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL CHECK (quote_currency <> base_currency),
+    rate NUMERIC(24,12) NOT NULL CHECK (rate > 0),
+    actor VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
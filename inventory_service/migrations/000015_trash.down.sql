@@ -0,0 +1,31 @@
+ALTER TABLE price_changes DROP CONSTRAINT IF EXISTS price_changes_product_id_fkey;
+ALTER TABLE price_changes ADD CONSTRAINT price_changes_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id);
+ALTER TABLE price_schedules DROP CONSTRAINT IF EXISTS price_schedules_product_id_fkey;
+ALTER TABLE price_schedules ADD CONSTRAINT price_schedules_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id);
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_variant_id_fkey;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id);
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_product_id_fkey;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id);
+
+CREATE OR REPLACE FUNCTION price_changes_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'price_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION stock_movements_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_categories_deleted_at;
+DROP INDEX IF EXISTS idx_products_deleted_at;
+
+ALTER TABLE categories DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE products DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,45 @@
+-- Trashed rows remember when they were deleted so that the purge job can
+-- hard-delete them once the retention period has passed.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+UPDATE products SET deleted_at = updated_at WHERE is_deleted AND deleted_at IS NULL;
+UPDATE categories SET deleted_at = updated_at WHERE is_deleted AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at) WHERE is_deleted = true;
+CREATE INDEX IF NOT EXISTS idx_categories_deleted_at ON categories (deleted_at) WHERE is_deleted = true;
+
+-- Purging a product takes its ledgers with it. The ledgers stay append-only
+-- for everything else: a purge marks its transaction with
+-- SET LOCAL inventory.purge = 'on', and only then may rows be deleted.
+CREATE OR REPLACE FUNCTION stock_movements_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('inventory.purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'stock_movements is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION price_changes_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('inventory.purge', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'price_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_product_id_fkey;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE stock_movements DROP CONSTRAINT IF EXISTS stock_movements_variant_id_fkey;
+ALTER TABLE stock_movements ADD CONSTRAINT stock_movements_variant_id_fkey
+    FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE;
+
+ALTER TABLE price_schedules DROP CONSTRAINT IF EXISTS price_schedules_product_id_fkey;
+ALTER TABLE price_schedules ADD CONSTRAINT price_schedules_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+ALTER TABLE price_changes DROP CONSTRAINT IF EXISTS price_changes_product_id_fkey;
+ALTER TABLE price_changes ADD CONSTRAINT price_changes_product_id_fkey
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
@@ -0,0 +1,2 @@
+-- Categories report their live product count on every read.
+CREATE INDEX IF NOT EXISTS idx_products_category_id ON products (category_id) WHERE is_deleted = false;
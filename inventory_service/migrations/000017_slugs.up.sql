@@ -0,0 +1,79 @@
+ALTER TABLE products ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS slug VARCHAR(120);
+
+-- Slugs stay unique across the trash too, so a restore never collides. The
+-- indexes come first so that the backfill below can look slugs up by them.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_slug ON products (slug);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_categories_slug ON categories (slug);
+
+-- slugify mirrors domain.Slugify, transliterations included, so that
+-- existing rows get the slug the service would have given them.
+CREATE FUNCTION pg_temp.slugify(name TEXT) RETURNS TEXT AS $$
+DECLARE
+    slug TEXT := lower(name);
+BEGIN
+    slug := replace(replace(replace(replace(replace(replace(slug,
+        'щ', 'shch'), 'ж', 'zh'), 'х', 'kh'), 'ц', 'ts'), 'ч', 'ch'), 'ш', 'sh');
+    slug := replace(replace(replace(replace(replace(replace(slug,
+        'ю', 'yu'), 'я', 'ya'), 'ғ', 'gh'), 'ң', 'ng'), 'æ', 'ae'), 'ß', 'ss');
+    slug := translate(slug, 'ъь', '');
+    slug := translate(slug,
+        'абвгдеёзийклмнопрстуфыэәқөұүһіàáâãäåçèéêëìíîïñòóôõöøùúûüýÿışğ',
+        'abvgdeeziyklmnoprstufyeaqouuhiaaaaaaceeeeiiiinoooooouuuuyyisg');
+    slug := trim(both '-' from regexp_replace(slug, '[^a-z0-9]+', '-', 'g'));
+    RETURN rtrim(left(slug, 90), '-');
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- backfill_slugs gives existing rows, oldest first, a slug the way the
+-- service assigns one: the slugified name, or fallback when it spells
+-- nothing, with the lowest numeric suffix that no row has taken yet.
+CREATE FUNCTION pg_temp.backfill_slugs(tbl TEXT, fallback TEXT) RETURNS VOID AS $$
+DECLARE
+    r RECORD;
+    base TEXT;
+    candidate TEXT;
+    n INT;
+    taken BOOLEAN;
+BEGIN
+    FOR r IN EXECUTE format('SELECT id, name FROM %I ORDER BY id', tbl) LOOP
+        base := coalesce(nullif(pg_temp.slugify(r.name), ''), fallback);
+        candidate := base;
+        n := 2;
+        LOOP
+            EXECUTE format('SELECT EXISTS (SELECT 1 FROM %I WHERE slug = $1)', tbl) INTO taken USING candidate;
+            EXIT WHEN NOT taken;
+            candidate := base || '-' || n;
+            n := n + 1;
+        END LOOP;
+        EXECUTE format('UPDATE %I SET slug = $1 WHERE id = $2', tbl) USING candidate, r.id;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT pg_temp.backfill_slugs('products', 'product');
+SELECT pg_temp.backfill_slugs('categories', 'category');
+
+DROP FUNCTION pg_temp.backfill_slugs(TEXT, TEXT);
+DROP FUNCTION pg_temp.slugify(TEXT);
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+ALTER TABLE categories ALTER COLUMN slug SET NOT NULL;
+
+-- Slugs an entity used to have. A slug that becomes current again, for any
+-- entity, is dropped from here.
+CREATE TABLE IF NOT EXISTS product_slug_redirects (
+    slug VARCHAR(120) PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_redirects_product_id ON product_slug_redirects (product_id);
+
+CREATE TABLE IF NOT EXISTS category_slug_redirects (
+    slug VARCHAR(120) PRIMARY KEY,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_slug_redirects_category_id ON category_slug_redirects (category_id);
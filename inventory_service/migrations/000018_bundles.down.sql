@@ -0,0 +1,10 @@
+ALTER TABLE price_changes DROP CONSTRAINT IF EXISTS price_changes_source_check;
+ALTER TABLE price_changes ADD CONSTRAINT price_changes_source_check
+    CHECK (source IN ('manual', 'scheduled')) NOT VALID;
+
+DROP TRIGGER IF EXISTS products_refresh_bundle_stock ON products;
+DROP FUNCTION IF EXISTS refresh_bundle_stock();
+DROP FUNCTION IF EXISTS bundle_stock_changed(BIGINT, INTEGER, INTEGER);
+DROP FUNCTION IF EXISTS bundle_stock(BIGINT);
+DROP TABLE IF EXISTS bundle_components;
+DROP TABLE IF EXISTS bundles;
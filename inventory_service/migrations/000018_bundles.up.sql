@@ -0,0 +1,78 @@
+-- A bundle is a product sold as a kit of other products. It keeps its row in
+-- products, so it is listed, priced and ordered like any other product.
+CREATE TABLE IF NOT EXISTS bundles (
+    product_id BIGINT PRIMARY KEY REFERENCES products(id) ON DELETE CASCADE,
+    pricing VARCHAR(16) NOT NULL CHECK (pricing IN ('fixed', 'discount')),
+    discount_percent NUMERIC(5,2) CHECK (discount_percent > 0 AND discount_percent < 100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CHECK ((pricing = 'discount') = (discount_percent IS NOT NULL))
+);
+
+-- Components are plain products; a product referenced here is not purged
+-- until the bundles using it are gone.
+CREATE TABLE IF NOT EXISTS bundle_components (
+    bundle_id BIGINT NOT NULL REFERENCES bundles(product_id) ON DELETE CASCADE,
+    component_id BIGINT NOT NULL REFERENCES products(id),
+    quantity INTEGER NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (bundle_id, component_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bundle_components_component_id ON bundle_components (component_id);
+
+-- bundle_stock is the number of complete kits the live component stock
+-- allows. A trashed component makes the bundle unavailable.
+CREATE OR REPLACE FUNCTION bundle_stock(bundle BIGINT) RETURNS INTEGER AS $$
+    SELECT COALESCE(MIN(CASE WHEN p.is_deleted THEN 0 ELSE p.stock / bc.quantity END), 0)::INTEGER
+    FROM bundle_components bc
+    JOIN products p ON p.id = bc.component_id
+    WHERE bc.bundle_id = bundle
+$$ LANGUAGE sql STABLE;
+
+-- bundle_stock_changed is called for every change of a bundle's derived
+-- stock. It does nothing until the outbox exists to publish the change.
+CREATE OR REPLACE FUNCTION bundle_stock_changed(bundle BIGINT, previous INTEGER, available INTEGER) RETURNS VOID AS $$
+BEGIN
+END;
+$$ LANGUAGE plpgsql;
+
+-- The bundle's stock column mirrors bundle_stock so that filters, sorting,
+-- facets and low-stock alerts treat bundles like other products. It is
+-- refreshed at commit, after every component row the transaction touched is
+-- locked, which keeps component writers from deadlocking on the bundle row.
+-- Bundles are locked in ID order and versioned like any other stock change.
+CREATE OR REPLACE FUNCTION refresh_bundle_stock() RETURNS trigger AS $$
+DECLARE
+    b RECORD;
+    available INTEGER;
+BEGIN
+    FOR b IN
+        SELECT p.id, p.stock
+        FROM products p
+        WHERE p.id IN (SELECT bundle_id FROM bundle_components WHERE component_id = NEW.id)
+        ORDER BY p.id
+        FOR UPDATE
+    LOOP
+        available := bundle_stock(b.id);
+        CONTINUE WHEN available = b.stock;
+        UPDATE products
+        SET stock = available, version = version + 1, updated_at = NOW()
+        WHERE id = b.id;
+        PERFORM bundle_stock_changed(b.id, b.stock, available);
+    END LOOP;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_refresh_bundle_stock ON products;
+CREATE CONSTRAINT TRIGGER products_refresh_bundle_stock
+    AFTER UPDATE OF stock, is_deleted ON products
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW
+    WHEN (OLD.stock IS DISTINCT FROM NEW.stock OR OLD.is_deleted IS DISTINCT FROM NEW.is_deleted)
+    EXECUTE FUNCTION refresh_bundle_stock();
+
+-- Derived bundle prices are recorded in the price history as their own source.
+ALTER TABLE price_changes DROP CONSTRAINT IF EXISTS price_changes_source_check;
+ALTER TABLE price_changes ADD CONSTRAINT price_changes_source_check
+    CHECK (source IN ('manual', 'scheduled', 'bundle'));
@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION bundle_stock_changed(bundle BIGINT, previous INTEGER, available INTEGER) RETURNS VOID AS $$
+BEGIN
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS outbox_dead_letters;
+DROP TABLE IF EXISTS outbox_events;
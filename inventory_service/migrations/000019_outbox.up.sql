@@ -0,0 +1,52 @@
+-- Domain events are written here in the same transaction as the change they
+-- describe and removed by the relay once published.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    available_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT
+);
+
+-- The relay looks up the oldest pending event of each aggregate.
+CREATE INDEX IF NOT EXISTS idx_outbox_events_aggregate ON outbox_events (aggregate_type, aggregate_id, id);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_available_at ON outbox_events (available_at, id);
+
+-- Events that kept failing are parked here with their last error.
+CREATE TABLE IF NOT EXISTS outbox_dead_letters (
+    id BIGINT PRIMARY KEY,
+    aggregate_type VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(128) NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    attempts INTEGER NOT NULL,
+    last_error TEXT NOT NULL,
+    failed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- A bundle's derived stock changes at commit, where no service code runs, so
+-- the database publishes it. The payload matches domain.StockChanged.
+CREATE OR REPLACE FUNCTION bundle_stock_changed(bundle BIGINT, previous INTEGER, available INTEGER) RETURNS VOID AS $$
+BEGIN
+    INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload, occurred_at, available_at)
+    VALUES (
+        'product',
+        bundle::TEXT,
+        'product.stock_changed',
+        jsonb_build_object(
+            'product_id', bundle,
+            'delta', available - previous,
+            'stock', available,
+            'reason', 'bundle',
+            'actor', 'system'
+        ),
+        NOW() AT TIME ZONE 'UTC',
+        NOW()
+    );
+END;
+$$ LANGUAGE plpgsql;
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/config"
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/handler"
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/repository"
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
@@ -19,6 +25,11 @@ func main() {
 		log.Fatalf("Error loading .env file: %v", err)
 	}
 
+	// Money settings apply to every amount the service parses or writes.
+	if err := configureMoney(config.NewMoneyConfig()); err != nil {
+		log.Fatalf("Invalid money settings: %v", err)
+	}
+
 	// Construct DB connection string from environment variables
 	dbHost := os.Getenv("DB_HOST")
 	dbPort := os.Getenv("DB_PORT")
@@ -47,8 +58,25 @@ func main() {
 	orderUseCase := usecase.NewOrderUseCase(orderRepo)
 	orderHandler := handler.NewOrderHandler(orderUseCase)
 
-	// Create a Gin router and define routes.
-	router := gin.Default()
+	// Relay order events from the outbox. OUTBOX_PUBLISHER=none leaves them
+	// in the outbox.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if outboxConfig := config.NewOutboxConfig(); outboxConfig.Publisher != "none" {
+		go outbox.NewRelay(db, outbox.NewLogPublisher(), outbox.RelayOptions{
+			Interval:    outboxConfig.RelayInterval,
+			BatchSize:   outboxConfig.BatchSize,
+			MaxAttempts: outboxConfig.MaxAttempts,
+			RetryDelay:  outboxConfig.RetryDelay,
+		}).Run(ctx)
+	}
+
+	// Create a Gin router and define routes. Errors are answered as problem
+	// details, which only show internal errors when PROBLEM_VERBOSE is set.
+	problem.SetVerbose(config.NewProblemConfig().Verbose)
+	router := gin.New()
+	router.Use(gin.Logger(), ginproblem.RequestID(), ginproblem.Recovery())
+	router.NoRoute(ginproblem.NoRoute)
 
 	// Health check endpoint.
 	router.GET("/health", func(c *gin.Context) {
@@ -69,3 +97,21 @@ func main() {
 		log.Fatalf("Failed to run server: %v", err)
 	}
 }
+
+// configureMoney applies the process-wide money settings before any amount is
+// parsed or written.
+func configureMoney(cfg *config.MoneyConfig) error {
+	currency, err := money.ParseCurrency(cfg.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	format, err := money.ParseFormat(cfg.JSONFormat)
+	if err != nil {
+		return err
+	}
+	if err := money.SetDefaultCurrency(currency); err != nil {
+		return err
+	}
+	money.SetJSONFormat(format)
+	return nil
+}
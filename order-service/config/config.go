@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// OutboxConfig controls the relay that publishes order events. Publisher is
+// "log" or "none"; with "none" the relay does not run and events wait in the
+// outbox. Failed deliveries are retried after RetryDelay, doubling each time,
+// and dead-lettered after MaxAttempts. The variables and defaults are the
+// inventory service's.
+type OutboxConfig struct {
+	Publisher     string
+	RelayInterval time.Duration
+	BatchSize     int
+	MaxAttempts   int
+	RetryDelay    time.Duration
+}
+
+func NewOutboxConfig() *OutboxConfig {
+	return &OutboxConfig{
+		Publisher:     getEnv("OUTBOX_PUBLISHER", "log"),
+		RelayInterval: getDurationEnv("OUTBOX_RELAY_INTERVAL", time.Second),
+		BatchSize:     getIntEnv("OUTBOX_BATCH_SIZE", 100),
+		MaxAttempts:   getIntEnv("OUTBOX_MAX_ATTEMPTS", 10),
+		RetryDelay:    getDurationEnv("OUTBOX_RETRY_DELAY", time.Second),
+	}
+}
+
+// MoneyConfig sets the currency assumed for amounts given without one and
+// whether amounts are written to JSON as "decimal" strings or "minor" units.
+// The variables and defaults are the inventory service's.
+type MoneyConfig struct {
+	DefaultCurrency string
+	JSONFormat      string
+}
+
+func NewMoneyConfig() *MoneyConfig {
+	return &MoneyConfig{
+		DefaultCurrency: getEnv("DEFAULT_CURRENCY", "USD"),
+		JSONFormat:      getEnv("MONEY_JSON_FORMAT", "decimal"),
+	}
+}
+
+// ProblemConfig sets whether error responses show the detail of internal
+// errors, which may expose internals such as SQL. It is off unless
+// PROBLEM_VERBOSE asks for it.
+type ProblemConfig struct {
+	Verbose bool
+}
+
+func NewProblemConfig() *ProblemConfig {
+	return &ProblemConfig{
+		Verbose: getBoolEnv("PROBLEM_VERBOSE", false),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
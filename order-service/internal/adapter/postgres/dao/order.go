@@ -9,7 +9,8 @@ type Order struct {
 	ID           int64            `db:"id"`
 	UserID       int64            `db:"user_id"`
 	Products     []OrderedProduct `db:"products"`
-	TotalAmount  float64          `db:"total_amount"`
+	TotalAmount  string           `db:"total_amount"`
+	Currency     string           `db:"currency"`
 	Status       string           `db:"status"`
 	DeliveryAddr string           `db:"delivery_addr"`
 }
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
+	"strconv"
+)
+
+const AggregateOrder = "order"
+
+const (
+	EventOrderCreated       = "order.created"
+	EventOrderUpdated       = "order.updated"
+	EventOrderStatusChanged = "order.status_changed"
+)
+
+// OrderSnapshot is the payload of order.created and order.updated.
+type OrderSnapshot struct {
+	ID           int64       `json:"id"`
+	UserID       int64       `json:"user_id"`
+	Products     []OrderLine `json:"products"`
+	TotalAmount  money.Money `json:"total_amount"`
+	Status       string      `json:"status"`
+	DeliveryAddr string      `json:"delivery_address"`
+}
+
+type OrderLine struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int   `json:"quantity"`
+}
+
+// OrderStatusChanged is the payload of order.status_changed.
+type OrderStatusChanged struct {
+	ID     int64  `json:"id"`
+	UserID int64  `json:"user_id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// NewOrderEvent wraps payload in an outbox event for order orderID.
+func NewOrderEvent(eventType string, orderID int64, payload interface{}) (outbox.Event, error) {
+	return outbox.NewEvent(AggregateOrder, strconv.FormatInt(orderID, 10), eventType, payload)
+}
+
+func NewOrderSnapshot(o Order) *OrderSnapshot {
+	products := make([]OrderLine, len(o.Products))
+	for i, p := range o.Products {
+		products[i] = OrderLine{ProductID: p.ProductID, Quantity: p.Quantity}
+	}
+	return &OrderSnapshot{
+		ID:           o.ID,
+		UserID:       o.UserID,
+		Products:     products,
+		TotalAmount:  o.TotalAmount,
+		Status:       o.Status,
+		DeliveryAddr: o.DeliveryAddr,
+	}
+}
@@ -1,5 +1,15 @@
 package domain
 
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+)
+
+var (
+	ErrInvalidTotal  = errors.New("total amount must be a non-negative amount with a currency")
+	ErrOrderNotFound = errors.New("order not found")
+)
+
 type OrderedProduct struct {
 	ProductID int64
 	Quantity  int
@@ -9,7 +19,25 @@ type Order struct {
 	ID           int64
 	UserID       int64
 	Products     []OrderedProduct
-	TotalAmount  float64
+	TotalAmount  money.Money
 	Status       string
 	DeliveryAddr string
 }
+
+// Validate checks the parts of an order that the store relies on.
+func (o Order) Validate() error {
+	if !o.TotalAmount.IsSet() || o.TotalAmount.IsNegative() {
+		return ErrInvalidTotal
+	}
+	return nil
+}
+
+// OrderRepository stores orders together with their product lines. GetOrder
+// and UpdateOrder fail with ErrOrderNotFound for an unknown ID.
+type OrderRepository interface {
+	CreateOrder(o Order) (Order, error)
+	GetOrder(id int64) (Order, error)
+	UpdateOrder(o Order) (Order, error)
+	// ListOrdersByUser returns the user's orders, oldest first.
+	ListOrdersByUser(userID int64) ([]Order, error)
+}
@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+
 type OrderedProduct struct {
 	ProductID int64 `json:"product_id"`
 	Quantity  int   `json:"quantity"`
@@ -9,7 +11,7 @@ type Order struct {
 	ID           int64            `json:"id"`
 	UserID       int64            `json:"user_id"`
 	Products     []OrderedProduct `json:"products"`
-	TotalAmount  float64          `json:"total_amount"`
+	TotalAmount  money.Money      `json:"total_amount"`
 	Status       string           `json:"status"`
 	DeliveryAddr string           `json:"delivery_address"`
 }
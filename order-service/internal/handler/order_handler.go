@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/usecase"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem/ginproblem"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"strconv"
@@ -19,12 +22,12 @@ func NewOrderHandler(u usecase.OrderUseCase) *OrderHandler {
 func (h *OrderHandler) CreateOrder(c *gin.Context) {
 	var o domain.Order
 	if err := c.ShouldBindJSON(&o); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ginproblem.WriteBindingError(c, err)
 		return
 	}
 	created, err := h.UseCase.CreateOrder(o)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, created)
@@ -33,12 +36,12 @@ func (h *OrderHandler) CreateOrder(c *gin.Context) {
 func (h *OrderHandler) GetOrder(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		ginproblem.Write(c, problem.New(http.StatusBadRequest, "invalid order id"))
 		return
 	}
 	order, err := h.UseCase.GetOrder(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, order)
@@ -47,18 +50,18 @@ func (h *OrderHandler) GetOrder(c *gin.Context) {
 func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order id"})
+		ginproblem.Write(c, problem.New(http.StatusBadRequest, "invalid order id"))
 		return
 	}
 	var o domain.Order
 	if err := c.ShouldBindJSON(&o); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ginproblem.WriteBindingError(c, err)
 		return
 	}
 	o.ID = id
 	updated, err := h.UseCase.UpdateOrder(o)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, updated)
@@ -67,13 +70,26 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 func (h *OrderHandler) ListOrdersByUser(c *gin.Context) {
 	userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		ginproblem.Write(c, problem.New(http.StatusBadRequest, "invalid user id"))
 		return
 	}
 	orders, err := h.UseCase.ListOrdersByUser(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, orders)
 }
+
+// writeError answers with the problem for a use case error. Errors the
+// domain does not declare are the server's fault.
+func (h *OrderHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTotal):
+		ginproblem.Write(c, problem.New(http.StatusBadRequest, err.Error()).WithCode("invalid_total"))
+	case errors.Is(err, domain.ErrOrderNotFound):
+		ginproblem.Write(c, problem.New(http.StatusNotFound, err.Error()).WithCode("order_not_found"))
+	default:
+		ginproblem.WriteInternal(c, http.StatusInternalServerError, err)
+	}
+}
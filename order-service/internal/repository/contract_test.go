@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+	"reflect"
+	"testing"
+)
+
+// testOrderRepository is the contract every domain.OrderRepository meets.
+// newRepository returns a repository with no orders and is called once per
+// case.
+func testOrderRepository(t *testing.T, newRepository func(t *testing.T) domain.OrderRepository) {
+	total := func(t *testing.T, amount string) money.Money {
+		t.Helper()
+		m, err := money.Parse(amount, "USD")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	order := func(t *testing.T, userID int64, amount string, products ...domain.OrderedProduct) domain.Order {
+		return domain.Order{
+			UserID:       userID,
+			Products:     products,
+			TotalAmount:  total(t, amount),
+			Status:       "pending",
+			DeliveryAddr: "1 Main St",
+		}
+	}
+
+	t.Run("create assigns an id and reads back", func(t *testing.T) {
+		repo := newRepository(t)
+		created, err := repo.CreateOrder(order(t, 7, "25.50",
+			domain.OrderedProduct{ProductID: 1, Quantity: 2},
+			domain.OrderedProduct{ProductID: 3, Quantity: 1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created.ID == 0 {
+			t.Fatal("created order has no id")
+		}
+
+		read, err := repo.GetOrder(created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, created) {
+			t.Fatalf("read back %+v, want %+v", read, created)
+		}
+	})
+
+	t.Run("missing orders are not found", func(t *testing.T) {
+		repo := newRepository(t)
+		if _, err := repo.GetOrder(999); !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("get: got error %v, want %v", err, domain.ErrOrderNotFound)
+		}
+		missing := order(t, 7, "1.00")
+		missing.ID = 999
+		if _, err := repo.UpdateOrder(missing); !errors.Is(err, domain.ErrOrderNotFound) {
+			t.Fatalf("update: got error %v, want %v", err, domain.ErrOrderNotFound)
+		}
+	})
+
+	t.Run("update replaces the order and its lines", func(t *testing.T) {
+		repo := newRepository(t)
+		created, err := repo.CreateOrder(order(t, 7, "10.00", domain.OrderedProduct{ProductID: 1, Quantity: 1}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		created.Status = "shipped"
+		created.TotalAmount = total(t, "12.00")
+		created.Products = []domain.OrderedProduct{{ProductID: 2, Quantity: 4}}
+		if _, err := repo.UpdateOrder(created); err != nil {
+			t.Fatal(err)
+		}
+
+		read, err := repo.GetOrder(created.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(read, created) {
+			t.Fatalf("read back %+v, want %+v", read, created)
+		}
+	})
+
+	t.Run("list returns a user's orders oldest first", func(t *testing.T) {
+		repo := newRepository(t)
+		var want []int64
+		for _, userID := range []int64{7, 8, 7} {
+			created, err := repo.CreateOrder(order(t, userID, "5.00"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if userID == 7 {
+				want = append(want, created.ID)
+			}
+		}
+
+		orders, err := repo.ListOrdersByUser(7)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int64
+		for _, o := range orders {
+			got = append(got, o.ID)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("got orders %v, want %v", got, want)
+		}
+
+		orders, err = repo.ListOrdersByUser(9)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 0 {
+			t.Fatalf("got %d orders for a user without any", len(orders))
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
+	"sync"
+)
+
+// MemoryOrderRepository keeps orders in process memory, for tests and local
+// runs. It writes no outbox events.
+type MemoryOrderRepository struct {
+	mu     sync.Mutex
+	orders map[int64]domain.Order
+	lastID int64
+}
+
+func NewMemoryOrderRepository() *MemoryOrderRepository {
+	return &MemoryOrderRepository{orders: make(map[int64]domain.Order)}
+}
+
+func (r *MemoryOrderRepository) CreateOrder(o domain.Order) (domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastID++
+	o.ID = r.lastID
+	r.orders[o.ID] = copyOrder(o)
+	return o, nil
+}
+
+func (r *MemoryOrderRepository) GetOrder(id int64) (domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	o, ok := r.orders[id]
+	if !ok {
+		return domain.Order{}, domain.ErrOrderNotFound
+	}
+	return copyOrder(o), nil
+}
+
+func (r *MemoryOrderRepository) UpdateOrder(o domain.Order) (domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.orders[o.ID]; !ok {
+		return domain.Order{}, domain.ErrOrderNotFound
+	}
+	r.orders[o.ID] = copyOrder(o)
+	return o, nil
+}
+
+func (r *MemoryOrderRepository) ListOrdersByUser(userID int64) ([]domain.Order, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var orders []domain.Order
+	for id := int64(1); id <= r.lastID; id++ {
+		if o, ok := r.orders[id]; ok && o.UserID == userID {
+			orders = append(orders, copyOrder(o))
+		}
+	}
+	return orders, nil
+}
+
+// copyOrder keeps callers from sharing a stored order's product lines.
+func copyOrder(o domain.Order) domain.Order {
+	o.Products = append([]domain.OrderedProduct(nil), o.Products...)
+	return o
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
+	"testing"
+)
+
+func TestMemoryOrderRepository(t *testing.T) {
+	testOrderRepository(t, func(t *testing.T) domain.OrderRepository {
+		return NewMemoryOrderRepository()
+	})
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+    "context"
     "database/sql"
-    "errors"
     "github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
+    "github.com/KaminurOrynbek/e-commerce_microservices/pkg/money"
+    "github.com/KaminurOrynbek/e-commerce_microservices/pkg/outbox"
 )
 
 type PgOrderRepository struct {
@@ -15,42 +17,52 @@ func NewPgOrderRepository(db *sql.DB) *PgOrderRepository {
 }
 
 func (r *PgOrderRepository) CreateOrder(o domain.Order) (domain.Order, error) {
+    ctx := context.Background()
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return domain.Order{}, err
+    }
+    defer tx.Rollback()
+
     query := `
-        INSERT INTO orders (user_id, total_amount, status, delivery_address)
-        VALUES ($1, $2, $3, $4) RETURNING id
+        INSERT INTO orders (user_id, total_amount, currency, status, delivery_address)
+        VALUES ($1, $2, $3, $4, $5) RETURNING id
     `
-    err := r.db.QueryRow(query, o.UserID, o.TotalAmount, o.Status, o.DeliveryAddr).Scan(&o.ID)
+    err = tx.QueryRowContext(ctx, query, o.UserID, o.TotalAmount.Decimal(), string(o.TotalAmount.Currency()), o.Status, o.DeliveryAddr).Scan(&o.ID)
     if err != nil {
         return domain.Order{}, err
     }
 
-    // Insert products into a separate table
-    for _, product := range o.Products {
-        productQuery := `
-            INSERT INTO order_products (order_id, product_id, quantity)
-            VALUES ($1, $2, $3)
-        `
-        _, err := r.db.Exec(productQuery, o.ID, product.ProductID, product.Quantity)
-        if err != nil {
-            return domain.Order{}, err
-        }
+    if err := insertOrderProducts(ctx, tx, o); err != nil {
+        return domain.Order{}, err
+    }
+    if err := writeOrderEvent(ctx, tx, domain.EventOrderCreated, o.ID, domain.NewOrderSnapshot(o)); err != nil {
+        return domain.Order{}, err
     }
 
+    if err := tx.Commit(); err != nil {
+        return domain.Order{}, err
+    }
     return o, nil
 }
 
 func (r *PgOrderRepository) GetOrder(id int64) (domain.Order, error) {
     query := `
-        SELECT id, user_id, total_amount, status, delivery_address
+        SELECT id, user_id, total_amount, currency, status, delivery_address
         FROM orders WHERE id = $1
     `
     var o domain.Order
-    err := r.db.QueryRow(query, id).Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, &o.DeliveryAddr)
+    var total, currency string
+    err := r.db.QueryRow(query, id).Scan(&o.ID, &o.UserID, &total, &currency, &o.Status, &o.DeliveryAddr)
     if err == sql.ErrNoRows {
-        return domain.Order{}, errors.New("order not found")
+        return domain.Order{}, domain.ErrOrderNotFound
     } else if err != nil {
         return domain.Order{}, err
     }
+    // NUMERIC is read as text so that the amount never passes through a float.
+    if o.TotalAmount, err = money.Parse(total, money.Currency(currency)); err != nil {
+        return domain.Order{}, err
+    }
 
     // Fetch products for the order
     productQuery := `
@@ -77,46 +89,63 @@ func (r *PgOrderRepository) GetOrder(id int64) (domain.Order, error) {
 }
 
 func (r *PgOrderRepository) UpdateOrder(o domain.Order) (domain.Order, error) {
+    ctx := context.Background()
+    tx, err := r.db.BeginTx(ctx, nil)
+    if err != nil {
+        return domain.Order{}, err
+    }
+    defer tx.Rollback()
+
+    // The row lock keeps the order's events in the order of their commits.
+    var previousStatus string
+    err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, o.ID).Scan(&previousStatus)
+    if err == sql.ErrNoRows {
+        return domain.Order{}, domain.ErrOrderNotFound
+    } else if err != nil {
+        return domain.Order{}, err
+    }
+
     query := `
         UPDATE orders
-        SET user_id = $1, total_amount = $2, status = $3, delivery_address = $4
-        WHERE id = $5
+        SET user_id = $1, total_amount = $2, currency = $3, status = $4, delivery_address = $5
+        WHERE id = $6
     `
-    result, err := r.db.Exec(query, o.UserID, o.TotalAmount, o.Status, o.DeliveryAddr, o.ID)
+    _, err = tx.ExecContext(ctx, query, o.UserID, o.TotalAmount.Decimal(), string(o.TotalAmount.Currency()), o.Status, o.DeliveryAddr, o.ID)
     if err != nil {
         return domain.Order{}, err
     }
 
-    rowsAffected, err := result.RowsAffected()
-    if err != nil || rowsAffected == 0 {
-        return domain.Order{}, errors.New("order not found")
-    }
-
     // Update products: delete existing and re-insert
     deleteQuery := `DELETE FROM order_products WHERE order_id = $1`
-    _, err = r.db.Exec(deleteQuery, o.ID)
+    _, err = tx.ExecContext(ctx, deleteQuery, o.ID)
     if err != nil {
         return domain.Order{}, err
     }
+    if err := insertOrderProducts(ctx, tx, o); err != nil {
+        return domain.Order{}, err
+    }
 
-    for _, product := range o.Products {
-        productQuery := `
-            INSERT INTO order_products (order_id, product_id, quantity)
-            VALUES ($1, $2, $3)
-        `
-        _, err := r.db.Exec(productQuery, o.ID, product.ProductID, product.Quantity)
-        if err != nil {
+    if err := writeOrderEvent(ctx, tx, domain.EventOrderUpdated, o.ID, domain.NewOrderSnapshot(o)); err != nil {
+        return domain.Order{}, err
+    }
+    if o.Status != previousStatus {
+        change := &domain.OrderStatusChanged{ID: o.ID, UserID: o.UserID, From: previousStatus, To: o.Status}
+        if err := writeOrderEvent(ctx, tx, domain.EventOrderStatusChanged, o.ID, change); err != nil {
             return domain.Order{}, err
         }
     }
 
+    if err := tx.Commit(); err != nil {
+        return domain.Order{}, err
+    }
     return o, nil
 }
 
 func (r *PgOrderRepository) ListOrdersByUser(userID int64) ([]domain.Order, error) {
     query := `
-        SELECT id, user_id, total_amount, status, delivery_address
+        SELECT id, user_id, total_amount, currency, status, delivery_address
         FROM orders WHERE user_id = $1
+        ORDER BY id
     `
     rows, err := r.db.Query(query, userID)
     if err != nil {
@@ -127,10 +156,14 @@ func (r *PgOrderRepository) ListOrdersByUser(userID int64) ([]domain.Order, erro
     var orders []domain.Order
     for rows.Next() {
         var o domain.Order
-        err := rows.Scan(&o.ID, &o.UserID, &o.TotalAmount, &o.Status, &o.DeliveryAddr)
+        var total, currency string
+        err := rows.Scan(&o.ID, &o.UserID, &total, &currency, &o.Status, &o.DeliveryAddr)
         if err != nil {
             return nil, err
         }
+        if o.TotalAmount, err = money.Parse(total, money.Currency(currency)); err != nil {
+            return nil, err
+        }
 
         // Fetch products for each order
         productQuery := `
@@ -157,4 +190,28 @@ func (r *PgOrderRepository) ListOrdersByUser(userID int64) ([]domain.Order, erro
     }
 
     return orders, nil
-}
\ No newline at end of file
+}
+
+func insertOrderProducts(ctx context.Context, tx *sql.Tx, o domain.Order) error {
+    for _, product := range o.Products {
+        productQuery := `
+            INSERT INTO order_products (order_id, product_id, quantity)
+            VALUES ($1, $2, $3)
+        `
+        _, err := tx.ExecContext(ctx, productQuery, o.ID, product.ProductID, product.Quantity)
+        if err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// writeOrderEvent adds an order event to the outbox inside tx, so that it is
+// published if and only if the change commits.
+func writeOrderEvent(ctx context.Context, tx *sql.Tx, eventType string, orderID int64, payload interface{}) error {
+    event, err := domain.NewOrderEvent(eventType, orderID, payload)
+    if err != nil {
+        return err
+    }
+    return outbox.Write(ctx, tx, event)
+}
@@ -0,0 +1,36 @@
+//go:build integration
+
+package repository
+
+import (
+	"database/sql"
+	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
+	_ "github.com/lib/pq"
+	"os"
+	"testing"
+)
+
+// TestPgOrderRepository runs against the database ORDER_TEST_DATABASE_URL
+// points at, which must have the migrations applied. Every case empties the
+// orders table first.
+func TestPgOrderRepository(t *testing.T) {
+	dsn := os.Getenv("ORDER_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("ORDER_TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatal(err)
+	}
+
+	testOrderRepository(t, func(t *testing.T) domain.OrderRepository {
+		if _, err := db.Exec(`TRUNCATE orders, outbox_events RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatal(err)
+		}
+		return NewPgOrderRepository(db)
+	})
+}
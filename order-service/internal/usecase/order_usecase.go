@@ -2,7 +2,6 @@ package usecase
 
 import (
 	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/domain"
-	"github.com/KaminurOrynbek/e-commerce_microservices/order-service/internal/repository"
 )
 
 // OrderUseCase defines the methods available for order business logic.
@@ -14,15 +13,18 @@ type OrderUseCase interface {
 }
 
 type orderUseCase struct {
-	repo *repository.PgOrderRepository
+	repo domain.OrderRepository
 }
 
 // NewOrderUseCase creates a new instance of the order use case.
-func NewOrderUseCase(repo *repository.PgOrderRepository) OrderUseCase {
+func NewOrderUseCase(repo domain.OrderRepository) OrderUseCase {
 	return &orderUseCase{repo: repo}
 }
 
 func (u *orderUseCase) CreateOrder(o domain.Order) (domain.Order, error) {
+	if err := o.Validate(); err != nil {
+		return domain.Order{}, err
+	}
 	return u.repo.CreateOrder(o)
 }
 
@@ -31,6 +33,9 @@ func (u *orderUseCase) GetOrder(id int64) (domain.Order, error) {
 }
 
 func (u *orderUseCase) UpdateOrder(o domain.Order) (domain.Order, error) {
+	if err := o.Validate(); err != nil {
+		return domain.Order{}, err
+	}
 	return u.repo.UpdateOrder(o)
 }
 
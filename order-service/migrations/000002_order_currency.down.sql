@@ -0,0 +1,2 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS currency;
+ALTER TABLE orders ALTER COLUMN total_amount TYPE NUMERIC(10, 2);
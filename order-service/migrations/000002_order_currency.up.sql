@@ -0,0 +1,2 @@
+ALTER TABLE orders ALTER COLUMN total_amount TYPE NUMERIC(15, 3);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
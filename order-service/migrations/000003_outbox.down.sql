@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS outbox_dead_letters;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS order_products;
+UPDATE orders SET products = '[]' WHERE products IS NULL;
+ALTER TABLE orders ALTER COLUMN products SET NOT NULL;
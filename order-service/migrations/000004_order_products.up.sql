@@ -0,0 +1,11 @@
+-- Order lines live in their own table, which the repository has always
+-- written to; the JSONB column it never filled stops being required.
+CREATE TABLE IF NOT EXISTS order_products (
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL,
+    quantity INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_products_order_id ON order_products (order_id);
+
+ALTER TABLE orders ALTER COLUMN products DROP NOT NULL;
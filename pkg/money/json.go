@@ -0,0 +1,142 @@
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Format selects how amounts are written to JSON.
+type Format int
+
+const (
+	// FormatDecimal writes {"amount": "19.99", "currency": "USD"}. The amount
+	// is a string so that clients do not parse it into a float.
+	FormatDecimal Format = iota
+	// FormatMinorUnits writes {"amount_minor": 1999, "currency": "USD"}.
+	FormatMinorUnits
+)
+
+// ParseFormat reads "decimal" or "minor".
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "decimal":
+		return FormatDecimal, nil
+	case "minor":
+		return FormatMinorUnits, nil
+	}
+	return 0, fmt.Errorf("unknown money format %q, expected decimal or minor", s)
+}
+
+// The JSON settings are process-wide and meant to be set once at startup,
+// before any request is served.
+var (
+	jsonFormat      = FormatDecimal
+	defaultCurrency = Currency("USD")
+)
+
+func SetJSONFormat(format Format) {
+	jsonFormat = format
+}
+
+// SetDefaultCurrency sets the currency assumed for JSON input that gives a
+// bare amount.
+func SetDefaultCurrency(currency Currency) error {
+	if !currency.Valid() {
+		return ErrInvalidCurrency
+	}
+	defaultCurrency = currency
+	return nil
+}
+
+func DefaultCurrency() Currency {
+	return defaultCurrency
+}
+
+type decimalJSON struct {
+	Amount   string   `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+type minorJSON struct {
+	AmountMinor int64    `json:"amount_minor"`
+	Currency    Currency `json:"currency"`
+}
+
+// MarshalJSON writes the amount in the configured Format. An unset amount
+// is written as null.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if !m.IsSet() {
+		return []byte("null"), nil
+	}
+	if jsonFormat == FormatMinorUnits {
+		return json.Marshal(minorJSON{AmountMinor: m.minor, Currency: m.currency})
+	}
+	return json.Marshal(decimalJSON{Amount: m.Decimal(), Currency: m.currency})
+}
+
+// UnmarshalJSON accepts either output format, and also a bare decimal string
+// or number in the default currency. Numbers are read from their literal
+// text, never through a float, and must be exact in the currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*m = Money{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '{' {
+		var raw struct {
+			Amount      json.RawMessage `json:"amount"`
+			AmountMinor *json.Number    `json:"amount_minor"`
+			Currency    string          `json:"currency"`
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		currency := defaultCurrency
+		if raw.Currency != "" {
+			var err error
+			if currency, err = ParseCurrency(raw.Currency); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case raw.Amount != nil && raw.AmountMinor != nil:
+			return errors.New("money takes either amount or amount_minor, not both")
+		case raw.AmountMinor != nil:
+			minor, err := raw.AmountMinor.Int64()
+			if err != nil {
+				return ErrInvalidAmount
+			}
+			*m = Money{minor: minor, currency: currency}
+			return nil
+		case raw.Amount != nil:
+			return m.unmarshalAmount(raw.Amount, currency)
+		}
+		return errors.New("money needs an amount or amount_minor")
+	}
+
+	return m.unmarshalAmount(data, defaultCurrency)
+}
+
+func (m *Money) unmarshalAmount(data []byte, currency Currency) error {
+	literal := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &literal); err != nil {
+			return err
+		}
+	}
+	parsed, err := Parse(literal, currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
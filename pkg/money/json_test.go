@@ -0,0 +1,119 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// withJSONSettings switches the process-wide JSON settings for one test.
+func withJSONSettings(t *testing.T, format Format, currency Currency) {
+	t.Helper()
+	previousFormat, previousCurrency := jsonFormat, defaultCurrency
+	t.Cleanup(func() { jsonFormat, defaultCurrency = previousFormat, previousCurrency })
+	SetJSONFormat(format)
+	if err := SetDefaultCurrency(currency); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{"": FormatDecimal, "decimal": FormatDecimal, " MINOR ": FormatMinorUnits} {
+		got, err := ParseFormat(input)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q): got %d, %v", input, got, err)
+		}
+	}
+	if _, err := ParseFormat("float"); err == nil {
+		t.Error("ParseFormat accepted an unknown format")
+	}
+	if err := SetDefaultCurrency("usd"); err == nil {
+		t.Error("SetDefaultCurrency accepted an invalid currency")
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	price := mustParse(t, "19.90", "EUR")
+	tests := []struct {
+		format Format
+		value  interface{}
+		want   string
+	}{
+		{FormatDecimal, price, `{"amount":"19.90","currency":"EUR"}`},
+		{FormatMinorUnits, price, `{"amount_minor":1990,"currency":"EUR"}`},
+		{FormatDecimal, struct{ Price Money }{}, `{"Price":null}`},
+	}
+	for _, tt := range tests {
+		withJSONSettings(t, tt.format, "USD")
+		got, err := json.Marshal(tt.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("format %d: got %s, want %s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	withJSONSettings(t, FormatDecimal, "GBP")
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{`{"amount":"19.90","currency":"EUR"}`, "19.90 EUR", false},
+		{`{"amount":19.9,"currency":"eur"}`, "19.90 EUR", false},
+		{`{"amount_minor":1990,"currency":"EUR"}`, "19.90 EUR", false},
+		{`{"amount":"5"}`, "5.00 GBP", false},
+		{`"7.25"`, "7.25 GBP", false},
+		{`7.25`, "7.25 GBP", false},
+		{`1500`, "1500.00 GBP", false},
+		{`null`, "", false},
+		// A float literal must not be rounded on its way in.
+		{`0.1000000000000000055511151231257827`, "", true},
+		{`19.999`, "", true},
+		{`{"amount":"1.00","amount_minor":100}`, "", true},
+		{`{"currency":"EUR"}`, "", true},
+		{`{"amount":"1.00","currency":"EURO"}`, "", true},
+		{`{"amount_minor":1.5}`, "", true},
+		{`"abc"`, "", true},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.input), &m)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: got error %v", tt.input, err)
+			continue
+		}
+		if err == nil && m.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.input, m.String(), tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	amounts := []Money{
+		mustParse(t, "19.99", "USD"),
+		mustParse(t, "-0.01", "EUR"),
+		mustParse(t, "1500", "JPY"),
+		mustParse(t, "1.234", "KWD"),
+		mustParse(t, "92233720368547758.07", "USD"),
+	}
+	for _, format := range []Format{FormatDecimal, FormatMinorUnits} {
+		withJSONSettings(t, format, "USD")
+		for _, want := range amounts {
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got Money
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("format %d: %s: %v", format, data, err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("format %d: got %v back from %s, want %v", format, got, data, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,281 @@
+// Package money represents monetary amounts as integer minor units of an ISO
+// 4217 currency, so that prices and totals add up without float rounding.
+//
+// Amounts are exact by default: parsing "19.999" as USD fails rather than
+// silently rounding. Operations that cannot stay exact, such as applying an
+// exchange rate or a percentage, take an explicit RoundingMode.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidCurrency  = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrInvalidAmount    = errors.New("amount must be a decimal number")
+	ErrPrecision        = errors.New("amount has more decimal places than its currency allows")
+	ErrOverflow         = errors.New("amount out of range")
+)
+
+// Currency is an upper-case ISO 4217 code such as "USD".
+type Currency string
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+func (c Currency) Valid() bool {
+	return currencyPattern.MatchString(string(c))
+}
+
+// exponents lists the currencies whose minor unit is not a hundredth.
+var exponents = map[Currency]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// Exponent returns the number of decimal places of the currency's minor unit.
+func (c Currency) Exponent() int {
+	if exponent, ok := exponents[c]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// ParseCurrency normalises a code to upper case and validates it.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", ErrInvalidCurrency
+	}
+	return c, nil
+}
+
+// RoundingMode decides what happens to a fraction of a minor unit.
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest minor unit and ties to the even one,
+	// which keeps sums of many rounded amounts unbiased.
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest minor unit and ties away from zero.
+	HalfUp
+	// Down drops the fraction, rounding towards zero.
+	Down
+)
+
+// Money is an amount in minor units of a currency. The zero value has no
+// currency and is only useful as "not set".
+type Money struct {
+	minor    int64
+	currency Currency
+}
+
+// New builds an amount from minor units, e.g. New(1999, "USD") is 19.99 USD.
+func New(minor int64, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, ErrInvalidCurrency
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// Zero returns a zero amount in the currency.
+func Zero(currency Currency) (Money, error) {
+	return New(0, currency)
+}
+
+var decimalPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)$`)
+
+// Parse reads a decimal amount such as "19.99". Extra decimal places are
+// accepted only when they are zeros, so "19.990" parses and "19.999" does
+// not.
+func Parse(amount string, currency Currency) (Money, error) {
+	r, err := parseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return fromRat(r, currency, nil)
+}
+
+// ParseRounded reads a decimal amount and rounds it to the currency's minor
+// unit.
+func ParseRounded(amount string, currency Currency, mode RoundingMode) (Money, error) {
+	r, err := parseDecimal(amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return fromRat(r, currency, &mode)
+}
+
+func parseDecimal(amount string) (*big.Rat, error) {
+	amount = strings.TrimSpace(amount)
+	if !decimalPattern.MatchString(amount) {
+		return nil, ErrInvalidAmount
+	}
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, ErrInvalidAmount
+	}
+	return r, nil
+}
+
+// fromRat scales a major-unit value to minor units. A nil mode requires the
+// result to be exact.
+func fromRat(r *big.Rat, currency Currency, mode *RoundingMode) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, ErrInvalidCurrency
+	}
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(currency.Exponent())))
+	if mode == nil && !scaled.IsInt() {
+		return Money{}, ErrPrecision
+	}
+	rounding := HalfEven
+	if mode != nil {
+		rounding = *mode
+	}
+	minor := round(scaled, rounding)
+	if !minor.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: minor.Int64(), currency: currency}, nil
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// round converts a rational number of minor units to an integer.
+func round(r *big.Rat, mode RoundingMode) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if remainder.Sign() == 0 || mode == Down {
+		return quotient
+	}
+
+	// Compare twice the remainder with the denominator to find which side
+	// of the half the fraction lies on.
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	away := false
+	switch twice.Cmp(r.Denom()) {
+	case 1:
+		away = true
+	case 0:
+		away = mode == HalfUp || quotient.Bit(0) == 1
+	}
+	if away {
+		quotient.Add(quotient, big.NewInt(int64(r.Sign())))
+	}
+	return quotient
+}
+
+// Minor returns the amount in minor units.
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// IsSet reports whether the amount carries a currency, i.e. is not the zero
+// value.
+func (m Money) IsSet() bool {
+	return m.currency != ""
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Rat returns the amount in major units as an exact rational number.
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(m.minor), pow10(m.currency.Exponent()))
+}
+
+// Decimal formats the amount in major units with exactly as many decimal
+// places as the currency has, e.g. "19.90".
+func (m Money) Decimal() string {
+	return m.Rat().FloatString(m.currency.Exponent())
+}
+
+// String formats the amount with its currency, e.g. "19.90 USD".
+func (m Money) String() string {
+	if !m.IsSet() {
+		return ""
+	}
+	return m.Decimal() + " " + string(m.currency)
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if other.minor > 0 && m.minor > math.MaxInt64-other.minor ||
+		other.minor < 0 && m.minor < math.MinInt64-other.minor {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: m.minor + other.minor, currency: m.currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{minor: -other.minor, currency: other.currency})
+}
+
+// Mul multiplies by a whole quantity, as for a line total.
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{minor: product.Int64(), currency: m.currency}, nil
+}
+
+// MulRat multiplies by a fractional factor, such as 0.8 for a 20% discount,
+// rounding the result to a minor unit.
+func (m Money) MulRat(factor *big.Rat, mode RoundingMode) (Money, error) {
+	return fromRat(new(big.Rat).Mul(m.Rat(), factor), m.currency, &mode)
+}
+
+// Convert applies an exchange rate, given in units of the target currency per
+// unit of m's currency, and rounds to the target's minor unit.
+func (m Money) Convert(rate *big.Rat, to Currency, mode RoundingMode) (Money, error) {
+	return fromRat(new(big.Rat).Mul(m.Rat(), rate), to, &mode)
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equal reports whether both amount and currency match.
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
@@ -0,0 +1,219 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func mustParse(t *testing.T, amount string, currency Currency) Money {
+	t.Helper()
+	m, err := Parse(amount, currency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestParseCurrency(t *testing.T) {
+	tests := []struct {
+		code string
+		want Currency
+		err  error
+	}{
+		{"USD", "USD", nil},
+		{" eur ", "EUR", nil},
+		{"US", "", ErrInvalidCurrency},
+		{"US1", "", ErrInvalidCurrency},
+		{"", "", ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := ParseCurrency(tt.code)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseCurrency(%q): got %q, %v, want %q, %v", tt.code, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency Currency
+		minor    int64
+		decimal  string
+		err      error
+	}{
+		{"19.99", "USD", 1999, "19.99", nil},
+		{"19.9", "USD", 1990, "19.90", nil},
+		{"19", "USD", 1900, "19.00", nil},
+		{" 19.990 ", "USD", 1999, "19.99", nil},
+		{".5", "USD", 50, "0.50", nil},
+		{"5.", "USD", 500, "5.00", nil},
+		{"-3.25", "USD", -325, "-3.25", nil},
+		{"+3.25", "USD", 325, "3.25", nil},
+		{"1500", "JPY", 1500, "1500", nil},
+		{"1.234", "KWD", 1234, "1.234", nil},
+		{"19.999", "USD", 0, "", ErrPrecision},
+		{"1.5", "JPY", 0, "", ErrPrecision},
+		{"1.2345", "KWD", 0, "", ErrPrecision},
+		{"1e3", "USD", 0, "", ErrInvalidAmount},
+		{"12,50", "USD", 0, "", ErrInvalidAmount},
+		{"abc", "USD", 0, "", ErrInvalidAmount},
+		{"", "USD", 0, "", ErrInvalidAmount},
+		{"1.00", "usd", 0, "", ErrInvalidCurrency},
+		{"92233720368547758.07", "USD", math.MaxInt64, "92233720368547758.07", nil},
+		{"92233720368547758.08", "USD", 0, "", ErrOverflow},
+		{"-92233720368547758.09", "USD", 0, "", ErrOverflow},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q, %s): got error %v, want %v", tt.amount, tt.currency, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got.Minor() != tt.minor || got.Decimal() != tt.decimal || got.Currency() != tt.currency {
+			t.Errorf("Parse(%q, %s): got %d minor, %q", tt.amount, tt.currency, got.Minor(), got.Decimal())
+		}
+	}
+}
+
+func TestParseRounded(t *testing.T) {
+	tests := []struct {
+		amount string
+		mode   RoundingMode
+		want   int64
+	}{
+		{"1.005", HalfEven, 100},
+		{"1.015", HalfEven, 102},
+		{"1.0051", HalfEven, 101},
+		{"1.005", HalfUp, 101},
+		{"1.004", HalfUp, 100},
+		{"-1.005", HalfUp, -101},
+		{"-1.005", HalfEven, -100},
+		{"-1.015", HalfEven, -102},
+		{"1.009", Down, 100},
+		{"-1.009", Down, -100},
+		{"1.00", Down, 100},
+	}
+	for _, tt := range tests {
+		got, err := ParseRounded(tt.amount, "USD", tt.mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Minor() != tt.want {
+			t.Errorf("ParseRounded(%q, %d): got %d, want %d", tt.amount, tt.mode, got.Minor(), tt.want)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := mustParse(t, "10.50", "USD"), mustParse(t, "0.75", "USD")
+
+	sum, err := a.Add(b)
+	if err != nil || sum.Decimal() != "11.25" {
+		t.Fatalf("Add: got %v, %v", sum, err)
+	}
+	difference, err := b.Sub(a)
+	if err != nil || difference.Decimal() != "-9.75" || !difference.IsNegative() {
+		t.Fatalf("Sub: got %v, %v", difference, err)
+	}
+	total, err := b.Mul(3)
+	if err != nil || total.Decimal() != "2.25" {
+		t.Fatalf("Mul: got %v, %v", total, err)
+	}
+	if cmp, err := a.Cmp(b); err != nil || cmp != 1 {
+		t.Fatalf("Cmp: got %d, %v", cmp, err)
+	}
+	if cmp, err := a.Cmp(a); err != nil || cmp != 0 {
+		t.Fatalf("Cmp: got %d, %v", cmp, err)
+	}
+
+	euros := mustParse(t, "1.00", "EUR")
+	if _, err := a.Add(euros); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Add across currencies: got %v", err)
+	}
+	if _, err := a.Cmp(euros); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Fatalf("Cmp across currencies: got %v", err)
+	}
+	if a.Equal(mustParse(t, "10.50", "EUR")) || !a.Equal(mustParse(t, "10.5", "USD")) {
+		t.Fatal("Equal must compare both amount and currency")
+	}
+}
+
+func TestOverflow(t *testing.T) {
+	max, _ := New(math.MaxInt64, "USD")
+	min, _ := New(math.MinInt64, "USD")
+	one, _ := New(1, "USD")
+
+	if _, err := max.Add(one); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Add: got %v", err)
+	}
+	if _, err := min.Sub(one); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sub: got %v", err)
+	}
+	if _, err := one.Sub(min); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Sub of the minimum: got %v", err)
+	}
+	if _, err := max.Mul(2); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Mul: got %v", err)
+	}
+	if _, err := max.MulRat(big.NewRat(3, 2), HalfEven); !errors.Is(err, ErrOverflow) {
+		t.Errorf("MulRat: got %v", err)
+	}
+}
+
+func TestMulRatAndConvert(t *testing.T) {
+	price := mustParse(t, "19.99", "USD")
+
+	tests := []struct {
+		mode RoundingMode
+		want string
+	}{
+		// 19.99 * 0.85 = 16.9915
+		{HalfEven, "16.99"},
+		{HalfUp, "16.99"},
+		{Down, "16.99"},
+	}
+	for _, tt := range tests {
+		got, err := price.MulRat(big.NewRat(85, 100), tt.mode)
+		if err != nil || got.Decimal() != tt.want {
+			t.Errorf("MulRat mode %d: got %v, %v, want %s", tt.mode, got, err, tt.want)
+		}
+	}
+
+	// 0.25 * 0.5 = 0.125 sits exactly on a half cent.
+	quarter := mustParse(t, "0.25", "USD")
+	for mode, want := range map[RoundingMode]string{HalfEven: "0.12", HalfUp: "0.13", Down: "0.12"} {
+		got, err := quarter.MulRat(big.NewRat(1, 2), mode)
+		if err != nil || got.Decimal() != want {
+			t.Errorf("MulRat tie mode %d: got %v, %v, want %s", mode, got, err, want)
+		}
+	}
+
+	// To a currency with no minor unit: 19.99 * 151.5 = 3028.485 JPY.
+	yen, err := price.Convert(big.NewRat(3030, 20), "JPY", HalfEven)
+	if err != nil || yen.Decimal() != "3028" || yen.Currency() != "JPY" {
+		t.Fatalf("Convert: got %v, %v", yen, err)
+	}
+	if _, err := price.Convert(big.NewRat(1, 1), "yen", HalfEven); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("Convert to an invalid currency: got %v", err)
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var m Money
+	if m.IsSet() || m.String() != "" {
+		t.Fatalf("the zero value is set: %q", m.String())
+	}
+	zero, err := Zero("EUR")
+	if err != nil || !zero.IsSet() || !zero.IsZero() || zero.String() != "0.00 EUR" {
+		t.Fatalf("Zero: got %q, %v", zero.String(), err)
+	}
+	if _, err := New(1, "eur"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("New with an invalid currency: got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package outbox implements the transactional outbox: services write domain
+// events into an outbox table in the same transaction as the change they
+// describe, and a Relay later hands them to a Publisher.
+//
+// Delivery is at least once. An event is removed only after the publisher
+// accepted it, so a crash in between publishes it again; consumers
+// de-duplicate on Event.ID. Events of one aggregate are published in the
+// order they were written, provided the writer holds a lock on the aggregate
+// (such as its row) until it commits.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidEvent = errors.New("event needs an aggregate type, aggregate ID and event type")
+
+// Event is a domain event as stored in the outbox and handed to publishers.
+type Event struct {
+	ID            int64
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       json.RawMessage
+	OccurredAt    time.Time
+	// Attempts counts failed deliveries before this one.
+	Attempts int
+}
+
+// NewEvent marshals payload into an event of aggregate aggregateType/aggregateID.
+func NewEvent(aggregateType, aggregateID, eventType string, payload interface{}) (Event, error) {
+	if strings.TrimSpace(aggregateType) == "" || strings.TrimSpace(aggregateID) == "" || strings.TrimSpace(eventType) == "" {
+		return Event{}, ErrInvalidEvent
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Type:          eventType,
+		Payload:       data,
+		OccurredAt:    time.Now().UTC(),
+	}, nil
+}
+
+// Execer is satisfied by *sql.Tx. Passing the transaction that makes the
+// change is what makes the outbox transactional.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Write appends events to the outbox inside tx.
+func Write(ctx context.Context, tx Execer, events ...Event) error {
+	query := `
+		INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload, occurred_at, available_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())`
+	for _, e := range events {
+		if e.AggregateType == "" || e.AggregateID == "" || e.Type == "" {
+			return ErrInvalidEvent
+		}
+		if _, err := tx.ExecContext(ctx, query, e.AggregateType, e.AggregateID, e.Type, []byte(e.Payload), e.OccurredAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publisher delivers events to wherever consumers read them. A nil error
+// means the event was accepted and may be removed from the outbox.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
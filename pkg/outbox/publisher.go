@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// LogPublisher writes events to the standard logger. It suits development
+// and deployments where nothing consumes the events yet.
+type LogPublisher struct{}
+
+func NewLogPublisher() *LogPublisher {
+	return &LogPublisher{}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event Event) error {
+	log.Printf("outbox event %d %s %s/%s: %s", event.ID, event.Type, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory, for tests. Fail, when
+// set, decides whether a delivery is rejected.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+	Fail   func(Event) error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Fail != nil {
+		if err := p.Fail(event); err != nil {
+			return err
+		}
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the events published so far, in delivery order.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	events := make([]Event, len(p.events))
+	copy(events, p.events)
+	return events
+}
+
+// Reset forgets the published events.
+func (p *MemoryPublisher) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = nil
+}
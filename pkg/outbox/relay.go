@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// RelayOptions tune a Relay. Zero values take the defaults below.
+type RelayOptions struct {
+	// Interval is how long the relay sleeps once the outbox is drained.
+	Interval time.Duration
+	// BatchSize caps the events claimed per transaction.
+	BatchSize int
+	// MaxAttempts is how many failed deliveries move an event to the
+	// dead-letter table.
+	MaxAttempts int
+	// RetryDelay is the wait after the first failure; it doubles with every
+	// further failure up to MaxRetryDelay.
+	RetryDelay time.Duration
+}
+
+const (
+	DefaultInterval    = time.Second
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 10
+	DefaultRetryDelay  = time.Second
+	MaxRetryDelay      = time.Hour
+)
+
+// Relay moves events from the outbox to a Publisher. Several relays may run
+// against the same database: each claims different aggregates.
+type Relay struct {
+	store     store
+	publisher Publisher
+	options   RelayOptions
+}
+
+func NewRelay(db *sql.DB, publisher Publisher, options RelayOptions) *Relay {
+	return newRelay(sqlStore{db: db}, publisher, options)
+}
+
+func newRelay(store store, publisher Publisher, options RelayOptions) *Relay {
+	if options.Interval <= 0 {
+		options.Interval = DefaultInterval
+	}
+	if options.BatchSize <= 0 {
+		options.BatchSize = DefaultBatchSize
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = DefaultMaxAttempts
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = DefaultRetryDelay
+	}
+	return &Relay{store: store, publisher: publisher, options: options}
+}
+
+// Run relays events until ctx is done. Full batches are followed by the next
+// one straight away; otherwise the relay waits one interval.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		claimed, err := r.RelayOnce(ctx)
+		if err != nil {
+			log.Printf("outbox relay failed: %v", err)
+		}
+		if err == nil && claimed == r.options.BatchSize {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce claims one batch and tries to deliver it. It returns how many
+// events were claimed, delivered or not.
+//
+// Only the oldest pending event of each aggregate is claimed, so a later
+// event never overtakes an earlier one that is waiting for a retry. Claimed
+// events stay locked until the batch commits, which keeps other relays off
+// the same aggregates.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	b, events, err := r.store.claim(ctx, r.options.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer b.rollback()
+
+	for _, e := range events {
+		if publishErr := r.publisher.Publish(ctx, e); publishErr != nil {
+			if err := r.fail(ctx, b, e, publishErr); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := b.remove(ctx, e); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), b.commit()
+}
+
+// fail schedules a retry, or moves the event to the dead-letter table once it
+// has used up its attempts. Dead-lettering unblocks the aggregate's later
+// events.
+func (r *Relay) fail(ctx context.Context, b batch, e Event, cause error) error {
+	attempts := e.Attempts + 1
+	if attempts < r.options.MaxAttempts {
+		return b.retry(ctx, e, attempts, cause, r.retryDelay(attempts))
+	}
+
+	log.Printf("outbox event %d %s %s/%s dead-lettered after %d attempts: %v", e.ID, e.Type, e.AggregateType, e.AggregateID, attempts, cause)
+	return b.deadLetter(ctx, e, attempts, cause)
+}
+
+func (r *Relay) retryDelay(attempts int) time.Duration {
+	delay := r.options.RetryDelay
+	for i := 1; i < attempts && delay < MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxRetryDelay {
+		delay = MaxRetryDelay
+	}
+	return delay
+}
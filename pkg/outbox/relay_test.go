@@ -0,0 +1,258 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+)
+
+// memoryStore keeps the outbox in memory the way the tables hold it, on a
+// clock the test moves by hand.
+type memoryStore struct {
+	now         time.Time
+	pending     []*storedEvent
+	deadLetters []Event
+}
+
+type storedEvent struct {
+	Event
+	availableAt time.Time
+	lastError   string
+}
+
+func (s *memoryStore) add(aggregateID, eventType string) {
+	e, err := NewEvent("order", aggregateID, eventType, nil)
+	if err != nil {
+		panic(err)
+	}
+	e.ID = int64(len(s.pending) + len(s.deadLetters) + 1)
+	s.pending = append(s.pending, &storedEvent{Event: e, availableAt: s.now})
+}
+
+func (s *memoryStore) find(id int64) *storedEvent {
+	for _, e := range s.pending {
+		if e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) claim(ctx context.Context, limit int) (batch, []Event, error) {
+	oldest := make(map[string]*storedEvent)
+	for _, e := range s.pending {
+		key := e.AggregateType + "/" + e.AggregateID
+		if o, ok := oldest[key]; !ok || e.ID < o.ID {
+			oldest[key] = e
+		}
+	}
+	var events []Event
+	for _, e := range oldest {
+		if !e.availableAt.After(s.now) {
+			events = append(events, e.Event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return &memoryBatch{store: s}, events, nil
+}
+
+// memoryBatch applies changes as commit is called, so that a failed batch
+// leaves the store as it was.
+type memoryBatch struct {
+	store   *memoryStore
+	changes []func()
+}
+
+func (b *memoryBatch) remove(ctx context.Context, e Event) error {
+	b.changes = append(b.changes, func() {
+		for i, p := range b.store.pending {
+			if p.ID == e.ID {
+				b.store.pending = append(b.store.pending[:i], b.store.pending[i+1:]...)
+				return
+			}
+		}
+	})
+	return nil
+}
+
+func (b *memoryBatch) retry(ctx context.Context, e Event, attempts int, cause error, delay time.Duration) error {
+	b.changes = append(b.changes, func() {
+		stored := b.store.find(e.ID)
+		stored.Attempts = attempts
+		stored.lastError = cause.Error()
+		stored.availableAt = b.store.now.Add(delay)
+	})
+	return nil
+}
+
+func (b *memoryBatch) deadLetter(ctx context.Context, e Event, attempts int, cause error) error {
+	e.Attempts = attempts
+	b.changes = append(b.changes, func() { b.store.deadLetters = append(b.store.deadLetters, e) })
+	return b.remove(ctx, e)
+}
+
+func (b *memoryBatch) commit() error {
+	for _, change := range b.changes {
+		change()
+	}
+	b.changes = nil
+	return nil
+}
+
+func (b *memoryBatch) rollback() error {
+	b.changes = nil
+	return nil
+}
+
+func eventTypes(events []Event) []string {
+	types := make([]string, len(events))
+	for i, e := range events {
+		types[i] = e.AggregateID + ":" + e.Type
+	}
+	return types
+}
+
+func expectTypes(t *testing.T, got []Event, want ...string) {
+	t.Helper()
+	types := eventTypes(got)
+	if len(types) != len(want) {
+		t.Fatalf("got %v, want %v", types, want)
+	}
+	for i := range types {
+		if types[i] != want[i] {
+			t.Fatalf("got %v, want %v", types, want)
+		}
+	}
+}
+
+func relayOnce(t *testing.T, relay *Relay, want int) {
+	t.Helper()
+	claimed, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claimed != want {
+		t.Fatalf("claimed %d events, want %d", claimed, want)
+	}
+}
+
+func TestRelayClaimsTheOldestEventPerAggregate(t *testing.T) {
+	store := &memoryStore{now: time.Now()}
+	store.add("1", "created")
+	store.add("1", "paid")
+	store.add("2", "created")
+	store.add("1", "shipped")
+	publisher := NewMemoryPublisher()
+	relay := newRelay(store, publisher, RelayOptions{})
+
+	relayOnce(t, relay, 2)
+	expectTypes(t, publisher.Events(), "1:created", "2:created")
+	relayOnce(t, relay, 1)
+	relayOnce(t, relay, 1)
+	relayOnce(t, relay, 0)
+	expectTypes(t, publisher.Events(), "1:created", "2:created", "1:paid", "1:shipped")
+}
+
+func TestRelayBatchSize(t *testing.T) {
+	store := &memoryStore{now: time.Now()}
+	for _, id := range []string{"1", "2", "3"} {
+		store.add(id, "created")
+	}
+	publisher := NewMemoryPublisher()
+	relay := newRelay(store, publisher, RelayOptions{BatchSize: 2})
+
+	relayOnce(t, relay, 2)
+	relayOnce(t, relay, 1)
+	expectTypes(t, publisher.Events(), "1:created", "2:created", "3:created")
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	store := &memoryStore{now: time.Now()}
+	store.add("1", "created")
+	store.add("1", "paid")
+	store.add("2", "created")
+	publisher := NewMemoryPublisher()
+	failing := true
+	publisher.Fail = func(e Event) error {
+		if failing && e.AggregateID == "1" {
+			return errors.New("broker unavailable")
+		}
+		return nil
+	}
+	relay := newRelay(store, publisher, RelayOptions{RetryDelay: time.Second, MaxAttempts: 5})
+
+	relayOnce(t, relay, 2)
+	expectTypes(t, publisher.Events(), "2:created")
+	failed := store.find(1)
+	if failed.Attempts != 1 || failed.lastError != "broker unavailable" || !failed.availableAt.Equal(store.now.Add(time.Second)) {
+		t.Fatalf("got attempts %d, error %q, available in %s", failed.Attempts, failed.lastError, failed.availableAt.Sub(store.now))
+	}
+
+	// The failed event is not due yet, and its aggregate's later event
+	// waits behind it.
+	relayOnce(t, relay, 0)
+
+	store.now = failed.availableAt
+	relayOnce(t, relay, 1)
+	if failed.Attempts != 2 || !failed.availableAt.Equal(store.now.Add(2*time.Second)) {
+		t.Fatalf("got attempts %d, available in %s after the second failure", failed.Attempts, failed.availableAt.Sub(store.now))
+	}
+
+	failing = false
+	store.now = failed.availableAt
+	relayOnce(t, relay, 1)
+	relayOnce(t, relay, 1)
+	expectTypes(t, publisher.Events(), "2:created", "1:created", "1:paid")
+	if len(store.pending) != 0 || len(store.deadLetters) != 0 {
+		t.Fatalf("left %d pending and %d dead letters", len(store.pending), len(store.deadLetters))
+	}
+}
+
+func TestRelayDeadLettersAfterMaxAttempts(t *testing.T) {
+	store := &memoryStore{now: time.Now()}
+	store.add("1", "created")
+	store.add("1", "paid")
+	publisher := NewMemoryPublisher()
+	publisher.Fail = func(e Event) error {
+		if e.Type == "created" {
+			return errors.New("rejected")
+		}
+		return nil
+	}
+	relay := newRelay(store, publisher, RelayOptions{RetryDelay: time.Millisecond, MaxAttempts: 3})
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		relayOnce(t, relay, 1)
+		if e := store.find(1); e != nil {
+			store.now = e.availableAt
+		}
+	}
+	if len(store.deadLetters) != 1 || store.deadLetters[0].ID != 1 || store.deadLetters[0].Attempts != 3 {
+		t.Fatalf("got dead letters %+v", store.deadLetters)
+	}
+
+	// Dead-lettering unblocks the aggregate's later events.
+	relayOnce(t, relay, 1)
+	expectTypes(t, publisher.Events(), "1:paid")
+}
+
+func TestRelayRetryDelayDoublesUpToTheCap(t *testing.T) {
+	relay := newRelay(&memoryStore{}, NewMemoryPublisher(), RelayOptions{RetryDelay: time.Second})
+	for attempts, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		12: 2048 * time.Second,
+		13: MaxRetryDelay,
+		40: MaxRetryDelay,
+	} {
+		if got := relay.retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// store is where a Relay claims events and records what became of them.
+type store interface {
+	// claim locks the oldest available event of up to limit aggregates and
+	// returns them in ID order, with the batch that settles them. An
+	// aggregate whose oldest event is waiting for a retry is skipped.
+	claim(ctx context.Context, limit int) (batch, []Event, error)
+}
+
+// batch settles claimed events. Nothing is kept unless commit succeeds.
+type batch interface {
+	remove(ctx context.Context, e Event) error
+	retry(ctx context.Context, e Event, attempts int, cause error, delay time.Duration) error
+	deadLetter(ctx context.Context, e Event, attempts int, cause error) error
+	commit() error
+	rollback() error
+}
+
+// sqlStore keeps the outbox in the outbox_events and outbox_dead_letters
+// tables. Claimed rows stay locked until the batch commits.
+type sqlStore struct {
+	db *sql.DB
+}
+
+func (s sqlStore) claim(ctx context.Context, limit int) (batch, []Event, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	query := `
+		SELECT o.id, o.aggregate_type, o.aggregate_id, o.event_type, o.payload, o.occurred_at, o.attempts
+		FROM outbox_events o
+		WHERE o.available_at <= NOW()
+		  AND NOT EXISTS (
+		      SELECT 1 FROM outbox_events e
+		      WHERE e.aggregate_type = o.aggregate_type AND e.aggregate_id = o.aggregate_id AND e.id < o.id)
+		ORDER BY o.id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	events, err := scanEvents(tx.QueryContext(ctx, query, limit))
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	return sqlBatch{tx: tx}, events, nil
+}
+
+func scanEvents(rows *sql.Rows, err error) ([]Event, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.AggregateType, &e.AggregateID, &e.Type, &payload, &e.OccurredAt, &e.Attempts); err != nil {
+			return nil, err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+type sqlBatch struct {
+	tx *sql.Tx
+}
+
+func (b sqlBatch) remove(ctx context.Context, e Event) error {
+	_, err := b.tx.ExecContext(ctx, `DELETE FROM outbox_events WHERE id = $1`, e.ID)
+	return err
+}
+
+func (b sqlBatch) retry(ctx context.Context, e Event, attempts int, cause error, delay time.Duration) error {
+	query := `UPDATE outbox_events SET attempts = $1, last_error = $2, available_at = NOW() + $3 * INTERVAL '1 millisecond' WHERE id = $4`
+	_, err := b.tx.ExecContext(ctx, query, attempts, cause.Error(), delay.Milliseconds(), e.ID)
+	return err
+}
+
+func (b sqlBatch) deadLetter(ctx context.Context, e Event, attempts int, cause error) error {
+	query := `
+		INSERT INTO outbox_dead_letters (id, aggregate_type, aggregate_id, event_type, payload, occurred_at, attempts, last_error, failed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())`
+	if _, err := b.tx.ExecContext(ctx, query, e.ID, e.AggregateType, e.AggregateID, e.Type, []byte(e.Payload), e.OccurredAt, attempts, cause.Error()); err != nil {
+		return err
+	}
+	return b.remove(ctx, e)
+}
+
+func (b sqlBatch) commit() error {
+	return b.tx.Commit()
+}
+
+func (b sqlBatch) rollback() error {
+	return b.tx.Rollback()
+}
@@ -0,0 +1,159 @@
+// Package ginproblem answers gin requests with problem details: the request
+// ID middleware, panic recovery, unknown routes and request bodies that fail
+// to bind. Importing it also makes validation errors name fields by their
+// JSON names.
+package ginproblem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const requestIDKey = "request_id"
+
+// RequestID tags each request with the caller's X-Request-ID, or a new one.
+// It echoes the ID on the response and sets it on the request, so that a
+// proxy forwards the same ID to the services behind it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := problem.RequestID(c.GetHeader(problem.RequestIDHeader))
+		c.Set(requestIDKey, id)
+		c.Request.Header.Set(problem.RequestIDHeader, id)
+		c.Header(problem.RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// RequestIDOf returns the ID RequestID gave the request.
+func RequestIDOf(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Recovery answers a panicking handler with a problem rather than an empty
+// 500.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		WriteInternal(c, http.StatusInternalServerError, fmt.Errorf("panic: %v", recovered))
+	})
+}
+
+// NoRoute answers requests for unknown paths.
+func NoRoute(c *gin.Context) {
+	Write(c, problem.New(http.StatusNotFound, "no route for "+c.Request.Method+" "+c.Request.URL.Path))
+}
+
+// Write answers with p, filling in the request it answers, and stops the
+// handler chain.
+func Write(c *gin.Context, p *problem.Problem) {
+	p.Instance = c.Request.URL.Path
+	p.RequestID = RequestIDOf(c)
+	c.Header("Content-Type", problem.ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// WriteInternal logs err and answers with status, showing err only in
+// verbose mode.
+func WriteInternal(c *gin.Context, status int, err error) {
+	log.Printf("request %s: %s %s: %v", RequestIDOf(c), c.Request.Method, c.Request.URL.Path, err)
+	p := problem.Internal(err)
+	p.Status, p.Title, p.Code = status, http.StatusText(status), problem.StatusCode(status)
+	Write(c, p)
+}
+
+// WriteBindingError answers a request whose body could not be bound: field
+// by field when it failed validation, as malformed when it is not JSON.
+func WriteBindingError(c *gin.Context, err error) {
+	var validationErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &validationErrors):
+		p := problem.New(http.StatusBadRequest, "request body failed validation").WithCode(problem.CodeValidationFailed)
+		for _, fieldErr := range validationErrors {
+			p.Errors = append(p.Errors, toFieldError(fieldErr))
+		}
+		Write(c, p)
+	case errors.As(err, &typeErr):
+		p := problem.New(http.StatusBadRequest, "request body failed validation").WithCode(problem.CodeValidationFailed)
+		p.Errors = []problem.FieldError{{Field: typeErr.Field, Code: "type", Message: "must be a " + jsonType(typeErr.Type)}}
+		Write(c, p)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		Write(c, problem.New(http.StatusBadRequest, "request body is not valid JSON").WithCode(problem.CodeMalformedBody))
+	default:
+		// Values with their own JSON parsing, such as amounts, fail with
+		// their own errors, and keep their code when they have one.
+		p := problem.New(http.StatusBadRequest, err.Error())
+		var coded interface{ Code() string }
+		if errors.As(err, &coded) && coded.Code() != "" {
+			p.Code = coded.Code()
+		}
+		Write(c, p)
+	}
+}
+
+func toFieldError(fieldErr validator.FieldError) problem.FieldError {
+	// The namespace starts with the request type, which clients never see.
+	field := fieldErr.Namespace()
+	if i := strings.IndexByte(field, '.'); i >= 0 {
+		field = field[i+1:]
+	}
+
+	var message string
+	switch fieldErr.Tag() {
+	case "required":
+		message = "is required"
+	case "gte", "min":
+		message = "must be at least " + fieldErr.Param()
+	case "lte", "max":
+		message = "must be at most " + fieldErr.Param()
+	case "gt":
+		message = "must be greater than " + fieldErr.Param()
+	case "oneof":
+		message = "must be one of: " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	default:
+		message = "failed the " + fieldErr.Tag() + " rule"
+	}
+	return problem.FieldError{Field: field, Code: fieldErr.Tag(), Message: message}
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// Validation errors name fields by their JSON names, as clients send them.
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
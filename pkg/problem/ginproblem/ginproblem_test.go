@@ -0,0 +1,135 @@
+package ginproblem
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/KaminurOrynbek/e-commerce_microservices/pkg/problem"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type itemRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Quantity int    `json:"quantity" binding:"gte=1"`
+	Kind     string `json:"kind" binding:"omitempty,oneof=book lamp"`
+}
+
+type codedError struct{}
+
+func (codedError) Error() string { return "amount has too many decimals" }
+func (codedError) Code() string  { return "invalid_amount" }
+
+func serve(t *testing.T, handler gin.HandlerFunc, method, path, body string) (*httptest.ResponseRecorder, problem.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), Recovery())
+	router.NoRoute(NoRoute)
+	router.POST("/test", handler)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(problem.RequestIDHeader, "req-1")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	if got := recorder.Header().Get("Content-Type"); !strings.HasPrefix(got, problem.ContentType) {
+		t.Fatalf("got content type %q", got)
+	}
+	var p problem.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.RequestID != "req-1" || p.Instance != path || p.Status != recorder.Code {
+		t.Fatalf("got request id %q, instance %q, status %d", p.RequestID, p.Instance, p.Status)
+	}
+	return recorder, p
+}
+
+func TestRequestIDIsEchoedAndForwarded(t *testing.T) {
+	var forwarded string
+	_, p := serve(t, func(c *gin.Context) {
+		forwarded = c.Request.Header.Get(problem.RequestIDHeader)
+		Write(c, problem.New(http.StatusConflict, "taken"))
+	}, http.MethodPost, "/test", "")
+	if forwarded != "req-1" || p.Code != "conflict" {
+		t.Fatalf("forwarded %q, got code %q", forwarded, p.Code)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/test", func(c *gin.Context) { forwarded = RequestIDOf(c) })
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if got := recorder.Header().Get(problem.RequestIDHeader); got == "" || got != forwarded {
+		t.Fatalf("answered with id %q, handler saw %q", got, forwarded)
+	}
+}
+
+func TestBindingErrorListsFields(t *testing.T) {
+	bind := func(c *gin.Context) {
+		var req itemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			WriteBindingError(c, err)
+		}
+	}
+
+	_, p := serve(t, bind, http.MethodPost, "/test", `{"quantity": 0, "kind": "chair"}`)
+	if p.Status != http.StatusBadRequest || p.Code != problem.CodeValidationFailed {
+		t.Fatalf("got status %d, code %q", p.Status, p.Code)
+	}
+	fields := map[string]string{}
+	for _, e := range p.Errors {
+		fields[e.Field] = e.Code
+	}
+	if fields["name"] != "required" || fields["quantity"] != "gte" || fields["kind"] != "oneof" {
+		t.Fatalf("got field errors %+v", p.Errors)
+	}
+
+	_, p = serve(t, bind, http.MethodPost, "/test", `{"name": "Lamp", "quantity": "many"}`)
+	if len(p.Errors) != 1 || p.Errors[0].Field != "quantity" || p.Errors[0].Message != "must be a number" {
+		t.Fatalf("got field errors %+v", p.Errors)
+	}
+
+	_, p = serve(t, bind, http.MethodPost, "/test", `{"name": `)
+	if p.Code != problem.CodeMalformedBody {
+		t.Fatalf("got code %q", p.Code)
+	}
+
+	_, p = serve(t, func(c *gin.Context) { WriteBindingError(c, codedError{}) }, http.MethodPost, "/test", "")
+	if p.Status != http.StatusBadRequest || p.Code != "invalid_amount" || p.Detail != "amount has too many decimals" {
+		t.Fatalf("got status %d, code %q, detail %q", p.Status, p.Code, p.Detail)
+	}
+}
+
+func TestInternalErrorsHideDetailUnlessVerbose(t *testing.T) {
+	defer problem.SetVerbose(problem.Verbose())
+	fail := func(c *gin.Context) {
+		WriteInternal(c, http.StatusBadGateway, errors.New("dial tcp: connection refused"))
+	}
+
+	problem.SetVerbose(false)
+	_, p := serve(t, fail, http.MethodPost, "/test", "")
+	if p.Status != http.StatusBadGateway || p.Code != "bad_gateway" || p.Detail != "" {
+		t.Fatalf("got %d %q %q", p.Status, p.Code, p.Detail)
+	}
+	_, p = serve(t, func(c *gin.Context) { panic("boom") }, http.MethodPost, "/test", "")
+	if p.Status != http.StatusInternalServerError || p.Detail != "" {
+		t.Fatalf("got %d %q for a panic", p.Status, p.Detail)
+	}
+
+	problem.SetVerbose(true)
+	if _, p = serve(t, fail, http.MethodPost, "/test", ""); p.Detail != "dial tcp: connection refused" {
+		t.Fatalf("got detail %q in verbose mode", p.Detail)
+	}
+}
+
+func TestNoRoute(t *testing.T) {
+	_, p := serve(t, nil, http.MethodGet, "/missing", "")
+	if p.Status != http.StatusNotFound || p.Detail != "no route for GET /missing" {
+		t.Fatalf("got %d %q", p.Status, p.Detail)
+	}
+}
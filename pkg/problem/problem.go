@@ -0,0 +1,168 @@
+// Package problem describes failed requests as RFC 7807 problem details, the
+// error format every service and the gateway answer with.
+//
+// Besides the standard members, a problem carries a stable code that clients
+// can switch on, the ID of the request it answers and, for invalid input, one
+// entry per offending field. The detail of unexpected errors is only shown
+// in verbose mode, since it may expose internals such as SQL.
+package problem
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// RequestIDHeader carries the ID of a request from the gateway to the
+// services, and back to the client.
+const RequestIDHeader = "X-Request-ID"
+
+// Codes used across services for problems that do not come from a domain
+// error. Other statuses get the code StatusCode returns.
+const (
+	CodeMalformedBody    = "malformed_body"
+	CodeValidationFailed = "validation_failed"
+)
+
+// FieldError is one invalid member of a request body.
+type FieldError struct {
+	// Field is the member's JSON name, dotted for nested members.
+	Field string `json:"field"`
+	// Code names the rule that failed, such as "required" or "gte".
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	// Extensions are further members specific to the problem. They cannot
+	// replace the members above.
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// New returns a problem for status. Its type is about:blank, so its title is
+// the status text, and its code is the status's generic one.
+func New(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   StatusCode(status),
+	}
+}
+
+// Internal returns the problem for an unexpected error. The error is only
+// shown in verbose mode; callers should log it.
+func Internal(err error) *Problem {
+	p := New(http.StatusInternalServerError, "")
+	if verbose && err != nil {
+		p.Detail = err.Error()
+	}
+	return p
+}
+
+// WithCode replaces the problem's code.
+func (p *Problem) WithCode(code string) *Problem {
+	p.Code = code
+	return p
+}
+
+// With adds an extension member.
+func (p *Problem) With(key string, value interface{}) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]interface{}{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type members Problem
+	data, err := json.Marshal((*members)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	merged := make(map[string]interface{}, len(p.Extensions))
+	for key, value := range p.Extensions {
+		merged[key] = value
+	}
+	var standard map[string]interface{}
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return nil, err
+	}
+	for key, value := range standard {
+		merged[key] = value
+	}
+	return json.Marshal(merged)
+}
+
+// Write sends the problem as the response.
+func (p *Problem) Write(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// StatusCode returns the generic code for a status, its text in snake case,
+// such as "not_found".
+func StatusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "unknown_error"
+	}
+	text = strings.NewReplacer("-", " ", "'", "").Replace(strings.ToLower(text))
+	return strings.Join(strings.Fields(text), "_")
+}
+
+// verbose is process-wide and meant to be set once at startup, like the
+// money package's JSON settings.
+var verbose bool
+
+// SetVerbose chooses whether problems for unexpected errors show the error.
+// Services turn it off in production.
+func SetVerbose(on bool) {
+	verbose = on
+}
+
+// Verbose reports whether internal details may be shown.
+func Verbose() bool {
+	return verbose
+}
+
+// RequestID returns the ID a caller sent in RequestIDHeader, or a new random
+// one when it sent none or one that is unfit for logs: longer than 128
+// characters or with anything but letters, digits, '-', '_' and '.'.
+func RequestID(header string) string {
+	if header != "" && len(header) <= 128 && strings.Trim(header, requestIDChars) == "" {
+		return header
+	}
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b[:])
+}
+
+const requestIDChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_."
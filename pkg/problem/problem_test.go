@@ -0,0 +1,81 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusCode(t *testing.T) {
+	cases := map[int]string{
+		http.StatusNotFound:             "not_found",
+		http.StatusUnprocessableEntity:  "unprocessable_entity",
+		http.StatusPreconditionRequired: "precondition_required",
+		http.StatusInternalServerError:  "internal_server_error",
+		http.StatusTeapot:               "im_a_teapot",
+		599:                             "unknown_error",
+	}
+	for status, want := range cases {
+		if got := StatusCode(status); got != want {
+			t.Errorf("StatusCode(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestWriteMergesExtensions(t *testing.T) {
+	p := New(http.StatusUnprocessableEntity, "invalid attributes").
+		WithCode("invalid_attributes").
+		With("fields", map[string]string{"ram_gb": "must be a number"}).
+		With("status", 200)
+	p.RequestID = "abc"
+
+	recorder := httptest.NewRecorder()
+	p.Write(recorder)
+
+	if got := recorder.Header().Get("Content-Type"); got != ContentType {
+		t.Fatalf("got content type %q", got)
+	}
+	if recorder.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got status %d", recorder.Code)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["type"] != "about:blank" || body["title"] != "Unprocessable Entity" || body["code"] != "invalid_attributes" || body["request_id"] != "abc" {
+		t.Fatalf("got body %v", body)
+	}
+	if body["status"] != float64(http.StatusUnprocessableEntity) {
+		t.Fatalf("an extension replaced the status: %v", body["status"])
+	}
+	if fields, ok := body["fields"].(map[string]interface{}); !ok || fields["ram_gb"] != "must be a number" {
+		t.Fatalf("got fields %v", body["fields"])
+	}
+}
+
+func TestInternalHidesDetailUnlessVerbose(t *testing.T) {
+	defer SetVerbose(Verbose())
+	err := errors.New("pq: relation \"products\" does not exist")
+
+	SetVerbose(false)
+	if p := Internal(err); p.Detail != "" || p.Code != "internal_server_error" {
+		t.Fatalf("got detail %q, code %q", p.Detail, p.Code)
+	}
+	SetVerbose(true)
+	if p := Internal(err); p.Detail != err.Error() {
+		t.Fatalf("got detail %q", p.Detail)
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	if got := RequestID("gw-1234.abcd_EF"); got != "gw-1234.abcd_EF" {
+		t.Fatalf("got %q for a valid id", got)
+	}
+	for _, header := range []string{"", "bad id", "line\nbreak", string(make([]byte, 129))} {
+		if got := RequestID(header); len(got) != 32 || got == header {
+			t.Fatalf("got %q for %q", got, header)
+		}
+	}
+}